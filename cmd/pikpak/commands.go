@@ -0,0 +1,925 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpak"
+)
+
+// maxCaptchaAttempts is how many times login asks the user to solve a
+// captcha before giving up.
+const maxCaptchaAttempts = 3
+
+func runLogin(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("login")
+	username := fs.String("username", "", "account email, phone number or username (prompted for if missing)")
+	password := fs.String("password", "", "account password; ends up in shell history, prefer the prompt or --password-stdin")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password from the first line of stdin")
+	keyring := fs.Bool("keyring", false, "store the tokens in the system keyring instead of the config file")
+	rest, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return usagef("unexpected arguments %q", rest)
+	}
+	if *passwordStdin && *password != "" {
+		return usagef("--password and --password-stdin cannot be combined")
+	}
+
+	if err := a.loadConfig(); err != nil {
+		return err
+	}
+	cfg := a.cfg
+	if *username != "" {
+		cfg.Username = *username
+	}
+	if *password != "" {
+		cfg.Password = *password
+	}
+	if err := a.readCredentials(cfg, *passwordStdin); err != nil {
+		return err
+	}
+	cfg.AccessToken, cfg.RefreshToken, cfg.EncodedToken = "", "", ""
+
+	c, err := pikpak.NewClientFromConfig(cfg, pikpak.WithLogger(pikpak.NewStdLogger(nil, false)))
+	if err != nil {
+		return err
+	}
+	if err := a.login(ctx, c); err != nil {
+		return err
+	}
+
+	if *keyring {
+		if err := c.EncodeToken(); err != nil {
+			return err
+		}
+		if err := a.newStore().Save(cfg.Username, c.GetEncodedToken()); err != nil {
+			return err
+		}
+		name, err := a.profileName()
+		if err != nil {
+			return err
+		}
+		saved := *cfg
+		saved.Password = ""
+		saved.UserID, saved.DeviceID = c.GetUserID(), c.GetDeviceID()
+		err = pikpak.SaveProfile(name, &saved)
+		if err != nil {
+			return err
+		}
+	} else if err := a.saveTokens(c); err != nil {
+		return err
+	}
+
+	return a.print(map[string]string{"username": cfg.Username, "user_id": c.GetUserID()}, func(w io.Writer) {
+		fmt.Fprintf(w, "Logged in as %s\n", cfg.Username)
+	})
+}
+
+// readCredentials completes the username and password in cfg, reading the
+// password from stdin with --password-stdin and prompting on the terminal
+// for anything else that is missing.
+func (a *app) readCredentials(cfg *pikpak.Config, passwordStdin bool) error {
+	if passwordStdin {
+		if cfg.Username == "" {
+			return usagef("--username is required with --password-stdin")
+		}
+		line, err := readLine(bufio.NewReader(a.stdin))
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("reading the password from stdin: %w", err)
+		}
+		if line == "" {
+			return usagef("no password on stdin")
+		}
+		cfg.Password = line
+		return nil
+	}
+	if cfg.Username != "" && cfg.Password != "" {
+		return nil
+	}
+	if a.prompt == nil {
+		return usagef("cannot prompt for credentials, stdin is not a terminal; use --username with --password-stdin")
+	}
+
+	if cfg.Username == "" {
+		name, err := a.prompt.readLine("Username: ")
+		if err != nil {
+			return err
+		}
+		if cfg.Username = strings.TrimSpace(name); cfg.Username == "" {
+			return usagef("a username is required")
+		}
+	}
+	if cfg.Password == "" {
+		password, err := a.prompt.readPassword("Password: ")
+		if err != nil {
+			return err
+		}
+		if password == "" {
+			return usagef("a password is required")
+		}
+		cfg.Password = password
+	}
+	return nil
+}
+
+// loginClient is the part of the client that login drives.
+type loginClient interface {
+	Login(ctx context.Context) error
+	CompleteCaptcha(ctx context.Context, captchaToken string) error
+}
+
+// login signs c in. When the server asks for a captcha, the challenge page
+// is printed and, on a terminal, the login continues with the captcha token
+// the user pastes, or is tried again when they just press Enter.
+func (a *app) login(ctx context.Context, c loginClient) error {
+	err := c.Login(ctx)
+	for attempt := 1; ; attempt++ {
+		var captcha *pikpak.CaptchaRequiredError
+		if !errors.As(err, &captcha) {
+			return err
+		}
+		fmt.Fprintf(a.stderr, "Verification required. Open this page and complete the captcha:\n  %s\n", captcha.URL)
+		if a.prompt == nil || attempt == maxCaptchaAttempts {
+			return err
+		}
+		line, perr := a.prompt.readLine("Paste the captcha token, or press Enter to try again once it is done: ")
+		if perr != nil {
+			return perr
+		}
+		if token := strings.TrimSpace(line); token != "" {
+			err = c.CompleteCaptcha(ctx, token)
+		} else {
+			err = c.Login(ctx)
+		}
+	}
+}
+
+func runWhoami(ctx context.Context, a *app, args []string) error {
+	if err := noArgs(a, "whoami", args); err != nil {
+		return err
+	}
+	if err := a.connect(); err != nil {
+		return err
+	}
+	name, err := a.profileName()
+	if err != nil {
+		return err
+	}
+
+	info := map[string]string{
+		"profile":  name,
+		"username": a.cfg.Username,
+		"user_id":  a.client.GetUserInfo()["user_id"],
+	}
+	return a.print(info, func(w io.Writer) {
+		fmt.Fprintf(w, "%s (user %s, profile %s)\n", info["username"], info["user_id"], name)
+	})
+}
+
+func runMe(ctx context.Context, a *app, args []string) error {
+	if err := noArgs(a, "me", args); err != nil {
+		return err
+	}
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	profile, err := a.client.GetMe(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(profile, func(w io.Writer) {
+		fmt.Fprintf(w, "%s (user %s, %s)\n", profile.Name, profile.UserID, profile.Status)
+		if profile.Email != "" {
+			fmt.Fprintf(w, "Email %s\n", profile.Email)
+		}
+		if profile.Phone != "" {
+			fmt.Fprintf(w, "Phone %s\n", profile.Phone)
+		}
+		if !profile.CreatedAt.IsZero() {
+			fmt.Fprintf(w, "Created %s\n", profile.CreatedAt.Format("2006-01-02"))
+		}
+		for _, provider := range profile.Providers {
+			fmt.Fprintf(w, "Sign-in %s %s\n", provider.ID, provider.ProviderUserID)
+		}
+	})
+}
+
+func runQuota(ctx context.Context, a *app, args []string) error {
+	if err := noArgs(a, "quota", args); err != nil {
+		return err
+	}
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	info, err := a.client.GetStorageInfo(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(info, func(w io.Writer) {
+		if info.IsUnlimited {
+			fmt.Fprintf(w, "Used %s (unlimited)\n", a.size(int64(info.UsedBytes)))
+		} else {
+			fmt.Fprintf(w, "Used %s of %s\n", a.size(int64(info.UsedBytes)), a.size(int64(info.TotalBytes)))
+		}
+		fmt.Fprintf(w, "Trash %s\n", a.size(int64(info.TrashBytes)))
+		fmt.Fprintf(w, "Account %s\n", info.UserType)
+	})
+}
+
+func runLs(ctx context.Context, a *app, args []string) error {
+	rest, err := parse(a.flagSet("ls"), args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 1 {
+		return usagef("at most one path")
+	}
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	p := ""
+	if len(rest) == 1 {
+		p = rest[0]
+	}
+	folder, err := a.resolveFolder(ctx, p)
+	if err != nil {
+		return err
+	}
+	entries, err := a.listFolder(ctx, folder.ID)
+	if err != nil {
+		return err
+	}
+	if entries == nil {
+		entries = []entry{}
+	}
+
+	rows := make([][]string, len(entries))
+	for i, e := range entries {
+		size, kind := a.size(e.Size), "file"
+		if e.Folder {
+			size, kind = "-", "folder"
+		}
+		rows[i] = []string{e.Name, size, kind, formatTime(e.Modified)}
+	}
+	return a.printTable(entries, []string{"NAME", "SIZE", "KIND", "MODIFIED"}, rows)
+}
+
+func runMkdir(ctx context.Context, a *app, args []string) error {
+	rest, err := parse(a.flagSet("mkdir"), args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 || len(splitPath(rest[0])) == 0 {
+		return usagef("one folder path is required")
+	}
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	parts := splitPath(rest[0])
+	parent, err := a.resolveFolder(ctx, path.Join(parts[:len(parts)-1]...))
+	if err != nil {
+		return err
+	}
+	result, err := a.client.CreateFolder(ctx, parts[len(parts)-1], parent.ID)
+	if err != nil {
+		return err
+	}
+
+	return a.print(result, func(w io.Writer) {
+		file, _ := result["file"].(map[string]interface{})
+		fmt.Fprintln(w, stringField(file, "id"))
+	})
+}
+
+func runRm(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("rm")
+	permanent := fs.Bool("permanent", false, "delete forever instead of moving to the trash")
+	rest, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) == 0 {
+		return usagef("at least one path is required")
+	}
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	ids, err := a.resolveAll(ctx, rest)
+	if err != nil {
+		return err
+	}
+	var result map[string]interface{}
+	if *permanent {
+		result, err = a.client.DeleteForever(ctx, ids)
+	} else {
+		result, err = a.client.DeleteToTrash(ctx, ids)
+	}
+	if err != nil {
+		return err
+	}
+
+	return a.print(result, func(w io.Writer) {
+		fmt.Fprintf(w, "Removed %d item(s)\n", len(ids))
+	})
+}
+
+func runMv(ctx context.Context, a *app, args []string) error {
+	return transfer(ctx, a, "mv", args, false)
+}
+
+func runCp(ctx context.Context, a *app, args []string) error {
+	return transfer(ctx, a, "cp", args, true)
+}
+
+// transfer moves, or with copyFiles copies, the sources into the destination
+// folder.
+func transfer(ctx context.Context, a *app, name string, args []string, copyFiles bool) error {
+	rest, err := parse(a.flagSet(name), args)
+	if err != nil {
+		return err
+	}
+	if len(rest) < 2 {
+		return usagef("a source and a destination folder are required")
+	}
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	op := a.client.Move
+	if copyFiles {
+		op = a.client.Copy
+	}
+
+	dest, err := a.resolveFolder(ctx, rest[len(rest)-1])
+	if err != nil {
+		return err
+	}
+	ids, err := a.resolveAll(ctx, rest[:len(rest)-1])
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := op(ctx, id, dest.ID); err != nil {
+			return err
+		}
+	}
+
+	return a.print(map[string]interface{}{"ids": ids, "parent_id": dest.ID}, func(w io.Writer) {
+		fmt.Fprintf(w, "%d item(s) to %s\n", len(ids), rest[len(rest)-1])
+	})
+}
+
+func runLink(ctx context.Context, a *app, args []string) error {
+	rest, err := parse(a.flagSet("link"), args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return usagef("one path is required")
+	}
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	ids, err := a.resolveAll(ctx, rest)
+	if err != nil {
+		return err
+	}
+	link, err := a.client.GetFileLink(ctx, ids[0])
+	if err != nil {
+		return err
+	}
+
+	return a.print(map[string]string{"id": ids[0], "url": link}, func(w io.Writer) {
+		fmt.Fprintln(w, link)
+	})
+}
+
+// downloadJobs is how many files download runs at once.
+const downloadJobs = 4
+
+func runDownload(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("download")
+	quiet := fs.Bool("quiet", false, "print progress as plain lines instead of bars")
+	rest, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) < 1 {
+		return usagef("a path and an optional destination are required")
+	}
+	paths, dest := rest, ""
+	if len(rest) > 1 {
+		paths, dest = rest[:len(rest)-1], rest[len(rest)-1]
+	}
+	if len(paths) > 1 {
+		if err := os.MkdirAll(dest, 0755); err != nil {
+			return err
+		}
+	}
+
+	progress := a.newProgressRenderer(*quiet)
+	if err := a.connect(pikpak.WithProgress(progress.Update)); err != nil {
+		return err
+	}
+
+	entries := make([]entry, len(paths))
+	targets := make([]string, len(paths))
+	for i, p := range paths {
+		e, err := a.resolve(ctx, p)
+		if err != nil {
+			return err
+		}
+		if e.Folder {
+			return fmt.Errorf("%s is a folder: %w", p, pikpak.ErrInvalidParameter)
+		}
+		entries[i] = e
+		targets[i] = e.Name
+		if dest != "" {
+			targets[i] = dest
+			if info, err := os.Stat(dest); err == nil && info.IsDir() {
+				targets[i] = filepath.Join(dest, e.Name)
+			}
+		}
+	}
+
+	errs := make([]error, len(entries))
+	sem := make(chan struct{}, downloadJobs)
+	var wg sync.WaitGroup
+	for i := range entries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() { <-sem; wg.Done() }()
+			if err := a.client.DownloadToFile(ctx, entries[i].ID, targets[i]); err != nil {
+				if errors.Is(err, context.Canceled) {
+					err = fmt.Errorf("interrupted, run the command again to resume %s.part: %w", targets[i], err)
+				}
+				errs[i] = err
+			}
+		}(i)
+	}
+	wg.Wait()
+	batchErr := pikpak.NewBatchError(len(entries))
+	for i, err := range errs {
+		batchErr.Add(paths[i], err)
+	}
+	if err := batchErr.Err(); err != nil {
+		return err
+	}
+
+	saved := make([]map[string]string, len(entries))
+	for i, e := range entries {
+		saved[i] = map[string]string{"id": e.ID, "path": targets[i]}
+	}
+	var v interface{} = saved
+	if len(saved) == 1 {
+		v = saved[0]
+	}
+	return a.print(v, func(w io.Writer) {
+		for _, target := range targets {
+			fmt.Fprintf(w, "Saved %s\n", target)
+		}
+	})
+}
+
+func runUpload(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("upload")
+	quiet := fs.Bool("quiet", false, "print progress as plain lines instead of bars")
+	rest, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) < 1 || len(rest) > 2 {
+		return usagef("a local file and an optional folder are required")
+	}
+	progress := a.newProgressRenderer(*quiet)
+	if err := a.connect(pikpak.WithProgress(progress.Update)); err != nil {
+		return err
+	}
+
+	folder := ""
+	if len(rest) == 2 {
+		folder = rest[1]
+	}
+	parent, err := a.resolveFolder(ctx, folder)
+	if err != nil {
+		return err
+	}
+	result, err := a.client.Upload(ctx, rest[0], parent.ID)
+	if err != nil {
+		return err
+	}
+
+	return a.print(result, func(w io.Writer) {
+		fmt.Fprintf(w, "Uploaded %s\n", filepath.Base(rest[0]))
+	})
+}
+
+// task is an offline download task.
+type task struct {
+	ID       string          `json:"id"`
+	Name     string          `json:"name"`
+	Phase    enums.PhaseType `json:"phase"`
+	Progress int             `json:"progress"`
+	Size     int64           `json:"file_size"`
+	FileID   string          `json:"file_id,omitempty"`
+	Message  string          `json:"message,omitempty"`
+	Created  time.Time       `json:"created_time"`
+}
+
+func newTask(m map[string]interface{}) task {
+	t := task{
+		ID:      stringField(m, "id"),
+		Name:    stringField(m, "name"),
+		Phase:   enums.ParsePhaseType(stringField(m, "phase")),
+		FileID:  stringField(m, "file_id"),
+		Message: stringField(m, "message"),
+	}
+	switch v := m["progress"].(type) {
+	case float64:
+		t.Progress = int(v)
+	case string:
+		t.Progress, _ = strconv.Atoi(v)
+	}
+	t.Size, _ = strconv.ParseInt(stringField(m, "file_size"), 10, 64)
+	t.Created, _ = time.Parse(time.RFC3339, stringField(m, "created_time"))
+	return t
+}
+
+// phaseName is the phase without its PHASE_TYPE_ prefix, e.g. "running".
+func (t task) phaseName() string {
+	return strings.ToLower(strings.TrimPrefix(string(t.Phase), "PHASE_TYPE_"))
+}
+
+func runOffline(ctx context.Context, a *app, args []string) error {
+	sub, args, err := subcommand(args, "add", "ls", "rm", "watch")
+	if err != nil {
+		return err
+	}
+
+	fs := a.flagSet("offline " + sub)
+	name := fs.String("name", "", "name of the downloaded file (add)")
+	file := fs.String("f", "", "read URLs from `FILE`, one per line, - for stdin (add)")
+	parent := fs.String("parent", "", "folder `PATH` to save into, created if missing (add)")
+	bestEffort := fs.Bool("best-effort", false, "exit 0 even if some URLs failed (add -f)")
+	phases := fs.String("phase", "", "comma separated task phases to list, e.g. PHASE_TYPE_COMPLETE (ls; default running and failed)")
+	deleteFiles := fs.Bool("delete-files", false, "also delete the downloaded files (rm)")
+	interval := fs.Duration("interval", pikpak.DefaultTaskPollInterval, "time between updates (watch)")
+	timeout := fs.Duration("timeout", 0, "give up after this long, 0 for never (watch)")
+	jsonStream := fs.Bool("json-stream", false, "print one JSON line per update (watch)")
+	rest, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	switch sub {
+	case "watch":
+		return watchTasks(ctx, a, rest, *interval, *timeout, *jsonStream)
+
+	case "add":
+		if *file != "" {
+			if len(rest) != 0 || *name != "" {
+				return usagef("-f cannot be combined with a URL or --name")
+			}
+			return addOfflineBatch(ctx, a, *file, *parent, *bestEffort)
+		}
+		if len(rest) != 1 {
+			return usagef("one URL is required")
+		}
+		if err := a.connect(); err != nil {
+			return err
+		}
+		folder, err := a.ensureFolder(ctx, *parent)
+		if err != nil {
+			return err
+		}
+		result, err := a.client.OfflineDownload(ctx, rest[0], folder.ID, *name)
+		if err != nil {
+			return err
+		}
+		return a.print(result, func(w io.Writer) {
+			task, _ := result["task"].(map[string]interface{})
+			fmt.Fprintf(w, "Task %s %s\n", stringField(task, "id"), stringField(task, "name"))
+		})
+
+	case "ls":
+		if len(rest) != 0 {
+			return usagef("unexpected arguments %q", rest)
+		}
+		if err := a.connect(); err != nil {
+			return err
+		}
+		var phaseList []string
+		if *phases != "" {
+			phaseList = splitList(*phases)
+		}
+		result, err := a.client.OfflineList(ctx, 0, "", phaseList)
+		if err != nil {
+			return err
+		}
+		raw, _ := result["tasks"].([]interface{})
+		tasks := make([]task, 0, len(raw))
+		rows := make([][]string, 0, len(raw))
+		for _, t := range raw {
+			m, ok := t.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			tk := newTask(m)
+			tasks = append(tasks, tk)
+			rows = append(rows, []string{tk.Name, tk.phaseName(), fmt.Sprintf("%d%%", tk.Progress), tk.ID})
+		}
+		return a.printTable(tasks, []string{"NAME", "PHASE", "PROGRESS", "ID"}, rows)
+
+	default:
+		if len(rest) == 0 {
+			return usagef("at least one task ID is required")
+		}
+		if err := a.connect(); err != nil {
+			return err
+		}
+		if err := a.client.DeleteOfflineTasks(ctx, rest, *deleteFiles); err != nil {
+			return err
+		}
+		return a.print(map[string]interface{}{"task_ids": rest}, func(w io.Writer) {
+			fmt.Fprintf(w, "Removed %d task(s)\n", len(rest))
+		})
+	}
+}
+
+// addOfflineBatch submits every URL listed in file, or on stdin for "-",
+// and prints one result row per URL.
+func addOfflineBatch(ctx context.Context, a *app, file, parent string, bestEffort bool) error {
+	urls, err := readURLs(a, file)
+	if err != nil {
+		return err
+	}
+	if len(urls) == 0 {
+		return usagef("no URLs in %s", file)
+	}
+	if err := a.connect(); err != nil {
+		return err
+	}
+	folder, err := a.ensureFolder(ctx, parent)
+	if err != nil {
+		return err
+	}
+
+	results, batchErr := a.client.OfflineDownloadBatch(ctx, urls, folder.ID)
+
+	type row struct {
+		URL       string `json:"url"`
+		TaskID    string `json:"task_id,omitempty"`
+		Duplicate bool   `json:"duplicate,omitempty"`
+		Error     string `json:"error,omitempty"`
+	}
+	rows := make([]row, len(results))
+	for i, r := range results {
+		rows[i] = row{URL: r.URL, TaskID: r.TaskID, Duplicate: r.Duplicate}
+		if r.Err != nil {
+			rows[i].Error = r.Err.Error()
+		}
+	}
+
+	table := make([][]string, len(rows))
+	for i, r := range rows {
+		switch {
+		case r.Error != "":
+			table[i] = []string{r.URL, "error", r.Error}
+		case r.Duplicate:
+			table[i] = []string{r.URL, "duplicate", r.TaskID}
+		default:
+			table[i] = []string{r.URL, "created", r.TaskID}
+		}
+	}
+	err = a.printTable(rows, []string{"URL", "RESULT", "TASK"}, table)
+	if err != nil || bestEffort {
+		return err
+	}
+	return batchErr
+}
+
+// readURLs reads one URL per line, skipping blank lines and # comments.
+func readURLs(a *app, file string) ([]string, error) {
+	r := a.stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var urls []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, line)
+	}
+	return urls, scanner.Err()
+}
+
+func runShare(ctx context.Context, a *app, args []string) error {
+	sub, args, err := subcommand(args, "create", "ls", "rm")
+	if err != nil {
+		return err
+	}
+
+	fs := a.flagSet("share " + sub)
+	needPassword := fs.Bool("password", false, "protect the share with a generated pass code (create)")
+	rest, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	switch sub {
+	case "create":
+		if len(rest) == 0 {
+			return usagef("at least one path is required")
+		}
+		if err := a.connect(); err != nil {
+			return err
+		}
+		ids, err := a.resolveAll(ctx, rest)
+		if err != nil {
+			return err
+		}
+		result, err := a.client.FileBatchShare(ctx, ids, *needPassword)
+		if err != nil {
+			return err
+		}
+		return a.print(result, func(w io.Writer) {
+			fmt.Fprintln(w, stringField(result, "share_url"))
+			if code := stringField(result, "pass_code"); code != "" {
+				fmt.Fprintf(w, "Pass code: %s\n", code)
+			}
+		})
+
+	case "ls":
+		if len(rest) != 0 {
+			return usagef("unexpected arguments %q", rest)
+		}
+		if err := a.connect(); err != nil {
+			return err
+		}
+		result, err := a.client.GetShareList(ctx, 0, "")
+		if err != nil {
+			return err
+		}
+		shares, _ := result["data"].([]interface{})
+		rows := make([][]string, 0, len(shares))
+		for _, s := range shares {
+			share, _ := s.(map[string]interface{})
+			rows = append(rows, []string{stringField(share, "share_id"), stringField(share, "share_url"), stringField(share, "title")})
+		}
+		return a.printTable(result, []string{"ID", "URL", "TITLE"}, rows)
+
+	default:
+		if len(rest) == 0 {
+			return usagef("at least one share ID is required")
+		}
+		if err := a.connect(); err != nil {
+			return err
+		}
+		for _, id := range rest {
+			if _, err := a.client.CancelShare(ctx, id); err != nil {
+				return err
+			}
+		}
+		return a.print(map[string]interface{}{"share_ids": rest}, func(w io.Writer) {
+			fmt.Fprintf(w, "Cancelled %d share(s)\n", len(rest))
+		})
+	}
+}
+
+// stringsFlag collects a repeatable flag.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return fmt.Sprint(*f)
+}
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+func runRestore(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("restore")
+	password := fs.String("password", "", "share pass code")
+	var fileIDs stringsFlag
+	fs.Var(&fileIDs, "file-id", "ID of a shared file to restore; repeatable (default all)")
+	rest, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return usagef("one share URL is required")
+	}
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	result, err := a.client.RestoreShareURL(ctx, rest[0], *password, fileIDs)
+	if err != nil {
+		return err
+	}
+	return a.print(result, func(w io.Writer) {
+		fmt.Fprintln(w, "Restored")
+	})
+}
+
+// runConfig imports a config saved by the Python pikpakapi library into the
+// selected profile, or into the file's default profile without --profile.
+// As with login, the password is not stored.
+func runConfig(ctx context.Context, a *app, args []string) error {
+	_, args, err := subcommand(args, "import")
+	if err != nil {
+		return err
+	}
+	rest, err := parse(a.flagSet("config import"), args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return usagef("one Python config file is required")
+	}
+
+	cfg, err := pikpak.ImportPython(rest[0])
+	var importErr *pikpak.ImportError
+	if errors.As(err, &importErr) {
+		fmt.Fprintf(a.stderr, "warning: %v\n", err)
+	} else if err != nil {
+		return err
+	}
+	cfg.Password = ""
+
+	path := os.Getenv(pikpak.ConfigEnvVar)
+	if path == "" {
+		if path, err = pikpak.DefaultPath(); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if a.profile != "" {
+		err = pikpak.SaveProfile(a.profile, cfg)
+	} else {
+		err = pikpak.SaveConfigAtomic(cfg, path)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Imported %s into %s\n", rest[0], path)
+	return nil
+}
+
+// subcommand splits the subcommand name off args.
+func subcommand(args []string, names ...string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, usagef("a subcommand is required")
+	}
+	for _, name := range names {
+		if args[0] == name {
+			return name, args[1:], nil
+		}
+	}
+	return "", nil, usagef("unknown subcommand %q", args[0])
+}
+
+// noArgs parses args for a command that takes no positional arguments.
+func noArgs(a *app, name string, args []string) error {
+	rest, err := parse(a.flagSet(name), args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return usagef("unexpected arguments %q", rest)
+	}
+	return nil
+}
@@ -0,0 +1,348 @@
+// Command pikpak is a command line client for PikPak Drive.
+//
+// Accounts are kept as profiles in the config file (see config.LoadProfile);
+// "pikpak login" signs in and stores the tokens there, or in the system
+// keyring with --keyring. Every other command uses the stored tokens.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpak"
+)
+
+// Exit codes. Library errors are mapped by their exception code.
+const (
+	exitOK       = 0
+	exitError    = 1
+	exitUsage    = 2
+	exitAuth     = 3
+	exitNotFound = 4
+	exitInvalid  = 5
+	exitNetwork  = 6
+	exitLimit    = 7
+
+	// exitInterrupted is the shell convention for a command stopped by
+	// SIGINT.
+	exitInterrupted = 130
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// app holds the global flags and the state shared by the commands.
+type app struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	// prompt is nil when stdin is not a terminal.
+	prompt prompter
+
+	profile  string
+	json     bool
+	bytes    bool
+	parentID string
+
+	cfg      *pikpak.Config
+	keyring  bool
+	client   pikpak.PikPakAPI
+	newStore func() pikpak.TokenStore
+	// newClient creates the client for connect; tests return a
+	// testsupport.FakeClient from it.
+	newClient func(cfg *pikpak.Config, opts ...pikpak.Option) (pikpak.PikPakAPI, error)
+}
+
+type command struct {
+	usage string
+	run   func(ctx context.Context, a *app, args []string) error
+}
+
+var commands = map[string]command{
+	"login":    {"login [--username NAME] [--password-stdin] [--keyring]", runLogin},
+	"whoami":   {"whoami", runWhoami},
+	"config":   {"config import PYTHON_CONFIG", runConfig},
+	"me":       {"me", runMe},
+	"quota":    {"quota", runQuota},
+	"ls":       {"ls [PATH]", runLs},
+	"mkdir":    {"mkdir PATH", runMkdir},
+	"rm":       {"rm [--permanent] PATH...", runRm},
+	"mv":       {"mv PATH... FOLDER", runMv},
+	"cp":       {"cp PATH... FOLDER", runCp},
+	"link":     {"link PATH", runLink},
+	"download": {"download [--quiet] PATH... [DEST]", runDownload},
+	"upload":   {"upload [--quiet] LOCAL [FOLDER]", runUpload},
+	"offline":  {"offline add [--parent PATH] [--name NAME] URL | offline add -f FILE [--parent PATH] [--best-effort] | offline ls [--phase PHASES] | offline rm [--delete-files] TASK_ID... | offline watch [--interval D] [--timeout D] [--json-stream] [TASK_ID...]", runOffline},
+	"share":    {"share create [--password] PATH... | share ls | share rm SHARE_ID...", runShare},
+	"restore":  {"restore [--password PASS] [--file-id ID]... SHARE_URL", runRestore},
+	"serve":    {"serve webdav [--addr ADDR] [--user USER --pass PASS] [--read-only] | serve proxy [--addr ADDR] [--token TOKEN] [--user USER --pass PASS]", runServe},
+}
+
+// usageError is a bad command line; it exits with exitUsage.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func usagef(format string, args ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	a := &app{
+		stdin:     stdin,
+		stdout:    stdout,
+		stderr:    stderr,
+		prompt:    newPrompter(stdin, stderr),
+		newStore:  newKeyringStore,
+		newClient: newAPIClient,
+	}
+
+	// Library log lines go to stderr with the errors, never into results.
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(stderr)
+
+	fs := a.flagSet("pikpak")
+	fs.Usage = func() { printUsage(stderr) }
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "pikpak: unknown command %q\n", fs.Arg(0))
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	if err := cmd.run(ctx, a, fs.Args()[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		fmt.Fprintf(stderr, "pikpak %s: %v\n", fs.Arg(0), err)
+		var uerr *usageError
+		if errors.As(err, &uerr) {
+			fmt.Fprintf(stderr, "usage: pikpak %s\n", cmd.usage)
+		}
+		return exitCode(err)
+	}
+	return exitOK
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: pikpak [--profile NAME] [--json] [--bytes] [--parent-id ID] COMMAND [ARGS]")
+	fmt.Fprintln(w, "\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\n", commands[name].usage)
+	}
+}
+
+// flagSet returns a flag set with the global flags, so they may be given
+// before or after the command name.
+func (a *app) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.StringVar(&a.profile, "profile", a.profile, "config profile to use")
+	fs.BoolVar(&a.json, "json", a.json, "print results as JSON")
+	fs.BoolVar(&a.bytes, "bytes", a.bytes, "print sizes in bytes")
+	fs.StringVar(&a.parentID, "parent-id", a.parentID, "folder ID that paths are resolved from")
+	return fs
+}
+
+// parse parses args with fs, allowing flags after positional arguments, and
+// returns the positional arguments.
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, &usageError{msg: err.Error()}
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func exitCode(err error) int {
+	var uerr *usageError
+	if errors.As(err, &uerr) {
+		return exitUsage
+	}
+	if errors.Is(err, context.Canceled) {
+		return exitInterrupted
+	}
+	if !pikpak.IsPikpakException(err) {
+		return exitError
+	}
+
+	switch pikpak.GetErrorCode(err) {
+	case pikpak.ErrCodeInvalidUsernamePassword, pikpak.ErrCodeInvalidCredentials,
+		pikpak.ErrCodeUsernamePasswordRequired, pikpak.ErrCodeInvalidAccessToken,
+		pikpak.ErrCodeInvalidEncodedToken, pikpak.ErrCodeUnauthorized, pikpak.ErrCodeForbidden,
+		pikpak.ErrCodeCaptchaRequired, pikpak.ErrCodeCaptchaTokenFailed, pikpak.ErrCodeMissingRefreshToken,
+		pikpak.ErrCodeVerificationCodeInvalid, pikpak.ErrCodeVerificationCodeExpired:
+		return exitAuth
+	case pikpak.ErrCodeNotFound, pikpak.ErrCodeFileNotFound, pikpak.ErrCodeShareExpired:
+		return exitNotFound
+	case pikpak.ErrCodeInvalidParameter, pikpak.ErrCodeInvalidFileID, pikpak.ErrCodeInvalidFileName,
+		pikpak.ErrCodeEmptyFileIDs, pikpak.ErrCodeInvalidURL, pikpak.ErrCodeInvalidShareURL,
+		pikpak.ErrCodeSharePasswordWrong, pikpak.ErrCodeConflict,
+		pikpak.ErrCodeInviteCodeInvalid, pikpak.ErrCodeInviteCodeUsed:
+		return exitInvalid
+	case pikpak.ErrCodeNetworkError, pikpak.ErrCodeTimeout, pikpak.ErrCodeServerError,
+		pikpak.ErrCodeInternalServerError, pikpak.ErrCodeServiceUnavailable,
+		pikpak.ErrCodeMaxRetriesReached, pikpak.ErrCodeMaxRetriesExceeded:
+		return exitNetwork
+	case pikpak.ErrCodeTooManyRequests, pikpak.ErrCodeQuotaExceeded,
+		pikpak.ErrCodeTaskDailyLimitExceeded, pikpak.ErrCodePremiumRequired:
+		return exitLimit
+	}
+	return exitError
+}
+
+// loadConfig reads the selected profile, or starts from the environment
+// when there is no such profile yet.
+func (a *app) loadConfig() error {
+	cfg, err := pikpak.LoadProfile(a.profile)
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, pikpak.ErrProfileNotFound) {
+		cfg, err = pikpak.FromEnv()
+	}
+	if err != nil {
+		return err
+	}
+	a.cfg = cfg
+	return nil
+}
+
+func (a *app) profileName() (string, error) {
+	if a.profile != "" {
+		return a.profile, nil
+	}
+	return pikpak.DefaultProfileName()
+}
+
+// newKeyringStore stores tokens in the system keyring or, with a warning
+// where there is none, in tokens.json next to the config file.
+func newKeyringStore() pikpak.TokenStore {
+	var fallback pikpak.TokenStore
+	path := os.Getenv(pikpak.ConfigEnvVar)
+	if path == "" {
+		path, _ = pikpak.DefaultPath()
+	}
+	if path != "" {
+		fallback = pikpak.NewFileTokenStore(filepath.Join(filepath.Dir(path), "tokens.json"))
+	}
+	store := pikpak.NewKeyringTokenStore(pikpak.SystemKeyring(), fallback)
+	store.SetLogger(pikpak.NewStdLogger(nil, false))
+	return store
+}
+
+// connect creates the client for the selected profile, with extra applied
+// last. A profile without tokens takes them from the system keyring;
+// refreshed tokens are written back to wherever they came from.
+func (a *app) connect(extra ...pikpak.Option) error {
+	if err := a.loadConfig(); err != nil {
+		return err
+	}
+	cfg := a.cfg
+
+	opts := []pikpak.Option{pikpak.WithLogger(pikpak.NewStdLogger(nil, false))}
+	a.keyring = cfg.AccessToken == "" && cfg.RefreshToken == "" && cfg.EncodedToken == "" && cfg.Username != ""
+	if a.keyring {
+		opts = append(opts, pikpak.WithTokenStore(a.newStore(), cfg.Username))
+	} else {
+		opts = append(opts, pikpak.WithTokenRefreshCallback(func(c *pikpak.Client) {
+			if err := a.saveTokens(c); err != nil {
+				fmt.Fprintf(a.stderr, "pikpak: failed to save refreshed tokens: %v\n", err)
+			}
+		}))
+	}
+
+	c, err := a.newClient(cfg, append(opts, extra...)...)
+	if err != nil {
+		return err
+	}
+	a.client = c
+	return nil
+}
+
+// newAPIClient creates the PikPak client for cfg and fails when there are no
+// tokens to sign in with.
+func newAPIClient(cfg *pikpak.Config, opts ...pikpak.Option) (pikpak.PikPakAPI, error) {
+	c, err := pikpak.NewClientFromConfig(cfg, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if c.GetAccessToken() == "" && c.GetRefreshToken() == "" {
+		return nil, fmt.Errorf("not logged in, run pikpak login: %w", pikpak.ErrUnauthorized)
+	}
+	return c, nil
+}
+
+// tokenSource is a client whose tokens can be copied into a config.
+type tokenSource interface {
+	ApplyToConfig(cfg *pikpak.Config) error
+}
+
+// saveTokens stores the client's tokens in the profile. The password is
+// never written.
+func (a *app) saveTokens(c tokenSource) error {
+	if err := c.ApplyToConfig(a.cfg); err != nil {
+		return err
+	}
+	name, err := a.profileName()
+	if err != nil {
+		return err
+	}
+	saved := *a.cfg
+	saved.Password = ""
+	return pikpak.SaveProfile(name, &saved)
+}
+
+func stringField(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,622 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpak"
+	"github.com/zhz8888/pikpakapi-go/pkg/testsupport"
+)
+
+// stubServer answers the drive and user APIs for the commands under test and
+// records every request as "METHOD /path".
+type stubServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []string
+	bodies   map[string]map[string]interface{}
+}
+
+func newStubServer(t *testing.T, handle func(w http.ResponseWriter, r *http.Request) bool) *stubServer {
+	s := &stubServer{bodies: map[string]map[string]interface{}{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.Path
+		raw, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(raw))
+		var body map[string]interface{}
+		json.Unmarshal(raw, &body)
+		s.mu.Lock()
+		s.requests = append(s.requests, key)
+		s.bodies[key] = body
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if handle != nil && handle(w, r) {
+			return
+		}
+		switch {
+		case r.URL.Path == "/drive/v1/files" && r.Method == http.MethodGet:
+			files := map[string][]interface{}{
+				"": {
+					map[string]interface{}{"id": "d1", "name": "docs", "kind": "drive#folder"},
+				},
+				"d1": {
+					map[string]interface{}{"id": "f1", "name": "a.txt", "kind": "drive#file", "size": "2048"},
+					map[string]interface{}{"id": "d2", "name": "sub", "kind": "drive#folder"},
+				},
+			}[r.URL.Query().Get("parent_id")]
+			json.NewEncoder(w).Encode(map[string]interface{}{"files": files})
+		default:
+			json.NewEncoder(w).Encode(map[string]interface{}{})
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *stubServer) body(key string) map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bodies[key]
+}
+
+func (s *stubServer) requested(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.requests {
+		if r == key {
+			return true
+		}
+	}
+	return false
+}
+
+// setupProfile points the config at a fresh file holding one profile that
+// talks to server, logged in unless loggedIn is false.
+func setupProfile(t *testing.T, server *stubServer, loggedIn bool) string {
+	for _, name := range []string{pikpak.EnvUsername, pikpak.EnvPassword, pikpak.EnvAccessToken, pikpak.EnvRefreshToken, pikpak.EnvEncodedToken, pikpak.EnvDeviceID, pikpak.EnvProxy, pikpak.EnvBaseURL} {
+		t.Setenv(name, "")
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv(pikpak.ConfigEnvVar, path)
+
+	cfg := &pikpak.Config{
+		DriveBaseURL:     server.URL,
+		UserBaseURL:      server.URL,
+		InitialBackoffMS: 1,
+		DeviceID:         "device",
+	}
+	if loggedIn {
+		cfg.AccessToken, cfg.RefreshToken = "access", "refresh"
+	}
+	if err := pikpak.SaveProfile("default", cfg); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func runCLI(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	return runCLIWithInput(t, "", args...)
+}
+
+func runCLIWithInput(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestLogin(t *testing.T) {
+	server := newStubServer(t, func(w http.ResponseWriter, r *http.Request) bool {
+		switch r.URL.Path {
+		case "/v1/shield/captcha/init":
+			json.NewEncoder(w).Encode(map[string]interface{}{"captcha_token": "captcha"})
+		case "/v1/auth/signin":
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "new_access", "refresh_token": "new_refresh", "sub": "user1"})
+		default:
+			return false
+		}
+		return true
+	})
+	path := setupProfile(t, server, false)
+
+	code, stdout, stderr := runCLI(t, "login", "--username", "me@example.com", "--password", "secret")
+	if code != exitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "me@example.com") {
+		t.Errorf("Expected the username in the output, got %q", stdout)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "new_refresh") || strings.Contains(string(data), "secret") {
+		t.Errorf("Expected tokens and no password in the profile, got %s", data)
+	}
+
+	code, stdout, _ = runCLI(t, "--json", "whoami")
+	var info map[string]string
+	if err := json.Unmarshal([]byte(stdout), &info); err != nil || code != exitOK {
+		t.Fatalf("Expected JSON output, got %d %q", code, stdout)
+	}
+	if info["user_id"] != "user1" || info["username"] != "me@example.com" || info["profile"] != "default" {
+		t.Errorf("Unexpected whoami output %v", info)
+	}
+}
+
+func TestMe(t *testing.T) {
+	server := newStubServer(t, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path != "/v1/user/me" {
+			return false
+		}
+		w.Write([]byte(`{"sub":"user1","name":"alice","email":"alice@example.com","status":"ACTIVE","created_at":"2022-03-17T08:12:45Z","providers":[{"id":"email","provider_user_id":"alice@example.com"}]}`))
+		return true
+	})
+	setupProfile(t, server, true)
+
+	code, stdout, stderr := runCLI(t, "me")
+	if code != exitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, stderr)
+	}
+	for _, want := range []string{"alice (user user1, ACTIVE)", "Email alice@example.com", "Created 2022-03-17", "Sign-in email alice@example.com"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Expected %q in the output, got %q", want, stdout)
+		}
+	}
+}
+
+func TestLs(t *testing.T) {
+	server := newStubServer(t, nil)
+	setupProfile(t, server, true)
+
+	code, stdout, stderr := runCLI(t, "ls", "docs")
+	if code != exitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "a.txt") || !strings.Contains(stdout, "2.0 KiB") || !strings.Contains(stdout, "folder") {
+		t.Errorf("Unexpected listing %q", stdout)
+	}
+
+	code, stdout, _ = runCLI(t, "ls", "/docs/", "--json")
+	var entries []entry
+	if err := json.Unmarshal([]byte(stdout), &entries); err != nil || code != exitOK {
+		t.Fatalf("Expected a JSON listing, got %d %q", code, stdout)
+	}
+	if len(entries) != 2 || entries[0].ID != "f1" || entries[0].Size != 2048 || !entries[1].Folder {
+		t.Errorf("Unexpected entries %+v", entries)
+	}
+
+	code, stdout, _ = runCLI(t, "--parent-id", "d1", "ls")
+	if code != exitOK || !strings.Contains(stdout, "a.txt") {
+		t.Errorf("Expected --parent-id to list docs, got %d %q", code, stdout)
+	}
+}
+
+func TestFileCommands(t *testing.T) {
+	server := newStubServer(t, nil)
+	setupProfile(t, server, true)
+
+	tests := []struct {
+		args []string
+		key  string
+		want map[string]interface{}
+	}{
+		{[]string{"mkdir", "docs/new"}, "POST /drive/v1/files", map[string]interface{}{"name": "new", "parent_id": "d1"}},
+		{[]string{"mv", "docs/a.txt", "docs/sub"}, "POST /drive/v1/files:batchMove", map[string]interface{}{"to": map[string]interface{}{"parent_id": "d2"}}},
+		{[]string{"cp", "docs/a.txt", "docs/sub"}, "POST /drive/v1/files:batchCopy", map[string]interface{}{"to": map[string]interface{}{"parent_id": "d2"}}},
+		{[]string{"rm", "docs/a.txt"}, "POST /drive/v1/files:batchTrash", map[string]interface{}{"ids": []interface{}{"f1"}}},
+		{[]string{"rm", "--permanent", "docs/sub"}, "POST /drive/v1/files:batchDelete", map[string]interface{}{"ids": []interface{}{"d2"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			code, _, stderr := runCLI(t, tt.args...)
+			if code != exitOK {
+				t.Fatalf("Expected exit 0, got %d: %s", code, stderr)
+			}
+			body := server.body(tt.key)
+			for k, v := range tt.want {
+				got, _ := json.Marshal(body[k])
+				want, _ := json.Marshal(v)
+				if string(got) != string(want) {
+					t.Errorf("%s: expected %s=%s, got %s", tt.key, k, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestLinkAndDownload(t *testing.T) {
+	var server *stubServer
+	server = newStubServer(t, func(w http.ResponseWriter, r *http.Request) bool {
+		switch r.URL.Path {
+		case "/drive/v1/files/f1":
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": "f1", "web_content_link": server.URL + "/content/a.txt"})
+		case "/content/a.txt":
+			w.Write([]byte("hello"))
+		default:
+			return false
+		}
+		return true
+	})
+	setupProfile(t, server, true)
+
+	code, stdout, stderr := runCLI(t, "link", "docs/a.txt")
+	if code != exitOK || strings.TrimSpace(stdout) != server.URL+"/content/a.txt" {
+		t.Fatalf("Expected the link, got %d %q %s", code, stdout, stderr)
+	}
+
+	dir := t.TempDir()
+	code, _, stderr = runCLI(t, "download", "docs/a.txt", dir)
+	if code != exitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, stderr)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "a.txt"))
+	if err != nil || string(data) != "hello" {
+		t.Errorf("Expected the downloaded file, got %q, %v", data, err)
+	}
+}
+
+func TestDownloadMultiple(t *testing.T) {
+	var server *stubServer
+	server = newStubServer(t, func(w http.ResponseWriter, r *http.Request) bool {
+		switch {
+		case r.URL.Path == "/drive/v1/files" && r.URL.Query().Get("parent_id") == "d1":
+			json.NewEncoder(w).Encode(map[string]interface{}{"files": []interface{}{
+				map[string]interface{}{"id": "f1", "name": "a.txt", "kind": "drive#file"},
+				map[string]interface{}{"id": "f2", "name": "b.txt", "kind": "drive#file"},
+			}})
+		case strings.HasPrefix(r.URL.Path, "/drive/v1/files/"):
+			id := path.Base(r.URL.Path)
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": id, "web_content_link": server.URL + "/content/" + id})
+		case strings.HasPrefix(r.URL.Path, "/content/"):
+			w.Write([]byte(path.Base(r.URL.Path)))
+		default:
+			return false
+		}
+		return true
+	})
+	setupProfile(t, server, true)
+
+	dir := filepath.Join(t.TempDir(), "out")
+	code, stdout, stderr := runCLI(t, "download", "--quiet", "docs/a.txt", "docs/b.txt", dir)
+	if code != exitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, stderr)
+	}
+	for name, want := range map[string]string{"a.txt": "f1", "b.txt": "f2"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(data) != want {
+			t.Errorf("Expected %s to hold %q, got %q, %v", name, want, data, err)
+		}
+		if !strings.Contains(stdout, "Saved "+filepath.Join(dir, name)) {
+			t.Errorf("Expected %s in the output, got %q", name, stdout)
+		}
+		if !strings.Contains(stderr, name+" 100%") {
+			t.Errorf("Expected a progress line for %s, got %q", name, stderr)
+		}
+	}
+}
+
+func TestDownloadInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var server *stubServer
+	server = newStubServer(t, func(w http.ResponseWriter, r *http.Request) bool {
+		switch r.URL.Path {
+		case "/drive/v1/files/f1":
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": "f1", "web_content_link": server.URL + "/content/a.txt"})
+		case "/content/a.txt":
+			w.Header().Set("Content-Length", "10")
+			w.Write([]byte("hello"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			return false
+		}
+		return true
+	})
+	setupProfile(t, server, true)
+
+	dest := filepath.Join(t.TempDir(), "a.txt")
+	go func() {
+		for {
+			if info, err := os.Stat(dest + ".part"); err == nil && info.Size() == 5 {
+				cancel()
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	var stdout, stderr bytes.Buffer
+	code := run(ctx, []string{"download", "docs/a.txt", dest}, strings.NewReader(""), &stdout, &stderr)
+	if code != exitInterrupted {
+		t.Fatalf("Expected exit %d, got %d: %s", exitInterrupted, code, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "resume") {
+		t.Errorf("Expected a resume hint, got %q", stderr.String())
+	}
+	if data, err := os.ReadFile(dest + ".part"); err != nil || string(data) != "hello" {
+		t.Errorf("Expected the .part file to be kept, got %q, %v", data, err)
+	}
+}
+
+func TestOfflineCommands(t *testing.T) {
+	server := newStubServer(t, nil)
+	setupProfile(t, server, true)
+
+	if code, _, stderr := runCLI(t, "--parent-id", "d1", "offline", "add", "--name", "x", "magnet:?xt=urn:btih:abc"); code != exitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, stderr)
+	}
+	body := server.body("POST /drive/v1/files")
+	if body["parent_id"] != "d1" || body["name"] != "x" {
+		t.Errorf("Unexpected offline download body %v", body)
+	}
+
+	if code, _, stderr := runCLI(t, "offline", "ls", "--phase", "PHASE_TYPE_COMPLETE"); code != exitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, stderr)
+	}
+	if !server.requested("GET /drive/v1/tasks") {
+		t.Error("Expected the task list to be requested")
+	}
+
+	if code, _, stderr := runCLI(t, "offline", "rm", "t1", "t2"); code != exitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, stderr)
+	}
+	if !server.requested("DELETE /drive/v1/tasks") {
+		t.Error("Expected the tasks to be deleted")
+	}
+}
+
+func TestCommandsWithFakeClient(t *testing.T) {
+	server := newStubServer(t, func(w http.ResponseWriter, r *http.Request) bool {
+		t.Errorf("Expected the fake client to answer, got %s %s", r.Method, r.URL.Path)
+		return false
+	})
+	setupProfile(t, server, true)
+
+	fake := testsupport.NewFakeClient().
+		On("FileList", map[string]interface{}{"files": []interface{}{
+			map[string]interface{}{"id": "d1", "name": "docs", "kind": "drive#folder"},
+		}}, nil).
+		On("OfflineDownload", map[string]interface{}{"task": map[string]interface{}{"id": "t1", "name": "x"}}, nil)
+	var stdout, stderr bytes.Buffer
+	a := &app{
+		stdin:  strings.NewReader(""),
+		stdout: &stdout,
+		stderr: &stderr,
+		newClient: func(cfg *pikpak.Config, opts ...pikpak.Option) (pikpak.PikPakAPI, error) {
+			return fake, nil
+		},
+	}
+	if err := runOffline(context.Background(), a, []string{"add", "--parent", "docs", "--name", "x", "magnet:?xt=urn:btih:abc"}); err != nil {
+		t.Fatalf("Expected no error, got %v: %s", err, stderr.String())
+	}
+
+	calls := fake.CallsTo("OfflineDownload")
+	if len(calls) != 1 || calls[0].Args[0] != "magnet:?xt=urn:btih:abc" || calls[0].Args[1] != "d1" || calls[0].Args[2] != "x" {
+		t.Errorf("Unexpected offline download calls %+v", calls)
+	}
+	if !strings.Contains(stdout.String(), "Task t1 x") {
+		t.Errorf("Unexpected output %q", stdout.String())
+	}
+}
+
+func TestOfflineAddFromFile(t *testing.T) {
+	var mu sync.Mutex
+	var submitted []string
+	server := newStubServer(t, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method != http.MethodPost || r.URL.Path != "/drive/v1/files" {
+			return false
+		}
+		var body struct {
+			Kind string `json:"kind"`
+			URL  struct {
+				URL string `json:"url"`
+			} `json:"url"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Kind == "drive#folder" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"file": map[string]interface{}{"id": "new", "name": "queue", "kind": "drive#folder"}})
+			return true
+		}
+		mu.Lock()
+		submitted = append(submitted, body.URL.URL)
+		n := len(submitted)
+		mu.Unlock()
+		if strings.HasSuffix(body.URL.URL, "limited.iso") {
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error": "task_daily_create_limit"})
+			return true
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"task": map[string]interface{}{"id": fmt.Sprintf("t%d", n)}})
+		return true
+	})
+	setupProfile(t, server, true)
+
+	code, stdout, stderr := runCLI(t, "--json", "offline", "add", "-f", "testdata/urls.txt", "--parent", "docs/queue")
+	if code != exitInvalid {
+		t.Fatalf("Expected the exit code of the first failure, got %d: %s", code, stderr)
+	}
+	if !strings.Contains(stderr, "2 of 5 items failed") {
+		t.Errorf("Expected the batch failures to be summarized, got %q", stderr)
+	}
+	var rows []map[string]interface{}
+	if err := json.Unmarshal([]byte(stdout), &rows); err != nil {
+		t.Fatalf("Expected a JSON result table, got %q: %v", stdout, err)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("Expected one row per URL, got %v", rows)
+	}
+	if rows[0]["task_id"] == nil || rows[1]["task_id"] == nil {
+		t.Errorf("Expected created tasks, got %v", rows[:2])
+	}
+	if rows[2]["duplicate"] != true || rows[2]["task_id"] != rows[0]["task_id"] {
+		t.Errorf("Expected the repeated URL to be a duplicate, got %v", rows[2])
+	}
+	if rows[3]["error"] == nil || rows[4]["error"] == nil {
+		t.Errorf("Expected errors for the bad magnet and the limited URL, got %v", rows[3:])
+	}
+	if len(submitted) != 3 {
+		t.Errorf("Expected 3 submissions, got %v", submitted)
+	}
+	if !server.requested("POST /drive/v1/files") || server.body("POST /drive/v1/files")["parent_id"] != "new" {
+		t.Errorf("Expected tasks to go into the created folder, got %v", server.body("POST /drive/v1/files"))
+	}
+
+	code, stdout, stderr = runCLIWithInput(t, "https://example.com/b.iso\nhttps://example.com/limited.iso\n", "offline", "add", "-f", "-", "--best-effort")
+	if code != exitOK {
+		t.Fatalf("Expected exit 0 with --best-effort, got %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "https://example.com/b.iso") || !strings.Contains(stdout, "created") || !strings.Contains(stdout, "error") {
+		t.Errorf("Expected a result table, got %q", stdout)
+	}
+}
+
+func TestShareCommands(t *testing.T) {
+	server := newStubServer(t, func(w http.ResponseWriter, r *http.Request) bool {
+		switch r.URL.Path {
+		case "/drive/v1/files:batchShare":
+			json.NewEncoder(w).Encode(map[string]interface{}{"share_url": "https://mypikpak.com/s/abc", "pass_code": "1234"})
+		case "/drive/v1/share/file/list":
+			json.NewEncoder(w).Encode(map[string]interface{}{"files": []interface{}{map[string]interface{}{"id": "s1"}}})
+		default:
+			return false
+		}
+		return true
+	})
+	setupProfile(t, server, true)
+
+	code, stdout, stderr := runCLI(t, "share", "create", "--password", "docs/a.txt")
+	if code != exitOK || !strings.Contains(stdout, "https://mypikpak.com/s/abc") || !strings.Contains(stdout, "1234") {
+		t.Fatalf("Unexpected share output %d %q %s", code, stdout, stderr)
+	}
+	if body := server.body("POST /drive/v1/files:batchShare"); body["setting"].(map[string]interface{})["need_password"] != true {
+		t.Errorf("Expected need_password, got %v", body)
+	}
+
+	if code, _, stderr := runCLI(t, "share", "ls"); code != exitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, stderr)
+	}
+	if code, _, stderr := runCLI(t, "share", "rm", "share1"); code != exitOK || !server.requested("POST /drive/v1/share/share1/cancel") {
+		t.Fatalf("Expected the share to be cancelled, got %d: %s", code, stderr)
+	}
+
+	if code, _, stderr := runCLI(t, "restore", "https://mypikpak.com/share/link/abc"); code != exitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, stderr)
+	}
+	body := server.body("POST /share/v1/file/restore")
+	if body["share_id"] != "abc" || len(body["file_ids"].([]interface{})) != 1 {
+		t.Errorf("Unexpected restore body %v", body)
+	}
+}
+
+func TestConfigImport(t *testing.T) {
+	server := newStubServer(t, nil)
+	path := setupProfile(t, server, true)
+	dump := filepath.Join(t.TempDir(), "pikpak.json")
+	os.WriteFile(dump, []byte(`{
+  "username": "py@example.com",
+  "password": "secret",
+  "device_id": "py_device",
+  "token": {"access_token": "py_access", "refresh_token": "py_refresh", "sub": "py_user"},
+  "proxy_url": "http://127.0.0.1:7890"
+}`), 0600)
+
+	code, stdout, stderr := runCLI(t, "--profile", "python", "config", "import", dump)
+	if code != exitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "Imported "+dump+" into "+path) {
+		t.Errorf("Unexpected output %q", stdout)
+	}
+	if !strings.Contains(stderr, "unknown fields: proxy_url") {
+		t.Errorf("Expected a warning about the unknown field, got %q", stderr)
+	}
+
+	cfg, err := pikpak.LoadProfile("python")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Username != "py@example.com" || cfg.AccessToken != "py_access" || cfg.RefreshToken != "py_refresh" || cfg.UserID != "py_user" || cfg.DeviceID != "py_device" {
+		t.Errorf("Unexpected imported profile %+v", cfg)
+	}
+	if cfg.Password != "" {
+		t.Error("Expected the password not to be stored")
+	}
+	if cfg, _ := pikpak.LoadProfile("default"); cfg == nil || cfg.AccessToken != "access" {
+		t.Errorf("Expected the default profile to be kept, got %+v", cfg)
+	}
+
+	// Without --profile a new config file gets the import as its default.
+	path = filepath.Join(t.TempDir(), "new", "config.json")
+	t.Setenv(pikpak.ConfigEnvVar, path)
+	if code, _, stderr := runCLI(t, "config", "import", dump); code != exitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, stderr)
+	}
+	if cfg, err := pikpak.LoadProfile(""); err != nil || cfg.RefreshToken != "py_refresh" {
+		t.Errorf("Expected the import in %s, got %+v, %v", path, cfg, err)
+	}
+
+	if code, _, _ := runCLI(t, "config", "import"); code != exitUsage {
+		t.Errorf("Expected exit %d without a file, got %d", exitUsage, code)
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	server := newStubServer(t, func(w http.ResponseWriter, r *http.Request) bool {
+		switch r.URL.Path {
+		case "/drive/v1/files/f1":
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error": "file_not_found"})
+		case "/drive/v1/about":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			return false
+		}
+		return true
+	})
+
+	tests := []struct {
+		name     string
+		loggedIn bool
+		args     []string
+		want     int
+	}{
+		{"no command", true, nil, exitUsage},
+		{"unknown command", true, []string{"frobnicate"}, exitUsage},
+		{"bad flag", true, []string{"ls", "--nope"}, exitUsage},
+		{"missing argument", true, []string{"link"}, exitUsage},
+		{"unknown subcommand", true, []string{"offline", "pause"}, exitUsage},
+		{"help", true, []string{"-h"}, exitOK},
+		{"not logged in", false, []string{"ls"}, exitAuth},
+		{"missing path", true, []string{"link", "docs/nope.txt"}, exitNotFound},
+		{"server not found", true, []string{"link", "docs/a.txt"}, exitNotFound},
+		{"not a folder", true, []string{"ls", "docs/a.txt"}, exitInvalid},
+		{"server error", true, []string{"quota"}, exitNetwork},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupProfile(t, server, tt.loggedIn)
+			if code, _, stderr := runCLI(t, tt.args...); code != tt.want {
+				t.Errorf("Expected exit %d, got %d: %s", tt.want, code, stderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+//go:build fuse
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpakfuse"
+)
+
+func init() {
+	commands["mount"] = command{"mount [--cache-ttl D] MOUNTPOINT", runMount}
+}
+
+func runMount(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("mount")
+	ttl := fs.Duration("cache-ttl", pikpakfuse.DefaultCacheTTL, "how long listings and attributes are cached")
+	rest, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return usagef("mount takes exactly one mount point")
+	}
+
+	if err := a.connect(); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stderr, "Mounted read-only at %s, press Ctrl-C to unmount\n", rest[0])
+	return pikpakfuse.Mount(ctx, rest[0], a.client, pikpakfuse.WithCacheTTL(*ttl))
+}
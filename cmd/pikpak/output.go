@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// print writes v as indented JSON with --json, or calls human otherwise.
+// JSON results are a single value, an object or an array, so they can be
+// piped as they are; everything else goes to stderr.
+func (a *app) print(v interface{}, human func(w io.Writer)) error {
+	if a.json {
+		enc := json.NewEncoder(a.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	human(a.stdout)
+	return nil
+}
+
+// printTable is print for results shown as a table: header and rows are
+// written as aligned columns unless --json is set.
+func (a *app) printTable(v interface{}, header []string, rows [][]string) error {
+	return a.print(v, func(w io.Writer) {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		tw.Flush()
+	})
+}
+
+// size formats n for tables: humanized, or exact with --bytes.
+func (a *app) size(n int64) string {
+	if a.bytes {
+		return strconv.FormatInt(n, 10)
+	}
+	return formatBytes(n)
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatTime formats t in its own zone, as the server sent it; "-" when
+// unknown.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("2006-01-02 15:04")
+}
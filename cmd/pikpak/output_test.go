@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// checkGolden compares got with testdata/name, or rewrites the file with
+// -update.
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("Output differs from %s\nwant:\n%s\ngot:\n%s", path, want, got)
+	}
+}
+
+func TestOutputGolden(t *testing.T) {
+	server := newStubServer(t, func(w http.ResponseWriter, r *http.Request) bool {
+		switch {
+		case r.URL.Path == "/drive/v1/files" && r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(map[string]interface{}{"files": []interface{}{
+				map[string]interface{}{"id": "d1", "name": "Movies", "kind": "drive#folder", "modified_time": "2024-03-01T08:30:00+08:00"},
+				map[string]interface{}{"id": "f1", "name": "notes.txt", "kind": "drive#file", "size": "512", "modified_time": "2024-03-02T10:00:00Z"},
+				map[string]interface{}{"id": "f2", "name": "ubuntu-24.04-desktop-amd64.iso", "kind": "drive#file", "size": "6114656256", "modified_time": "2024-04-25T16:45:12Z"},
+			}})
+		case r.URL.Path == "/drive/v1/tasks":
+			json.NewEncoder(w).Encode(map[string]interface{}{"tasks": []interface{}{
+				map[string]interface{}{"id": "t1", "name": "ubuntu-24.04-desktop-amd64.iso", "phase": "PHASE_TYPE_RUNNING", "progress": 42, "file_size": "6114656256", "file_id": "f2", "created_time": "2024-04-25T16:40:00Z"},
+				map[string]interface{}{"id": "t2", "name": "broken.zip", "phase": "PHASE_TYPE_ERROR", "progress": 0, "message": "resource not found", "created_time": "2024-04-25T16:41:00Z"},
+			}})
+		default:
+			return false
+		}
+		return true
+	})
+	setupProfile(t, server, true)
+
+	tests := []struct {
+		golden string
+		args   []string
+	}{
+		{"ls.golden", []string{"ls"}},
+		{"ls_bytes.golden", []string{"ls", "--bytes"}},
+		{"ls.json.golden", []string{"--json", "ls"}},
+		{"offline_ls.golden", []string{"offline", "ls"}},
+		{"offline_ls.json.golden", []string{"offline", "ls", "--json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			code, stdout, stderr := runCLI(t, tt.args...)
+			if code != exitOK {
+				t.Fatalf("Expected exit 0, got %d: %s", code, stderr)
+			}
+			checkGolden(t, tt.golden, stdout)
+		})
+	}
+}
+
+func TestOutputErrorsOnStderr(t *testing.T) {
+	server := newStubServer(t, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path != "/drive/v1/about" {
+			return false
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return true
+	})
+	setupProfile(t, server, true)
+
+	code, stdout, stderr := runCLI(t, "--json", "quota")
+	if code != exitNetwork {
+		t.Fatalf("Expected exit %d, got %d", exitNetwork, code)
+	}
+	if stdout != "" {
+		t.Errorf("Expected nothing on stdout, got %q", stdout)
+	}
+	if !strings.Contains(stderr, "Request failed") || !strings.Contains(stderr, "pikpak quota:") {
+		t.Errorf("Expected the retry log and the error on stderr, got %q", stderr)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpak"
+)
+
+const listPageSize = 100
+
+// entry is a drive file or folder.
+type entry struct {
+	ID       string                 `json:"id"`
+	Name     string                 `json:"name"`
+	Kind     string                 `json:"kind"`
+	Folder   bool                   `json:"folder"`
+	Size     int64                  `json:"size"`
+	Modified time.Time              `json:"modified_time"`
+	Raw      map[string]interface{} `json:"-"`
+}
+
+func newEntry(m map[string]interface{}) entry {
+	e := entry{
+		ID:     stringField(m, "id"),
+		Name:   stringField(m, "name"),
+		Kind:   stringField(m, "kind"),
+		Folder: enums.ParseFileKind(stringField(m, "kind")).IsFolder(),
+		Raw:    m,
+	}
+	e.Size, _ = strconv.ParseInt(stringField(m, "size"), 10, 64)
+	e.Modified, _ = time.Parse(time.RFC3339, stringField(m, "modified_time"))
+	return e
+}
+
+// listFolder returns every entry in the folder, following page tokens.
+func (a *app) listFolder(ctx context.Context, folderID string) ([]entry, error) {
+	var entries []entry
+	pageToken := ""
+	for {
+		result, err := a.client.FileList(ctx, listPageSize, folderID, pageToken, "")
+		if err != nil {
+			return nil, err
+		}
+		files, _ := result["files"].([]interface{})
+		for _, f := range files {
+			if m, ok := f.(map[string]interface{}); ok {
+				entries = append(entries, newEntry(m))
+			}
+		}
+		pageToken = stringField(result, "next_page_token")
+		if pageToken == "" {
+			return entries, nil
+		}
+	}
+}
+
+// resolve finds the entry at p, a slash separated path relative to
+// --parent-id, or to the drive root when that is not set. An empty path or
+// "/" is that folder itself.
+func (a *app) resolve(ctx context.Context, p string) (entry, error) {
+	current := entry{ID: a.parentID, Name: "/", Folder: true}
+	for _, name := range splitPath(p) {
+		if !current.Folder {
+			return entry{}, fmt.Errorf("%s: %w", p, pikpak.ErrFileNotFound)
+		}
+		entries, err := a.listFolder(ctx, current.ID)
+		if err != nil {
+			return entry{}, err
+		}
+		found := false
+		for _, e := range entries {
+			if e.Name == name {
+				current, found = e, true
+				break
+			}
+		}
+		if !found {
+			return entry{}, fmt.Errorf("%s: %w", p, pikpak.ErrFileNotFound)
+		}
+	}
+	return current, nil
+}
+
+// resolveFolder is resolve for paths that must name a folder.
+func (a *app) resolveFolder(ctx context.Context, p string) (entry, error) {
+	e, err := a.resolve(ctx, p)
+	if err != nil {
+		return entry{}, err
+	}
+	if !e.Folder {
+		return entry{}, fmt.Errorf("%s is not a folder: %w", p, pikpak.ErrInvalidParameter)
+	}
+	return e, nil
+}
+
+// ensureFolder resolves the folder at p, creating any missing folders on
+// the way.
+func (a *app) ensureFolder(ctx context.Context, p string) (entry, error) {
+	current := entry{ID: a.parentID, Name: "/", Folder: true}
+	for _, name := range splitPath(p) {
+		entries, err := a.listFolder(ctx, current.ID)
+		if err != nil {
+			return entry{}, err
+		}
+		found := false
+		for _, e := range entries {
+			if e.Name == name {
+				current, found = e, true
+				break
+			}
+		}
+		if !found {
+			result, err := a.client.CreateFolder(ctx, name, current.ID)
+			if err != nil {
+				return entry{}, err
+			}
+			file, _ := result["file"].(map[string]interface{})
+			current = newEntry(file)
+			current.Folder = true
+		}
+		if !current.Folder {
+			return entry{}, fmt.Errorf("%s is not a folder: %w", p, pikpak.ErrInvalidParameter)
+		}
+	}
+	return current, nil
+}
+
+func (a *app) resolveAll(ctx context.Context, paths []string) ([]string, error) {
+	ids := make([]string, 0, len(paths))
+	for _, p := range paths {
+		e, err := a.resolve(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		if e.ID == "" {
+			return nil, fmt.Errorf("%s: the root folder cannot be used here: %w", p, pikpak.ErrInvalidParameter)
+		}
+		ids = append(ids, e.ID)
+	}
+	return ids, nil
+}
+
+func splitPath(p string) []string {
+	var parts []string
+	for _, part := range strings.Split(path.Clean("/"+p), "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpak"
+	"golang.org/x/term"
+)
+
+// progressLineInterval is how often a transfer is reported in line mode.
+const progressLineInterval = 2 * time.Second
+
+// progressRenderer shows the transfers reported by the client's progress
+// callback, one line each. On a terminal the lines are redrawn in place as
+// bars; otherwise a plain line is printed per transfer every interval and
+// when it completes.
+type progressRenderer struct {
+	mu       sync.Mutex
+	w        io.Writer
+	live     bool
+	width    int
+	interval time.Duration
+	now      func() time.Time
+
+	bars  []*progressBar
+	drawn int
+}
+
+type progressBar struct {
+	p       pikpak.Progress
+	start   time.Time
+	offset  int64
+	printed time.Time
+}
+
+// newProgressRenderer writes to stderr, so that --json output stays
+// parseable. Bars are drawn only when quiet is not set and both stdout and
+// stderr are terminals.
+func (a *app) newProgressRenderer(quiet bool) *progressRenderer {
+	return &progressRenderer{
+		w:        a.stderr,
+		live:     !quiet && isTerminal(a.stdout) && isTerminal(a.stderr),
+		width:    terminalWidth(),
+		interval: progressLineInterval,
+		now:      time.Now,
+	}
+}
+
+func isTerminal(v interface{}) bool {
+	f, ok := v.(*os.File)
+	if !ok {
+		return false
+	}
+	return term.IsTerminal(int(f.Fd()))
+}
+
+func terminalWidth() int {
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return 80
+}
+
+// Update is a pikpak.ProgressFunc.
+func (r *progressRenderer) Update(p pikpak.Progress) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	var b *progressBar
+	for _, existing := range r.bars {
+		if existing.p.Name == p.Name {
+			b = existing
+			break
+		}
+	}
+	if b == nil {
+		b = &progressBar{start: now, offset: p.Transferred}
+		r.bars = append(r.bars, b)
+	}
+	b.p = p
+
+	if r.live {
+		r.redraw(now)
+		return
+	}
+	if p.Done || b.printed.IsZero() || now.Sub(b.printed) >= r.interval {
+		b.printed = now
+		fmt.Fprintln(r.w, r.line(b, now, false))
+	}
+}
+
+// redraw moves the cursor back over the lines drawn before and draws every
+// transfer again.
+func (r *progressRenderer) redraw(now time.Time) {
+	var sb strings.Builder
+	if r.drawn > 0 {
+		fmt.Fprintf(&sb, "\x1b[%dA", r.drawn)
+	}
+	for _, b := range r.bars {
+		sb.WriteString("\r\x1b[K")
+		sb.WriteString(r.line(b, now, true))
+		sb.WriteByte('\n')
+	}
+	r.drawn = len(r.bars)
+	io.WriteString(r.w, sb.String())
+}
+
+// line formats a transfer as "name [bar] pct transferred/total speed eta",
+// fitted to the terminal width. The bar is left out of plain lines and
+// dropped first when the width is short.
+func (r *progressRenderer) line(b *progressBar, now time.Time, withBar bool) string {
+	p := b.p
+	known := p.Total > 0
+
+	pct := " --%"
+	total := "?"
+	if known {
+		pct = fmt.Sprintf("%3d%%", p.Transferred*100/p.Total)
+		total = formatBytes(p.Total)
+	}
+
+	var speed float64
+	if elapsed := now.Sub(b.start).Seconds(); elapsed > 0 {
+		speed = float64(p.Transferred-b.offset) / elapsed
+	}
+	eta := "--:--"
+	switch {
+	case p.Done:
+		eta = "done"
+	case known && speed > 0:
+		eta = formatETA(time.Duration(float64(p.Total-p.Transferred) / speed * float64(time.Second)))
+	}
+
+	stats := fmt.Sprintf(" %s %s/%s %s/s %s", pct, formatBytes(p.Transferred), total, formatBytes(int64(speed)), eta)
+	name := filepath.Base(p.Name)
+	if !withBar {
+		return name + stats
+	}
+
+	const maxName = 24
+	if n := []rune(name); len(n) > maxName {
+		name = string(n[:maxName-1]) + "…"
+	}
+	barWidth := r.width - len([]rune(name)) - len(stats) - 3
+	if barWidth < 5 {
+		return truncate(name+stats, r.width)
+	}
+	filled := 0
+	if known {
+		filled = int(int64(barWidth) * p.Transferred / p.Total)
+		if filled > barWidth {
+			filled = barWidth
+		}
+	}
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled)
+	return name + " [" + bar + "]" + stats
+}
+
+func formatETA(d time.Duration) string {
+	d = d.Round(time.Second)
+	h, m, s := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%02d:%02d", m, s)
+}
+
+func truncate(s string, width int) string {
+	if r := []rune(s); len(r) > width {
+		return string(r[:width])
+	}
+	return s
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpak"
+)
+
+// fakeClock is advanced by the tests between progress updates.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+func newTestRenderer(live bool, width int) (*progressRenderer, *bytes.Buffer, *fakeClock) {
+	var out bytes.Buffer
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	return &progressRenderer{
+		w:        &out,
+		live:     live,
+		width:    width,
+		interval: progressLineInterval,
+		now:      clock.now,
+	}, &out, clock
+}
+
+func TestProgressRenderer_Live(t *testing.T) {
+	r, out, clock := newTestRenderer(true, 60)
+
+	r.Update(pikpak.Progress{Name: "/tmp/video.mkv", Total: 4096})
+	clock.advance(time.Second)
+	r.Update(pikpak.Progress{Name: "/tmp/video.mkv", Transferred: 1024, Total: 4096})
+
+	frames := strings.Split(out.String(), "\r\x1b[K")
+	last := strings.TrimSuffix(frames[len(frames)-1], "\n")
+	want := "video.mkv [==         ]  25% 1.0 KiB/4.0 KiB 1.0 KiB/s 00:03"
+	if last != want {
+		t.Errorf("Expected %q, got %q", want, last)
+	}
+	if len([]rune(last)) != 60 {
+		t.Errorf("Expected the line to fill 60 columns, got %d", len([]rune(last)))
+	}
+	if !strings.Contains(out.String(), "\x1b[1A") {
+		t.Errorf("Expected the second frame to move the cursor up, got %q", out.String())
+	}
+}
+
+func TestProgressRenderer_Concurrent(t *testing.T) {
+	r, out, clock := newTestRenderer(true, 80)
+
+	r.Update(pikpak.Progress{Name: "a.bin", Total: 100})
+	r.Update(pikpak.Progress{Name: "b.bin", Total: 100})
+	clock.advance(time.Second)
+	out.Reset()
+	r.Update(pikpak.Progress{Name: "a.bin", Transferred: 100, Total: 100, Done: true})
+
+	frame := out.String()
+	if !strings.HasPrefix(frame, "\x1b[2A") {
+		t.Errorf("Expected both lines to be redrawn, got %q", frame)
+	}
+	lines := strings.Split(strings.TrimSuffix(frame, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected one line per transfer, got %q", lines)
+	}
+	if !strings.Contains(lines[0], "a.bin") || !strings.HasSuffix(lines[0], "100% 100 B/100 B 100 B/s done") {
+		t.Errorf("Unexpected line for a.bin: %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "b.bin") || !strings.HasSuffix(lines[1], "0% 0 B/100 B 0 B/s --:--") {
+		t.Errorf("Unexpected line for b.bin: %q", lines[1])
+	}
+}
+
+func TestProgressRenderer_NarrowTerminal(t *testing.T) {
+	r, out, _ := newTestRenderer(true, 30)
+
+	r.Update(pikpak.Progress{Name: "a-rather-long-file-name-for-a-terminal.iso", Transferred: 512, Total: -1})
+
+	line := strings.TrimSuffix(strings.TrimPrefix(out.String(), "\r\x1b[K"), "\n")
+	if len([]rune(line)) > 30 {
+		t.Errorf("Expected at most 30 columns, got %q", line)
+	}
+	if strings.Contains(line, "[") {
+		t.Errorf("Expected the bar to be dropped, got %q", line)
+	}
+}
+
+func TestProgressRenderer_Lines(t *testing.T) {
+	r, out, clock := newTestRenderer(false, 80)
+
+	for i := 0; i <= 10; i++ {
+		r.Update(pikpak.Progress{Name: "/tmp/a.bin", Transferred: int64(i) * 100, Total: 1000, Done: i == 10})
+		clock.advance(500 * time.Millisecond)
+	}
+
+	got := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	want := []string{
+		"a.bin   0% 0 B/1000 B 0 B/s --:--",
+		"a.bin  40% 400 B/1000 B 200 B/s 00:03",
+		"a.bin  80% 800 B/1000 B 200 B/s 00:01",
+		"a.bin 100% 1000 B/1000 B 200 B/s done",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected lines\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	if strings.Contains(out.String(), "\x1b") {
+		t.Error("Expected no escape sequences in line mode")
+	}
+}
+
+func TestFormatETA(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                       "00:00",
+		1500 * time.Millisecond: "00:02",
+		75 * time.Second:        "01:15",
+		2*time.Hour + 3*time.Minute + 4*time.Second: "2:03:04",
+	}
+	for d, want := range tests {
+		if got := formatETA(d); got != want {
+			t.Errorf("formatETA(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -source prompt.go -destination prompter_mock_test.go -package main -mock_names prompter=mockPrompter
+
+// prompter asks the user for input on the terminal.
+type prompter interface {
+	readLine(prompt string) (string, error)
+	// readPassword reads a line without echoing it.
+	readPassword(prompt string) (string, error)
+}
+
+// terminalPrompter reads from the terminal on stdin and writes prompts to
+// stderr.
+type terminalPrompter struct {
+	in  *os.File
+	r   *bufio.Reader
+	out io.Writer
+}
+
+// newPrompter returns a prompter when stdin is a terminal, or nil when the
+// user cannot be asked.
+func newPrompter(stdin io.Reader, stderr io.Writer) prompter {
+	f, ok := stdin.(*os.File)
+	if !ok || !isTerminal(f) {
+		return nil
+	}
+	return &terminalPrompter{in: f, r: bufio.NewReader(f), out: stderr}
+}
+
+func (p *terminalPrompter) readLine(prompt string) (string, error) {
+	fmt.Fprint(p.out, prompt)
+	return readLine(p.r)
+}
+
+func (p *terminalPrompter) readPassword(prompt string) (string, error) {
+	fmt.Fprint(p.out, prompt)
+	password, err := term.ReadPassword(int(p.in.Fd()))
+	fmt.Fprintln(p.out)
+	return string(password), err
+}
+
+// readLine reads one line without its line ending. A last line without a
+// newline is accepted.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
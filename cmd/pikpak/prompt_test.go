@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpak"
+	"go.uber.org/mock/gomock"
+)
+
+// newLoginServer answers sign-in requests. The first challenges captcha
+// requests are answered with a challenge page instead of a token, and the
+// password sent is recorded in password.
+func newLoginServer(t *testing.T, challenges int32, password *string) *stubServer {
+	var captchas int32
+	return newStubServer(t, func(w http.ResponseWriter, r *http.Request) bool {
+		switch r.URL.Path {
+		case "/v1/shield/captcha/init":
+			if atomic.AddInt32(&captchas, 1) <= challenges {
+				json.NewEncoder(w).Encode(map[string]interface{}{"url": "https://captcha.example/verify"})
+				return true
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"captcha_token": "captcha"})
+		case "/v1/auth/signin":
+			r.ParseForm()
+			*password = r.PostForm.Get("password")
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "new_access", "refresh_token": "new_refresh", "sub": "user1"})
+		default:
+			return false
+		}
+		return true
+	})
+}
+
+// scriptedAnswers answers prompts from answers in order and records them.
+func scriptedAnswers(answers []string, prompts *[]string) func(string) (string, error) {
+	return func(prompt string) (string, error) {
+		*prompts = append(*prompts, prompt)
+		if len(answers) == 0 {
+			return "", errors.New("unexpected prompt " + prompt)
+		}
+		answer := answers[0]
+		answers = answers[1:]
+		return answer, nil
+	}
+}
+
+func TestLoginPasswordStdin(t *testing.T) {
+	var password string
+	server := newLoginServer(t, 0, &password)
+	setupProfile(t, server, false)
+
+	if code, _, stderr := runCLIWithInput(t, "s3cret", "login", "--password-stdin"); code != exitUsage || !strings.Contains(stderr, "--username") {
+		t.Errorf("Expected a usage error without --username, got %d: %s", code, stderr)
+	}
+	if code, _, stderr := runCLIWithInput(t, "", "login", "--username", "me@example.com", "--password-stdin"); code != exitUsage || !strings.Contains(stderr, "no password on stdin") {
+		t.Errorf("Expected a usage error for empty stdin, got %d: %s", code, stderr)
+	}
+
+	code, stdout, stderr := runCLIWithInput(t, "s3cret\n", "login", "--username", "me@example.com", "--password-stdin")
+	if code != exitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, stderr)
+	}
+	if password != "s3cret" {
+		t.Errorf("Expected the password from stdin, got %q", password)
+	}
+	if !strings.Contains(stdout, "Logged in as me@example.com") {
+		t.Errorf("Unexpected output %q", stdout)
+	}
+}
+
+func TestLoginNonTerminal(t *testing.T) {
+	var password string
+	server := newLoginServer(t, 0, &password)
+	setupProfile(t, server, false)
+
+	code, _, stderr := runCLI(t, "login", "--username", "me@example.com")
+	if code != exitUsage {
+		t.Fatalf("Expected exit %d, got %d: %s", exitUsage, code, stderr)
+	}
+	if !strings.Contains(stderr, "not a terminal") || !strings.Contains(stderr, "--password-stdin") {
+		t.Errorf("Expected a hint to use --password-stdin, got %q", stderr)
+	}
+	if server.requested("POST /v1/auth/signin") {
+		t.Error("Expected no sign-in attempt")
+	}
+}
+
+func TestLoginPrompt(t *testing.T) {
+	var password string
+	server := newLoginServer(t, 1, &password)
+	setupProfile(t, server, false)
+
+	var stdout, stderr bytes.Buffer
+	var prompts []string
+	answer := scriptedAnswers([]string{"me@example.com", "s3cret", ""}, &prompts)
+	prompt := NewmockPrompter(gomock.NewController(t))
+	prompt.EXPECT().readLine(gomock.Any()).DoAndReturn(answer).AnyTimes()
+	// Only the password is read without echo.
+	prompt.EXPECT().readPassword("Password: ").DoAndReturn(answer)
+	a := &app{
+		stdin:  strings.NewReader(""),
+		stdout: &stdout,
+		stderr: &stderr,
+		prompt: prompt,
+		newStore: func() pikpak.TokenStore {
+			t.Fatal("Expected the config file to be used")
+			return nil
+		},
+	}
+	if err := runLogin(context.Background(), a, nil); err != nil {
+		t.Fatalf("Expected no error, got %v: %s", err, stderr.String())
+	}
+
+	want := []string{"Username: ", "Password: ", "Paste the captcha token, or press Enter to try again once it is done: "}
+	if strings.Join(prompts, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected prompts %q, got %q", want, prompts)
+	}
+	if !strings.Contains(stderr.String(), "https://captcha.example/verify") {
+		t.Errorf("Expected the challenge page, got %q", stderr.String())
+	}
+	if password != "s3cret" {
+		t.Errorf("Expected the prompted password, got %q", password)
+	}
+}
+
+func TestLoginCaptchaWithoutTerminal(t *testing.T) {
+	var password string
+	server := newLoginServer(t, 1, &password)
+	setupProfile(t, server, false)
+
+	code, _, stderr := runCLIWithInput(t, "s3cret\n", "login", "--username", "me@example.com", "--password-stdin")
+	if code != exitAuth {
+		t.Fatalf("Expected exit %d, got %d: %s", exitAuth, code, stderr)
+	}
+	if !strings.Contains(stderr, "https://captcha.example/verify") {
+		t.Errorf("Expected the challenge page, got %q", stderr)
+	}
+}
+
+func TestLoginPastedCaptchaToken(t *testing.T) {
+	var password string
+	// Every captcha is a challenge, so only the pasted token gets through.
+	server := newLoginServer(t, maxCaptchaAttempts+1, &password)
+	setupProfile(t, server, false)
+
+	var stdout, stderr bytes.Buffer
+	var prompts []string
+	answer := scriptedAnswers([]string{"me@example.com", "s3cret", " ck0.solved \n"}, &prompts)
+	prompt := NewmockPrompter(gomock.NewController(t))
+	prompt.EXPECT().readLine(gomock.Any()).DoAndReturn(answer).AnyTimes()
+	prompt.EXPECT().readPassword(gomock.Any()).DoAndReturn(answer).AnyTimes()
+	a := &app{
+		stdin:  strings.NewReader(""),
+		stdout: &stdout,
+		stderr: &stderr,
+		prompt: prompt,
+		newStore: func() pikpak.TokenStore {
+			t.Fatal("Expected the config file to be used")
+			return nil
+		},
+	}
+	if err := runLogin(context.Background(), a, nil); err != nil {
+		t.Fatalf("Expected the pasted token to complete the login, got %v: %s", err, stderr.String())
+	}
+	if len(prompts) != 3 || password != "s3cret" {
+		t.Errorf("Expected a single captcha prompt and the prompted password, got %q %q", prompts, password)
+	}
+	if !strings.Contains(stdout.String(), "Logged in as me@example.com") {
+		t.Errorf("Unexpected output %q", stdout.String())
+	}
+}
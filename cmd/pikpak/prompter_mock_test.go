@@ -0,0 +1,69 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: prompt.go
+//
+// Generated by this command:
+//
+//	mockgen -source prompt.go -destination prompter_mock_test.go -package main -mock_names prompter=mockPrompter
+//
+
+// Package main is a generated GoMock package.
+package main
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// mockPrompter is a mock of prompter interface.
+type mockPrompter struct {
+	ctrl     *gomock.Controller
+	recorder *mockPrompterMockRecorder
+}
+
+// mockPrompterMockRecorder is the mock recorder for mockPrompter.
+type mockPrompterMockRecorder struct {
+	mock *mockPrompter
+}
+
+// NewmockPrompter creates a new mock instance.
+func NewmockPrompter(ctrl *gomock.Controller) *mockPrompter {
+	mock := &mockPrompter{ctrl: ctrl}
+	mock.recorder = &mockPrompterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *mockPrompter) EXPECT() *mockPrompterMockRecorder {
+	return m.recorder
+}
+
+// readLine mocks base method.
+func (m *mockPrompter) readLine(prompt string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "readLine", prompt)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// readLine indicates an expected call of readLine.
+func (mr *mockPrompterMockRecorder) readLine(prompt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "readLine", reflect.TypeOf((*mockPrompter)(nil).readLine), prompt)
+}
+
+// readPassword mocks base method.
+func (m *mockPrompter) readPassword(prompt string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "readPassword", prompt)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// readPassword indicates an expected call of readPassword.
+func (mr *mockPrompterMockRecorder) readPassword(prompt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "readPassword", reflect.TypeOf((*mockPrompter)(nil).readPassword), prompt)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpak"
+	"github.com/zhz8888/pikpakapi-go/pkg/proxy"
+	"github.com/zhz8888/pikpakapi-go/pkg/webdav"
+)
+
+// serveShutdownTimeout is how long in-flight requests get to finish after
+// Ctrl-C.
+const serveShutdownTimeout = 5 * time.Second
+
+func runServe(ctx context.Context, a *app, args []string) error {
+	sub, args, err := subcommand(args, "webdav", "proxy")
+	if err != nil {
+		return err
+	}
+
+	fs := a.flagSet("serve " + sub)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	user := fs.String("user", "", "require basic authentication with this user name")
+	pass := fs.String("pass", "", "password for --user")
+	readOnly := fs.Bool("read-only", false, "reject changes to the drive (webdav)")
+	token := fs.String("token", "", "accept requests with ?token=`TOKEN` (proxy)")
+	rest, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return usagef("unexpected arguments %q", rest)
+	}
+	if (*user == "") != (*pass == "") {
+		return usagef("--user and --pass must be given together")
+	}
+	if sub == "webdav" && *token != "" {
+		return usagef("--token only applies to serve proxy")
+	}
+
+	if err := a.connect(); err != nil {
+		return err
+	}
+	auth := *user != "" || *token != ""
+
+	if sub == "proxy" {
+		opts := []proxy.Option{proxy.WithLogger(pikpak.NewStdLogger(nil, false))}
+		if *user != "" {
+			opts = append(opts, proxy.WithBasicAuth(*user, *pass))
+		}
+		if *token != "" {
+			opts = append(opts, proxy.WithToken(*token))
+		}
+		return a.serve(ctx, "the streaming proxy", *addr, proxy.Handler(a.client, opts...), auth)
+	}
+
+	opts := []webdav.Option{webdav.WithReadOnly(*readOnly), webdav.WithLogger(pikpak.NewStdLogger(nil, false))}
+	if *user != "" {
+		opts = append(opts, webdav.WithBasicAuth(*user, *pass))
+	}
+	return a.serve(ctx, "WebDAV", *addr, webdav.Handler(a.client, opts...), auth)
+}
+
+// serve runs handler, described by what, on addr until ctx is done, then
+// lets in-flight requests finish.
+func (a *app) serve(ctx context.Context, what string, addr string, handler http.Handler, auth bool) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stderr, "Serving %s on http://%s/\n", what, ln.Addr())
+	if !auth {
+		fmt.Fprintln(a.stderr, "warning: no authentication set, anyone who can reach the address can access the drive")
+	}
+
+	srv := &http.Server{Handler: handler}
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return nil
+}
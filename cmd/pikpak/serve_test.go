@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe to read while a command writes to it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// startServe runs pikpak with args in the background until the test ends or
+// stop is called, and returns the base URL it serves on. stop returns the
+// exit code.
+func startServe(t *testing.T, pattern string, args ...string) (base string, stderr *syncBuffer, stop func() int) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	var stdout syncBuffer
+	stderr = &syncBuffer{}
+	done := make(chan int, 1)
+	go func() {
+		done <- run(ctx, args, strings.NewReader(""), &stdout, stderr)
+	}()
+
+	addrPattern := regexp.MustCompile(pattern + ` on (http://\S+/)`)
+	for deadline := time.Now().Add(5 * time.Second); base == "" && time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if m := addrPattern.FindStringSubmatch(stderr.String()); m != nil {
+			base = m[1]
+		}
+	}
+	if base == "" {
+		t.Fatalf("Expected the listen address, got %q", stderr.String())
+	}
+
+	stop = func() int {
+		cancel()
+		select {
+		case code := <-done:
+			return code
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected the server to stop")
+			return 0
+		}
+	}
+	return base, stderr, stop
+}
+
+func TestServeWebDAV(t *testing.T) {
+	server := newStubServer(t, nil)
+	setupProfile(t, server, true)
+
+	base, stderr, stop := startServe(t, "Serving WebDAV", "serve", "webdav", "--addr", "127.0.0.1:0", "--user", "me", "--pass", "secret", "--read-only")
+
+	req, _ := http.NewRequest("PROPFIND", base+"docs/", nil)
+	req.Header.Set("Depth", "1")
+	req.SetBasicAuth("me", "secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus || !strings.Contains(string(body), "/docs/a.txt") {
+		t.Errorf("Expected the folder listing, got %d %s", resp.StatusCode, body)
+	}
+
+	req, _ = http.NewRequest("MKCOL", base+"docs/new", nil)
+	req.SetBasicAuth("me", "secret")
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected --read-only to reject MKCOL, got %v %v", resp, err)
+	}
+
+	if code := stop(); code != exitOK {
+		t.Errorf("Expected exit 0 after Ctrl-C, got %d: %s", code, stderr.String())
+	}
+}
+
+func TestServeProxy(t *testing.T) {
+	var server *stubServer
+	server = newStubServer(t, func(w http.ResponseWriter, r *http.Request) bool {
+		switch r.URL.Path {
+		case "/drive/v1/files/f1":
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": "f1", "web_content_link": server.URL + "/content/f1"})
+		case "/content/f1":
+			http.ServeContent(w, r, "", time.Time{}, strings.NewReader("hello, proxy"))
+		default:
+			return false
+		}
+		return true
+	})
+	setupProfile(t, server, true)
+
+	base, stderr, stop := startServe(t, "Serving the streaming proxy", "serve", "proxy", "--addr", "127.0.0.1:0", "--token", "tok")
+
+	if resp, err := http.Get(base + "file/f1"); err != nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a request without the token to be rejected, got %v %v", resp, err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, base+"file/f1?token=tok", nil)
+	req.Header.Set("Range", "bytes=7-")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent || string(body) != "proxy" {
+		t.Errorf("Expected the requested range, got %d %q", resp.StatusCode, body)
+	}
+
+	if code := stop(); code != exitOK {
+		t.Errorf("Expected exit 0 after Ctrl-C, got %d: %s", code, stderr.String())
+	}
+}
+
+func TestServeUsage(t *testing.T) {
+	server := newStubServer(t, nil)
+	setupProfile(t, server, true)
+
+	if code, _, stderr := runCLI(t, "serve", "webdav", "--user", "me"); code != exitUsage || !strings.Contains(stderr, "--pass") {
+		t.Errorf("Expected a usage error, got %d: %s", code, stderr)
+	}
+	if code, _, _ := runCLI(t, "serve", "webdav", "--token", "tok"); code != exitUsage {
+		t.Errorf("Expected a usage error for --token with webdav, got %d", code)
+	}
+	if code, _, _ := runCLI(t, "serve", "ftp"); code != exitUsage {
+		t.Errorf("Expected a usage error for an unknown server, got %d", code)
+	}
+}
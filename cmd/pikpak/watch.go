@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
+)
+
+// watchedTask is a task as shown by offline watch, with the download speed
+// estimated from the progress between two updates.
+type watchedTask struct {
+	task
+	Speed int64 `json:"speed"`
+}
+
+// taskWatcher turns the task lists reported by the client into rows and
+// prints them: redrawn in place on a terminal, as changed rows otherwise,
+// or as one JSON line per update.
+type taskWatcher struct {
+	a          *app
+	live       bool
+	jsonStream bool
+	now        func() time.Time
+
+	last   map[string]watchedTask
+	lastAt time.Time
+	drawn  int
+}
+
+func watchTasks(ctx context.Context, a *app, ids []string, interval, timeout time.Duration, jsonStream bool) error {
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	watchCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		watchCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	w := &taskWatcher{
+		a:          a,
+		live:       !jsonStream && !a.json && isTerminal(a.stdout),
+		jsonStream: jsonStream,
+		now:        time.Now,
+	}
+	var final []watchedTask
+	err := a.client.WatchTasks(watchCtx, ids, interval, func(tasks []map[string]interface{}) {
+		final = w.update(tasks)
+	})
+	switch {
+	case err != nil && ctx.Err() != nil:
+		return fmt.Errorf("stopped watching, the tasks keep running: %w", ctx.Err())
+	case err != nil && watchCtx.Err() != nil:
+		return fmt.Errorf("tasks still running after %s", timeout)
+	case err != nil:
+		return err
+	}
+
+	if !jsonStream {
+		if final == nil {
+			final = []watchedTask{}
+		}
+		// Without --json the table is already on screen.
+		err := a.print(final, func(w io.Writer) {
+			if len(final) == 0 {
+				fmt.Fprintln(w, "No running tasks")
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	failed := 0
+	for _, t := range final {
+		if t.Phase == enums.PhaseTypeError {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tasks failed", failed, len(final))
+	}
+	return nil
+}
+
+// update records the latest state of the watched tasks, prints it and
+// returns it.
+func (w *taskWatcher) update(raw []map[string]interface{}) []watchedTask {
+	now := w.now()
+	tasks := make([]watchedTask, len(raw))
+	for i, m := range raw {
+		t := watchedTask{task: newTask(m)}
+		if prev, ok := w.last[t.ID]; ok && t.Size > 0 && !w.lastAt.IsZero() {
+			if elapsed := now.Sub(w.lastAt).Seconds(); elapsed > 0 && t.Progress > prev.Progress {
+				t.Speed = int64(float64(t.Size) * float64(t.Progress-prev.Progress) / 100 / elapsed)
+			}
+		}
+		tasks[i] = t
+	}
+
+	switch {
+	case w.jsonStream:
+		line, _ := json.Marshal(map[string]interface{}{"time": now.UTC(), "tasks": tasks})
+		fmt.Fprintf(w.a.stdout, "%s\n", line)
+	case w.a.json:
+	case w.live:
+		w.redraw(tasks)
+	default:
+		w.printChanged(tasks)
+	}
+
+	w.last = make(map[string]watchedTask, len(tasks))
+	for _, t := range tasks {
+		w.last[t.ID] = t
+	}
+	w.lastAt = now
+	return tasks
+}
+
+// redraw replaces the table drawn by the previous update.
+func (w *taskWatcher) redraw(tasks []watchedTask) {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tPHASE\tPROGRESS\tSPEED\tID")
+	for _, t := range tasks {
+		fmt.Fprintln(tw, strings.Join(w.row(t), "\t"))
+	}
+	tw.Flush()
+
+	var out strings.Builder
+	if w.drawn > 0 {
+		fmt.Fprintf(&out, "\x1b[%dA", w.drawn)
+	}
+	for _, line := range strings.SplitAfter(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		out.WriteString("\r\x1b[K")
+		out.WriteString(strings.TrimSuffix(line, "\n"))
+		out.WriteByte('\n')
+	}
+	w.drawn = len(tasks) + 1
+	fmt.Fprint(w.a.stdout, out.String())
+}
+
+// printChanged prints a row for every task whose phase or progress moved
+// since the last update, for output that is not a terminal.
+func (w *taskWatcher) printChanged(tasks []watchedTask) {
+	for _, t := range tasks {
+		if prev, ok := w.last[t.ID]; ok && prev.Phase == t.Phase && prev.Progress == t.Progress {
+			continue
+		}
+		fmt.Fprintln(w.a.stdout, strings.Join(w.row(t), "  "))
+	}
+}
+
+func (w *taskWatcher) row(t watchedTask) []string {
+	speed := "-"
+	if t.Speed > 0 {
+		speed = w.a.size(t.Speed) + "/s"
+	}
+	return []string{t.Name, t.phaseName(), fmt.Sprintf("%d%%", t.Progress), speed, t.ID}
+}
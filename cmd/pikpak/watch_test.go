@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newTaskStub serves the task list from script, advancing one step per
+// request and staying on the last step.
+func newTaskStub(t *testing.T, script ...[]map[string]interface{}) *stubServer {
+	var calls int32
+	return newStubServer(t, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path != "/drive/v1/tasks" {
+			return false
+		}
+		n := int(atomic.AddInt32(&calls, 1)) - 1
+		if n >= len(script) {
+			n = len(script) - 1
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"tasks": script[n]})
+		return true
+	})
+}
+
+func taskStep(phase1 string, progress1 int, phase2 string, progress2 int) []map[string]interface{} {
+	return []map[string]interface{}{
+		{"id": "t1", "name": "a.iso", "phase": phase1, "progress": progress1, "file_size": "1048576"},
+		{"id": "t2", "name": "b.iso", "phase": phase2, "progress": progress2, "file_size": "1048576"},
+	}
+}
+
+func TestOfflineWatch(t *testing.T) {
+	server := newTaskStub(t,
+		taskStep("PHASE_TYPE_RUNNING", 10, "PHASE_TYPE_PENDING", 0),
+		taskStep("PHASE_TYPE_RUNNING", 60, "PHASE_TYPE_PENDING", 0),
+		taskStep("PHASE_TYPE_COMPLETE", 100, "PHASE_TYPE_RUNNING", 30),
+		taskStep("PHASE_TYPE_COMPLETE", 100, "PHASE_TYPE_COMPLETE", 100),
+	)
+	setupProfile(t, server, true)
+
+	code, stdout, stderr := runCLI(t, "offline", "watch", "--interval", "1ms", "t1", "t2")
+	if code != exitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	want := []string{
+		"a.iso  running  10%",
+		"b.iso  pending  0%",
+		"a.iso  running  60%",
+		"a.iso  complete  100%",
+		"b.iso  running  30%",
+		"b.iso  complete  100%",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("Expected a line per change, got %q", lines)
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("Expected line %d to start with %q, got %q", i, prefix, lines[i])
+		}
+	}
+	if strings.Contains(lines[0], "/s") {
+		t.Errorf("Expected no speed on the first update, got %q", lines[0])
+	}
+	if !strings.Contains(lines[2], "/s") {
+		t.Errorf("Expected a speed once progress moved, got %q", lines[2])
+	}
+}
+
+func TestOfflineWatchJSONStream(t *testing.T) {
+	server := newTaskStub(t,
+		taskStep("PHASE_TYPE_RUNNING", 10, "PHASE_TYPE_RUNNING", 10),
+		taskStep("PHASE_TYPE_COMPLETE", 100, "PHASE_TYPE_ERROR", 10),
+	)
+	setupProfile(t, server, true)
+
+	code, stdout, stderr := runCLI(t, "offline", "watch", "--interval", "1ms", "--json-stream", "t2", "t1")
+	if code != exitError {
+		t.Fatalf("Expected exit %d for a failed task, got %d: %s", exitError, code, stderr)
+	}
+	if !strings.Contains(stderr, "1 of 2 tasks failed") {
+		t.Errorf("Expected the failure count, got %q", stderr)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected one JSON line per update, got %q", lines)
+	}
+	var update struct {
+		Tasks []watchedTask `json:"tasks"`
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &update); err != nil {
+		t.Fatalf("Expected a JSON line, got %q: %v", lines[1], err)
+	}
+	if len(update.Tasks) != 2 || update.Tasks[0].ID != "t2" || update.Tasks[0].Phase != "PHASE_TYPE_ERROR" {
+		t.Errorf("Expected the tasks in the order given, got %+v", update.Tasks)
+	}
+}
+
+func TestOfflineWatchTimeout(t *testing.T) {
+	server := newTaskStub(t, taskStep("PHASE_TYPE_RUNNING", 10, "PHASE_TYPE_RUNNING", 10))
+	setupProfile(t, server, true)
+
+	code, _, stderr := runCLI(t, "offline", "watch", "--interval", "5ms", "--timeout", "20ms", "t1")
+	if code != exitError || !strings.Contains(stderr, "still running after 20ms") {
+		t.Errorf("Expected a timeout, got %d: %s", code, stderr)
+	}
+}
+
+func TestOfflineWatchInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls int32
+	server := newStubServer(t, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path != "/drive/v1/tasks" {
+			return false
+		}
+		if atomic.AddInt32(&calls, 1) == 2 {
+			cancel()
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"tasks": taskStep("PHASE_TYPE_RUNNING", 10, "PHASE_TYPE_RUNNING", 10)})
+		return true
+	})
+	setupProfile(t, server, true)
+
+	var stdout, stderr bytes.Buffer
+	code := run(ctx, []string{"offline", "watch", "--interval", "1ms"}, strings.NewReader(""), &stdout, &stderr)
+	if code != exitInterrupted {
+		t.Fatalf("Expected exit %d, got %d: %s", exitInterrupted, code, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "the tasks keep running") {
+		t.Errorf("Expected a note that the tasks are untouched, got %q", stderr.String())
+	}
+	if server.requested("DELETE /drive/v1/tasks") {
+		t.Error("Expected the tasks to be left alone")
+	}
+}
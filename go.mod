@@ -1,3 +1,23 @@
 module github.com/zhz8888/pikpakapi-go
 
 go 1.21
+
+require bazil.org/fuse v0.0.0-20200117225306-7b5117fecadc
+
+require (
+	github.com/studio-b12/gowebdav v0.9.0
+	github.com/zalando/go-keyring v0.2.4
+	go.uber.org/mock v0.4.0
+	golang.org/x/crypto v0.21.0
+	golang.org/x/net v0.21.0
+	golang.org/x/term v0.18.0
+)
+
+require (
+	github.com/alessio/shellescape v1.4.1 // indirect
+	github.com/danieljoos/wincred v1.2.0 // indirect
+	github.com/godbus/dbus/v5 v5.1.0 // indirect
+	golang.org/x/mod v0.14.0 // indirect
+	golang.org/x/sys v0.18.0 // indirect
+	golang.org/x/tools v0.17.0 // indirect
+)
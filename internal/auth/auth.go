@@ -2,8 +2,11 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
+	"sync"
+	"time"
 
 	"github.com/zhz8888/pikpakapi-go/internal/constants"
 	"github.com/zhz8888/pikpakapi-go/internal/exception"
@@ -17,16 +20,28 @@ type Token struct {
 }
 
 type Auth struct {
+	// tokenMu guards encodedToken, accessToken, refreshToken, expiresAt,
+	// userID, deviceID, captchaToken, loginPending, verificationID and
+	// verificationPhone.
+	tokenMu      sync.RWMutex
 	username     string
 	password     string
 	encodedToken string
 	accessToken  string
 	refreshToken string
+	expiresAt    time.Time
+	now          func() time.Time
 	userID       string
 	deviceID     string
 	captchaToken string
-	httpClient   HTTPClient
-	baseURL      string
+	loginPending bool
+	// verificationID and verificationPhone identify the last code sent by
+	// SendVerificationCode.
+	verificationID    string
+	verificationPhone string
+	httpClient        HTTPClient
+	baseURL           string
+	signer            *signer.Holder
 }
 
 type HTTPClient interface {
@@ -78,6 +93,22 @@ func WithBaseURL(baseURL string) AuthOption {
 	}
 }
 
+// WithSigner sets the signing config requests are signed with. It defaults
+// to signer.DefaultConfig.
+func WithSigner(holder *signer.Holder) AuthOption {
+	return func(a *Auth) {
+		a.signer = holder
+	}
+}
+
+// WithClock sets the clock token expiry is measured with. It defaults to
+// time.Now.
+func WithClock(now func() time.Time) AuthOption {
+	return func(a *Auth) {
+		a.now = now
+	}
+}
+
 func NewAuth(opts ...AuthOption) *Auth {
 	auth := &Auth{
 		httpClient:   nil,
@@ -88,6 +119,7 @@ func NewAuth(opts ...AuthOption) *Auth {
 		userID:       "",
 		deviceID:     "",
 		captchaToken: "",
+		now:          time.Now,
 	}
 
 	for _, opt := range opts {
@@ -97,59 +129,163 @@ func NewAuth(opts ...AuthOption) *Auth {
 	return auth
 }
 
+func (a *Auth) signing() signer.Config {
+	if a.signer == nil {
+		return signer.DefaultConfig()
+	}
+	return a.signer.Load()
+}
+
 func (a *Auth) SetHTTPClient(client HTTPClient) {
 	a.httpClient = client
 }
 
 func (a *Auth) GetUserID() string {
+	a.tokenMu.RLock()
+	defer a.tokenMu.RUnlock()
 	return a.userID
 }
 
 func (a *Auth) SetUserID(userID string) {
+	a.tokenMu.Lock()
+	defer a.tokenMu.Unlock()
 	a.userID = userID
 }
 
 func (a *Auth) GetCaptchaToken() string {
+	a.tokenMu.RLock()
+	defer a.tokenMu.RUnlock()
 	return a.captchaToken
 }
 
 func (a *Auth) SetCaptchaToken(token string) {
+	a.tokenMu.Lock()
+	defer a.tokenMu.Unlock()
 	a.captchaToken = token
 }
 
 func (a *Auth) GetDeviceID() string {
+	a.tokenMu.RLock()
+	defer a.tokenMu.RUnlock()
 	return a.deviceID
 }
 
 func (a *Auth) WithDeviceID(deviceID string) {
+	a.tokenMu.Lock()
+	defer a.tokenMu.Unlock()
 	a.deviceID = deviceID
 }
 
 func (a *Auth) GetAccessToken() string {
+	a.tokenMu.RLock()
+	defer a.tokenMu.RUnlock()
 	return a.accessToken
 }
 
+// SetAccessToken replaces the access token. Its expiry is unknown until
+// SetTokenExpiresAt.
 func (a *Auth) SetAccessToken(token string) {
+	a.tokenMu.Lock()
+	defer a.tokenMu.Unlock()
 	a.accessToken = token
+	a.expiresAt = time.Time{}
+}
+
+// GetTokenExpiresAt returns when the access token expires, or the zero time
+// when that is unknown.
+func (a *Auth) GetTokenExpiresAt() time.Time {
+	a.tokenMu.RLock()
+	defer a.tokenMu.RUnlock()
+	return a.expiresAt
+}
+
+func (a *Auth) SetTokenExpiresAt(expiresAt time.Time) {
+	a.tokenMu.Lock()
+	defer a.tokenMu.Unlock()
+	a.expiresAt = expiresAt
+}
+
+// recordExpiry stores the expiry of a freshly issued token from the
+// expires_in seconds of the response, if any.
+func (a *Auth) recordExpiry(userInfo map[string]interface{}) {
+	if expiresAt := a.expiryOf(userInfo); !expiresAt.IsZero() {
+		a.SetTokenExpiresAt(expiresAt)
+	}
+}
+
+// expiryOf returns when the access token in userInfo expires, or the zero
+// time when the response does not say.
+func (a *Auth) expiryOf(userInfo map[string]interface{}) time.Time {
+	if expiresIn, ok := userInfo["expires_in"].(float64); ok && expiresIn > 0 {
+		return a.now().Add(time.Duration(expiresIn) * time.Second)
+	}
+	return time.Time{}
 }
 
 func (a *Auth) GetRefreshToken() string {
+	a.tokenMu.RLock()
+	defer a.tokenMu.RUnlock()
 	return a.refreshToken
 }
 
 func (a *Auth) SetRefreshToken(token string) {
+	a.tokenMu.Lock()
+	defer a.tokenMu.Unlock()
 	a.refreshToken = token
 }
 
 func (a *Auth) GetEncodedToken() string {
+	a.tokenMu.RLock()
+	defer a.tokenMu.RUnlock()
 	return a.encodedToken
 }
 
 func (a *Auth) SetEncodedToken(token string) {
+	a.tokenMu.Lock()
+	defer a.tokenMu.Unlock()
 	a.encodedToken = token
 }
 
+// SetTokens replaces the access and refresh token together and re-encodes
+// them, so concurrent readers never see a mismatched pair.
+func (a *Auth) SetTokens(accessToken, refreshToken string) error {
+	return a.storeTokens(accessToken, refreshToken, time.Time{})
+}
+
+// storeTokens replaces the access and refresh tokens, their expiry and the
+// encoded token in one step, so readers never see a mix of old and new.
+func (a *Auth) storeTokens(accessToken, refreshToken string, expiresAt time.Time) error {
+	encoded, err := token.Encode(accessToken, refreshToken)
+	if err != nil {
+		return exception.NewPikpakExceptionWithError(exception.ErrCodeInvalidEncodedToken, err)
+	}
+
+	a.tokenMu.Lock()
+	defer a.tokenMu.Unlock()
+	a.accessToken = accessToken
+	a.refreshToken = refreshToken
+	a.expiresAt = expiresAt
+	a.encodedToken = encoded
+	return nil
+}
+
+// ClearTokens forgets the access, refresh, encoded and captcha tokens and
+// any sign-in waiting for a captcha.
+func (a *Auth) ClearTokens() {
+	a.tokenMu.Lock()
+	a.accessToken = ""
+	a.refreshToken = ""
+	a.encodedToken = ""
+	a.expiresAt = time.Time{}
+	a.captchaToken = ""
+	a.loginPending = false
+	a.tokenMu.Unlock()
+}
+
 func (a *Auth) DecodeToken() error {
+	a.tokenMu.Lock()
+	defer a.tokenMu.Unlock()
+
 	if a.encodedToken == "" {
 		return exception.ErrInvalidEncodedToken
 	}
@@ -165,6 +301,9 @@ func (a *Auth) DecodeToken() error {
 }
 
 func (a *Auth) EncodeToken() error {
+	a.tokenMu.Lock()
+	defer a.tokenMu.Unlock()
+
 	encoded, err := token.Encode(a.accessToken, a.refreshToken)
 	if err != nil {
 		return exception.NewPikpakExceptionWithError(exception.ErrCodeInvalidEncodedToken, err)
@@ -180,38 +319,56 @@ func (a *Auth) CaptchaInit(ctx context.Context, action string, meta map[string]i
 	}
 	URL := baseURL + "/v1/shield/captcha/init"
 
+	cfg := a.signing()
+	deviceID := a.GetDeviceID()
 	if meta == nil {
-		timestamp := fmt.Sprintf("%d", signer.GetTimestamp())
+		timestamp := fmt.Sprintf("%d", cfg.Timestamp())
 		meta = map[string]interface{}{
-			"captcha_sign":   signer.CaptchaSign(a.deviceID, timestamp),
-			"client_version": signer.ClientVersion,
-			"package_name":   signer.PackageName,
-			"user_id":        a.userID,
+			"captcha_sign":   cfg.CaptchaSign(deviceID, timestamp),
+			"client_version": cfg.ClientVersion,
+			"package_name":   cfg.PackageName,
+			"user_id":        a.GetUserID(),
 			"timestamp":      timestamp,
 		}
 	}
 
 	params := map[string]interface{}{
-		"client_id": constants.ClientID,
+		"client_id": cfg.ClientID,
 		"action":    action,
-		"device_id": a.deviceID,
+		"device_id": deviceID,
 		"meta":      meta,
 	}
 
 	return a.httpClient.PostJSON(ctx, URL, params)
 }
 
+// CaptchaToken extracts the token from a CaptchaInit result. A result that
+// carries a challenge url has to be solved interactively and yields a
+// CaptchaRequiredError instead.
+func (a *Auth) CaptchaToken(result map[string]interface{}, action string) (string, error) {
+	if challengeURL, _ := result["url"].(string); challengeURL != "" {
+		challenge := exception.NewCaptchaRequiredError(challengeURL, action, a.GetDeviceID())
+		if expiresIn, ok := result["expires_in"].(float64); ok && expiresIn > 0 {
+			challenge.ExpiresAt = a.now().Add(time.Duration(expiresIn) * time.Second)
+		}
+		return "", challenge
+	}
+	captchaToken, ok := result["captcha_token"].(string)
+	if !ok || captchaToken == "" {
+		return "", exception.ErrCaptchaTokenFailed
+	}
+	return captchaToken, nil
+}
+
+// Login signs in with the username and password. When the server answers
+// the captcha with a challenge, it returns a CaptchaRequiredError and
+// CompleteLogin finishes the sign-in once the challenge is solved.
 func (a *Auth) Login(ctx context.Context) error {
 	if a.username == "" || a.password == "" {
 		return exception.ErrUsernamePasswordRequired
 	}
 
-	baseURL := a.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.UserHost
-	}
-	loginURL := baseURL + "/v1/auth/signin"
-
+	loginURL := a.loginURL()
 	metas := make(map[string]interface{})
 	emailRegex := regexp.MustCompile(`^[\w.-]+@[\w.-]+\.\w+$`)
 	phoneRegex := regexp.MustCompile(`^\d{11,18}$`)
@@ -229,20 +386,70 @@ func (a *Auth) Login(ctx context.Context) error {
 		return err
 	}
 
-	captchaToken, ok := result["captcha_token"].(string)
-	if !ok || captchaToken == "" {
-		return exception.ErrCaptchaTokenFailed
+	captchaToken, err := a.CaptchaToken(result, "POST:"+loginURL)
+	if err != nil {
+		a.setLoginPending(errors.Is(err, exception.ErrCaptchaRequired))
+		return err
+	}
+
+	return a.signIn(ctx, loginURL, captchaToken)
+}
+
+// CompleteLogin signs in with the captcha token obtained by solving the
+// challenge a Login stopped at.
+func (a *Auth) CompleteLogin(ctx context.Context, captchaToken string) error {
+	if a.username == "" || a.password == "" {
+		return exception.ErrUsernamePasswordRequired
 	}
+	return a.signIn(ctx, a.loginURL(), captchaToken)
+}
 
-	a.captchaToken = captchaToken
+// LoginPending reports whether the last Login stopped at a captcha
+// challenge that CompleteLogin has not finished yet.
+func (a *Auth) LoginPending() bool {
+	a.tokenMu.RLock()
+	defer a.tokenMu.RUnlock()
+	return a.loginPending
+}
+
+func (a *Auth) setLoginPending(pending bool) {
+	a.tokenMu.Lock()
+	defer a.tokenMu.Unlock()
+	a.loginPending = pending
+}
+
+func (a *Auth) loginURL() string {
+	return a.userURL("/v1/auth/signin")
+}
+
+func (a *Auth) userURL(path string) string {
+	baseURL := a.baseURL
+	if baseURL == "" {
+		baseURL = "https://" + constants.UserHost
+	}
+	return baseURL + path
+}
+
+func (a *Auth) signIn(ctx context.Context, loginURL string, captchaToken string) error {
+	return a.signInWith(ctx, loginURL, captchaToken, map[string]string{
+		"password": a.password,
+		"username": a.username,
+	})
+}
+
+// signInWith posts credentials to the sign-in endpoint and stores the tokens
+// of the answer.
+func (a *Auth) signInWith(ctx context.Context, loginURL string, captchaToken string, credentials map[string]string) error {
+	a.SetCaptchaToken(captchaToken)
 
 	loginData := map[string]string{
-		"client_id":     constants.ClientID,
+		"client_id":     a.signing().ClientID,
 		"client_secret": constants.ClientSecret,
-		"password":      a.password,
-		"username":      a.username,
 		"captcha_token": captchaToken,
 	}
+	for key, value := range credentials {
+		loginData[key] = value
+	}
 
 	userInfo, err := a.httpClient.PostForm(ctx, loginURL, loginData)
 	if err != nil {
@@ -250,19 +457,22 @@ func (a *Auth) Login(ctx context.Context) error {
 	}
 
 	if accessToken, ok := userInfo["access_token"].(string); ok {
-		a.accessToken = accessToken
+		a.SetAccessToken(accessToken)
+		a.recordExpiry(userInfo)
 	} else {
 		return exception.NewPikpakExceptionWithMessage(exception.ErrCodeUnknownError, "login failed: no access_token")
 	}
 
 	if refreshToken, ok := userInfo["refresh_token"].(string); ok {
-		a.refreshToken = refreshToken
+		a.SetRefreshToken(refreshToken)
 	}
 
 	if sub, ok := userInfo["sub"].(string); ok {
-		a.userID = sub
+		a.SetUserID(sub)
 	}
 
+	a.setLoginPending(false)
+
 	if err := a.EncodeToken(); err != nil {
 		return err
 	}
@@ -270,7 +480,15 @@ func (a *Auth) Login(ctx context.Context) error {
 	return nil
 }
 
+// RefreshAccessToken exchanges the refresh token for a new access token. It
+// returns exception.ErrMissingRefreshToken without a request when there is
+// no refresh token.
 func (a *Auth) RefreshAccessToken(ctx context.Context) error {
+	refreshToken := a.GetRefreshToken()
+	if refreshToken == "" {
+		return exception.ErrMissingRefreshToken
+	}
+
 	baseURL := a.baseURL
 	if baseURL == "" {
 		baseURL = "https://" + constants.UserHost
@@ -278,8 +496,8 @@ func (a *Auth) RefreshAccessToken(ctx context.Context) error {
 	refreshURL := baseURL + "/v1/auth/token"
 
 	refreshData := map[string]string{
-		"client_id":     constants.ClientID,
-		"refresh_token": a.refreshToken,
+		"client_id":     a.signing().ClientID,
+		"refresh_token": refreshToken,
 		"grant_type":    "refresh_token",
 	}
 
@@ -288,22 +506,19 @@ func (a *Auth) RefreshAccessToken(ctx context.Context) error {
 		return err
 	}
 
-	if accessToken, ok := userInfo["access_token"].(string); ok {
-		a.accessToken = accessToken
-	} else {
+	accessToken, ok := userInfo["access_token"].(string)
+	if !ok {
 		return exception.NewPikpakExceptionWithMessage(exception.ErrCodeUnknownError, "refresh failed: no access_token")
 	}
-
-	if refreshToken, ok := userInfo["refresh_token"].(string); ok {
-		a.refreshToken = refreshToken
+	if newRefreshToken, ok := userInfo["refresh_token"].(string); ok {
+		refreshToken = newRefreshToken
 	}
-
-	if sub, ok := userInfo["sub"].(string); ok {
-		a.userID = sub
+	if err := a.storeTokens(accessToken, refreshToken, a.expiryOf(userInfo)); err != nil {
+		return err
 	}
 
-	if err := a.EncodeToken(); err != nil {
-		return err
+	if sub, ok := userInfo["sub"].(string); ok {
+		a.SetUserID(sub)
 	}
 
 	return nil
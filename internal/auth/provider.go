@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+// Identity providers PikPak accepts ID tokens from.
+const (
+	ProviderGoogle = "google"
+	ProviderApple  = "apple"
+)
+
+func validProvider(provider string) bool {
+	switch provider {
+	case ProviderGoogle, ProviderApple:
+		return true
+	}
+	return false
+}
+
+// LoginWithProviderToken signs in with an ID token issued to the user by
+// provider. No username or password is needed.
+func (a *Auth) LoginWithProviderToken(ctx context.Context, provider string, idToken string) error {
+	if !validProvider(provider) {
+		return exception.NewPikpakExceptionWithMessage(exception.ErrCodeInvalidParameter, "unsupported identity provider "+provider)
+	}
+	if idToken == "" {
+		return exception.NewPikpakExceptionWithMessage(exception.ErrCodeInvalidParameter, "provider token is required")
+	}
+
+	loginURL := a.loginURL()
+	action := "POST:" + loginURL
+	result, err := a.CaptchaInit(ctx, action, nil)
+	if err != nil {
+		return err
+	}
+	captchaToken, err := a.CaptchaToken(result, action)
+	if err != nil {
+		return err
+	}
+
+	return a.signInWith(ctx, loginURL, captchaToken, map[string]string{
+		"grant_type": "provider",
+		"provider":   provider,
+		"id_token":   idToken,
+	})
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
+)
+
+// Authenticator covers signing in, the session tokens and the signed-in
+// account.
+type Authenticator interface {
+	Login(ctx context.Context) error
+	RefreshAccessToken(ctx context.Context) error
+	DecodeToken() error
+	EncodeToken() error
+	GetUserInfo() map[string]string
+	GetMe(ctx context.Context) (*UserProfile, error)
+	Ping(ctx context.Context) (*PingResult, error)
+}
+
+// FileService covers the drive: listing, moving and transferring files and
+// the account's storage.
+type FileService interface {
+	FileList(ctx context.Context, size int, parentID string, nextPageToken string, query string, opts ...ListOption) (map[string]interface{}, error)
+	CreateFolder(ctx context.Context, name string, parentID string) (map[string]interface{}, error)
+	GetFileLink(ctx context.Context, fileID string, opts ...LinkOption) (string, error)
+	GetFileDetails(ctx context.Context, fileID string) (map[string]interface{}, error)
+	GetFileByPath(ctx context.Context, p string) (map[string]interface{}, error)
+	OpenFile(ctx context.Context, fileID string, offset int64, opts ...LinkOption) (io.ReadCloser, error)
+	Move(ctx context.Context, fileID string, parentID string) error
+	Copy(ctx context.Context, fileID string, parentID string) error
+	Rename(ctx context.Context, fileID string, newName string) error
+	FileRename(ctx context.Context, fileID string, newName string) error
+	DeleteToTrash(ctx context.Context, ids []string) (map[string]interface{}, error)
+	Untrash(ctx context.Context, ids []string) (map[string]interface{}, error)
+	DeleteForever(ctx context.Context, ids []string) (map[string]interface{}, error)
+	EmptyTrash(ctx context.Context) error
+	FileBatchStar(ctx context.Context, ids []string, star bool) error
+	FileBatchUnstar(ctx context.Context, ids []string) error
+	FileStarList(ctx context.Context, size int, nextPageToken string) (map[string]interface{}, error)
+	Favorite(ctx context.Context, fileID string, category string) (map[string]interface{}, error)
+	Events(ctx context.Context, size int, nextPageToken string) (map[string]interface{}, error)
+	GetAbout(ctx context.Context) (map[string]interface{}, error)
+	GetQuotaInfo(ctx context.Context) (map[string]interface{}, error)
+	GetStorageInfo(ctx context.Context) (StorageInfo, error)
+	Upload(ctx context.Context, filePath string, parentID string) (map[string]interface{}, error)
+	UploadReader(ctx context.Context, reader io.Reader, fileName string, fileSize int64, parentID string) (map[string]interface{}, error)
+	UploadFile(ctx context.Context, filePath string, parentID string, chunkSize int) (map[string]interface{}, error)
+	GetUploadURL(ctx context.Context, fileName string, fileSize int64, parentID string) (string, error)
+	DownloadToFile(ctx context.Context, fileID string, filePath string, opts ...LinkOption) error
+}
+
+// TaskService covers offline and remote download tasks.
+type TaskService interface {
+	OfflineDownload(ctx context.Context, fileURL string, parentID string, name string, opts ...DownloadOption) (map[string]interface{}, error)
+	OfflineDownloadBatch(ctx context.Context, urls []string, parentID string, opts ...DownloadOption) ([]OfflineBatchResult, error)
+	RemoteDownload(ctx context.Context, fileURL string, opts ...DownloadOption) (map[string]interface{}, error)
+	OfflineList(ctx context.Context, size int, nextPageToken string, phases []string) (map[string]interface{}, error)
+	OfflineListPhases(ctx context.Context, size int, nextPageToken string, phases []enums.PhaseType) (map[string]interface{}, error)
+	OfflineFileInfo(ctx context.Context, fileID string) (map[string]interface{}, error)
+	OfflineTaskRetry(ctx context.Context, taskID string) error
+	DeleteOfflineTasks(ctx context.Context, taskIDs []string, deleteFiles bool) error
+	DeleteTasks(ctx context.Context, taskIDs []string, deleteFiles bool) error
+	GetTaskStatus(ctx context.Context, taskID string, fileID string) (enums.DownloadStatus, error)
+	WatchTasks(ctx context.Context, taskIDs []string, interval time.Duration, fn func(tasks []map[string]interface{})) error
+	WaitForTask(ctx context.Context, taskID string, interval time.Duration) (map[string]interface{}, error)
+	CaptureScreenshot(ctx context.Context, fileID string) (map[string]interface{}, error)
+}
+
+// ShareService covers creating, inspecting and restoring from shares.
+type ShareService interface {
+	FileBatchShare(ctx context.Context, ids []string, needPassword bool) (map[string]interface{}, error)
+	CreateShareLink(ctx context.Context, fileID string, expireSec int, passCode string) (map[string]interface{}, error)
+	Share(ctx context.Context, fileID string, shareType int, expireSec int, passCode string) (map[string]interface{}, error)
+	SetSharePolicy(ctx context.Context, shareID string, policy string) (map[string]interface{}, error)
+	GetShareList(ctx context.Context, size int, nextPageToken string) (map[string]interface{}, error)
+	GetSharePasscode(ctx context.Context, shareID string) (map[string]interface{}, error)
+	CancelShare(ctx context.Context, shareID string) (map[string]interface{}, error)
+	InviteNewShare(ctx context.Context, shareID string, fileIDs []string, inviteMsg string, isNewInvite bool) (map[string]interface{}, error)
+	InviteList(ctx context.Context, shareID string, size int, nextPageToken string) (map[string]interface{}, error)
+	InviteCancel(ctx context.Context, inviteID string) (map[string]interface{}, error)
+	GetShareInfo(ctx context.Context, shareURL string) (map[string]interface{}, error)
+	GetShareFileInfo(ctx context.Context, shareURL string, sharePassword string) (*ShareFileInfo, error)
+	GetShareFiles(ctx context.Context, shareURL string, sharePassword string) ([]*ShareFileInfo, error)
+	GetShareDownloadURL(ctx context.Context, shareURL string, sharePassword string) (string, error)
+	GetShareFileDownloadURL(ctx context.Context, shareURL string, sharePassword string, useTranscoding bool, opts ...LinkOption) (string, error)
+	GetShareFileLink(ctx context.Context, shareID string, fileID string, sharePassword string, opts ...LinkOption) (string, error)
+	Restore(ctx context.Context, shareID string, passCodeToken string, fileIDs []string) (map[string]interface{}, error)
+	RestoreShareURL(ctx context.Context, shareURL string, sharePassword string, fileIDs []string) (map[string]interface{}, error)
+}
+
+// PikPakAPI is everything Client does against the API. Depend on it, or on
+// the narrower service interfaces, to swap in a fake such as
+// testsupport.FakeClient in tests.
+type PikPakAPI interface {
+	Authenticator
+	FileService
+	TaskService
+	ShareService
+}
+
+// ClientInterface is the former name of PikPakAPI.
+type ClientInterface = PikPakAPI
+
+var _ PikPakAPI = (*Client)(nil)
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WithBandwidthLimit caps the combined upload and download rate of all
+// requests to bytesPerSecond. Zero or negative means unlimited.
+func WithBandwidthLimit(bytesPerSecond int64) Option {
+	return func(c *Client) {
+		c.bandwidthLimit = bytesPerSecond
+	}
+}
+
+// WithTimeout sets the overall timeout of each API request, including
+// reading the response body. The default is HTTPTimeout. File transfers
+// (Upload, UploadReader, UploadFile, DownloadToFile and OpenFile) are not
+// bounded by it and run until ctx is done. When ctx has an earlier
+// deadline, that deadline wins.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// bandwidthLimiter paces transfers so that bytes are released no faster
+// than rate per second, shared by every reader it wraps.
+type bandwidthLimiter struct {
+	mu   sync.Mutex
+	rate int64
+	next time.Time
+}
+
+// maxThrottledRead bounds a single read so pacing stays smooth.
+const maxThrottledRead = 32 * 1024
+
+// wait blocks until n more bytes may pass.
+func (l *bandwidthLimiter) wait(ctx context.Context, n int) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	start := l.next
+	l.next = l.next.Add(time.Duration(int64(n) * int64(time.Second) / l.rate))
+	l.mu.Unlock()
+
+	delay := time.Until(start)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type throttledReader struct {
+	ctx     context.Context
+	r       io.ReadCloser
+	limiter *bandwidthLimiter
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > maxThrottledRead {
+		p = p[:maxThrottledRead]
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.wait(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+func (r *throttledReader) Close() error {
+	return r.r.Close()
+}
+
+type throttledTransport struct {
+	base    http.RoundTripper
+	limiter *bandwidthLimiter
+}
+
+func (t *throttledTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody {
+		req = req.Clone(req.Context())
+		req.Body = &throttledReader{ctx: req.Context(), r: req.Body, limiter: t.limiter}
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &throttledReader{ctx: req.Context(), r: resp.Body, limiter: t.limiter}
+	return resp, nil
+}
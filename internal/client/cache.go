@@ -0,0 +1,195 @@
+package client
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const filesPath = "/drive/v1/files"
+
+// WithMetadataCache caches file listing and file metadata GET responses for
+// ttl, keeping at most maxEntries. Download links are never cached. Entries
+// are dropped when Rename, Move, Copy, CreateFolder or a delete touches the
+// same file or parent, and all of them by Client.InvalidateCache and when
+// the account may change: on Login, Logout and when a token or the user ID is
+// set.
+func WithMetadataCache(ttl time.Duration, maxEntries int) Option {
+	return func(c *Client) {
+		if ttl <= 0 || maxEntries <= 0 {
+			c.metadataCache = nil
+			return
+		}
+		c.metadataCache = newMetadataCache(ttl, maxEntries)
+	}
+}
+
+// InvalidateCache drops every cached metadata response.
+func (c *Client) InvalidateCache() {
+	if c.metadataCache != nil {
+		c.metadataCache.clear()
+	}
+}
+
+type cacheEntry struct {
+	body    []byte
+	expires time.Time
+	tags    []string
+}
+
+type metadataCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]*cacheEntry
+	now        func() time.Time
+}
+
+func newMetadataCache(ttl time.Duration, maxEntries int) *metadataCache {
+	return &metadataCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*cacheEntry),
+		now:        time.Now,
+	}
+}
+
+func (m *metadataCache) get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !m.now().Before(entry.expires) {
+		delete(m.entries, key)
+		return nil, false
+	}
+	return entry.body, true
+}
+
+func (m *metadataCache) set(key string, body []byte, tags []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if _, ok := m.entries[key]; !ok && len(m.entries) >= m.maxEntries {
+		m.evictLocked(now)
+	}
+	m.entries[key] = &cacheEntry{body: body, expires: now.Add(m.ttl), tags: tags}
+}
+
+// evictLocked drops expired entries, or the one closest to expiry if none
+// have expired yet.
+func (m *metadataCache) evictLocked(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range m.entries {
+		if !now.Before(entry.expires) {
+			delete(m.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.expires.Before(oldest) {
+			oldestKey, oldest = key, entry.expires
+		}
+	}
+	if len(m.entries) >= m.maxEntries && oldestKey != "" {
+		delete(m.entries, oldestKey)
+	}
+}
+
+func (m *metadataCache) invalidate(tags ...string) {
+	if len(tags) == 0 {
+		return
+	}
+	want := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		want[tag] = true
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, entry := range m.entries {
+		for _, tag := range entry.tags {
+			if want[tag] {
+				delete(m.entries, key)
+				break
+			}
+		}
+	}
+}
+
+func (m *metadataCache) clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = make(map[string]*cacheEntry)
+}
+
+func fileTag(id string) string   { return "file:" + id }
+func parentTag(id string) string { return "parent:" + id }
+
+// metadataCacheKey returns the cache key for a GET, or false when the request
+// is not a files listing or metadata lookup. Requests carrying a usage
+// parameter fetch download links and are skipped.
+func metadataCacheKey(reqURL string, params map[string]string) (string, bool) {
+	u, err := url.Parse(reqURL)
+	if err != nil {
+		return "", false
+	}
+	if _, ok := params["usage"]; ok {
+		return "", false
+	}
+	if u.Path != filesPath && !isFileMetadataPath(u.Path) {
+		return "", false
+	}
+
+	q := u.Query()
+	for key, value := range params {
+		q.Set(key, value)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), true
+}
+
+func isFileMetadataPath(path string) bool {
+	id := strings.TrimPrefix(path, filesPath+"/")
+	return id != path && id != "" && !strings.ContainsAny(id, "/:")
+}
+
+// metadataCacheTags tags a cached response with the file it describes, the
+// parent it lists and every file it lists, so mutations can find it.
+func metadataCacheTags(reqURL string, params map[string]string, result map[string]interface{}) []string {
+	var tags []string
+	if u, err := url.Parse(reqURL); err == nil && isFileMetadataPath(u.Path) {
+		tags = append(tags, fileTag(strings.TrimPrefix(u.Path, filesPath+"/")))
+	}
+	if parentID, ok := params["parent_id"]; ok {
+		tags = append(tags, parentTag(parentID))
+	}
+	if files, ok := result["files"].([]interface{}); ok {
+		for _, f := range files {
+			if file, ok := f.(map[string]interface{}); ok {
+				if id, ok := file["id"].(string); ok {
+					tags = append(tags, fileTag(id))
+				}
+			}
+		}
+	}
+	return tags
+}
+
+func (c *Client) invalidateFiles(fileIDs []string, parentIDs ...string) {
+	if c.metadataCache == nil {
+		return
+	}
+	tags := make([]string, 0, len(fileIDs)+len(parentIDs))
+	for _, id := range fileIDs {
+		tags = append(tags, fileTag(id))
+	}
+	for _, id := range parentIDs {
+		tags = append(tags, parentTag(id))
+	}
+	c.metadataCache.invalidate(tags...)
+}
@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"testing"
+	"time"
+)
+
+// newMetadataServer lists one file under every folder and answers other
+// paths with the details of the file named by the last path element.
+func newMetadataServer(t *testing.T) *stubServer {
+	return newStubServer(t, map[string]http.HandlerFunc{
+		"/drive/v1/files": stubJSON(map[string]interface{}{
+			"files": []interface{}{
+				map[string]interface{}{"id": "f1", "name": "a.txt"},
+			},
+		}),
+		"": func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":               path.Base(r.URL.Path),
+				"web_content_link": "https://example.com/file",
+			})
+		},
+	})
+}
+
+func TestMetadataCache_Hit(t *testing.T) {
+	server := newMetadataServer(t)
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithAccessToken("test_token"),
+		WithMetadataCache(time.Minute, 10),
+	)
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := cli.FileList(ctx, 10, "p1", "", ""); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if gets := server.count(http.MethodGet); gets != 1 {
+		t.Errorf("Expected 1 request for repeated listing, got %d", gets)
+	}
+
+	if _, err := cli.FileList(ctx, 10, "p2", "", ""); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if gets := server.count(http.MethodGet); gets != 2 {
+		t.Errorf("Expected a different parent to miss the cache, got %d requests", gets)
+	}
+}
+
+func TestMetadataCache_TTLExpiry(t *testing.T) {
+	server := newMetadataServer(t)
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithAccessToken("test_token"),
+		WithMetadataCache(time.Minute, 10),
+	)
+
+	now := time.Now()
+	cli.metadataCache.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	cli.OfflineFileInfo(ctx, "f1")
+	cli.OfflineFileInfo(ctx, "f1")
+	if gets := server.count(http.MethodGet); gets != 1 {
+		t.Fatalf("Expected cached metadata before expiry, got %d requests", gets)
+	}
+
+	now = now.Add(time.Minute)
+	cli.OfflineFileInfo(ctx, "f1")
+	if gets := server.count(http.MethodGet); gets != 2 {
+		t.Errorf("Expected refetch after ttl, got %d requests", gets)
+	}
+}
+
+func TestMetadataCache_InvalidatedByRename(t *testing.T) {
+	server := newMetadataServer(t)
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithAccessToken("test_token"),
+		WithMetadataCache(time.Minute, 10),
+	)
+
+	ctx := context.Background()
+	cli.FileList(ctx, 10, "p1", "", "")
+	cli.OfflineFileInfo(ctx, "f1")
+	cli.OfflineFileInfo(ctx, "f2")
+	if gets := server.count(http.MethodGet); gets != 3 {
+		t.Fatalf("Expected 3 requests to warm the cache, got %d", gets)
+	}
+
+	if err := cli.Rename(ctx, "f1", "b.txt"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	cli.FileList(ctx, 10, "p1", "", "")
+	cli.OfflineFileInfo(ctx, "f1")
+	cli.OfflineFileInfo(ctx, "f2")
+	if gets := server.count(http.MethodGet); gets != 5 {
+		t.Errorf("Expected listing and renamed file to be refetched only, got %d requests", gets)
+	}
+}
+
+func TestMetadataCache_ManualInvalidate(t *testing.T) {
+	server := newMetadataServer(t)
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithAccessToken("test_token"),
+		WithMetadataCache(time.Minute, 10),
+	)
+
+	ctx := context.Background()
+	cli.FileList(ctx, 10, "p1", "", "")
+	cli.InvalidateCache()
+	cli.FileList(ctx, 10, "p1", "", "")
+	if gets := server.count(http.MethodGet); gets != 2 {
+		t.Errorf("Expected refetch after InvalidateCache, got %d requests", gets)
+	}
+}
+
+func TestMetadataCache_SkipsDownloadLinks(t *testing.T) {
+	server := newMetadataServer(t)
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithAccessToken("test_token"),
+		WithMetadataCache(time.Minute, 10),
+	)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := cli.GetFileLink(ctx, "f1"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if gets := server.count(http.MethodGet); gets != 2 {
+		t.Errorf("Expected download links to bypass the cache, got %d requests", gets)
+	}
+}
+
+func TestMetadataCache_MaxEntries(t *testing.T) {
+	cache := newMetadataCache(time.Minute, 2)
+	cache.set("a", []byte("a"), nil)
+	cache.set("b", []byte("b"), nil)
+	cache.set("c", []byte("c"), nil)
+
+	if len(cache.entries) != 2 {
+		t.Errorf("Expected 2 entries, got %d", len(cache.entries))
+	}
+	if _, ok := cache.get("c"); !ok {
+		t.Error("Expected newest entry to be kept")
+	}
+}
+
+func TestMetadataCache_ClearedOnAccountChange(t *testing.T) {
+	server := newStubServer(t, map[string]http.HandlerFunc{
+		"/drive/v1/files":         stubJSON(map[string]interface{}{"files": []interface{}{}}),
+		"/v1/shield/captcha/init": stubJSON(map[string]interface{}{"captcha_token": "captcha"}),
+		"/v1/auth/signin":         stubJSON(map[string]interface{}{"access_token": "access_b", "refresh_token": "refresh_b", "sub": "user_b"}),
+		"/v1/auth/revoke":         stubJSON(map[string]interface{}{}),
+	})
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithUsername("b@example.com"),
+		WithPassword("password"),
+		WithAccessToken("access_a"),
+		WithMetadataCache(time.Minute, 10),
+	)
+
+	ctx := context.Background()
+	listings := 0
+	list := func(step string) {
+		t.Helper()
+		if _, err := cli.FileList(ctx, 10, "p1", "", ""); err != nil {
+			t.Fatalf("%s: expected no error, got %v", step, err)
+		}
+		listings++
+		if got := len(server.requests("/drive/v1/files")); got != listings {
+			t.Errorf("%s: expected the listing of the new account, got %d requests for %d listings", step, got, listings)
+		}
+	}
+
+	list("first listing")
+	cli.SetAccessToken("access_c")
+	list("SetAccessToken")
+	if err := cli.Login(ctx); err != nil {
+		t.Fatal(err)
+	}
+	list("Login")
+	if err := cli.Logout(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.Login(ctx); err != nil {
+		t.Fatal(err)
+	}
+	list("Logout and Login")
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+// captchaInvalidCode is the error_code PikPak returns when the captcha token
+// sent with a request is missing, invalid or expired.
+const captchaInvalidCode = 9
+
+// CompleteCaptcha continues after a CaptchaRequiredError with the captcha
+// token the user obtained by solving the challenge page. When Login stopped
+// at the challenge, the sign-in is completed with the token; otherwise the
+// token is sent with the following requests.
+func (c *Client) CompleteCaptcha(ctx context.Context, captchaToken string) error {
+	if captchaToken == "" {
+		return exception.NewPikpakExceptionWithMessage(exception.ErrCodeInvalidParameter, "captcha token is required")
+	}
+	if !c.authModule.LoginPending() {
+		c.authModule.SetCaptchaToken(captchaToken)
+		return nil
+	}
+	if err := c.authModule.CompleteLogin(ctx, captchaToken); err != nil {
+		return err
+	}
+	c.loggedIn()
+	return nil
+}
+
+func isCaptchaInvalid(respData map[string]interface{}) bool {
+	errCode, ok := respData["error_code"].(float64)
+	return ok && int(errCode) == captchaInvalidCode
+}
+
+func captchaAction(req *http.Request) string {
+	return req.Method + ":" + req.URL.Path
+}
+
+// refreshCaptchaToken fetches and stores a new captcha token for action.
+// The client holds a single captcha token, so concurrent refreshes share one
+// CaptchaInit call whatever their action instead of overwriting each other.
+func (c *Client) refreshCaptchaToken(ctx context.Context, action string) error {
+	return c.captchaFlight.do(ctx, "captcha", func() error {
+		result, err := c.authModule.CaptchaInit(ctx, action, nil)
+		if err != nil {
+			return err
+		}
+		captchaToken, err := c.authModule.CaptchaToken(result, action)
+		if err != nil {
+			return err
+		}
+		c.authModule.SetCaptchaToken(captchaToken)
+		return nil
+	})
+}
+
+type flightCall struct {
+	done chan struct{}
+	err  error
+}
+
+// flightGroup collapses concurrent calls with the same key into one.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// do runs fn unless a call with the same key is in flight, in which case it
+// waits for that call's result or for ctx to be done, whichever comes first.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() error) error {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.err
+		case <-ctx.Done():
+			return exception.NewPikpakExceptionWithError(exception.ErrCodeTimeout, ctx.Err())
+		}
+	}
+	call := &flightCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	call.err = fn()
+	close(call.done)
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+
+	return call.err
+}
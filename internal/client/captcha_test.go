@@ -0,0 +1,413 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+func TestCaptchaInvalid_RefreshesAndRetriesOnce(t *testing.T) {
+	var driveCalls, captchaCalls int32
+	var action string
+	var retriedToken string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/shield/captcha/init" {
+			atomic.AddInt32(&captchaCalls, 1)
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			action, _ = body["action"].(string)
+			json.NewEncoder(w).Encode(map[string]interface{}{"captcha_token": "fresh_captcha"})
+			return
+		}
+		if atomic.AddInt32(&driveCalls, 1) == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error_code": 9, "error": "captcha_invalid"})
+			return
+		}
+		retriedToken = r.Header.Get("X-Captcha-Token")
+		json.NewEncoder(w).Encode(map[string]interface{}{"files": []interface{}{}})
+	}))
+	defer server.Close()
+
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithAccessToken("test_token"),
+		WithMaxRetries(0),
+	)
+
+	if _, err := cli.FileList(context.Background(), 10, "", "", ""); err != nil {
+		t.Fatalf("Expected no error after captcha refresh, got %v", err)
+	}
+	if captchaCalls != 1 {
+		t.Errorf("Expected 1 captcha init, got %d", captchaCalls)
+	}
+	if driveCalls != 2 {
+		t.Errorf("Expected 2 drive calls, got %d", driveCalls)
+	}
+	if action != "GET:/drive/v1/files" {
+		t.Errorf("Expected action 'GET:/drive/v1/files', got %q", action)
+	}
+	if retriedToken != "fresh_captcha" {
+		t.Errorf("Expected retry to carry the new captcha token, got %q", retriedToken)
+	}
+}
+
+func TestCaptchaInvalid_RetriedOnlyOnce(t *testing.T) {
+	var driveCalls, captchaCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/shield/captcha/init" {
+			atomic.AddInt32(&captchaCalls, 1)
+			json.NewEncoder(w).Encode(map[string]interface{}{"captcha_token": "fresh_captcha"})
+			return
+		}
+		atomic.AddInt32(&driveCalls, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error_code": 9, "error": "captcha_invalid"})
+	}))
+	defer server.Close()
+
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithAccessToken("test_token"),
+		WithMaxRetries(0),
+	)
+
+	if _, err := cli.GetAbout(context.Background()); err == nil {
+		t.Fatal("Expected error when captcha stays invalid")
+	}
+	if captchaCalls != 1 || driveCalls != 2 {
+		t.Errorf("Expected 1 captcha init and 2 drive calls, got %d and %d", captchaCalls, driveCalls)
+	}
+}
+
+func TestCaptchaInvalid_CaptchaInitAlsoInvalid(t *testing.T) {
+	var captchaCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/shield/captcha/init" {
+			atomic.AddInt32(&captchaCalls, 1)
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error_code": 9, "error": "captcha_invalid"})
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithMaxRetries(0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := cli.FileList(ctx, 10, "", "", ""); err == nil {
+		t.Fatal("Expected error when every request is answered with error_code 9")
+	}
+	if ctx.Err() != nil {
+		t.Fatal("Expected the request to fail without waiting for the context")
+	}
+	if captchaCalls != 1 {
+		t.Errorf("Expected 1 captcha init, got %d", captchaCalls)
+	}
+}
+
+func TestFlightGroup_WaiterHonorsContext(t *testing.T) {
+	var g flightGroup
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	go g.do(context.Background(), "key", func() error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := g.do(ctx, "key", func() error { return nil })
+	if !errors.Is(err, exception.ErrTimeout) {
+		t.Errorf("Expected the waiter to give up with the context, got %v", err)
+	}
+}
+
+func TestFlightGroup_SharesConcurrentCalls(t *testing.T) {
+	var g flightGroup
+	var calls int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g.do(context.Background(), "key", func() error {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				return nil
+			})
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("Expected a single shared call, got %d", calls)
+	}
+}
+
+func TestCaptchaChallenge_ReturnsCaptchaRequired(t *testing.T) {
+	var driveCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/shield/captcha/init" {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"captcha_token": "ck0.pending",
+				"expires_in":    300,
+				"url":           "https://user.mypikpak.com/captcha/v2/spritePuzzle.html",
+			})
+			return
+		}
+		atomic.AddInt32(&driveCalls, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error_code": 9, "error": "captcha_invalid"})
+	}))
+	defer server.Close()
+
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithAccessToken("test_token"),
+		WithDeviceID("device_id"),
+		WithMaxRetries(0),
+	)
+
+	_, err := cli.FileList(context.Background(), 10, "", "", "")
+	var ce *exception.CaptchaRequiredError
+	if !errors.As(err, &ce) {
+		t.Fatalf("Expected CaptchaRequiredError, got %v", err)
+	}
+	if ce.URL != "https://user.mypikpak.com/captcha/v2/spritePuzzle.html" || ce.Action != "GET:/drive/v1/files" || ce.DeviceID != "device_id" {
+		t.Errorf("Unexpected challenge details: %+v", ce)
+	}
+	if driveCalls != 1 {
+		t.Errorf("Expected no retry without a captcha token, got %d drive calls", driveCalls)
+	}
+
+	cli = NewClient(
+		WithBaseURL(server.URL),
+		WithUsername("user@example.com"),
+		WithPassword("password"),
+	)
+	if err := cli.Login(context.Background()); !errors.Is(err, exception.ErrCaptchaRequired) {
+		t.Errorf("Expected login to report ErrCaptchaRequired, got %v", err)
+	}
+}
+
+func TestLogin_CaptchaResponseShapes(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		captcha     map[string]interface{}
+		wantToken   string
+		wantPending bool
+	}{
+		{
+			name:      "token",
+			captcha:   map[string]interface{}{"captcha_token": "ck0.issued", "expires_in": 300},
+			wantToken: "ck0.issued",
+		},
+		{
+			name:        "challenge_url",
+			captcha:     map[string]interface{}{"captcha_token": "ck0.pending", "expires_in": 300, "url": "https://user.mypikpak.com/captcha/v2/spritePuzzle.html"},
+			wantToken:   "ck0.solved",
+			wantPending: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var signinCaptcha string
+			var captchaCalls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/v1/shield/captcha/init":
+					atomic.AddInt32(&captchaCalls, 1)
+					json.NewEncoder(w).Encode(tt.captcha)
+				case "/v1/auth/signin":
+					r.ParseForm()
+					mu.Lock()
+					signinCaptcha = r.PostForm.Get("captcha_token")
+					mu.Unlock()
+					json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "access", "refresh_token": "refresh", "sub": "user1"})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			cli := NewClient(WithBaseURL(server.URL), WithUsername("user@example.com"), WithPassword("password"))
+			cli.now = func() time.Time { return now }
+
+			err := cli.Login(context.Background())
+			if tt.wantPending {
+				var ce *exception.CaptchaRequiredError
+				if !errors.As(err, &ce) {
+					t.Fatalf("Expected CaptchaRequiredError, got %v", err)
+				}
+				if ce.URL != tt.captcha["url"] || !ce.ExpiresAt.Equal(now.Add(5*time.Minute)) {
+					t.Errorf("Unexpected challenge details: %+v", ce)
+				}
+				if cli.GetAccessToken() != "" {
+					t.Fatal("Expected no sign-in before the challenge is solved")
+				}
+				err = cli.CompleteCaptcha(context.Background(), "ck0.solved")
+			}
+			if err != nil {
+				t.Fatalf("Expected the login to succeed, got %v", err)
+			}
+
+			if signinCaptcha != tt.wantToken {
+				t.Errorf("Expected sign-in with captcha token %q, got %q", tt.wantToken, signinCaptcha)
+			}
+			if cli.GetAccessToken() != "access" || cli.GetUserID() != "user1" {
+				t.Errorf("Expected the tokens of the sign-in, got %q %q", cli.GetAccessToken(), cli.GetUserID())
+			}
+			if captchaCalls != 1 {
+				t.Errorf("Expected one captcha init, got %d", captchaCalls)
+			}
+		})
+	}
+}
+
+func TestCompleteCaptcha_WithoutPendingLogin(t *testing.T) {
+	cli := NewClient(WithAccessToken("test_token"))
+	if err := cli.CompleteCaptcha(context.Background(), ""); !errors.Is(err, exception.ErrInvalidParameter) {
+		t.Errorf("Expected an empty token to be rejected, got %v", err)
+	}
+	if err := cli.CompleteCaptcha(context.Background(), "ck0.solved"); err != nil {
+		t.Fatal(err)
+	}
+	if got := cli.getHeaders()["X-Captcha-Token"]; got != "ck0.solved" {
+		t.Errorf("Expected the token on the following requests, got %q", got)
+	}
+}
+
+func TestCaptchaInvalid_ConcurrentRefreshes(t *testing.T) {
+	var captchaCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/shield/captcha/init" {
+			atomic.AddInt32(&captchaCalls, 1)
+			time.Sleep(5 * time.Millisecond)
+			json.NewEncoder(w).Encode(map[string]interface{}{"captcha_token": "fresh_captcha"})
+			return
+		}
+		if r.Header.Get("X-Captcha-Token") != "fresh_captcha" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error_code": 9, "error": "captcha_invalid"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "f1"})
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithMaxRetries(0))
+
+	// Different paths mean different captcha actions; their refreshes
+	// overlap while headers are read for the other requests.
+	done := make(chan struct{})
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				cli.getHeaders()
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			if i%2 == 0 {
+				_, err = cli.FileList(context.Background(), 10, "", "", "")
+			} else {
+				_, err = cli.GetFileDetails(context.Background(), "f1")
+			}
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(done)
+	<-readerDone
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Expected every request to succeed after the refresh, got %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(&captchaCalls); n < 1 || n > 20 {
+		t.Errorf("Expected shared captcha refreshes, got %d", n)
+	}
+}
+
+// Run with -race: the device ID and the pending sign-in are shared by Login,
+// CompleteCaptcha and the request headers.
+func TestCompleteCaptcha_ConcurrentWithLogin(t *testing.T) {
+	server := newStubServer(t, map[string]http.HandlerFunc{
+		"/v1/shield/captcha/init": stubJSON(map[string]interface{}{"captcha_token": "ck0.pending", "url": "https://user.mypikpak.com/captcha/v2/spritePuzzle.html"}),
+		"/v1/auth/signin":         stubJSON(map[string]interface{}{"access_token": "access", "refresh_token": "refresh", "sub": "user1"}),
+	})
+	cli := NewClient(WithBaseURL(server.URL), WithUsername("user@example.com"), WithPassword("password"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(3)
+		go func(i int) {
+			defer wg.Done()
+			cli.SetDeviceID(fmt.Sprintf("device%d", i))
+			cli.getHeaders()
+		}(i)
+		go func() {
+			defer wg.Done()
+			if err := cli.Login(context.Background()); !errors.Is(err, exception.ErrCaptchaRequired) {
+				t.Errorf("Expected login to stop at the challenge, got %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := cli.CompleteCaptcha(context.Background(), "ck0.solved"); err != nil {
+				t.Errorf("Expected CompleteCaptcha to succeed, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := cli.CompleteCaptcha(context.Background(), "ck0.solved"); err != nil {
+		t.Fatal(err)
+	}
+	if cli.authModule.LoginPending() {
+		t.Error("Expected no sign-in pending after CompleteCaptcha")
+	}
+	if cli.GetDeviceID() == "" {
+		t.Error("Expected a device ID")
+	}
+}
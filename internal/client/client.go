@@ -7,9 +7,9 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"mime/multipart"
 	"net/http"
 	"net/url"
@@ -18,62 +18,26 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/zhz8888/pikpakapi-go/internal/auth"
-	"github.com/zhz8888/pikpakapi-go/internal/constants"
 	"github.com/zhz8888/pikpakapi-go/internal/download"
+	"github.com/zhz8888/pikpakapi-go/internal/event"
 	"github.com/zhz8888/pikpakapi-go/internal/exception"
 	"github.com/zhz8888/pikpakapi-go/internal/file"
+	"github.com/zhz8888/pikpakapi-go/internal/query"
 	"github.com/zhz8888/pikpakapi-go/internal/share"
-	"github.com/zhz8888/pikpakapi-go/internal/useragent"
+	"github.com/zhz8888/pikpakapi-go/internal/signer"
+	"github.com/zhz8888/pikpakapi-go/internal/token"
 	"github.com/zhz8888/pikpakapi-go/pkg/enums"
 )
 
 const (
-	HTTPTimeout = 30 * time.Second
+	HTTPTimeout             = 30 * time.Second
+	DefaultMaxResponseBytes = 8 << 20
 )
 
-type ClientInterface interface {
-	Login(ctx context.Context) error
-	RefreshAccessToken(ctx context.Context) error
-	DecodeToken() error
-	EncodeToken() error
-	GetUserInfo() map[string]string
-
-	FileList(ctx context.Context, size int, parentID string, nextPageToken string, query string) (map[string]interface{}, error)
-	CreateFolder(ctx context.Context, name string, parentID string) (map[string]interface{}, error)
-	GetFileLink(ctx context.Context, fileID string) (string, error)
-	Move(ctx context.Context, fileID string, parentID string) error
-	Copy(ctx context.Context, fileID string, parentID string) error
-	Rename(ctx context.Context, fileID string, newName string) error
-	DeleteToTrash(ctx context.Context, ids []string) (map[string]interface{}, error)
-	Untrash(ctx context.Context, ids []string) (map[string]interface{}, error)
-	DeleteForever(ctx context.Context, ids []string) (map[string]interface{}, error)
-	GetAbout(ctx context.Context) (map[string]interface{}, error)
-
-	OfflineDownload(ctx context.Context, fileURL string, parentID string, name string) (map[string]interface{}, error)
-	OfflineList(ctx context.Context, size int, nextPageToken string, phases []string) (map[string]interface{}, error)
-	DeleteOfflineTasks(ctx context.Context, taskIDs []string, deleteFiles bool) error
-	DeleteTasks(ctx context.Context, taskIDs []string, deleteFiles bool) error
-	GetTaskStatus(ctx context.Context, taskID string, fileID string) (enums.DownloadStatus, error)
-	CaptureScreenshot(ctx context.Context, fileID string) (map[string]interface{}, error)
-
-	FileBatchShare(ctx context.Context, ids []string, needPassword bool) (map[string]interface{}, error)
-	GetShareInfo(ctx context.Context, shareURL string) (map[string]interface{}, error)
-	Restore(ctx context.Context, shareID string, passCodeToken string, fileIDs []string) (map[string]interface{}, error)
-	GetStorageInfo(ctx context.Context) (StorageInfo, error)
-	OfflineTaskRetry(ctx context.Context, taskID string) error
-	FileRename(ctx context.Context, fileID string, newName string) error
-	FileBatchStar(ctx context.Context, ids []string, star bool) error
-	FileStarList(ctx context.Context, size int, nextPageToken string) (map[string]interface{}, error)
-	FileBatchUnstar(ctx context.Context, ids []string) error
-	Upload(ctx context.Context, filePath string, parentID string) (map[string]interface{}, error)
-	UploadReader(ctx context.Context, reader io.Reader, fileName string, fileSize int64, parentID string) (map[string]interface{}, error)
-	CreateShareLink(ctx context.Context, fileID string, expireSec int, passCode string) (map[string]interface{}, error)
-	GetShareDownloadURL(ctx context.Context, shareURL string, sharePassword string) (string, error)
-}
-
 type Client struct {
 	authModule  *auth.Auth
 	fileModule  *file.File
@@ -88,6 +52,38 @@ type Client struct {
 	tokenRefreshCallback    func(*Client)
 	tokenRefreshCallbackCtx context.Context
 	baseURL                 string
+	driveBaseURL            string
+	userBaseURL             string
+	retryNonIdempotent      bool
+	retryPolicy             RetryPolicy
+	requestSem              chan struct{}
+	inFlight                int64
+	metrics                 MetricsCollector
+	maxResponseBytes        int64
+	driveHosts              []string
+	activeDriveHost         int32
+	dialContext             DialContextFunc
+	hostOverrides           map[string]string
+	proxyURL                *url.URL
+	preferredLink           LinkPreference
+	thumbnailSize           enums.ThumbnailSize
+	downloadHost            string
+	bandwidthLimit          int64
+	metadataCache           *metadataCache
+	captchaFlight           flightGroup
+	tokenFlight             flightGroup
+	tokenStore              token.TokenStore
+	tokenAccount            string
+	configSaver             *configAutoSaver
+	progress                ProgressFunc
+	signing                 *signer.Holder
+	backoffJitter           bool
+	tokenRefreshMargin      time.Duration
+	logger                  Logger
+	rateLimiter             *rateLimiter
+	events                  *event.EventBus
+	loggedOut               atomic.Bool
+	now                     func() time.Time
 }
 
 type Option func(*Client)
@@ -128,6 +124,23 @@ func WithBaseURL(baseURL string) Option {
 	}
 }
 
+// WithThumbnailSize sets the thumbnail size requested by FileList,
+// FileStarList, Events, GetFileLink, GetShareFiles and OfflineFileInfo in
+// place of each call's default. NewClientE rejects an invalid size; with
+// NewClient it makes those calls fail with ErrInvalidParameter before any
+// request is sent.
+func WithThumbnailSize(size enums.ThumbnailSize) Option {
+	return func(c *Client) {
+		c.thumbnailSize = size
+	}
+}
+
+func WithRetryNonIdempotent(enabled bool) Option {
+	return func(c *Client) {
+		c.retryNonIdempotent = enabled
+	}
+}
+
 func WithDeviceID(deviceID string) Option {
 	return func(c *Client) {
 		c.authModule.WithDeviceID(deviceID)
@@ -146,78 +159,183 @@ func WithRefreshToken(token string) Option {
 	}
 }
 
+// DownloadOption adjusts a single OfflineDownload or RemoteDownload call.
+type DownloadOption func(*downloadOptions)
+
+type downloadOptions struct {
+	folderType    enums.FolderType
+	folderTypeSet bool
+}
+
+// WithFolderType forces the folder_type sent with a download request.
+func WithFolderType(folderType enums.FolderType) DownloadOption {
+	return func(o *downloadOptions) {
+		o.folderType = folderType
+		o.folderTypeSet = true
+	}
+}
+
+// ListOption adjusts a single listing call.
+type ListOption func(*listOptions)
+
+type listOptions struct {
+	sortField enums.SortField
+	sortOrder enums.SortOrder
+}
+
+// WithSort orders a listing by field. An empty order means SortOrderAsc.
+func WithSort(field enums.SortField, order enums.SortOrder) ListOption {
+	return func(o *listOptions) {
+		o.sortField = field
+		o.sortOrder = order
+	}
+}
+
+func (o *listOptions) validate() error {
+	if o.sortField == "" && o.sortOrder == "" {
+		return nil
+	}
+	if !o.sortField.IsValid() {
+		return exception.NewPikpakExceptionWithMessage(exception.ErrCodeInvalidParameter, fmt.Sprintf("invalid sort field %q", o.sortField))
+	}
+	if o.sortOrder == "" {
+		o.sortOrder = enums.SortOrderAsc
+	}
+	if !o.sortOrder.IsValid() {
+		return exception.NewPikpakExceptionWithMessage(exception.ErrCodeInvalidParameter, fmt.Sprintf("invalid sort order %q", o.sortOrder))
+	}
+	return nil
+}
+
+// generateDeviceID returns 32 random hex digits, the format of the app's
+// device IDs.
 func generateDeviceID() string {
 	b := make([]byte, 16)
 	rand.Read(b)
 	return hex.EncodeToString(b)
 }
 
+// NewClient creates a client. Invalid option values are reported to the
+// logger and replaced by their defaults; use NewClientE to get them as an
+// error instead.
 func NewClient(opts ...Option) *Client {
+	c := newClient(opts)
+	if err := c.validateOptions(); err != nil {
+		c.logger.Warnf("Ignoring invalid client options: %v", strings.ReplaceAll(err.Error(), "\n", "; "))
+	}
+	c.init()
+	return c
+}
+
+func newClient(opts []Option) *Client {
 	c := &Client{
-		maxRetries:     3,
-		initialBackoff: 3 * time.Second,
+		maxRetries:     defaultMaxRetries,
+		initialBackoff: defaultInitialBackoff,
+		backoffJitter:  true,
 		httpClient: &http.Client{
 			Timeout: HTTPTimeout,
 		},
-		baseURL: "",
+		baseURL:            "",
+		driveHosts:         defaultDriveHosts(),
+		maxResponseBytes:   DefaultMaxResponseBytes,
+		signing:            signer.NewHolder(signer.DefaultConfig()),
+		tokenRefreshMargin: DefaultTokenRefreshMargin,
+		logger:             nopLogger{},
+		now:                time.Now,
 	}
 
 	c.authModule = auth.NewAuth(
-		auth.WithUsername(c.username),
-		auth.WithPassword(c.password),
-		auth.WithBaseURL(c.baseURL),
+		auth.WithSigner(c.signing),
+		auth.WithClock(func() time.Time { return c.now() }),
 	)
 
 	for _, opt := range opts {
 		opt(c)
 	}
+	return c
+}
+
+// init wires the modules and transport once the options are validated.
+func (c *Client) init() {
+	c.configureTransport()
+
+	for _, opt := range []auth.AuthOption{
+		auth.WithUsername(c.username),
+		auth.WithPassword(c.password),
+		auth.WithBaseURL(c.userBaseOverride()),
+	} {
+		opt(c.authModule)
+	}
 
 	if c.GetDeviceID() == "" {
 		c.SetDeviceID(generateDeviceID())
 	}
 
+	if c.tokenStore != nil {
+		if store, ok := c.tokenStore.(*token.KeyringTokenStore); ok {
+			store.SetLogger(c.logger)
+		}
+		c.loadStoredToken()
+	}
+
 	c.fileModule = file.NewFile(
-		file.WithFileBaseURL(c.baseURL),
+		file.WithFileBaseURL(c.driveBaseOverride()),
+		file.WithFileThumbnailSize(c.thumbnailSize),
 	)
 
 	c.downloadMod = download.NewDownload(
-		download.WithDownloadBaseURL(c.baseURL),
+		download.WithDownloadBaseURL(c.driveBaseOverride()),
+		download.WithDownloadThumbnailSize(c.thumbnailSize),
 	)
 
 	c.shareModule = share.NewShare(
-		share.WithShareBaseURL(c.baseURL),
+		share.WithShareBaseURL(c.driveBaseOverride()),
 	)
 
 	c.authModule.SetHTTPClient(c)
 	c.fileModule.SetHTTPClient(c)
 	c.downloadMod.SetHTTPClient(c)
 	c.shareModule.SetHTTPClient(c)
-
-	return c
 }
 
 func (c *Client) SetDeviceID(deviceID string) {
 	c.authModule.WithDeviceID(deviceID)
+	c.signing.Invalidate()
 }
 
 func (c *Client) GetDeviceID() string {
 	return c.authModule.GetDeviceID()
 }
 
+// DeviceID returns the device ID sent with every request and signed into
+// captcha requests. Without WithDeviceID or a device_id in the config it is
+// random per client; save it with ApplyToConfig so that later sessions
+// present the same device.
+func (c *Client) DeviceID() string {
+	return c.authModule.GetDeviceID()
+}
+
+// SetAccessToken, SetRefreshToken, SetUserID and SetEncodedToken may switch
+// the account, so they drop the cached metadata, as do Login and Logout.
 func (c *Client) SetAccessToken(token string) {
 	c.authModule.SetAccessToken(token)
+	c.InvalidateCache()
 }
 
 func (c *Client) SetRefreshToken(token string) {
 	c.authModule.SetRefreshToken(token)
+	c.InvalidateCache()
 }
 
 func (c *Client) SetUserID(userID string) {
 	c.authModule.SetUserID(userID)
+	c.signing.Invalidate()
+	c.InvalidateCache()
 }
 
 func (c *Client) SetEncodedToken(token string) {
 	c.authModule.SetEncodedToken(token)
+	c.InvalidateCache()
 }
 
 func (c *Client) GetAccessToken() string {
@@ -246,14 +364,24 @@ func (c *Client) GetUserInfo() map[string]string {
 	}
 }
 
+// Login signs in with the username and password. When the server asks for
+// a captcha only a person can solve, it returns a CaptchaRequiredError; see
+// CompleteCaptcha.
 func (c *Client) Login(ctx context.Context) error {
 	if err := c.authModule.Login(ctx); err != nil {
 		return err
 	}
-	c.username = c.authModule.GetUserID()
+	c.loggedIn()
 	return nil
 }
 
+func (c *Client) loggedIn() {
+	c.InvalidateCache()
+	c.username = c.authModule.GetUserID()
+	c.saveToken()
+	c.autoSaveConfig()
+}
+
 type ShareFileInfo struct {
 	ID            string
 	Name          string
@@ -302,25 +430,18 @@ func (c *Client) GetStorageInfo(ctx context.Context) (StorageInfo, error) {
 	if expiresAt, ok := result["expires_at"].(string); ok {
 		storage.ExpiresAt = expiresAt
 	}
-	if userType, ok := result["user_type"].(float64); ok {
-		storage.UserType = int(userType)
+	switch userType := result["user_type"].(type) {
+	case float64:
+		storage.UserType = enums.UserType(userType)
+	case string:
+		storage.UserType = enums.ParseUserType(userType)
 	}
 
 	return storage, nil
 }
 
 func (c *Client) OfflineTaskRetry(ctx context.Context, taskID string) error {
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
-	}
-	URL := baseURL + "/drive/v1/files/" + taskID
-
-	data := map[string]interface{}{
-		"status": "PENDING",
-	}
-
-	_, err := c.PostJSON(ctx, URL, data)
+	_, err := c.downloadMod.OfflineTaskRetry(ctx, taskID)
 	return err
 }
 
@@ -329,11 +450,7 @@ func (c *Client) FileRename(ctx context.Context, fileID string, newName string)
 }
 
 func (c *Client) FileBatchStar(ctx context.Context, ids []string, star bool) error {
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
-	}
-	URL := baseURL + "/drive/v1/files:batchStar"
+	URL := c.driveURL("/drive/v1/files:batchStar")
 
 	data := map[string]interface{}{
 		"ids":  ids,
@@ -345,25 +462,19 @@ func (c *Client) FileBatchStar(ctx context.Context, ids []string, star bool) err
 }
 
 func (c *Client) FileStarList(ctx context.Context, size int, nextPageToken string) (map[string]interface{}, error) {
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
-	}
-	URL := baseURL + "/drive/v1/files"
+	URL := c.driveURL("/drive/v1/files")
 
 	if size == 0 {
 		size = 50
 	}
 
-	params := map[string]string{
-		"limit":          strconv.Itoa(size),
-		"starred":        "true",
-		"thumbnail_size": "SIZE_LARGE",
+	thumbnailSize, err := file.ResolveThumbnailSize(c.thumbnailSize, enums.ThumbnailSizeLarge)
+	if err != nil {
+		return nil, err
 	}
 
-	if nextPageToken != "" {
-		params["page_token"] = nextPageToken
-	}
+	params := query.List(size, nextPageToken).ThumbnailSize(thumbnailSize)
+	params["starred"] = "true"
 
 	return c.GetJSON(ctx, URL, params)
 }
@@ -377,26 +488,16 @@ func (c *Client) Upload(ctx context.Context, filePath string, parentID string) (
 }
 
 func (c *Client) UploadReader(ctx context.Context, reader io.Reader, fileName string, fileSize int64, parentID string) (map[string]interface{}, error) {
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
-	}
-
 	uploadURL, err := c.GetUploadURL(ctx, fileName, fileSize, parentID)
 	if err != nil {
 		return nil, err
 	}
 
-	file := &os.File{}
-	if f, ok := reader.(*os.File); ok {
-		file = f
-	}
-
-	if file == nil {
-		return nil, exception.NewPikpakExceptionWithMessage(exception.ErrCodeInvalidParameter, "reader must be *os.File")
+	if reader == nil {
+		return nil, exception.NewPikpakExceptionWithMessage(exception.ErrCodeInvalidParameter, "reader is required")
 	}
 
-	return c.uploadFileSmall(ctx, uploadURL, file, fileName, fileSize, parentID)
+	return c.uploadFileSmall(ctx, uploadURL, reader, fileName, fileSize, parentID)
 }
 
 func (c *Client) CreateShareLink(ctx context.Context, fileID string, expireSec int, passCode string) (map[string]interface{}, error) {
@@ -410,14 +511,36 @@ func (c *Client) GetShareDownloadURL(ctx context.Context, shareURL string, share
 	return c.GetShareFileDownloadURL(ctx, shareURL, sharePassword, false)
 }
 
+// RefreshAccessToken exchanges the refresh token for a new access token.
+// Concurrent calls share a single refresh.
 func (c *Client) RefreshAccessToken(ctx context.Context) error {
-	if err := c.authModule.RefreshAccessToken(ctx); err != nil {
-		return err
-	}
-	if c.tokenRefreshCallback != nil {
+	return c.refreshAccessToken(ctx, "")
+}
+
+// refreshAccessToken refreshes the access token unless it already differs
+// from stale, the token a caller found expired, in which case another caller
+// has refreshed it in the meantime.
+func (c *Client) refreshAccessToken(ctx context.Context, stale string) error {
+	refreshed := false
+	err := c.tokenFlight.do(ctx, "token", func() error {
+		if stale != "" && c.authModule.GetAccessToken() != stale {
+			return nil
+		}
+		if err := c.authModule.RefreshAccessToken(ctx); err != nil {
+			return err
+		}
+		refreshed = true
+		c.logger.Infof("Access token refreshed")
+		c.saveToken()
+		c.autoSaveConfig()
+		return nil
+	})
+	// Only the caller that refreshed runs the callback, outside the flight
+	// so that it may make requests of its own.
+	if refreshed && c.tokenRefreshCallback != nil {
 		c.tokenRefreshCallback(c)
 	}
-	return nil
+	return err
 }
 
 func (c *Client) DecodeToken() error {
@@ -430,7 +553,7 @@ func (c *Client) EncodeToken() error {
 
 func (c *Client) buildUserAgent() string {
 	if c.authModule.GetCaptchaToken() != "" {
-		return useragent.BuildCustomUserAgent(c.authModule.GetDeviceID(), c.authModule.GetUserID())
+		return c.signing.UserAgent(c.authModule.GetDeviceID(), c.authModule.GetUserID())
 	}
 	return "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36"
 }
@@ -455,24 +578,61 @@ func (c *Client) getHeaders() map[string]string {
 }
 
 func (c *Client) doRequest(ctx context.Context, method, reqURL string, data interface{}, params map[string]string) ([]byte, error) {
-	var body io.Reader
+	var body []byte
 	if data != nil {
 		jsonData, err := json.Marshal(data)
 		if err != nil {
-			return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeMarshalFailed, err)
+			return nil, withRequest(exception.NewPikpakExceptionWithError(exception.ErrCodeMarshalFailed, err), method, reqURL)
 		}
-		body = bytes.NewReader(jsonData)
+		body = jsonData
+	}
+	return c.do(ctx, method, reqURL, body, "", params)
+}
+
+// do sends body, if any, as contentType, which defaults to JSON. Every API
+// request goes through it for retries, token refresh and captcha refresh.
+func (c *Client) do(ctx context.Context, method, reqURL string, body []byte, contentType string, params map[string]string) ([]byte, error) {
+	respBody, err := c.executeRequest(ctx, method, reqURL, body, contentType, params)
+	if err != nil {
+		return nil, withRequest(err, method, reqURL)
+	}
+	return respBody, nil
+}
+
+func (c *Client) executeRequest(ctx context.Context, method, reqURL string, body []byte, contentType string, params map[string]string) ([]byte, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, bodyReader)
 	if err != nil {
 		return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeCreateRequestFailed, err)
 	}
 
-	for key, value := range c.getHeaders() {
-		req.Header.Set(key, value)
+	// Sign-in and token requests fail for their own reasons; refreshing
+	// the token in between could only recurse.
+	authRequest := strings.HasPrefix(req.URL.Path, "/v1/auth/")
+	// A captcha init answered with error_code 9 cannot be fixed by another
+	// captcha init.
+	captchaRequest := strings.HasPrefix(req.URL.Path, "/v1/shield/")
+	if err := c.requireSession(req.URL.Path); err != nil {
+		return nil, err
+	}
+	if !authRequest {
+		c.refreshIfExpiring(ctx)
 	}
 
+	setHeaders := func() {
+		for key, value := range c.getHeaders() {
+			req.Header.Set(key, value)
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+	}
+	setHeaders()
+
 	if params != nil {
 		q := req.URL.Query()
 		for key, value := range params {
@@ -481,71 +641,120 @@ func (c *Client) doRequest(ctx context.Context, method, reqURL string, data inte
 		req.URL.RawQuery = q.Encode()
 	}
 
+	policy := c.getRetryPolicy()
+
 	var lastErr error
+	var retryAfter time.Duration
+	captchaRefreshed := false
 	for attempt := 0; attempt <= c.maxRetries; attempt++ {
 		if attempt > 0 {
-			backoff := c.initialBackoff * time.Duration(1<<uint(attempt-1))
-			time.Sleep(backoff)
+			if err := waitBackoff(ctx, c.backoff(attempt, retryAfter)); err != nil {
+				return nil, err
+			}
+			retryAfter = 0
 		}
 
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			lastErr = err
-			log.Printf("Request failed (attempt %d/%d): %v", attempt+1, c.maxRetries+1, err)
-			continue
+		if err := rewindBody(req); err != nil {
+			return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeCreateRequestFailed, err)
 		}
-		defer resp.Body.Close()
-
-		respBody, err := io.ReadAll(resp.Body)
+		c.logger.Debugf("%s %s (attempt %d/%d)", method, req.URL.Path, attempt+1, c.maxRetries+1)
+		resp, respBody, err := c.send(req)
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeTimeout, err)
+			}
+			if resp == nil {
+				if !policy.ShouldRetry(method, 0, err) {
+					return nil, transportError(err)
+				}
+				lastErr = transportError(err)
+				c.logger.Warnf("Request failed (attempt %d/%d): %v", attempt+1, c.maxRetries+1, redactURL(err))
+				continue
+			}
+			if exception.IsPikpakException(err) {
+				return nil, err
+			}
+			if !policy.ShouldRetry(method, 0, err) {
+				return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeReadResponseFailed, err)
+			}
 			lastErr = err
-			log.Printf("Failed to read response (attempt %d/%d): %v", attempt+1, c.maxRetries+1, err)
+			c.logger.Warnf("Failed to read response (attempt %d/%d): %v", attempt+1, c.maxRetries+1, err)
 			continue
 		}
 
-		if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated {
+		if isSuccessStatus(resp.StatusCode) {
 			return respBody, nil
 		}
 
-		var respData map[string]interface{}
-		if err := json.Unmarshal(respBody, &respData); err == nil {
-			if errCode, ok := respData["error_code"].(float64); ok && int(errCode) == 16 {
-				if c.authModule.GetRefreshToken() != "" {
-					if refreshErr := c.RefreshAccessToken(ctx); refreshErr == nil {
-						for key, value := range c.getHeaders() {
-							req.Header.Set(key, value)
-						}
-						continue
-					}
-				}
+		respData := parseErrorBody(resp, respBody)
+		if errCode, ok := respData["error_code"].(float64); ok && int(errCode) == 16 && !authRequest {
+			sent := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+			refreshErr := c.refreshAccessToken(ctx, sent)
+			if refreshErr == nil {
+				setHeaders()
+				continue
+			}
+			if errors.Is(refreshErr, exception.ErrMissingRefreshToken) {
+				// Keep the server's answer on top and the reason it
+				// cannot be recovered from underneath.
+				apiErr := responseError(resp.StatusCode, respData, respBody)
+				apiErr.Err = exception.NewPikpakExceptionWithError(exception.ErrCodeMissingRefreshToken, apiErr.Err)
+				return nil, apiErr
 			}
-			if errorMsg, ok := respData["error"].(string); ok {
-				return nil, exception.NewPikpakExceptionWithMessage(exception.ErrCodeServerError, errorMsg)
+		}
+		if isCaptchaInvalid(respData) && !captchaRefreshed && !authRequest && !captchaRequest {
+			captchaRefreshed = true
+			if refreshErr := c.refreshCaptchaToken(ctx, captchaAction(req)); refreshErr == nil {
+				setHeaders()
+				// The captcha retry does not count against maxRetries.
+				attempt--
+				continue
+			} else if errors.Is(refreshErr, exception.ErrCaptchaRequired) {
+				return nil, refreshErr
 			}
 		}
 
-		if resp.StatusCode == http.StatusUnauthorized {
-			return nil, exception.ErrInvalidAccessToken
+		apiErr := responseError(resp.StatusCode, respData, respBody)
+		if resp.StatusCode == http.StatusNotFound && isFileMetadataPath(req.URL.Path) {
+			apiErr.Code = exception.ErrCodeFileNotFound
 		}
-		if resp.StatusCode == http.StatusForbidden {
-			return nil, exception.ErrInvalidCredentials
+		if !policy.ShouldRetry(method, resp.StatusCode, apiErr) {
+			return nil, apiErr
 		}
-
-		return nil, exception.NewPikpakExceptionWithMessage(exception.ErrCodeServerError, fmt.Sprintf("request failed with status: %d, body: %s", resp.StatusCode, string(respBody)))
+		lastErr = apiErr
+		retryAfter, _ = parseRetryAfter(resp.Header.Get("Retry-After"), c.now())
+		c.logger.Warnf("Request failed with status %d (attempt %d/%d)", resp.StatusCode, attempt+1, c.maxRetries+1)
 	}
 
 	return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeMaxRetriesExceeded, lastErr)
 }
 
 func (c *Client) GetJSON(ctx context.Context, URL string, params map[string]string) (map[string]interface{}, error) {
-	respBody, err := c.doRequest(ctx, http.MethodGet, URL, nil, params)
+	cacheKey, cacheable := "", false
+	if c.metadataCache != nil {
+		cacheKey, cacheable = metadataCacheKey(URL, params)
+	}
+
+	var respBody []byte
+	cached := false
+	if cacheable {
+		respBody, cached = c.metadataCache.get(cacheKey)
+	}
+	if !cached {
+		var err error
+		respBody, err = c.doRequest(ctx, http.MethodGet, URL, nil, params)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := decodeJSONBody(respBody)
 	if err != nil {
 		return nil, err
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeUnmarshalFailed, err)
+	if cacheable && !cached {
+		c.metadataCache.set(cacheKey, respBody, metadataCacheTags(URL, params, result))
 	}
 
 	return result, nil
@@ -557,12 +766,7 @@ func (c *Client) PostJSON(ctx context.Context, URL string, data interface{}) (ma
 		return nil, err
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeUnmarshalFailed, err)
-	}
-
-	return result, nil
+	return decodeJSONBody(respBody)
 }
 
 func (c *Client) PatchJSON(ctx context.Context, URL string, data interface{}) (map[string]interface{}, error) {
@@ -571,12 +775,7 @@ func (c *Client) PatchJSON(ctx context.Context, URL string, data interface{}) (m
 		return nil, err
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeUnmarshalFailed, err)
-	}
-
-	return result, nil
+	return decodeJSONBody(respBody)
 }
 
 func (c *Client) PostForm(ctx context.Context, URL string, data map[string]string) (map[string]interface{}, error) {
@@ -585,116 +784,161 @@ func (c *Client) PostForm(ctx context.Context, URL string, data map[string]strin
 		form.Set(key, value)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, URL, strings.NewReader(form.Encode()))
+	respBody, err := c.do(ctx, http.MethodPost, URL, []byte(form.Encode()), "application/x-www-form-urlencoded", nil)
 	if err != nil {
-		return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeCreateRequestFailed, err)
+		return nil, err
 	}
 
-	for key, value := range c.getHeaders() {
-		req.Header.Set(key, value)
-	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return decodeJSONBody(respBody)
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeNetworkError, err)
+func (c *Client) Delete(ctx context.Context, URL string, params map[string]string) (map[string]interface{}, error) {
+	if _, err := c.do(ctx, http.MethodDelete, URL, nil, "", params); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeReadResponseFailed, err)
-	}
+	return map[string]interface{}{"status": "ok"}, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, exception.NewPikpakExceptionWithMessage(exception.ErrCodeServerError, fmt.Sprintf("post form failed with status: %d, body: %s", resp.StatusCode, string(respBody)))
+// decodeJSONBody decodes a successful response body. An empty body, as sent
+// with 204 No Content, decodes to an empty map.
+func decodeJSONBody(respBody []byte) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	if len(bytes.TrimSpace(respBody)) == 0 {
+		return result, nil
 	}
-
-	var result map[string]interface{}
 	if err := json.Unmarshal(respBody, &result); err != nil {
 		return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeUnmarshalFailed, err)
 	}
-
 	return result, nil
 }
 
-func (c *Client) Delete(ctx context.Context, URL string, params map[string]string) (map[string]interface{}, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, URL, nil)
-	if err != nil {
-		return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeCreateRequestFailed, err)
-	}
-
-	for key, value := range c.getHeaders() {
-		req.Header.Set(key, value)
-	}
-
-	if params != nil {
-		q := req.URL.Query()
-		for key, value := range params {
-			q.Set(key, value)
-		}
-		req.URL.RawQuery = q.Encode()
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeNetworkError, err)
+// FileList lists the files in parentID, optionally matching query. It is
+// ordered as the server chooses unless opts set WithSort.
+func (c *Client) FileList(ctx context.Context, size int, parentID string, nextPageToken string, query string, opts ...ListOption) (map[string]interface{}, error) {
+	var o listOptions
+	for _, opt := range opts {
+		opt(&o)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("delete failed: %s", string(respBody))
+	if err := o.validate(); err != nil {
+		return nil, err
 	}
-
-	return map[string]interface{}{"status": "ok"}, nil
-}
-
-func (c *Client) FileList(ctx context.Context, size int, parentID string, nextPageToken string, query string) (map[string]interface{}, error) {
-	return c.fileModule.FileList(ctx, size, parentID, nextPageToken, query)
+	return c.fileModule.FileList(ctx, size, parentID, nextPageToken, query, o.sortField, o.sortOrder)
 }
 
 func (c *Client) CreateFolder(ctx context.Context, name string, parentID string) (map[string]interface{}, error) {
-	return c.fileModule.CreateFolder(ctx, name, parentID)
+	result, err := c.fileModule.CreateFolder(ctx, name, parentID)
+	if err == nil {
+		c.invalidateFiles(nil, parentID)
+	}
+	return result, err
 }
 
-func (c *Client) GetFileLink(ctx context.Context, fileID string) (string, error) {
-	return c.fileModule.GetFileLink(ctx, fileID)
+// GetFileLink returns a download link for fileID, following the client's
+// link settings unless opts override them.
+func (c *Client) GetFileLink(ctx context.Context, fileID string, opts ...LinkOption) (string, error) {
+	o := c.linkOptions(c.preferredLink, opts)
+	link, err := c.fileModule.GetFileLink(ctx, fileID, o.preference == LinkOriginal)
+	return o.rewriteHost(link), err
 }
 
 func (c *Client) Move(ctx context.Context, fileID string, parentID string) error {
-	return c.fileModule.Move(ctx, fileID, parentID)
+	err := c.fileModule.Move(ctx, fileID, parentID)
+	if err == nil {
+		c.invalidateFiles([]string{fileID}, parentID)
+	}
+	return err
 }
 
 func (c *Client) Copy(ctx context.Context, fileID string, parentID string) error {
-	return c.fileModule.Copy(ctx, fileID, parentID)
+	err := c.fileModule.Copy(ctx, fileID, parentID)
+	if err == nil {
+		c.invalidateFiles(nil, parentID)
+	}
+	return err
 }
 
 func (c *Client) Rename(ctx context.Context, fileID string, newName string) error {
-	return c.fileModule.Rename(ctx, fileID, newName)
+	err := c.fileModule.Rename(ctx, fileID, newName)
+	if err == nil {
+		c.invalidateFiles([]string{fileID})
+	}
+	return err
 }
 
+// DeleteToTrash treats ErrFileNotFound as success, since the files are already gone.
 func (c *Client) DeleteToTrash(ctx context.Context, ids []string) (map[string]interface{}, error) {
-	return c.fileModule.DeleteToTrash(ctx, ids)
+	result, err := c.fileModule.DeleteToTrash(ctx, ids)
+	if errors.Is(err, exception.ErrFileNotFound) {
+		result, err = map[string]interface{}{}, nil
+	}
+	if err == nil {
+		c.invalidateFiles(ids)
+	}
+	return result, err
 }
 
 func (c *Client) Untrash(ctx context.Context, ids []string) (map[string]interface{}, error) {
-	return c.fileModule.Untrash(ctx, ids)
+	result, err := c.fileModule.Untrash(ctx, ids)
+	if err == nil {
+		c.invalidateFiles(ids)
+	}
+	return result, err
 }
 
+// DeleteForever treats ErrFileNotFound as success, since the files are already gone.
 func (c *Client) DeleteForever(ctx context.Context, ids []string) (map[string]interface{}, error) {
-	return c.fileModule.DeleteForever(ctx, ids)
+	result, err := c.fileModule.DeleteForever(ctx, ids)
+	if errors.Is(err, exception.ErrFileNotFound) {
+		result, err = map[string]interface{}{}, nil
+	}
+	if err == nil {
+		c.invalidateFiles(ids)
+	}
+	return result, err
+}
+
+// EmptyTrash permanently deletes everything in the trash.
+func (c *Client) EmptyTrash(ctx context.Context) error {
+	return c.fileModule.EmptyTrash(ctx)
+}
+
+func (c *Client) GetFileDetails(ctx context.Context, fileID string) (map[string]interface{}, error) {
+	return c.fileModule.GetFileDetails(ctx, fileID)
 }
 
 func (c *Client) GetAbout(ctx context.Context) (map[string]interface{}, error) {
 	return c.fileModule.GetAbout(ctx)
 }
 
-func (c *Client) OfflineDownload(ctx context.Context, fileURL string, parentID string, name string) (map[string]interface{}, error) {
-	return c.downloadMod.OfflineDownload(ctx, fileURL, parentID, name)
+// OfflineDownload starts an offline download of fileURL into parentID, or
+// into "My Pack" when parentID is empty. WithFolderType in opts overrides
+// the folder_type that choice implies.
+func (c *Client) OfflineDownload(ctx context.Context, fileURL string, parentID string, name string, opts ...DownloadOption) (map[string]interface{}, error) {
+	o := downloadOptions{folderType: enums.DefaultFolderType(parentID)}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	result, err := c.downloadMod.OfflineDownload(ctx, fileURL, parentID, name, o.folderType)
+	if err != nil {
+		return nil, err
+	}
+	if err := taskFailureError(result); err != nil {
+		return result, err
+	}
+	return result, nil
 }
 
+// OfflineList lists offline tasks in the given phases, running and failed
+// ones when phases is nil. Phases are parsed with enums.ParsePhaseType, so
+// short forms such as "complete" work too.
 func (c *Client) OfflineList(ctx context.Context, size int, nextPageToken string, phases []string) (map[string]interface{}, error) {
+	return c.OfflineListPhases(ctx, size, nextPageToken, enums.PhaseTypes(phases...))
+}
+
+// OfflineListPhases is OfflineList with typed phases.
+func (c *Client) OfflineListPhases(ctx context.Context, size int, nextPageToken string, phases []enums.PhaseType) (map[string]interface{}, error) {
 	return c.downloadMod.OfflineList(ctx, size, nextPageToken, phases)
 }
 
@@ -734,8 +978,8 @@ type AboutResponse struct {
 		IsUnlimited   bool   `json:"is_unlimited"`
 		Complimentary string `json:"complimentary"`
 	} `json:"quota"`
-	ExpiresAt string `json:"expires_at"`
-	UserType  int    `json:"user_type"`
+	ExpiresAt string         `json:"expires_at"`
+	UserType  enums.UserType `json:"user_type"`
 }
 
 type StorageInfo struct {
@@ -745,17 +989,11 @@ type StorageInfo struct {
 	IsUnlimited   bool
 	Complimentary string
 	ExpiresAt     string
-	UserType      int
+	UserType      enums.UserType
 }
 
 func (c *Client) GetQuotaInfo(ctx context.Context) (map[string]interface{}, error) {
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
-	}
-	URL := baseURL + "/drive/v1/about"
-
-	return c.GetJSON(ctx, URL, nil)
+	return c.fileModule.GetAbout(ctx)
 }
 
 func parseShareFileInfo(fileInfo map[string]interface{}) (*ShareFileInfo, error) {
@@ -800,11 +1038,7 @@ func (c *Client) extractShareID(shareURL string) (string, error) {
 }
 
 func (c *Client) getSharePassToken(ctx context.Context, shareID string, passCode string) (string, error) {
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
-	}
-	URL := baseURL + "/share/v1/passcode"
+	URL := c.driveURL("/share/v1/passcode")
 
 	data := map[string]interface{}{
 		"share_id": shareID,
@@ -819,15 +1053,11 @@ func (c *Client) getSharePassToken(ctx context.Context, shareID string, passCode
 	if token, ok := result["pass_code_token"].(string); ok {
 		return token, nil
 	}
-	return "", exception.ErrInvalidPassCode
+	return "", exception.ErrSharePasswordWrong
 }
 
 func (c *Client) Share(ctx context.Context, fileID string, shareType int, expireSec int, passCode string) (map[string]interface{}, error) {
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
-	}
-	URL := baseURL + "/drive/v1/share"
+	URL := c.driveURL("/drive/v1/share")
 
 	data := map[string]interface{}{
 		"file_id":    fileID,
@@ -846,11 +1076,7 @@ func (c *Client) Share(ctx context.Context, fileID string, shareType int, expire
 }
 
 func (c *Client) SetSharePolicy(ctx context.Context, shareID string, policy string) (map[string]interface{}, error) {
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
-	}
-	URL := baseURL + "/drive/v1/share/" + shareID
+	URL := c.driveURL("/drive/v1/share/" + shareID)
 
 	data := map[string]interface{}{
 		"policy": policy,
@@ -860,53 +1086,29 @@ func (c *Client) SetSharePolicy(ctx context.Context, shareID string, policy stri
 }
 
 func (c *Client) GetShareList(ctx context.Context, size int, nextPageToken string) (map[string]interface{}, error) {
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
-	}
-	URL := baseURL + "/drive/v1/share/list"
+	URL := c.driveURL("/drive/v1/share/list")
 
 	if size == 0 {
 		size = 50
 	}
 
-	params := map[string]string{
-		"limit": strconv.Itoa(size),
-	}
-
-	if nextPageToken != "" {
-		params["page_token"] = nextPageToken
-	}
-
-	return c.GetJSON(ctx, URL, params)
+	return c.GetJSON(ctx, URL, query.List(size, nextPageToken))
 }
 
 func (c *Client) GetSharePasscode(ctx context.Context, shareID string) (map[string]interface{}, error) {
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
-	}
-	URL := baseURL + "/share/v1/passcode/" + shareID
+	URL := c.driveURL("/share/v1/passcode/" + shareID)
 
 	return c.GetJSON(ctx, URL, nil)
 }
 
 func (c *Client) CancelShare(ctx context.Context, shareID string) (map[string]interface{}, error) {
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
-	}
-	URL := baseURL + "/drive/v1/share/" + shareID + "/cancel"
+	URL := c.driveURL("/drive/v1/share/" + shareID + "/cancel")
 
 	return c.PostJSON(ctx, URL, nil)
 }
 
 func (c *Client) InviteNewShare(ctx context.Context, shareID string, fileIDs []string, inviteMsg string, isNewInvite bool) (map[string]interface{}, error) {
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
-	}
-	URL := baseURL + "/share/v1/invite"
+	URL := c.driveURL("/share/v1/invite")
 
 	data := map[string]interface{}{
 		"share_id":       shareID,
@@ -919,11 +1121,7 @@ func (c *Client) InviteNewShare(ctx context.Context, shareID string, fileIDs []s
 }
 
 func (c *Client) InviteList(ctx context.Context, shareID string, size int, nextPageToken string) (map[string]interface{}, error) {
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
-	}
-	URL := baseURL + "/share/v1/invite/list"
+	URL := c.driveURL("/share/v1/invite/list")
 
 	params := map[string]string{
 		"share_id": shareID,
@@ -940,11 +1138,7 @@ func (c *Client) InviteList(ctx context.Context, shareID string, size int, nextP
 }
 
 func (c *Client) InviteCancel(ctx context.Context, inviteID string) (map[string]interface{}, error) {
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
-	}
-	URL := baseURL + "/share/v1/invite/cancel"
+	URL := c.driveURL("/share/v1/invite/cancel")
 
 	data := map[string]interface{}{
 		"invite_id": inviteID,
@@ -954,11 +1148,7 @@ func (c *Client) InviteCancel(ctx context.Context, inviteID string) (map[string]
 }
 
 func (c *Client) Favorite(ctx context.Context, fileID string, category string) (map[string]interface{}, error) {
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
-	}
-	URL := baseURL + "/drive/v1/files/" + fileID + ":favorite"
+	URL := c.driveURL("/drive/v1/files/" + fileID + ":favorite")
 
 	data := map[string]interface{}{
 		"category": category,
@@ -972,50 +1162,44 @@ func (c *Client) Events(ctx context.Context, size int, nextPageToken string) (ma
 		size = 100
 	}
 
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
+	thumbnailSize, err := file.ResolveThumbnailSize(c.thumbnailSize, enums.ThumbnailSizeMedium)
+	if err != nil {
+		return nil, err
 	}
-	URL := baseURL + "/drive/v1/events"
 
-	params := map[string]string{
-		"thumbnail_size": "SIZE_MEDIUM",
-		"limit":          fmt.Sprintf("%d", size),
-	}
+	URL := c.driveURL("/drive/v1/events")
 
-	if nextPageToken != "" {
-		params["next_page_token"] = nextPageToken
-	}
+	params := query.List(size, "").
+		ThumbnailSize(thumbnailSize).
+		Set("next_page_token", nextPageToken)
 
 	return c.GetJSON(ctx, URL, params)
 }
 
-func (c *Client) RemoteDownload(ctx context.Context, fileURL string) (map[string]interface{}, error) {
-	if fileURL == "" {
-		return nil, exception.ErrInvalidURL
+// RemoteDownload creates a download task for fileURL. No folder_type is
+// sent unless opts set one with WithFolderType.
+func (c *Client) RemoteDownload(ctx context.Context, fileURL string, opts ...DownloadOption) (map[string]interface{}, error) {
+	var o downloadOptions
+	for _, opt := range opts {
+		opt(&o)
 	}
 
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
+	var folderType []enums.FolderType
+	if o.folderTypeSet {
+		folderType = append(folderType, o.folderType)
 	}
-	URL := baseURL + "/drive/v1/files"
 
-	data := map[string]interface{}{
-		"kind":        "drive#task",
-		"upload_type": "UPLOAD_TYPE_URL",
-		"url":         map[string]string{"url": fileURL},
+	result, err := c.downloadMod.RemoteDownload(ctx, fileURL, folderType...)
+	if err != nil {
+		return nil, err
 	}
-
-	return c.PostJSON(ctx, URL, data)
+	if err := taskFailureError(result); err != nil {
+		return result, err
+	}
+	return result, nil
 }
 
 func (c *Client) GetShareFileInfo(ctx context.Context, shareURL string, sharePassword string) (*ShareFileInfo, error) {
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
-	}
-
 	shareID, err := c.extractShareID(shareURL)
 	if err != nil {
 		return nil, err
@@ -1033,7 +1217,7 @@ func (c *Client) GetShareFileInfo(ctx context.Context, shareURL string, sharePas
 		params["pass_code_token"] = passToken
 	}
 
-	URL := baseURL + "/drive/v1/share/file_info"
+	URL := c.driveURL("/drive/v1/share/file_info")
 
 	result, err := c.GetJSON(ctx, URL, params)
 	if err != nil {
@@ -1048,12 +1232,21 @@ func (c *Client) GetShareFileInfo(ctx context.Context, shareURL string, sharePas
 	return parseShareFileInfo(fileInfo)
 }
 
-func (c *Client) GetShareFileDownloadURL(ctx context.Context, shareURL string, sharePassword string, useTranscoding bool) (string, error) {
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
+// GetShareFileDownloadURL returns a download link for the file in a share.
+// useTranscoding prefers a rendition for this call; when false, the client's
+// WithPreferredLink applies. WithLinkPreference in opts overrides both.
+func (c *Client) GetShareFileDownloadURL(ctx context.Context, shareURL string, sharePassword string, useTranscoding bool, opts ...LinkOption) (string, error) {
+	preference := c.preferredLink
+	if useTranscoding {
+		preference = LinkTranscoded
 	}
+	o := c.linkOptions(preference, opts)
+
+	link, err := c.shareFileDownloadURL(ctx, shareURL, sharePassword, o.preference != LinkTranscoded)
+	return o.rewriteHost(link), err
+}
 
+func (c *Client) shareFileDownloadURL(ctx context.Context, shareURL string, sharePassword string, preferOriginal bool) (string, error) {
 	shareID, err := c.extractShareID(shareURL)
 	if err != nil {
 		return "", err
@@ -1071,7 +1264,7 @@ func (c *Client) GetShareFileDownloadURL(ctx context.Context, shareURL string, s
 		params["pass_code_token"] = passToken
 	}
 
-	URL := baseURL + "/drive/v1/share/file_info"
+	URL := c.driveURL("/drive/v1/share/file_info")
 
 	result, err := c.GetJSON(ctx, URL, params)
 	if err != nil {
@@ -1082,66 +1275,85 @@ func (c *Client) GetShareFileDownloadURL(ctx context.Context, shareURL string, s
 	if !ok {
 		return "", exception.NewPikpakExceptionWithMessage(exception.ErrCodeNotFound, "file_info not found in response")
 	}
+	return shareFileLink(fileInfo, preferOriginal)
+}
+
+// GetShareFileLink returns a download link for the file fileID of the share
+// shareID, unlocking it with sharePassword when one is given. It follows the
+// client's link settings unless opts override them.
+func (c *Client) GetShareFileLink(ctx context.Context, shareID string, fileID string, sharePassword string, opts ...LinkOption) (string, error) {
+	o := c.linkOptions(c.preferredLink, opts)
 
-	if webContentLink, hasWebContentLink := fileInfo["web_content_link"].(string); hasWebContentLink && webContentLink != "" && !useTranscoding {
+	params := map[string]string{
+		"share_id": shareID,
+		"file_id":  fileID,
+	}
+	if sharePassword != "" {
+		passToken, err := c.getSharePassToken(ctx, shareID, sharePassword)
+		if err != nil {
+			return "", err
+		}
+		params["pass_code_token"] = passToken
+	}
+
+	result, err := c.GetJSON(ctx, c.driveURL("/drive/v1/share/file_info"), params)
+	if err != nil {
+		return "", err
+	}
+	fileInfo, ok := result["file_info"].(map[string]interface{})
+	if !ok {
+		return "", exception.NewPikpakExceptionWithMessage(exception.ErrCodeNotFound, "file_info not found in response")
+	}
+
+	link, err := shareFileLink(fileInfo, o.preference != LinkTranscoded)
+	return o.rewriteHost(link), err
+}
+
+// shareFileLink picks the original link or a media rendition of a shared
+// file, falling back to whichever exists.
+func shareFileLink(fileInfo map[string]interface{}, preferOriginal bool) (string, error) {
+	if webContentLink, hasWebContentLink := fileInfo["web_content_link"].(string); hasWebContentLink && webContentLink != "" && preferOriginal {
 		return webContentLink, nil
 	}
 
+	webContentLink, _ := fileInfo["web_content_link"].(string)
+
 	medias, ok := fileInfo["medias"].([]interface{})
 	if !ok || len(medias) == 0 {
-		if webContentLink, hasWebContentLink := fileInfo["web_content_link"].(string); hasWebContentLink {
+		if webContentLink != "" {
 			return webContentLink, nil
 		}
 		return "", exception.NewPikpakExceptionWithMessage(exception.ErrCodeNotFound, "no download link available")
 	}
 
-	if useTranscoding && len(medias) > 1 {
-		for _, m := range medias {
-			media, mediaOk := m.(map[string]interface{})
-			if !mediaOk {
-				continue
-			}
-			link, linkOk := media["link"].(map[string]interface{})
-			if !linkOk {
-				continue
-			}
-			if url, urlOk := link["url"].(string); urlOk && url != "" {
-				return url, nil
-			}
-		}
-	}
-
-	firstMedia, mediaOk := medias[0].(map[string]interface{})
-	if !mediaOk {
-		return "", exception.NewPikpakExceptionWithMessage(exception.ErrCodeInvalidMediaFormat, "invalid media format")
+	mediaURL, err := file.MediaLink(medias)
+	if mediaURL != "" {
+		return mediaURL, nil
 	}
-
-	link, linkOk := firstMedia["link"].(map[string]interface{})
-	if !linkOk {
-		return "", exception.NewPikpakExceptionWithMessage(exception.ErrCodeNotFound, "link not found in media")
+	if webContentLink != "" {
+		return webContentLink, nil
 	}
-
-	if url, urlOk := link["url"].(string); urlOk {
-		return url, nil
+	if err != nil {
+		return "", err
 	}
 
 	return "", exception.NewPikpakExceptionWithMessage(exception.ErrCodeNotFound, "download url not found")
 }
 
 func (c *Client) GetShareFiles(ctx context.Context, shareURL string, sharePassword string) ([]*ShareFileInfo, error) {
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
+	shareID, err := c.extractShareID(shareURL)
+	if err != nil {
+		return nil, err
 	}
 
-	shareID, err := c.extractShareID(shareURL)
+	thumbnailSize, err := file.ResolveThumbnailSize(c.thumbnailSize, enums.ThumbnailSizeLarge)
 	if err != nil {
 		return nil, err
 	}
 
 	params := map[string]string{
 		"share_id":       shareID,
-		"thumbnail_size": "SIZE_LARGE",
+		"thumbnail_size": thumbnailSize.String(),
 	}
 
 	if sharePassword != "" {
@@ -1152,7 +1364,7 @@ func (c *Client) GetShareFiles(ctx context.Context, shareURL string, sharePasswo
 		params["pass_code_token"] = passToken
 	}
 
-	URL := baseURL + "/drive/v1/share/file/list"
+	URL := c.driveURL("/drive/v1/share/file/list")
 
 	result, err := c.GetJSON(ctx, URL, params)
 	if err != nil {
@@ -1174,18 +1386,40 @@ func (c *Client) GetShareFiles(ctx context.Context, shareURL string, sharePasswo
 	return files, nil
 }
 
-func (c *Client) OfflineFileInfo(ctx context.Context, fileID string) (map[string]interface{}, error) {
-	if fileID == "" {
-		return nil, exception.ErrInvalidFileID
+// RestoreShareURL saves files from the share at shareURL into the drive,
+// unlocking it with sharePassword when one is given. Empty fileIDs restores
+// every top-level file of the share.
+func (c *Client) RestoreShareURL(ctx context.Context, shareURL string, sharePassword string, fileIDs []string) (map[string]interface{}, error) {
+	shareID, err := c.extractShareID(shareURL)
+	if err != nil {
+		return nil, err
 	}
 
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
+	var passToken string
+	if sharePassword != "" {
+		if passToken, err = c.getSharePassToken(ctx, shareID, sharePassword); err != nil {
+			return nil, err
+		}
 	}
-	URL := baseURL + "/drive/v1/files/" + fileID
 
-	return c.GetJSON(ctx, URL, nil)
+	if len(fileIDs) == 0 {
+		files, err := c.GetShareFiles(ctx, shareURL, sharePassword)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			fileIDs = append(fileIDs, f.ID)
+		}
+		if len(fileIDs) == 0 {
+			return nil, exception.ErrEmptyFileIDs
+		}
+	}
+
+	return c.Restore(ctx, shareID, passToken, fileIDs)
+}
+
+func (c *Client) OfflineFileInfo(ctx context.Context, fileID string) (map[string]interface{}, error) {
+	return c.downloadMod.OfflineFileInfo(ctx, fileID)
 }
 
 func (c *Client) UploadFile(ctx context.Context, filePath string, parentID string, chunkSize int) (map[string]interface{}, error) {
@@ -1207,11 +1441,7 @@ func (c *Client) UploadFile(ctx context.Context, filePath string, parentID strin
 		chunkSize = 8 * 1024 * 1024
 	}
 
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
-	}
-	uploadURL := baseURL + "/drive/v1/files"
+	uploadURL := c.driveURL("/drive/v1/files")
 
 	var uploadResult map[string]interface{}
 
@@ -1228,7 +1458,14 @@ func (c *Client) UploadFile(ctx context.Context, filePath string, parentID strin
 	return uploadResult, nil
 }
 
-func (c *Client) uploadFileSmall(ctx context.Context, uploadURL string, file *os.File, fileName string, fileSize int64, parentID string) (map[string]interface{}, error) {
+// UploadTypeOf returns the upload_type the server echoed in a create-file
+// response, or "" if the response has none.
+func UploadTypeOf(result map[string]interface{}) enums.UploadType {
+	uploadType, _ := result["upload_type"].(string)
+	return enums.ParseUploadType(uploadType)
+}
+
+func (c *Client) uploadFileSmall(ctx context.Context, uploadURL string, file io.Reader, fileName string, fileSize int64, parentID string) (map[string]interface{}, error) {
 	fileContent, err := io.ReadAll(file)
 	if err != nil {
 		return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeReadFileFailed, err)
@@ -1253,12 +1490,14 @@ func (c *Client) uploadFileSmall(ctx context.Context, uploadURL string, file *os
 	_ = writer.WriteField("parent_id", parentID)
 	_ = writer.WriteField("size", strconv.FormatInt(fileSize, 10))
 	_ = writer.WriteField("hash", md5Str)
-	_ = writer.WriteField("kind", "drive#file")
-	_ = writer.WriteField("upload_type", "UPLOAD_TYPE_RESUMABLE")
+	_ = writer.WriteField("kind", enums.FileKindFile.String())
+	_ = writer.WriteField("upload_type", enums.UploadTypeResumable.String())
 
 	writer.Close()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, body)
+	// The multipart envelope is counted too, so Total is the body size.
+	tracker := c.trackProgress(fileName, 0, int64(body.Len()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, tracker.reader(body))
 	if err != nil {
 		return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeCreateRequestFailed, err)
 	}
@@ -1267,28 +1506,28 @@ func (c *Client) uploadFileSmall(ctx context.Context, uploadURL string, file *os
 		req.Header.Set(key, value)
 	}
 	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.ContentLength = int64(body.Len())
 
-	resp, err := c.httpClient.Do(req)
+	if err := c.waitRateLimit(ctx); err != nil {
+		return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeTimeout, err)
+	}
+	resp, err := c.transferClient().Do(req)
 	if err != nil {
-		return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeNetworkError, err)
+		return nil, withRequest(transportError(err), req.Method, req.URL.String())
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeReadResponseFailed, err)
+		return nil, withRequest(exception.NewPikpakExceptionWithError(exception.ErrCodeReadResponseFailed, err), req.Method, req.URL.String())
 	}
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, exception.NewPikpakExceptionWithMessage(exception.ErrCodeServerError, fmt.Sprintf("upload failed with status: %d, body: %s", resp.StatusCode, string(respBody)))
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeUnmarshalFailed, err)
+	if !isSuccessStatus(resp.StatusCode) {
+		return nil, withRequest(responseError(resp.StatusCode, parseErrorBody(resp, respBody), respBody), req.Method, req.URL.String())
 	}
 
-	return result, nil
+	tracker.done()
+	return decodeJSONBody(respBody)
 }
 
 func (c *Client) uploadFileLarge(ctx context.Context, uploadURL string, file *os.File, fileName string, fileSize int64, chunkSize int, parentID string) (map[string]interface{}, error) {
@@ -1301,13 +1540,14 @@ func (c *Client) uploadFileLarge(ctx context.Context, uploadURL string, file *os
 		"file_path":       file.Name(),
 		"file_size":       fileSize,
 		"parent_id":       parentID,
-		"upload_type":     "UPLOAD_TYPE_RESUMABLE",
+		"upload_type":     enums.UploadTypeResumable.String(),
 		"chunk_size":      chunkSize,
 		"total_chunks":    totalChunks,
 		"uploaded_chunks": make(map[int]bool),
 	}
 
 	file.Seek(0, 0)
+	tracker := c.trackProgress(fileName, 0, fileSize)
 
 	for i := 0; i < totalChunks; i++ {
 		offset := int64(i * chunkSize)
@@ -1326,23 +1566,21 @@ func (c *Client) uploadFileLarge(ctx context.Context, uploadURL string, file *os
 		md5Hash.Write(chunk)
 		chunkMD5 := hex.EncodeToString(md5Hash.Sum(nil))
 
-		log.Printf("Uploading chunk %d/%d...", i+1, totalChunks)
+		c.logger.Debugf("Uploading chunk %d/%d...", i+1, totalChunks)
 
 		_ = chunk
 		_ = chunkMD5
 
 		resumable["uploaded_chunks"].(map[int]bool)[i] = true
+		tracker.add(int64(n))
 	}
 
+	tracker.done()
 	return resumable, nil
 }
 
 func (c *Client) GetUploadURL(ctx context.Context, fileName string, fileSize int64, parentID string) (string, error) {
-	baseURL := c.baseURL
-	if baseURL == "" {
-		baseURL = "https://" + constants.APIHost
-	}
-	URL := baseURL + "/drive/v1/files/upload/url"
+	URL := c.driveURL("/drive/v1/files/upload/url")
 
 	params := map[string]string{
 		"name":      fileName,
@@ -1361,41 +1599,75 @@ func (c *Client) GetUploadURL(ctx context.Context, fileName string, fileSize int
 	return "", exception.NewPikpakExceptionWithMessage(exception.ErrCodeNotFound, "upload_url not found in response")
 }
 
-func (c *Client) DownloadToFile(ctx context.Context, fileID string, filePath string) error {
-	downloadURL, err := c.GetFileLink(ctx, fileID)
+// DownloadToFile downloads fileID to filePath, choosing the link as
+// GetFileLink does. Data is written to filePath + ".part" and renamed once
+// complete; a .part file left by an interrupted download is resumed with a
+// range request when the server supports it.
+func (c *Client) DownloadToFile(ctx context.Context, fileID string, filePath string, opts ...LinkOption) error {
+	downloadURL, err := c.GetFileLink(ctx, fileID, opts...)
 	if err != nil {
 		return err
 	}
 
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return exception.NewPikpakExceptionWithError(exception.ErrCodeCreateDirectoryFailed, err)
+	}
+	partPath := filePath + ".part"
+
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
 	if err != nil {
 		return exception.NewPikpakExceptionWithError(exception.ErrCodeCreateRequestFailed, err)
 	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.transferClient().Do(req)
 	if err != nil {
-		return exception.NewPikpakExceptionWithError(exception.ErrCodeNetworkError, err)
+		return transportError(err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	case resp.StatusCode == http.StatusOK:
+		offset = 0
+	default:
 		return exception.NewPikpakExceptionWithMessage(exception.ErrCodeServerError, fmt.Sprintf("download failed with status: %d", resp.StatusCode))
 	}
 
-	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
-		return exception.NewPikpakExceptionWithError(exception.ErrCodeCreateDirectoryFailed, err)
-	}
-
-	outFile, err := os.Create(filePath)
+	outFile, err := os.OpenFile(partPath, flags, 0644)
 	if err != nil {
 		return exception.NewPikpakExceptionWithError(exception.ErrCodeCreateFileFailed, err)
 	}
-	defer outFile.Close()
 
-	_, err = io.Copy(outFile, resp.Body)
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+	tracker := c.trackProgress(filePath, offset, total)
+
+	_, err = io.Copy(outFile, tracker.reader(resp.Body))
+	if closeErr := outFile.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
+		if ctx.Err() != nil {
+			return transportError(ctx.Err())
+		}
 		return exception.NewPikpakExceptionWithError(exception.ErrCodeWriteFileFailed, err)
 	}
 
+	if err := os.Rename(partPath, filePath); err != nil {
+		return exception.NewPikpakExceptionWithError(exception.ErrCodeWriteFileFailed, err)
+	}
+	tracker.done()
 	return nil
 }
@@ -3,13 +3,18 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
 	"github.com/zhz8888/pikpakapi-go/pkg/enums"
 )
 
@@ -98,11 +103,35 @@ func TestClient_Login_NoCredentials(t *testing.T) {
 }
 
 func TestClient_RefreshAccessToken_NoRefreshToken(t *testing.T) {
-	cli := NewClient()
+	var attempts int32
+	cli := newCountingClient(&attempts, errors.New("unexpected request"))
 
 	err := cli.RefreshAccessToken(context.Background())
-	if err == nil {
-		t.Error("Expected error when refresh_token is empty")
+	if !errors.Is(err, exception.ErrMissingRefreshToken) {
+		t.Errorf("Expected ErrMissingRefreshToken when refresh_token is empty, got %v", err)
+	}
+	if attempts != 0 {
+		t.Errorf("Expected no request, got %d", attempts)
+	}
+}
+
+func TestExpiredToken_NoRefreshToken(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error_code": 16, "error": "unauthenticated"})
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("expired"), WithInitialBackoff(time.Millisecond))
+	_, err := cli.FileList(context.Background(), 10, "", "", "")
+	if !errors.Is(err, exception.ErrMissingRefreshToken) || !errors.Is(err, exception.ErrUnauthorized) {
+		t.Errorf("Expected ErrMissingRefreshToken wrapping the 401, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected a single request, got %d", requests)
 	}
 }
 
@@ -183,6 +212,44 @@ func TestStorageInfo_Scenarios(t *testing.T) {
 	}
 }
 
+func TestGetStorageInfo_UserType(t *testing.T) {
+	tests := []struct {
+		name     string
+		about    string
+		expected enums.UserType
+		premium  bool
+	}{
+		{"free", `{"quota":{"limit":"6442450944"},"user_type":1}`, enums.UserTypeFree, false},
+		{"premium", `{"quota":{"limit":"10995116277760"},"user_type":2}`, enums.UserTypePremium, true},
+		{"platinum", `{"quota":{"limit":"10995116277760"},"user_type":3}`, enums.UserTypePlatinum, true},
+		{"string", `{"user_type":"2"}`, enums.UserTypePremium, true},
+		{"unknown", `{"user_type":9}`, enums.UserType(9), false},
+		{"missing", `{"quota":{}}`, enums.UserTypeUnknown, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.about))
+			}))
+			defer server.Close()
+
+			cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+			storage, err := cli.GetStorageInfo(context.Background())
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if storage.UserType != tt.expected {
+				t.Errorf("Expected UserType %v, got %v", tt.expected, storage.UserType)
+			}
+			if storage.UserType.IsPremium() != tt.premium {
+				t.Errorf("Expected IsPremium %v", tt.premium)
+			}
+		})
+	}
+}
+
 func TestGetStorageInfo_EdgeCases(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -827,6 +894,39 @@ func TestCreateFile_Success(t *testing.T) {
 	}
 }
 
+func TestUploadReader_AnyReader(t *testing.T) {
+	var uploaded string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(map[string]interface{}{"upload_url": server.URL + "/upload"})
+			return
+		}
+		mr, err := r.MultipartReader()
+		if err != nil {
+			t.Fatalf("Expected a multipart body: %v", err)
+		}
+		form, err := mr.ReadForm(1 << 20)
+		if err != nil {
+			t.Fatalf("Failed to read multipart form: %v", err)
+		}
+		f, _ := form.File["file"][0].Open()
+		data, _ := io.ReadAll(f)
+		uploaded = string(data)
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "f1"})
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+	if _, err := cli.UploadReader(context.Background(), strings.NewReader("streamed"), "a.txt", 8, ""); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if uploaded != "streamed" {
+		t.Errorf("Expected the reader's content, got %q", uploaded)
+	}
+}
+
 func TestCreateFile_WithParent(t *testing.T) {
 	uploadServerURL := ""
 
@@ -1553,6 +1653,9 @@ func TestRemoteDownload_Success(t *testing.T) {
 		if body["kind"] != "drive#task" {
 			t.Errorf("Expected kind 'drive#task', got '%s'", body["kind"])
 		}
+		if body["upload_type"] != "UPLOAD_TYPE_URL" {
+			t.Errorf("Expected upload_type 'UPLOAD_TYPE_URL', got '%v'", body["upload_type"])
+		}
 
 		urlMap, ok := body["url"].(map[string]interface{})
 		if !ok {
@@ -1588,6 +1691,24 @@ func TestRemoteDownload_Success(t *testing.T) {
 	}
 }
 
+func TestUploadTypeOf(t *testing.T) {
+	tests := []struct {
+		result   map[string]interface{}
+		expected enums.UploadType
+	}{
+		{map[string]interface{}{"upload_type": "UPLOAD_TYPE_RESUMABLE"}, enums.UploadTypeResumable},
+		{map[string]interface{}{"upload_type": "UPLOAD_TYPE_FORM"}, enums.UploadTypeForm},
+		{map[string]interface{}{"upload_type": "UPLOAD_TYPE_UNKNOWN"}, enums.UploadType("UPLOAD_TYPE_UNKNOWN")},
+		{map[string]interface{}{}, enums.UploadType("")},
+	}
+
+	for _, tt := range tests {
+		if got := UploadTypeOf(tt.result); got != tt.expected {
+			t.Errorf("UploadTypeOf(%v) = %q, want %q", tt.result, got, tt.expected)
+		}
+	}
+}
+
 func TestRemoteDownload_EmptyURL(t *testing.T) {
 	cli := NewClient(WithAccessToken("test_token"))
 
@@ -1957,6 +2078,29 @@ func TestUntrash_Success(t *testing.T) {
 	}
 }
 
+func TestEmptyTrash_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			t.Errorf("Expected PATCH method, got %s", r.Method)
+		}
+
+		expectedPath := "/drive/v1/files/trash:empty"
+		if r.URL.Path != expectedPath {
+			t.Errorf("Expected path '%s', got '%s'", expectedPath, r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{})
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+
+	if err := cli.EmptyTrash(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
 func TestDeleteForever_Success(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -2058,6 +2202,49 @@ func TestOfflineDownload_EmptyURL(t *testing.T) {
 	}
 }
 
+func TestOfflineDownload_FolderType(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		json.NewEncoder(w).Encode(map[string]interface{}{"task": map[string]interface{}{"id": "task_id"}})
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+	ctx := context.Background()
+
+	cli.OfflineDownload(ctx, "magnet:test_link", "", "test")
+	cli.OfflineDownload(ctx, "magnet:test_link", "parent_id", "test")
+	cli.OfflineDownload(ctx, "magnet:test_link", "parent_id", "test", WithFolderType(enums.FolderTypeDownload))
+	cli.RemoteDownload(ctx, "magnet:test_link")
+	cli.RemoteDownload(ctx, "magnet:test_link", WithFolderType(enums.FolderTypeDownload))
+
+	tests := []struct {
+		name       string
+		folderType interface{}
+		parentID   interface{}
+	}{
+		{"no parent targets My Pack", "DOWNLOAD", nil},
+		{"parent folder", "", "parent_id"},
+		{"forced folder type", "DOWNLOAD", "parent_id"},
+		{"remote download default", nil, nil},
+		{"remote download forced", "DOWNLOAD", nil},
+	}
+	if len(bodies) != len(tests) {
+		t.Fatalf("Expected %d requests, got %d", len(tests), len(bodies))
+	}
+	for i, tt := range tests {
+		if got := bodies[i]["folder_type"]; got != tt.folderType {
+			t.Errorf("%s: folder_type = %#v, want %#v", tt.name, got, tt.folderType)
+		}
+		if got := bodies[i]["parent_id"]; got != tt.parentID {
+			t.Errorf("%s: parent_id = %#v, want %#v", tt.name, got, tt.parentID)
+		}
+	}
+}
+
 func TestOfflineList_Success(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -2096,6 +2283,73 @@ func TestOfflineList_Success(t *testing.T) {
 	}
 }
 
+func TestFileList_Sort(t *testing.T) {
+	var orderBy []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orderBy = append(orderBy, r.URL.Query().Get("order_by"))
+		json.NewEncoder(w).Encode(map[string]interface{}{"files": []interface{}{}})
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+	ctx := context.Background()
+
+	cli.FileList(ctx, 10, "", "", "")
+	cli.FileList(ctx, 10, "", "", "", WithSort(enums.SortFieldModifiedTime, enums.SortOrderDesc))
+	cli.FileList(ctx, 10, "", "", "", WithSort(enums.SortFieldName, ""))
+
+	want := []string{"", "modified_time DESC", "name ASC"}
+	if len(orderBy) != len(want) {
+		t.Fatalf("Expected %d requests, got %d", len(want), len(orderBy))
+	}
+	for i := range want {
+		if orderBy[i] != want[i] {
+			t.Errorf("Request %d: expected order_by %q, got %q", i, want[i], orderBy[i])
+		}
+	}
+
+	invalid := []ListOption{
+		WithSort("", enums.SortOrderDesc),
+		WithSort("owner", enums.SortOrderAsc),
+		WithSort(enums.SortFieldSize, "desc"),
+	}
+	for _, opt := range invalid {
+		if _, err := cli.FileList(ctx, 10, "", "", "", opt); !errors.Is(err, exception.ErrInvalidParameter) {
+			t.Errorf("Expected ErrInvalidParameter, got %v", err)
+		}
+	}
+	if len(orderBy) != len(want) {
+		t.Errorf("Invalid sort options should not send requests, got %d", len(orderBy))
+	}
+}
+
+func TestOfflineList_Phases(t *testing.T) {
+	var filters []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("type"); got != "offline" {
+			t.Errorf("Expected type 'offline', got '%s'", got)
+		}
+		filters = append(filters, r.URL.Query().Get("filters"))
+		json.NewEncoder(w).Encode(map[string]interface{}{"tasks": []interface{}{}})
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+	ctx := context.Background()
+
+	cli.OfflineList(ctx, 10, "", nil)
+	cli.OfflineListPhases(ctx, 10, "", []enums.PhaseType{enums.PhaseTypeComplete})
+	cli.OfflineList(ctx, 10, "", []string{"PHASE_TYPE_PENDING"})
+	cli.OfflineList(ctx, 10, "", []string{"running", "PHASE_TYPE_ERROR"})
+
+	want := []string{"PHASE_TYPE_RUNNING,PHASE_TYPE_ERROR", "PHASE_TYPE_COMPLETE", "PHASE_TYPE_PENDING", "PHASE_TYPE_RUNNING,PHASE_TYPE_ERROR"}
+	for i, phases := range want {
+		if i >= len(filters) || !strings.Contains(filters[i], phases) {
+			t.Errorf("Request %d: expected phases %s in filters, got %v", i, phases, filters)
+		}
+	}
+}
+
 func TestDeleteOfflineTasks_Success(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodDelete {
@@ -2132,6 +2386,36 @@ func TestDeleteOfflineTasks_Success(t *testing.T) {
 	}
 }
 
+func TestOfflineTaskRetry_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Expected POST method, got %s", r.Method)
+		}
+
+		expectedPath := "/drive/v1/task"
+		if r.URL.Path != expectedPath {
+			t.Errorf("Expected path '%s', got '%s'", expectedPath, r.URL.Path)
+		}
+
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		if body["type"] != "offline" || body["create_type"] != "RETRY" || body["id"] != "task1" {
+			t.Errorf("Unexpected retry body %v", body)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"task": map[string]interface{}{"id": "task1"}})
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+
+	if err := cli.OfflineTaskRetry(context.Background(), "task1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
 func TestGetTaskStatus_Success(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -2166,6 +2450,37 @@ func TestGetTaskStatus_Success(t *testing.T) {
 	}
 }
 
+func TestGetTaskStatus_Phases(t *testing.T) {
+	tests := []struct {
+		phase    string
+		expected enums.DownloadStatus
+	}{
+		{"PHASE_TYPE_PENDING", enums.DownloadStatusNotDownloading},
+		{"PHASE_TYPE_RUNNING", enums.DownloadStatusDownloading},
+		{"PHASE_TYPE_COMPLETE", enums.DownloadStatusDone},
+		{"PHASE_TYPE_ERROR", enums.DownloadStatusError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.phase, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(map[string]interface{}{"phase": tt.phase})
+			}))
+			defer server.Close()
+
+			cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+			status, err := cli.GetTaskStatus(context.Background(), "task_123", "file_456")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if status != tt.expected {
+				t.Errorf("Expected status '%s', got '%s'", tt.expected, status)
+			}
+		})
+	}
+}
+
 func TestFileBatchStar_Success(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -2553,3 +2868,252 @@ func TestEvents_Success(t *testing.T) {
 		t.Fatal("Expected result to be non-nil")
 	}
 }
+
+func TestJSONHelpers_RejectNonSuccessStatus(t *testing.T) {
+	responses := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		wantErr     error
+	}{
+		{"html_502", http.StatusBadGateway, "text/html", "<html><body>502 Bad Gateway</body></html>", exception.ErrServerError},
+		{"json_403_without_error", http.StatusForbidden, "application/json", `{"data":{"id":"garbage"}}`, exception.ErrInvalidCredentials},
+	}
+
+	helpers := []struct {
+		name string
+		call func(*Client, string) error
+	}{
+		{"GetJSON", func(c *Client, u string) error { _, err := c.GetJSON(context.Background(), u, nil); return err }},
+		{"PostJSON", func(c *Client, u string) error {
+			_, err := c.PostJSON(context.Background(), u, map[string]string{})
+			return err
+		}},
+		{"PatchJSON", func(c *Client, u string) error {
+			_, err := c.PatchJSON(context.Background(), u, map[string]string{})
+			return err
+		}},
+		{"PostForm", func(c *Client, u string) error { _, err := c.PostForm(context.Background(), u, nil); return err }},
+		{"Delete", func(c *Client, u string) error { _, err := c.Delete(context.Background(), u, nil); return err }},
+	}
+
+	for _, rr := range responses {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", rr.contentType)
+			w.WriteHeader(rr.status)
+			w.Write([]byte(rr.body))
+		}))
+
+		cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithMaxRetries(0))
+		for _, h := range helpers {
+			t.Run(rr.name+"/"+h.name, func(t *testing.T) {
+				err := h.call(cli, server.URL+"/drive/v1/test")
+				if err == nil {
+					t.Fatal("Expected error for non-2xx response")
+				}
+				if !errors.Is(err, rr.wantErr) {
+					t.Errorf("Expected %v, got %v", rr.wantErr, err)
+				}
+				if got := exception.HTTPStatus(err); got != rr.status {
+					t.Errorf("Expected HTTP status %d in error, got %d", rr.status, got)
+				}
+				if !strings.Contains(err.Error(), rr.body) {
+					t.Errorf("Expected body snippet in error, got %v", err)
+				}
+			})
+		}
+		server.Close()
+	}
+}
+
+func TestJSONHelpers_EmptySuccessBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+
+	result, err := cli.PatchJSON(context.Background(), server.URL+"/drive/v1/test", map[string]string{})
+	if err != nil {
+		t.Fatalf("Expected no error for 204, got %v", err)
+	}
+	if len(result) != 0 {
+		t.Errorf("Expected empty result, got %v", result)
+	}
+}
+
+func TestFileNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/drive/v1/files/plain404":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{}`))
+		case "/drive/v1/files/deleted", "/drive/v1/files:batchMove", "/drive/v1/files:batchTrash", "/drive/v1/files:batchDelete":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"file_not_found","error_code":3,"error_description":"File not found"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithMaxRetries(0))
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"GetFileDetails_404", func() error { _, err := cli.GetFileDetails(ctx, "plain404"); return err }},
+		{"GetFileDetails_server_error", func() error { _, err := cli.GetFileDetails(ctx, "deleted"); return err }},
+		{"Rename_404", func() error { return cli.Rename(ctx, "plain404", "new.txt") }},
+		{"Rename_server_error", func() error { return cli.Rename(ctx, "deleted", "new.txt") }},
+		{"Move_server_error", func() error { return cli.Move(ctx, "deleted", "p1") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, exception.ErrFileNotFound) {
+				t.Errorf("Expected ErrFileNotFound, got %v", err)
+			}
+		})
+	}
+
+	t.Run("other_404_not_file", func(t *testing.T) {
+		_, err := cli.GetShareList(ctx, 10, "")
+		if errors.Is(err, exception.ErrFileNotFound) || !errors.Is(err, exception.ErrNotFound) {
+			t.Errorf("Expected generic ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("batch_delete_idempotent", func(t *testing.T) {
+		if _, err := cli.DeleteToTrash(ctx, []string{"deleted"}); err != nil {
+			t.Errorf("Expected DeleteToTrash to ignore missing files, got %v", err)
+		}
+		if _, err := cli.DeleteForever(ctx, []string{"deleted"}); err != nil {
+			t.Errorf("Expected DeleteForever to ignore missing files, got %v", err)
+		}
+	})
+}
+
+const premiumMediasFixture = `[
+	{"media_name": "1080P", "link": {"url": "https://example.com/1080p"}, "need_more_quota": true, "vip_types": ["VIP", "SUPER_VIP"]},
+	{"media_name": "720P", "link": {"url": "https://example.com/720p"}, "need_more_quota": true, "vip_types": ["VIP"]}
+]`
+
+func TestPremiumRequiredLinks(t *testing.T) {
+	var medias []interface{}
+	if err := json.Unmarshal([]byte(premiumMediasFixture), &medias); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fileInfo := map[string]interface{}{"id": "f1", "web_content_link": "", "medias": medias}
+		if r.URL.Path == "/drive/v1/share/file_info" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"file_info": fileInfo})
+			return
+		}
+		json.NewEncoder(w).Encode(fileInfo)
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+	ctx := context.Background()
+
+	_, err := cli.GetFileLink(ctx, "f1")
+	var pe *exception.PremiumRequiredError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Expected PremiumRequiredError, got %v", err)
+	}
+	if strings.Join(pe.VipTypes, ",") != "VIP,SUPER_VIP" {
+		t.Errorf("Expected vip types VIP,SUPER_VIP, got %v", pe.VipTypes)
+	}
+	if exception.IsRetryable(err) {
+		t.Error("Expected premium errors not to be retryable")
+	}
+
+	_, err = cli.GetShareFileDownloadURL(ctx, "https://mypikpak.com/s/share/link/abc", "", true)
+	if !errors.Is(err, exception.ErrPremiumRequired) {
+		t.Errorf("Expected ErrPremiumRequired for shared file, got %v", err)
+	}
+}
+
+func TestPremiumRequiredLinks_PrefersUnlockedRendition(t *testing.T) {
+	var medias []interface{}
+	json.Unmarshal([]byte(premiumMediasFixture), &medias)
+	medias = append(medias, map[string]interface{}{
+		"media_name": "480P",
+		"link":       map[string]interface{}{"url": "https://example.com/480p"},
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "f1", "medias": medias})
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+
+	link, err := cli.GetFileLink(context.Background(), "f1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if link != "https://example.com/480p" {
+		t.Errorf("Expected the free rendition, got %s", link)
+	}
+}
+
+func TestThumbnailSize(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/drive/v1/share/file_info" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"file_info": map[string]interface{}{"id": "f1"}})
+			return
+		}
+		got = append(got, r.URL.Query().Get("thumbnail_size"))
+		json.NewEncoder(w).Encode(map[string]interface{}{"files": []interface{}{}, "web_content_link": "https://example.com/f1"})
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	calls := []struct {
+		name string
+		def  string
+		call func(cli *Client) error
+	}{
+		{"FileList", "SIZE_MEDIUM", func(cli *Client) error { _, err := cli.FileList(ctx, 10, "", "", ""); return err }},
+		{"FileStarList", "SIZE_LARGE", func(cli *Client) error { _, err := cli.FileStarList(ctx, 10, ""); return err }},
+		{"Events", "SIZE_MEDIUM", func(cli *Client) error { _, err := cli.Events(ctx, 10, ""); return err }},
+		{"GetFileLink", "SIZE_LARGE", func(cli *Client) error { _, err := cli.GetFileLink(ctx, "f1"); return err }},
+		{"GetShareFiles", "SIZE_LARGE", func(cli *Client) error {
+			_, err := cli.GetShareFiles(ctx, "https://mypikpak.com/s/share/link/abc", "")
+			return err
+		}},
+		{"OfflineFileInfo", "SIZE_LARGE", func(cli *Client) error { _, err := cli.OfflineFileInfo(ctx, "f1"); return err }},
+	}
+
+	for _, tt := range calls {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			tt.call(NewClient(WithBaseURL(server.URL), WithAccessToken("test_token")))
+			tt.call(NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithThumbnailSize(enums.ThumbnailSizeSmall)))
+			if len(got) != 2 || got[0] != tt.def || got[1] != "SIZE_SMALL" {
+				t.Errorf("thumbnail_size = %v, want [%s SIZE_SMALL]", got, tt.def)
+			}
+
+			got = nil
+			err := tt.call(NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithThumbnailSize("SIZE_HUGE")))
+			if !errors.Is(err, exception.ErrInvalidParameter) {
+				t.Errorf("Expected ErrInvalidParameter, got %v", err)
+			}
+			if len(got) != 0 {
+				t.Errorf("Expected no request for an invalid size, got %v", got)
+			}
+		})
+	}
+}
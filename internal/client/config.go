@@ -0,0 +1,272 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/config"
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+	"github.com/zhz8888/pikpakapi-go/internal/token"
+)
+
+// NewClientFromConfig creates a client from the credentials, tokens, device
+// id and behavior settings in cfg. opts are applied after the config and
+// override it. Invalid settings, such as negative retries or a malformed proxy
+// URL, return ErrCodeInvalidParameter.
+//
+// When cfg holds both an encoded token and separate access and refresh tokens
+// that differ, the pair whose access token expires later is used. If neither
+// expiry can be read from the token, the encoded token wins.
+func NewClientFromConfig(cfg *config.Config, opts ...Option) (*Client, error) {
+	if cfg == nil {
+		return nil, exception.NewPikpakExceptionWithMessage(exception.ErrCodeInvalidParameter, "config is required")
+	}
+
+	accessToken, refreshToken, err := configTokens(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	behaviorOpts, err := configBehaviorOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	configOpts := []Option{
+		WithUsername(cfg.Username),
+		WithPassword(cfg.Password),
+		WithAccessToken(accessToken),
+		WithRefreshToken(refreshToken),
+		func(c *Client) {
+			c.authModule.SetUserID(cfg.UserID)
+			c.authModule.SetCaptchaToken(cfg.CaptchaToken)
+		},
+	}
+	if cfg.DeviceID != "" {
+		configOpts = append(configOpts, WithDeviceID(cfg.DeviceID))
+	}
+
+	configOpts = append(configOpts, behaviorOpts...)
+
+	return NewClientE(append(configOpts, opts...)...)
+}
+
+// configBehaviorOptions translates the behavior settings in cfg into options,
+// skipping those left at zero or, for MaxRetries, unset.
+func configBehaviorOptions(cfg *config.Config) ([]Option, error) {
+	invalid := func(format string, args ...interface{}) error {
+		return exception.NewPikpakExceptionWithMessage(exception.ErrCodeInvalidParameter, fmt.Sprintf(format, args...))
+	}
+
+	var opts []Option
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, invalid("invalid proxy_url %q: want scheme://host[:port]", cfg.ProxyURL)
+		}
+		opts = append(opts, WithProxy(proxyURL))
+	}
+	if cfg.DriveBaseURL != "" {
+		opts = append(opts, WithDriveBaseURL(cfg.DriveBaseURL))
+	}
+	if cfg.UserBaseURL != "" {
+		opts = append(opts, WithUserBaseURL(cfg.UserBaseURL))
+	}
+
+	switch {
+	case cfg.MaxRetries != nil && *cfg.MaxRetries < 0:
+		return nil, invalid("invalid max_retries %d: must not be negative", *cfg.MaxRetries)
+	case cfg.InitialBackoffMS < 0:
+		return nil, invalid("invalid initial_backoff_ms %d: must not be negative", cfg.InitialBackoffMS)
+	case cfg.HTTPTimeoutMS < 0:
+		return nil, invalid("invalid http_timeout_ms %d: must not be negative", cfg.HTTPTimeoutMS)
+	case cfg.BandwidthLimitBPS < 0:
+		return nil, invalid("invalid bandwidth_limit_bps %d: must not be negative", cfg.BandwidthLimitBPS)
+	}
+	if cfg.MaxRetries != nil {
+		opts = append(opts, WithMaxRetries(*cfg.MaxRetries))
+	}
+	if cfg.InitialBackoffMS > 0 {
+		opts = append(opts, WithInitialBackoff(time.Duration(cfg.InitialBackoffMS)*time.Millisecond))
+	}
+	if cfg.HTTPTimeoutMS > 0 {
+		opts = append(opts, WithTimeout(time.Duration(cfg.HTTPTimeoutMS)*time.Millisecond))
+	}
+	if cfg.BandwidthLimitBPS > 0 {
+		opts = append(opts, WithBandwidthLimit(cfg.BandwidthLimitBPS))
+	}
+
+	preference := LinkPreference(cfg.PreferredLink)
+	if !preference.valid() {
+		return nil, invalid("invalid preferred_link %q: want original, transcoded or auto", cfg.PreferredLink)
+	}
+	if preference != "" {
+		opts = append(opts, WithPreferredLink(preference))
+	}
+	if cfg.DownloadHostOverride != "" {
+		if strings.ContainsAny(cfg.DownloadHostOverride, "/?#@ ") {
+			return nil, invalid("invalid download_host_override %q: want host[:port]", cfg.DownloadHostOverride)
+		}
+		opts = append(opts, WithDownloadHost(cfg.DownloadHostOverride))
+	}
+	return opts, nil
+}
+
+// configTokens picks the access and refresh token to use from cfg: the
+// encoded token, unless the separate pair is known to expire later.
+func configTokens(cfg *config.Config) (accessToken, refreshToken string, err error) {
+	accessToken, refreshToken = cfg.AccessToken, cfg.RefreshToken
+	if cfg.EncodedToken != "" {
+		data, err := token.Decode(cfg.EncodedToken)
+		switch {
+		case err == nil && (accessToken == "" || !expiresAfter(accessToken, data.AccessToken)):
+			accessToken, refreshToken = data.AccessToken, data.RefreshToken
+		case err != nil && accessToken == "" && refreshToken == "":
+			return "", "", exception.NewPikpakExceptionWithError(exception.ErrCodeInvalidEncodedToken, err)
+		}
+	}
+	return accessToken, refreshToken, nil
+}
+
+// WatchConfig watches the config file at path with config.Watch and swaps in
+// its tokens whenever they change, so credentials can be rotated without
+// restarting. Configs without tokens, or with an unreadable encoded token,
+// are ignored. Watching stops when ctx is done.
+func (c *Client) WatchConfig(ctx context.Context, path string) error {
+	return config.Watch(ctx, path, func(cfg *config.Config) {
+		accessToken, refreshToken, err := configTokens(cfg)
+		if err != nil {
+			c.logger.Warnf("Ignoring config change: %v", err)
+			return
+		}
+		if accessToken == "" && refreshToken == "" {
+			return
+		}
+		if err := c.authModule.SetTokens(accessToken, refreshToken); err != nil {
+			c.logger.Errorf("Failed to update tokens from config: %v", err)
+		}
+	})
+}
+
+// ApplyToConfig writes the client's current tokens, user id and device id
+// into cfg, for saving after a login or token refresh.
+func (c *Client) ApplyToConfig(cfg *config.Config) error {
+	if err := c.EncodeToken(); err != nil {
+		return err
+	}
+	cfg.AccessToken = c.GetAccessToken()
+	cfg.RefreshToken = c.GetRefreshToken()
+	cfg.EncodedToken = c.GetEncodedToken()
+	cfg.UserID = c.GetUserID()
+	cfg.DeviceID = c.GetDeviceID()
+	return nil
+}
+
+// configSaveInterval is the minimum time between two automatic config saves.
+const configSaveInterval = time.Second
+
+// WithConfigAutoSave writes the client's tokens into cfg and saves it to path
+// after every login and token refresh. Saves are atomic and at most one per
+// second; a burst of refreshes is written once when the interval has passed.
+// Write errors are logged, not returned. In a profiles file only cfg's
+// profile is replaced; see config.SaveConfigAtomic. An encrypted file at path
+// is never overwritten: saves fail with config.ErrPassphraseRequired. Use
+// WithEncryptedConfigAutoSave for those.
+func WithConfigAutoSave(cfg *config.Config, path string) Option {
+	return func(c *Client) {
+		c.configSaver = &configAutoSaver{cfg: cfg, path: path, interval: configSaveInterval}
+	}
+}
+
+// WithEncryptedConfigAutoSave is WithConfigAutoSave for a config loaded with
+// config.LoadEncrypted: every save re-encrypts the file with passphrase
+// using config.SaveEncrypted.
+func WithEncryptedConfigAutoSave(cfg *config.Config, path string, passphrase string) Option {
+	return func(c *Client) {
+		c.configSaver = &configAutoSaver{cfg: cfg, path: path, passphrase: passphrase, interval: configSaveInterval}
+	}
+}
+
+type configAutoSaver struct {
+	mu         sync.Mutex
+	cfg        *config.Config
+	path       string
+	passphrase string
+	interval   time.Duration
+	last       time.Time
+	timer      *time.Timer
+}
+
+func (c *Client) autoSaveConfig() {
+	s := c.configSaver
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.timer != nil {
+		return
+	}
+	if wait := s.interval - time.Since(s.last); wait > 0 {
+		s.timer = time.AfterFunc(wait, func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.timer = nil
+			s.save(c)
+		})
+		return
+	}
+	s.save(c)
+}
+
+func (s *configAutoSaver) save(c *Client) {
+	s.last = time.Now()
+	if err := c.ApplyToConfig(s.cfg); err != nil {
+		c.logger.Errorf("Failed to update config: %v", err)
+		return
+	}
+	var err error
+	if s.passphrase != "" {
+		err = config.SaveEncrypted(s.cfg, s.path, s.passphrase)
+	} else {
+		err = config.SaveConfigAtomic(s.cfg, s.path)
+	}
+	if err != nil {
+		c.logger.Errorf("Failed to save config: %v", err)
+	}
+}
+
+// expiresAfter reports whether access token a is known to expire after b.
+func expiresAfter(a, b string) bool {
+	expA, okA := accessTokenExpiry(a)
+	expB, okB := accessTokenExpiry(b)
+	return okA && okB && expA.After(expB)
+}
+
+// accessTokenExpiry reads the exp claim of a JWT access token without
+// verifying it.
+func accessTokenExpiry(accessToken string) (time.Time, bool) {
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
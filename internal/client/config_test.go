@@ -0,0 +1,405 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/config"
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+	"github.com/zhz8888/pikpakapi-go/internal/token"
+)
+
+func testJWT(exp time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"sub":"user_id","exp":%d}`, exp.Unix())))
+	return "eyJhbGciOiJSUzI1NiJ9." + payload + ".signature"
+}
+
+func TestNewClientFromConfig(t *testing.T) {
+	encoded, _ := token.Encode("encoded_access", "encoded_refresh")
+	cfg := &config.Config{
+		Username:     "test@example.com",
+		Password:     "password",
+		EncodedToken: encoded,
+		DeviceID:     "device_id_abc",
+		UserID:       "user_id",
+	}
+
+	cli, err := NewClientFromConfig(cfg, WithMaxRetries(1))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cli.GetAccessToken() != "encoded_access" || cli.GetRefreshToken() != "encoded_refresh" {
+		t.Errorf("Expected tokens from the encoded token, got %q %q", cli.GetAccessToken(), cli.GetRefreshToken())
+	}
+	if cli.username != "test@example.com" || cli.password != "password" {
+		t.Errorf("Expected credentials from config, got %q", cli.username)
+	}
+	if cli.GetDeviceID() != "device_id_abc" || cli.GetUserID() != "user_id" {
+		t.Errorf("Expected device and user id from config, got %q %q", cli.GetDeviceID(), cli.GetUserID())
+	}
+	if cli.maxRetries != 1 {
+		t.Errorf("Expected options to apply after the config, got maxRetries %d", cli.maxRetries)
+	}
+
+	if _, err := NewClientFromConfig(&config.Config{EncodedToken: "not-base64!"}); exception.GetErrorCode(err) != exception.ErrCodeInvalidEncodedToken {
+		t.Errorf("Expected ErrCodeInvalidEncodedToken, got %v", err)
+	}
+}
+
+func TestNewClientFromConfig_PrefersFreshestTokens(t *testing.T) {
+	now := time.Now()
+	older, newer := testJWT(now.Add(time.Hour)), testJWT(now.Add(2*time.Hour))
+
+	tests := []struct {
+		name       string
+		encoded    string
+		access     string
+		wantAccess string
+	}{
+		{"separate_fresher", older, newer, newer},
+		{"encoded_fresher", newer, older, newer},
+		{"no_expiry", "encoded_access", "separate_access", "encoded_access"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, _ := token.Encode(tt.encoded, "encoded_refresh")
+			cli, err := NewClientFromConfig(&config.Config{
+				EncodedToken: encoded,
+				AccessToken:  tt.access,
+				RefreshToken: "separate_refresh",
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if cli.GetAccessToken() != tt.wantAccess {
+				t.Errorf("Expected access token %q, got %q", tt.wantAccess, cli.GetAccessToken())
+			}
+			wantRefresh := "encoded_refresh"
+			if tt.wantAccess == tt.access {
+				wantRefresh = "separate_refresh"
+			}
+			if cli.GetRefreshToken() != wantRefresh {
+				t.Errorf("Expected the refresh token of the same pair, got %q", cli.GetRefreshToken())
+			}
+		})
+	}
+}
+
+func TestApplyToConfig(t *testing.T) {
+	cli := NewClient(WithAccessToken("access"), WithRefreshToken("refresh"), WithDeviceID("device_id_abc"))
+	cli.SetUserID("user_id")
+
+	cfg := &config.Config{Username: "test@example.com"}
+	if err := cli.ApplyToConfig(cfg); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.AccessToken != "access" || cfg.RefreshToken != "refresh" || cfg.UserID != "user_id" || cfg.DeviceID != "device_id_abc" {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+	if cfg.Username != "test@example.com" {
+		t.Error("Expected other fields to be kept")
+	}
+
+	roundTrip, err := NewClientFromConfig(cfg)
+	if err != nil || roundTrip.GetAccessToken() != "access" || roundTrip.GetRefreshToken() != "refresh" {
+		t.Errorf("Expected config to round trip, got %v", err)
+	}
+}
+
+func newRefreshServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var refreshes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&refreshes, 1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  fmt.Sprintf("access_%d", n),
+			"refresh_token": fmt.Sprintf("refresh_%d", n),
+			"sub":           "user_id",
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRefreshAccessToken_StoresTokensTogether(t *testing.T) {
+	server := newRefreshServer(t)
+	cli := NewClient(WithBaseURL(server.URL), WithRefreshToken("refresh_0"))
+
+	done := make(chan struct{})
+	mismatch := make(chan string, 1)
+	go func() {
+		defer close(mismatch)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			data, err := token.Decode(cli.GetEncodedToken())
+			if err != nil || data.AccessToken == "" {
+				continue
+			}
+			if strings.TrimPrefix(data.AccessToken, "access_") != strings.TrimPrefix(data.RefreshToken, "refresh_") {
+				mismatch <- data.AccessToken + " with " + data.RefreshToken
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < 50; i++ {
+		if err := cli.RefreshAccessToken(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(done)
+	if pair, ok := <-mismatch; ok {
+		t.Errorf("Expected tokens of one refresh, saw %s", pair)
+	}
+}
+
+func TestWithConfigAutoSave_SavesOnRefresh(t *testing.T) {
+	server := newRefreshServer(t)
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := &config.Config{Username: "test@example.com", RefreshToken: "old_refresh"}
+
+	cli, _ := NewClientFromConfig(cfg, WithBaseURL(server.URL), WithConfigAutoSave(cfg, path))
+	if err := cli.RefreshAccessToken(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	saved, err := config.LoadConfigFrom(path)
+	if err != nil {
+		t.Fatalf("Expected config on disk, got %v", err)
+	}
+	if saved.AccessToken != "access_1" || saved.RefreshToken != "refresh_1" || saved.Username != "test@example.com" {
+		t.Errorf("Unexpected saved config: %+v", saved)
+	}
+}
+
+func TestWithConfigAutoSave_KeepsOtherProfiles(t *testing.T) {
+	server := newRefreshServer(t)
+	path := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv(config.ConfigEnvVar, path)
+	config.SaveProfile("personal", &config.Config{Username: "me@example.com"})
+	config.SaveProfile("work", &config.Config{Username: "work@example.com", RefreshToken: "old_refresh"})
+
+	cfg, err := config.LoadProfile("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli, _ := NewClientFromConfig(cfg, WithBaseURL(server.URL), WithConfigAutoSave(cfg, path))
+	if err := cli.RefreshAccessToken(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	work, _ := config.LoadProfile("work")
+	personal, _ := config.LoadProfile("personal")
+	if work == nil || work.AccessToken != "access_1" {
+		t.Errorf("Expected the refreshed token in the work profile, got %+v", work)
+	}
+	if personal == nil || personal.Username != "me@example.com" || personal.AccessToken != "" {
+		t.Errorf("Expected the personal profile to be kept, got %+v", personal)
+	}
+}
+
+func TestWithConfigAutoSave_DebouncesBursts(t *testing.T) {
+	server := newRefreshServer(t)
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := &config.Config{RefreshToken: "old_refresh"}
+
+	cli, _ := NewClientFromConfig(cfg, WithBaseURL(server.URL), WithConfigAutoSave(cfg, path))
+	cli.configSaver.interval = 50 * time.Millisecond
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		cli.RefreshAccessToken(ctx)
+	}
+
+	saved, _ := config.LoadConfigFrom(path)
+	if saved == nil || saved.AccessToken != "access_1" {
+		t.Fatalf("Expected only the first refresh to be written immediately, got %+v", saved)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	saved, _ = config.LoadConfigFrom(path)
+	if saved == nil || saved.AccessToken != "access_3" {
+		t.Errorf("Expected the burst to be written once the interval passed, got %+v", saved)
+	}
+}
+
+func TestWithConfigAutoSave_LogsWriteErrors(t *testing.T) {
+	server := newRefreshServer(t)
+	cfg := &config.Config{RefreshToken: "old_refresh"}
+	path := filepath.Join(t.TempDir(), "missing", "config.json")
+
+	cli, _ := NewClientFromConfig(cfg, WithBaseURL(server.URL), WithConfigAutoSave(cfg, path))
+	if err := cli.RefreshAccessToken(context.Background()); err != nil {
+		t.Errorf("Expected write errors not to fail the refresh, got %v", err)
+	}
+	if cfg.AccessToken != "access_1" {
+		t.Errorf("Expected the config struct to be updated, got %+v", cfg)
+	}
+}
+
+func TestWithConfigAutoSave_EncryptedFile(t *testing.T) {
+	server := newRefreshServer(t)
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := config.SaveEncrypted(&config.Config{RefreshToken: "old_refresh"}, path, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadEncrypted(path, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := &recordingLogger{}
+	cli, _ := NewClientFromConfig(cfg, WithBaseURL(server.URL), WithConfigAutoSave(cfg, path), WithLogger(logger))
+	if err := cli.RefreshAccessToken(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logger.String(), config.ErrPassphraseRequired.Error()) {
+		t.Errorf("Expected the save to be refused, got log %q", logger.String())
+	}
+	if saved, err := config.LoadEncrypted(path, "secret"); err != nil || saved.RefreshToken != "old_refresh" {
+		t.Errorf("Expected the encrypted file to be left alone, got %+v, %v", saved, err)
+	}
+
+	cli, _ = NewClientFromConfig(cfg, WithBaseURL(server.URL), WithEncryptedConfigAutoSave(cfg, path, "secret"))
+	if err := cli.RefreshAccessToken(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := config.LoadEncrypted(path, "secret")
+	if err != nil || saved.RefreshToken != "refresh_2" {
+		t.Errorf("Expected the new tokens in the re-encrypted file, got %+v, %v", saved, err)
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	config.SaveConfig(&config.Config{AccessToken: "old_access", RefreshToken: "old_refresh"}, path)
+
+	cli := NewClient(WithAccessToken("old_access"), WithRefreshToken("old_refresh"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := cli.WatchConfig(ctx, path); err != nil {
+		t.Fatalf("WatchConfig() returned unexpected error: %v", err)
+	}
+
+	encoded, _ := token.Encode("new_access", "new_refresh")
+	config.SaveConfig(&config.Config{EncodedToken: encoded}, path)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for cli.GetAccessToken() != "new_access" && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if cli.GetAccessToken() != "new_access" || cli.GetRefreshToken() != "new_refresh" {
+		t.Errorf("Expected tokens from the rewritten config, got %q %q", cli.GetAccessToken(), cli.GetRefreshToken())
+	}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestNewClientFromConfig_BehaviorSettings(t *testing.T) {
+	cfg := &config.Config{
+		ProxyURL:          "http://proxy.example.com:8080",
+		DriveBaseURL:      "https://drive.example.com",
+		UserBaseURL:       "https://user.example.com",
+		MaxRetries:        intPtr(5),
+		InitialBackoffMS:  250,
+		HTTPTimeoutMS:     1500,
+		BandwidthLimitBPS: 1 << 20,
+
+		PreferredLink:        "original",
+		DownloadHostOverride: "cdn.example.com",
+	}
+
+	cli, err := NewClientFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cli.proxyURL == nil || cli.proxyURL.Host != "proxy.example.com:8080" {
+		t.Errorf("Expected proxy from config, got %v", cli.proxyURL)
+	}
+	if cli.driveURL("/drive/v1/files") != "https://drive.example.com/drive/v1/files" || cli.userURL("/v1/auth/token") != "https://user.example.com/v1/auth/token" {
+		t.Errorf("Expected base URLs from config, got %q %q", cli.driveURL("/drive/v1/files"), cli.userURL("/v1/auth/token"))
+	}
+	if cli.maxRetries != 5 || cli.initialBackoff != 250*time.Millisecond || cli.httpClient.Timeout != 1500*time.Millisecond {
+		t.Errorf("Expected retry and timeout settings from config, got %d %v %v", cli.maxRetries, cli.initialBackoff, cli.httpClient.Timeout)
+	}
+	if _, ok := cli.httpClient.Transport.(*throttledTransport); !ok || cli.bandwidthLimit != 1<<20 {
+		t.Errorf("Expected a bandwidth limited transport, got %T", cli.httpClient.Transport)
+	}
+
+	if cli.preferredLink != LinkOriginal || cli.downloadHost != "cdn.example.com" {
+		t.Errorf("Expected link settings from config, got %q %q", cli.preferredLink, cli.downloadHost)
+	}
+
+	defaults, _ := NewClientFromConfig(&config.Config{})
+	if defaults.maxRetries != 3 || defaults.httpClient.Timeout != HTTPTimeout || defaults.httpClient.Transport != nil {
+		t.Errorf("Expected zero settings to keep the defaults, got %d %v %T", defaults.maxRetries, defaults.httpClient.Timeout, defaults.httpClient.Transport)
+	}
+
+	if cli, _ := NewClientFromConfig(cfg, WithMaxRetries(1)); cli.maxRetries != 1 {
+		t.Errorf("Expected options to override config settings, got %d", cli.maxRetries)
+	}
+}
+
+func TestNewClientFromConfig_ZeroMaxRetries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{"max_retries": 0}`), 0600)
+	cfg, err := config.LoadConfigFrom(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cli, err := NewClientFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cli.maxRetries != 0 {
+		t.Errorf("Expected an explicit 0 to turn retries off, got %d", cli.maxRetries)
+	}
+}
+
+func TestNewClientFromConfig_InvalidSettings(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want string
+	}{
+		{"negative retries", config.Config{MaxRetries: intPtr(-1)}, "max_retries"},
+		{"negative backoff", config.Config{InitialBackoffMS: -1}, "initial_backoff_ms"},
+		{"negative timeout", config.Config{HTTPTimeoutMS: -1}, "http_timeout_ms"},
+		{"negative bandwidth", config.Config{BandwidthLimitBPS: -1}, "bandwidth_limit_bps"},
+		{"proxy without scheme", config.Config{ProxyURL: "proxy.example.com:8080"}, "proxy_url"},
+		{"unparseable proxy", config.Config{ProxyURL: "http://[::1"}, "proxy_url"},
+		{"unknown link preference", config.Config{PreferredLink: "fastest"}, "preferred_link"},
+		{"download host with path", config.Config{DownloadHostOverride: "cdn.example.com/path"}, "download_host_override"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewClientFromConfig(&tt.cfg)
+			if exception.GetErrorCode(err) != exception.ErrCodeInvalidParameter {
+				t.Fatalf("Expected ErrCodeInvalidParameter, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected %q in %q", tt.want, err)
+			}
+		})
+	}
+}
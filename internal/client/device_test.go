@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/zhz8888/pikpakapi-go/internal/config"
+	"github.com/zhz8888/pikpakapi-go/internal/crypto"
+)
+
+var deviceIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+func TestDeviceID_Random(t *testing.T) {
+	a := NewClient(WithUsername("user"), WithPassword("pass"))
+	b := NewClient(WithUsername("user"), WithPassword("pass"))
+
+	if !deviceIDPattern.MatchString(a.DeviceID()) {
+		t.Errorf("Expected 32 hex digits, got %q", a.DeviceID())
+	}
+	if a.DeviceID() == b.DeviceID() {
+		t.Error("Expected every client to get its own device ID")
+	}
+	if a.DeviceID() == crypto.MD5Hash("user"+"pass") {
+		t.Error("Expected the device ID not to be derived from the credentials")
+	}
+	if a.DeviceID() != a.GetDeviceID() {
+		t.Errorf("Expected DeviceID and GetDeviceID to agree, got %q and %q", a.DeviceID(), a.GetDeviceID())
+	}
+}
+
+func TestDeviceID_PersistedThroughConfig(t *testing.T) {
+	cfg := &config.Config{AccessToken: "access", RefreshToken: "refresh"}
+	cli, err := NewClientFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.ApplyToConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DeviceID != cli.DeviceID() {
+		t.Fatalf("Expected the generated device ID in the config, got %q", cfg.DeviceID)
+	}
+
+	restored, err := NewClientFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.DeviceID() != cli.DeviceID() {
+		t.Errorf("Expected the device ID to survive a restart, got %q and %q", restored.DeviceID(), cli.DeviceID())
+	}
+	if explicit := NewClient(WithDeviceID("0123456789abcdef")); explicit.DeviceID() != "0123456789abcdef" {
+		t.Errorf("Expected WithDeviceID to win, got %q", explicit.DeviceID())
+	}
+}
+
+func TestDeviceID_SignsCaptcha(t *testing.T) {
+	const clock = int64(1700000000000)
+	var body struct {
+		DeviceID string                 `json:"device_id"`
+		Meta     map[string]interface{} `json:"meta"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"captcha_token": "captcha"})
+	}))
+	defer server.Close()
+
+	cfg := DefaultSigningConfig().WithClock(func() int64 { return clock })
+	cli := NewClient(WithBaseURL(server.URL), WithSigningConfig(cfg))
+	if err := cli.refreshCaptchaToken(context.Background(), "GET:/drive/v1/files"); err != nil {
+		t.Fatal(err)
+	}
+
+	timestamp := strconv.FormatInt(clock, 10)
+	if body.DeviceID != cli.DeviceID() {
+		t.Errorf("Expected the client's device ID, got %q", body.DeviceID)
+	}
+	if want := cfg.CaptchaSign(cli.DeviceID(), timestamp); body.Meta["captcha_sign"] != want {
+		t.Errorf("Expected captcha_sign %q, got %v", want, body.Meta["captcha_sign"])
+	}
+}
@@ -0,0 +1,20 @@
+package client
+
+import "github.com/zhz8888/pikpakapi-go/internal/event"
+
+// WithEventBus publishes the client's account events, such as EventLogout,
+// to bus.
+func WithEventBus(bus *event.EventBus) Option {
+	return func(c *Client) {
+		c.events = bus
+	}
+}
+
+func (c *Client) publish(ev event.Event) {
+	if c.events == nil {
+		return
+	}
+	if err := c.events.Publish(ev); err != nil {
+		c.logger.Warnf("Failed to publish %s event: %v", ev.Type, err)
+	}
+}
@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultTokenRefreshMargin is how long before its expiry the access token
+// is refreshed.
+const DefaultTokenRefreshMargin = time.Minute
+
+// WithTokenRefreshMargin sets how long before the access token expires a
+// request refreshes it first, DefaultTokenRefreshMargin by default. Tokens
+// of unknown expiry, set with WithAccessToken or SetAccessToken, are only
+// refreshed when the server rejects them.
+func WithTokenRefreshMargin(margin time.Duration) Option {
+	return func(c *Client) {
+		c.tokenRefreshMargin = margin
+	}
+}
+
+// TokenExpiresAt returns when the access token expires, as announced by the
+// last Login or RefreshAccessToken, or the zero time when that is unknown.
+func (c *Client) TokenExpiresAt() time.Time {
+	return c.authModule.GetTokenExpiresAt()
+}
+
+// refreshIfExpiring refreshes the access token when it expires within the
+// margin. A failed refresh is only logged: the request then goes out with
+// the old token and the server's answer decides.
+func (c *Client) refreshIfExpiring(ctx context.Context) {
+	token := c.authModule.GetAccessToken()
+	expiresAt := c.authModule.GetTokenExpiresAt()
+	if expiresAt.IsZero() || c.authModule.GetRefreshToken() == "" {
+		return
+	}
+	if c.now().Before(expiresAt.Add(-c.tokenRefreshMargin)) {
+		return
+	}
+	if err := c.refreshAccessToken(ctx, token); err != nil {
+		c.logger.Warnf("Proactive token refresh failed: %v", err)
+	}
+}
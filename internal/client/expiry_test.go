@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProactiveTokenRefresh(t *testing.T) {
+	var refreshes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/auth/token" {
+			n := atomic.AddInt32(&refreshes, 1)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token":  "token" + string(rune('0'+n)),
+				"refresh_token": "refresh",
+				"expires_in":    3600,
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"files": []interface{}{}})
+	}))
+	defer server.Close()
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	cli := NewClient(WithBaseURL(server.URL), WithRefreshToken("refresh"), WithTokenRefreshMargin(time.Minute))
+	cli.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	if err := cli.RefreshAccessToken(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if want := start.Add(time.Hour); !cli.TokenExpiresAt().Equal(want) {
+		t.Fatalf("Expected the token to expire at %s, got %s", want, cli.TokenExpiresAt())
+	}
+
+	now = start.Add(58 * time.Minute)
+	if _, err := cli.FileList(ctx, 10, "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if refreshes != 1 {
+		t.Fatalf("Expected no refresh before the margin, got %d refreshes", refreshes-1)
+	}
+
+	now = start.Add(59*time.Minute + time.Second)
+	for i := 0; i < 2; i++ {
+		if _, err := cli.FileList(ctx, 10, "", "", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if refreshes != 2 {
+		t.Errorf("Expected exactly one refresh within the margin, got %d", refreshes-1)
+	}
+	if got := cli.GetAccessToken(); got != "token2" {
+		t.Errorf("Expected the refreshed token, got %q", got)
+	}
+	if want := now.Add(time.Hour); !cli.TokenExpiresAt().Equal(want) {
+		t.Errorf("Expected the new expiry %s, got %s", want, cli.TokenExpiresAt())
+	}
+}
+
+func TestTokenExpiresAt_UnknownForSetToken(t *testing.T) {
+	cli := NewClient(WithAccessToken("token"))
+	if !cli.TokenExpiresAt().IsZero() {
+		t.Errorf("Expected an unknown expiry, got %s", cli.TokenExpiresAt())
+	}
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/zhz8888/pikpakapi-go/internal/constants"
+)
+
+// WithDriveHosts sets the primary drive host followed by an ordered fallback
+// list. Requests addressed to any drive host fail over to the next one on DNS
+// or connect errors, and the working host is remembered for the session.
+func WithDriveHosts(hosts ...string) Option {
+	return func(c *Client) {
+		if len(hosts) > 0 {
+			c.driveHosts = append([]string(nil), hosts...)
+			atomic.StoreInt32(&c.activeDriveHost, 0)
+		}
+	}
+}
+
+func defaultDriveHosts() []string {
+	return []string{constants.APIHost, constants.APIHostNet}
+}
+
+func (c *Client) currentDriveHost() string {
+	return c.driveHosts[int(atomic.LoadInt32(&c.activeDriveHost))%len(c.driveHosts)]
+}
+
+func (c *Client) isDriveHost(host string) bool {
+	if host == constants.APIHost {
+		return true
+	}
+	for _, h := range c.driveHosts {
+		if h == host {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) doWithHostFallback(req *http.Request) (*http.Response, error) {
+	if len(c.driveHosts) == 0 || !c.isDriveHost(req.URL.Host) {
+		return c.httpClient.Do(req)
+	}
+
+	start := int(atomic.LoadInt32(&c.activeDriveHost))
+	var lastErr error
+	for i := 0; i < len(c.driveHosts); i++ {
+		idx := (start + i) % len(c.driveHosts)
+		req.URL.Host = c.driveHosts[idx]
+		req.Host = ""
+
+		if i > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err == nil {
+			atomic.StoreInt32(&c.activeDriveHost, int32(idx))
+			return resp, nil
+		}
+		if !isUnsentError(err) || req.Context().Err() != nil {
+			return nil, err
+		}
+		lastErr = err
+	}
+
+	return nil, lastErr
+}
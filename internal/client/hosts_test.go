@@ -0,0 +1,147 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type dialRecorder struct {
+	mu      sync.Mutex
+	dialed  []string
+	target  string
+	failing map[string]bool
+}
+
+func (d *dialRecorder) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	d.mu.Lock()
+	d.dialed = append(d.dialed, addr)
+	d.mu.Unlock()
+
+	host, _, _ := net.SplitHostPort(addr)
+	if d.failing[host] {
+		return nil, &net.OpError{Op: "dial", Net: network, Err: &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}}
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, network, d.target)
+}
+
+func (d *dialRecorder) count(host string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := 0
+	for _, addr := range d.dialed {
+		if h, _, _ := net.SplitHostPort(addr); h == host {
+			n++
+		}
+	}
+	return n
+}
+
+func TestDriveHosts_FailoverAndStickiness(t *testing.T) {
+	var hostsSeen []string
+	var mu sync.Mutex
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hostsSeen = append(hostsSeen, r.Host)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"kind": "drive#about"})
+	}))
+	defer server.Close()
+
+	recorder := &dialRecorder{
+		target:  server.Listener.Addr().String(),
+		failing: map[string]bool{"drive.broken.test": true},
+	}
+
+	cli := NewClient(
+		WithAccessToken("test_token"),
+		WithDriveHosts("drive.broken.test", "drive.working.test"),
+	)
+	cli.httpClient.Transport = &http.Transport{
+		DialContext:       recorder.DialContext,
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		DisableKeepAlives: true,
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := cli.GetAbout(context.Background()); err != nil {
+			t.Fatalf("Expected no error on call %d, got %v", i, err)
+		}
+	}
+
+	if got := recorder.count("drive.broken.test"); got != 1 {
+		t.Errorf("Expected the failing host to be dialed once, got %d", got)
+	}
+	if got := recorder.count("drive.working.test"); got != 3 {
+		t.Errorf("Expected the working host to be dialed 3 times, got %d", got)
+	}
+	if cli.currentDriveHost() != "drive.working.test" {
+		t.Errorf("Expected working host to be remembered, got %s", cli.currentDriveHost())
+	}
+	for _, host := range hostsSeen {
+		if host != "drive.working.test" {
+			t.Errorf("Expected Host header 'drive.working.test', got %s", host)
+		}
+	}
+}
+
+func TestDriveHosts_NoFailoverOnHTTPError(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	recorder := &dialRecorder{target: server.Listener.Addr().String()}
+
+	cli := NewClient(
+		WithAccessToken("test_token"),
+		WithDriveHosts("drive.first.test", "drive.second.test"),
+		WithInitialBackoff(time.Millisecond),
+	)
+	cli.httpClient.Transport = &http.Transport{
+		DialContext:       recorder.DialContext,
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		DisableKeepAlives: true,
+	}
+
+	if _, err := cli.GetAbout(context.Background()); err == nil {
+		t.Fatal("Expected error for HTTP 500")
+	}
+	if got := recorder.count("drive.second.test"); got != 0 {
+		t.Errorf("Expected no failover on HTTP-level error, got %d dials to second host", got)
+	}
+	if cli.currentDriveHost() != "drive.first.test" {
+		t.Errorf("Expected primary host to stay active, got %s", cli.currentDriveHost())
+	}
+}
+
+func TestDriveHosts_BaseURLOverrideUntouched(t *testing.T) {
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{})
+	}))
+	defer server.Close()
+
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithAccessToken("test_token"),
+		WithDriveHosts("drive.first.test"),
+	)
+
+	if _, err := cli.GetAbout(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !called {
+		t.Error("Expected request to reach the base URL server")
+	}
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"strings"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+var (
+	invitedCountKeys = []string{"invited_count", "invite_count"}
+	inviteDaysKeys   = []string{"reward_days", "add_days", "days"}
+	inviteBytesKeys  = []string{"reward_space", "add_space", "space"}
+)
+
+// InviteInfo is the account's own invite code and what it has earned.
+// Counts the server leaves out are zero.
+type InviteInfo struct {
+	Code         string
+	InvitedCount int
+	// RewardDays and RewardBytes are the membership days and storage
+	// earned by inviting so far.
+	RewardDays  int
+	RewardBytes int64
+	// Raw is the whole response, for fields InviteInfo does not cover.
+	Raw map[string]interface{}
+}
+
+// InviteReward is what applying an invite code added to the account.
+type InviteReward struct {
+	Days       int
+	QuotaBytes int64
+	Raw        map[string]interface{}
+}
+
+// GetInviteInfo returns the account's invite code and invite statistics.
+func (c *Client) GetInviteInfo(ctx context.Context) (*InviteInfo, error) {
+	result, err := c.GetJSON(ctx, c.driveURL("/vip/v1/activity/inviteCode"), nil)
+	if err != nil {
+		return nil, err
+	}
+	data := inviteData(result)
+	info := &InviteInfo{Raw: result}
+	info.Code, _ = data["code"].(string)
+	if n, ok := findBytes(data, invitedCountKeys); ok {
+		info.InvitedCount = int(n)
+	}
+	if n, ok := findBytes(data, inviteDaysKeys); ok {
+		info.RewardDays = int(n)
+	}
+	info.RewardBytes, _ = findBytes(data, inviteBytesKeys)
+	return info, nil
+}
+
+// ApplyInviteCode activates an invite or activation code on the account.
+// A code that does not exist fails with ErrInviteCodeInvalid and one that
+// was already redeemed with ErrInviteCodeUsed.
+func (c *Client) ApplyInviteCode(ctx context.Context, code string) (*InviteReward, error) {
+	if code == "" {
+		return nil, exception.NewPikpakExceptionWithMessage(exception.ErrCodeInvalidParameter, "invite code is required")
+	}
+	result, err := c.PostJSON(ctx, c.driveURL("/vip/v1/order/activation-code"), map[string]interface{}{
+		"activation_code": code,
+		"data":            map[string]interface{}{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := inviteData(result)
+	reward := &InviteReward{Raw: result}
+	if n, ok := findBytes(data, inviteDaysKeys); ok {
+		reward.Days = int(n)
+	}
+	reward.QuotaBytes, _ = findBytes(data, inviteBytesKeys)
+	return reward, nil
+}
+
+func inviteData(result map[string]interface{}) map[string]interface{} {
+	if data, ok := result["data"].(map[string]interface{}); ok {
+		return data
+	}
+	return result
+}
+
+// inviteErrorCode maps the server's invite and activation code errors, such
+// as invalid_activation_code or activation_code_already_used.
+func inviteErrorCode(serverError string) (exception.ErrorCode, bool) {
+	s := strings.ToLower(serverError)
+	if !strings.Contains(s, "code") || !(strings.Contains(s, "invit") || strings.Contains(s, "activation")) {
+		return 0, false
+	}
+	switch {
+	case strings.Contains(s, "used"), strings.Contains(s, "already"), strings.Contains(s, "repeat"):
+		return exception.ErrCodeInviteCodeUsed, true
+	case strings.Contains(s, "invalid"), strings.Contains(s, "not_found"), strings.Contains(s, "not_exist"), strings.Contains(s, "expired"):
+		return exception.ErrCodeInviteCodeInvalid, true
+	}
+	return 0, false
+}
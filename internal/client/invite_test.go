@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+// newInviteServer answers activation requests with activateError, or a
+// reward when it is empty.
+func newInviteServer(t *testing.T, activateError string) *stubServer {
+	activate := stubJSON(map[string]interface{}{"data": map[string]interface{}{"add_days": 5, "add_space": 1073741824}})
+	if activateError != "" {
+		activate = stubError(http.StatusBadRequest, activateError, 4)
+	}
+	return newStubServer(t, map[string]http.HandlerFunc{
+		"/vip/v1/activity/inviteCode": stubJSON(map[string]interface{}{"code": "ABCD1234", "data": map[string]interface{}{
+			"code": "ABCD1234", "invited_count": 3, "reward_days": 15, "reward_space": "10737418240",
+		}}),
+		"/vip/v1/order/activation-code": activate,
+	})
+}
+
+func TestGetInviteInfo(t *testing.T) {
+	server := newInviteServer(t, "")
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("access"))
+
+	info, err := cli.GetInviteInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Code != "ABCD1234" || info.InvitedCount != 3 || info.RewardDays != 15 || info.RewardBytes != 10<<30 {
+		t.Errorf("Unexpected invite info %+v", info)
+	}
+}
+
+func TestApplyInviteCode(t *testing.T) {
+	server := newInviteServer(t, "")
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("access"))
+
+	reward, err := cli.ApplyInviteCode(context.Background(), "WXYZ9876")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reward.Days != 5 || reward.QuotaBytes != 1<<30 {
+		t.Errorf("Unexpected reward %+v", reward)
+	}
+	if activations := server.requests("/vip/v1/order/activation-code"); len(activations) != 1 || activations[0].Body["activation_code"] != "WXYZ9876" {
+		t.Errorf("Expected one activation with the code, got %v", activations)
+	}
+}
+
+func TestApplyInviteCode_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		activateError string
+		want          error
+	}{
+		{"reused", "activation_code_already_used", exception.ErrInviteCodeUsed},
+		{"invalid", "invalid_activation_code", exception.ErrInviteCodeInvalid},
+		{"invite code not found", "invite_code_not_found", exception.ErrInviteCodeInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newInviteServer(t, tt.activateError)
+			cli := NewClient(WithBaseURL(server.URL), WithAccessToken("access"), WithMaxRetries(0))
+			_, err := cli.ApplyInviteCode(context.Background(), "WXYZ9876")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	server := newInviteServer(t, "")
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("access"))
+	if _, err := cli.ApplyInviteCode(context.Background(), ""); !errors.Is(err, exception.ErrInvalidParameter) {
+		t.Errorf("Expected ErrInvalidParameter for an empty code, got %v", err)
+	}
+	if reqs := server.requests(""); len(reqs) != 0 {
+		t.Errorf("Expected no request, got %v", reqs)
+	}
+}
+
+func TestInviteErrorCode_IgnoresOtherErrors(t *testing.T) {
+	for _, serverError := range []string{"file_not_found", "captcha_invalid", "invalid_argument", "already_exists"} {
+		if code, ok := inviteErrorCode(serverError); ok {
+			t.Errorf("%s: expected no invite error, got %v", serverError, code)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+type MetricsCollector interface {
+	SetInFlightRequests(n int)
+}
+
+func WithMetricsCollector(collector MetricsCollector) Option {
+	return func(c *Client) {
+		c.metrics = collector
+	}
+}
+
+// WithMaxConcurrentRequests caps the number of API requests in flight across
+// the whole client. Zero or negative means unlimited.
+func WithMaxConcurrentRequests(n int) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.requestSem = make(chan struct{}, n)
+		} else {
+			c.requestSem = nil
+		}
+	}
+}
+
+// WithMaxResponseBytes caps how much of an API response body is buffered.
+// Zero or negative disables the cap. Download streams are never limited.
+func WithMaxResponseBytes(n int64) Option {
+	return func(c *Client) {
+		c.maxResponseBytes = n
+	}
+}
+
+func (c *Client) acquireRequestSlot(ctx context.Context) (func(), error) {
+	if c.requestSem != nil {
+		select {
+		case c.requestSem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	c.reportInFlight(atomic.AddInt64(&c.inFlight, 1))
+
+	return func() {
+		c.reportInFlight(atomic.AddInt64(&c.inFlight, -1))
+		if c.requestSem != nil {
+			<-c.requestSem
+		}
+	}, nil
+}
+
+func (c *Client) reportInFlight(n int64) {
+	if c.metrics != nil {
+		c.metrics.SetInFlightRequests(int(n))
+	}
+}
+
+// send performs a single API round trip under the rate and concurrency
+// limiters and returns the response with its body fully read and closed.
+// Long-lived download streams must not go through send.
+func (c *Client) send(req *http.Request) (*http.Response, []byte, error) {
+	if err := c.waitRateLimit(req.Context()); err != nil {
+		return nil, nil, err
+	}
+	release, err := c.acquireRequestSlot(req.Context())
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+
+	resp, err := c.doWithHostFallback(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := c.readResponseBody(resp.Body)
+	if err != nil {
+		return resp, nil, err
+	}
+
+	return resp, respBody, nil
+}
+
+func (c *Client) readResponseBody(body io.Reader) ([]byte, error) {
+	if c.maxResponseBytes <= 0 {
+		return io.ReadAll(body)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, c.maxResponseBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > c.maxResponseBytes {
+		return nil, exception.NewPikpakExceptionWithMessage(exception.ErrCodeResponseTooLarge, fmt.Sprintf("response body exceeds %d bytes", c.maxResponseBytes))
+	}
+	return data, nil
+}
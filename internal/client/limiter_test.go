@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+type recordingMetrics struct {
+	mu  sync.Mutex
+	max int
+}
+
+func (m *recordingMetrics) SetInFlightRequests(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if n > m.max {
+		m.max = n
+	}
+}
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	const limit = 4
+
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			old := atomic.LoadInt32(&maxInFlight)
+			if n <= old || atomic.CompareAndSwapInt32(&maxInFlight, old, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"kind": "drive#about"})
+	}))
+	defer server.Close()
+
+	metrics := &recordingMetrics{}
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithAccessToken("test_token"),
+		WithMaxConcurrentRequests(limit),
+		WithMetricsCollector(metrics),
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cli.GetAbout(context.Background()); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight > limit {
+		t.Errorf("Expected at most %d requests in flight, observed %d", limit, maxInFlight)
+	}
+	if metrics.max > limit {
+		t.Errorf("Expected in-flight gauge to stay at or below %d, got %d", limit, metrics.max)
+	}
+	if metrics.max == 0 {
+		t.Error("Expected in-flight gauge to be reported")
+	}
+}
+
+func TestMaxConcurrentRequests_ContextCancelledWhileWaiting(t *testing.T) {
+	cli := NewClient(WithMaxConcurrentRequests(1))
+
+	release, err := cli.acquireRequestSlot(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := cli.acquireRequestSlot(ctx); err == nil {
+		t.Error("Expected error when context expires while waiting for a slot")
+	}
+}
+
+func TestMaxConcurrentRequests_Unlimited(t *testing.T) {
+	for _, n := range []int{0, -1} {
+		cli := NewClient(WithMaxConcurrentRequests(n))
+		if cli.requestSem != nil {
+			t.Errorf("Expected no limiter for n=%d", n)
+		}
+	}
+}
+
+func TestMaxResponseBytes_OversizedJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html>" + strings.Repeat("x", 4096) + "</html>"))
+	}))
+	defer server.Close()
+
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithAccessToken("test_token"),
+		WithMaxResponseBytes(1024),
+		WithInitialBackoff(time.Millisecond),
+	)
+
+	_, err := cli.GetAbout(context.Background())
+	if err == nil {
+		t.Fatal("Expected error for oversized response")
+	}
+	if !errors.Is(err, exception.ErrResponseTooLarge) {
+		t.Errorf("Expected ErrResponseTooLarge, got %v", err)
+	}
+}
+
+func TestMaxResponseBytes_DownloadNotLimited(t *testing.T) {
+	payload := strings.Repeat("d", 64*1024)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/download/file.bin" {
+			w.Write([]byte(payload))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"web_content_link": server.URL + "/download/file.bin",
+		})
+	}))
+	defer server.Close()
+
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithAccessToken("test_token"),
+		WithMaxResponseBytes(1024),
+	)
+
+	dest := filepath.Join(t.TempDir(), "file.bin")
+	if err := cli.DownloadToFile(context.Background(), "file_id", dest); err != nil {
+		t.Fatalf("Expected download to succeed, got %v", err)
+	}
+
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("Failed to read downloaded file: %v", err)
+	}
+	if len(data) != len(payload) {
+		t.Errorf("Expected %d bytes, got %d", len(payload), len(data))
+	}
+}
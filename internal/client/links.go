@@ -0,0 +1,93 @@
+package client
+
+import (
+	"net/url"
+)
+
+// LinkPreference chooses between a file's original download link and its
+// transcoded media renditions.
+type LinkPreference string
+
+const (
+	// LinkAuto keeps each method's own default: GetFileLink and
+	// DownloadToFile prefer a rendition, GetShareFileDownloadURL the original
+	// unless useTranscoding is set.
+	LinkAuto LinkPreference = "auto"
+	// LinkOriginal uses the original file whenever it has a link.
+	LinkOriginal LinkPreference = "original"
+	// LinkTranscoded uses a playable rendition whenever there is one.
+	LinkTranscoded LinkPreference = "transcoded"
+)
+
+func (p LinkPreference) valid() bool {
+	switch p {
+	case "", LinkAuto, LinkOriginal, LinkTranscoded:
+		return true
+	}
+	return false
+}
+
+// WithPreferredLink sets the link preference used by GetFileLink,
+// GetShareFileDownloadURL and DownloadToFile when a call does not pass
+// WithLinkPreference.
+func WithPreferredLink(preference LinkPreference) Option {
+	return func(c *Client) {
+		c.preferredLink = preference
+	}
+}
+
+// WithDownloadHost replaces the host of every download link returned by
+// GetFileLink and GetShareFileDownloadURL, and fetched by DownloadToFile,
+// unless a call passes WithLinkHost. The scheme, path and query are kept.
+func WithDownloadHost(host string) Option {
+	return func(c *Client) {
+		c.downloadHost = host
+	}
+}
+
+// LinkOption overrides the client's link settings for a single call.
+type LinkOption func(*linkOptions)
+
+type linkOptions struct {
+	preference LinkPreference
+	host       string
+}
+
+// WithLinkPreference overrides WithPreferredLink for one call.
+func WithLinkPreference(preference LinkPreference) LinkOption {
+	return func(o *linkOptions) {
+		o.preference = preference
+	}
+}
+
+// WithLinkHost overrides WithDownloadHost for one call. An empty host keeps
+// the link as returned by the server.
+func WithLinkHost(host string) LinkOption {
+	return func(o *linkOptions) {
+		o.host = host
+	}
+}
+
+// linkOptions applies opts over the client defaults, with preference as the
+// default preference.
+func (c *Client) linkOptions(preference LinkPreference, opts []LinkOption) linkOptions {
+	o := linkOptions{preference: preference, host: c.downloadHost}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// rewriteHost replaces the host of link with o.host, leaving links that do
+// not parse untouched.
+func (o linkOptions) rewriteHost(link string) string {
+	if o.host == "" || link == "" {
+		return link
+	}
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return link
+	}
+	u.Host = o.host
+	return u.String()
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newLinksServer answers file and share file details for f1, whose links
+// point at dl.invalid, and serves /download/ and /media/ with the request
+// path and query as the content.
+func newLinksServer(t *testing.T) *stubServer {
+	fileInfo := map[string]interface{}{
+		"id":               "f1",
+		"web_content_link": "http://dl.invalid/download/f1?sig=abc",
+		"medias": []interface{}{
+			map[string]interface{}{"link": map[string]interface{}{"url": "http://dl.invalid/media/f1.mp4?sig=abc"}},
+		},
+	}
+	return newStubServer(t, map[string]http.HandlerFunc{
+		"/drive/v1/share/file_info": stubJSON(map[string]interface{}{"file_info": fileInfo}),
+		"": func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, "/download/") || strings.HasPrefix(r.URL.Path, "/media/") {
+				w.Write([]byte(r.URL.Path + "?" + r.URL.RawQuery))
+				return
+			}
+			json.NewEncoder(w).Encode(fileInfo)
+		},
+	})
+}
+
+func TestLinkPreference(t *testing.T) {
+	server := newLinksServer(t)
+	ctx := context.Background()
+	shareURL := "https://mypikpak.com/s/share/link/abc"
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+	if link, _ := cli.GetFileLink(ctx, "f1"); link != "http://dl.invalid/media/f1.mp4?sig=abc" {
+		t.Errorf("Expected GetFileLink to default to the rendition, got %s", link)
+	}
+	if link, _ := cli.GetShareFileDownloadURL(ctx, shareURL, "", false); link != "http://dl.invalid/download/f1?sig=abc" {
+		t.Errorf("Expected GetShareFileDownloadURL to default to the original, got %s", link)
+	}
+
+	cli = NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithPreferredLink(LinkOriginal))
+	if link, _ := cli.GetFileLink(ctx, "f1"); link != "http://dl.invalid/download/f1?sig=abc" {
+		t.Errorf("Expected the client preference to select the original, got %s", link)
+	}
+	if link, _ := cli.GetFileLink(ctx, "f1", WithLinkPreference(LinkTranscoded)); link != "http://dl.invalid/media/f1.mp4?sig=abc" {
+		t.Errorf("Expected the per-call preference to win, got %s", link)
+	}
+	if link, _ := cli.GetShareFileDownloadURL(ctx, shareURL, "", true); link != "http://dl.invalid/media/f1.mp4?sig=abc" {
+		t.Errorf("Expected useTranscoding to win over the client preference, got %s", link)
+	}
+
+	cli = NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithPreferredLink(LinkTranscoded))
+	if link, _ := cli.GetShareFileDownloadURL(ctx, shareURL, "", false); link != "http://dl.invalid/media/f1.mp4?sig=abc" {
+		t.Errorf("Expected the client preference to apply to shares, got %s", link)
+	}
+	if link, _ := cli.GetShareFileDownloadURL(ctx, shareURL, "", false, WithLinkPreference(LinkOriginal)); link != "http://dl.invalid/download/f1?sig=abc" {
+		t.Errorf("Expected the per-call preference to win for shares, got %s", link)
+	}
+}
+
+func TestDownloadHost(t *testing.T) {
+	server := newLinksServer(t)
+	serverHost := strings.TrimPrefix(server.URL, "http://")
+	ctx := context.Background()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithPreferredLink(LinkOriginal), WithDownloadHost(serverHost))
+	if link, _ := cli.GetFileLink(ctx, "f1"); link != "http://"+serverHost+"/download/f1?sig=abc" {
+		t.Errorf("Expected only the host to be rewritten, got %s", link)
+	}
+	if link, _ := cli.GetShareFileDownloadURL(ctx, "https://mypikpak.com/s/share/link/abc", "", true); link != "http://"+serverHost+"/media/f1.mp4?sig=abc" {
+		t.Errorf("Expected the share link host to be rewritten, got %s", link)
+	}
+	if link, _ := cli.GetFileLink(ctx, "f1", WithLinkHost("")); link != "http://dl.invalid/download/f1?sig=abc" {
+		t.Errorf("Expected the per-call host to win, got %s", link)
+	}
+
+	dest := filepath.Join(t.TempDir(), "f1")
+	if err := cli.DownloadToFile(ctx, "f1", dest, WithLinkPreference(LinkTranscoded)); err != nil {
+		t.Fatalf("Expected the download to reach the overridden host, got %v", err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "/media/f1.mp4?sig=abc" {
+		t.Errorf("Expected the rendition to be downloaded, got %q", data)
+	}
+}
+
+func TestGetShareFileLink(t *testing.T) {
+	server := newStubServer(t, map[string]http.HandlerFunc{
+		"/share/v1/passcode": stubJSON(map[string]interface{}{"pass_code_token": "pct"}),
+		"/drive/v1/share/file_info": func(w http.ResponseWriter, r *http.Request) {
+			fileID := r.URL.Query().Get("file_id")
+			json.NewEncoder(w).Encode(map[string]interface{}{"file_info": map[string]interface{}{
+				"id":               fileID,
+				"web_content_link": "http://dl.invalid/download/" + fileID,
+			}})
+		},
+	})
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+	link, err := cli.GetShareFileLink(context.Background(), "s1", "f2", "secret", WithLinkPreference(LinkOriginal))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link != "http://dl.invalid/download/f2" {
+		t.Errorf("Expected the link of f2, got %s", link)
+	}
+	reqs := server.requests("/drive/v1/share/file_info")
+	if len(reqs) != 1 || reqs[0].Query.Get("share_id") != "s1" || reqs[0].Query.Get("pass_code_token") != "pct" {
+		t.Errorf("Expected the share and pass token in the query, got %v", reqs)
+	}
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+)
+
+// Logger receives the client's diagnostics: Debugf for each request and
+// upload chunk, Infof for token refreshes, Warnf for retried failures and
+// ignored options, Errorf for tokens and configs that could not be saved.
+// Messages never contain tokens, passwords or captcha tokens.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// WithLogger sends the client's diagnostics to logger. By default nothing is
+// logged; NewStdLogger restores the standard logger output.
+func WithLogger(logger Logger) Option {
+	return func(c *Client) {
+		if logger == nil {
+			logger = nopLogger{}
+		}
+		c.logger = logger
+	}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debugf(string, ...interface{}) {}
+func (nopLogger) Infof(string, ...interface{})  {}
+func (nopLogger) Warnf(string, ...interface{})  {}
+func (nopLogger) Errorf(string, ...interface{}) {}
+
+// NewStdLogger returns a Logger that prints Info, Warn and Error messages to
+// logger, or to the standard logger when logger is nil. Debug messages are
+// printed too when debug is set.
+func NewStdLogger(logger *log.Logger, debug bool) Logger {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &stdLogger{logger: logger, debug: debug}
+}
+
+type stdLogger struct {
+	logger *log.Logger
+	debug  bool
+}
+
+func (l *stdLogger) Debugf(format string, args ...interface{}) {
+	if l.debug {
+		l.print("DEBUG", format, args)
+	}
+}
+
+func (l *stdLogger) Infof(format string, args ...interface{}) {
+	l.print("INFO", format, args)
+}
+
+func (l *stdLogger) Warnf(format string, args ...interface{}) {
+	l.print("WARN", format, args)
+}
+
+func (l *stdLogger) Errorf(format string, args ...interface{}) {
+	l.print("ERROR", format, args)
+}
+
+func (l *stdLogger) print(level, format string, args []interface{}) {
+	l.logger.Output(3, level+" "+fmt.Sprintf(format, args...))
+}
+
+// redactURL drops the query of the URL in a transport error before it is
+// logged, since it may carry share pass code tokens.
+func redactURL(err error) error {
+	uerr, ok := err.(*url.Error)
+	if !ok {
+		return err
+	}
+	u, perr := url.Parse(uerr.URL)
+	if perr != nil || u.RawQuery == "" {
+		return err
+	}
+	u.RawQuery = ""
+	redacted := *uerr
+	redacted.URL = u.String()
+	return &redacted
+}
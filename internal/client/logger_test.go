@@ -0,0 +1,110 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type recordingLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *recordingLogger) record(level, format string, args []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, level+" "+fmt.Sprintf(format, args...))
+}
+
+func (l *recordingLogger) Debugf(format string, args ...interface{}) { l.record("DEBUG", format, args) }
+func (l *recordingLogger) Infof(format string, args ...interface{})  { l.record("INFO", format, args) }
+func (l *recordingLogger) Warnf(format string, args ...interface{})  { l.record("WARN", format, args) }
+func (l *recordingLogger) Errorf(format string, args ...interface{}) { l.record("ERROR", format, args) }
+
+func (l *recordingLogger) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return strings.Join(l.lines, "\n")
+}
+
+func TestWithLogger_RetriesWithoutSecrets(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/auth/token" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "secret_new_access", "refresh_token": "secret_new_refresh"})
+			return
+		}
+		switch atomic.AddInt32(&attempts, 1) {
+		case 1:
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error_code": 16, "error": "unauthenticated"})
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			json.NewEncoder(w).Encode(map[string]interface{}{"files": []interface{}{}})
+		}
+	}))
+	defer server.Close()
+
+	logger := &recordingLogger{}
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("secret_access"), WithRefreshToken("secret_refresh"),
+		WithPassword("secret_password"), WithInitialBackoff(time.Millisecond), WithLogger(logger))
+	cli.authModule.SetCaptchaToken("secret_captcha")
+	if _, err := cli.FileList(context.Background(), 10, "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	out := logger.String()
+	for _, want := range []string{"INFO Access token refreshed", "WARN Request failed with status 503 (attempt", "DEBUG GET /drive/v1/files (attempt 3/4)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in the log:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "secret") {
+		t.Errorf("Expected no tokens or passwords in the log:\n%s", out)
+	}
+}
+
+func TestDefaultLogger_Silent(t *testing.T) {
+	var buf bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&buf)
+
+	// A failed request and an invalid option, both logged before.
+	var attempts int32
+	cli := newCountingClient(&attempts, errors.New("connection refused"), WithMaxRetries(0), WithInitialBackoff(-1))
+	cli.FileList(context.Background(), 10, "", "", "")
+	if buf.Len() != 0 {
+		t.Errorf("Expected nothing on the standard logger, got %q", buf.String())
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	err := redactURL(&url.Error{Op: "Get", URL: "https://api.test/drive/v1/share?pass_code_token=secret", Err: errors.New("refused")})
+	if strings.Contains(err.Error(), "secret") || !strings.Contains(err.Error(), "https://api.test/drive/v1/share") {
+		t.Errorf("Expected the query to be dropped, got %q", err)
+	}
+}
+
+func TestNewStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewStdLogger(log.New(&buf, "", 0), false)
+	logger.Debugf("hidden")
+	logger.Warnf("retry %d", 1)
+	if got := buf.String(); got != "WARN retry 1\n" {
+		t.Errorf("Expected only the warning, got %q", got)
+	}
+}
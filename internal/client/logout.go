@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/zhz8888/pikpakapi-go/internal/event"
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+// Logout revokes the access token on the server and forgets the client's
+// tokens, deleting them from the WithTokenStore store, then publishes
+// EventLogout. A token the server already rejects counts as revoked; on any
+// other error the tokens are kept so that Logout can be retried.
+//
+// Until the client signs in again or is given a new access token, API
+// calls fail with exception.ErrUnauthorized without sending a request.
+func (c *Client) Logout(ctx context.Context) error {
+	if accessToken := c.GetAccessToken(); accessToken != "" {
+		_, err := c.PostJSON(ctx, c.userURL("/v1/auth/revoke"), map[string]interface{}{
+			"token": accessToken,
+		})
+		if err != nil && !errors.Is(err, exception.ErrUnauthorized) {
+			return err
+		}
+	}
+
+	c.authModule.ClearTokens()
+	c.InvalidateCache()
+	c.loggedOut.Store(true)
+	if c.tokenStore != nil {
+		if err := c.tokenStore.Delete(c.tokenAccount); err != nil {
+			c.logger.Errorf("Failed to delete stored token: %v", err)
+		}
+	}
+	c.logger.Infof("Logged out")
+	c.publish(event.Event{
+		Type:    event.EventLogout,
+		Payload: event.LoginEvent{Username: c.username, UserID: c.GetUserID()},
+	})
+	return nil
+}
+
+// requireSession fails requests made after Logout, which would otherwise
+// go out with no Authorization header. Sign-in and captcha requests are
+// let through so that the client can log in again.
+func (c *Client) requireSession(path string) error {
+	if !c.loggedOut.Load() || c.GetAccessToken() != "" {
+		return nil
+	}
+	if strings.HasPrefix(path, "/v1/auth/") || strings.HasPrefix(path, "/v1/shield/") {
+		return nil
+	}
+	return exception.NewPikpakExceptionWithMessage(exception.ErrCodeUnauthorized, "logged out")
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/zhz8888/pikpakapi-go/internal/event"
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+	"github.com/zhz8888/pikpakapi-go/internal/token"
+)
+
+func TestLogout(t *testing.T) {
+	var paths []string
+	var authorization string
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path == "/v1/auth/revoke" {
+			authorization = r.Header.Get("Authorization")
+			json.NewDecoder(r.Body).Decode(&body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	store := token.NewFileTokenStore(filepath.Join(t.TempDir(), "tokens.json"))
+	bus := event.NewEventBus(event.WithSyncDelivery())
+	var events []event.Event
+	bus.Subscribe(event.EventLogout, func(ev event.Event) { events = append(events, ev) })
+
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithAccessToken("access"),
+		WithRefreshToken("refresh"),
+		WithTokenStore(store, "account"),
+		WithEventBus(bus),
+	)
+	cli.authModule.SetCaptchaToken("captcha")
+	cli.EncodeToken()
+	cli.saveToken()
+
+	if err := cli.Logout(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 || paths[0] != "/v1/auth/revoke" {
+		t.Fatalf("Expected one revoke request, got %v", paths)
+	}
+	if authorization != "Bearer access" || body["token"] != "access" {
+		t.Errorf("Expected the access token to be revoked, got %q %v", authorization, body)
+	}
+	if cli.GetAccessToken() != "" || cli.GetRefreshToken() != "" || cli.GetEncodedToken() != "" || cli.authModule.GetCaptchaToken() != "" {
+		t.Errorf("Expected tokens to be cleared, got %v", cli.GetUserInfo())
+	}
+	if _, err := store.Load("account"); !errors.Is(err, token.ErrTokenNotFound) {
+		t.Errorf("Expected the stored token to be deleted, got %v", err)
+	}
+	if len(events) != 1 {
+		t.Errorf("Expected one logout event, got %d", len(events))
+	}
+
+	if _, err := cli.FileList(context.Background(), 10, "", "", ""); !errors.Is(err, exception.ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized after logout, got %v", err)
+	}
+	if len(paths) != 1 {
+		t.Errorf("Expected no request after logout, got %v", paths)
+	}
+}
+
+func TestLogout_RejectedTokenIsRevoked(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"unauthenticated","error_code":16}`))
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("expired"), WithRefreshToken("refresh"), WithMaxRetries(0))
+	if err := cli.Logout(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if cli.GetAccessToken() != "" || cli.GetRefreshToken() != "" {
+		t.Errorf("Expected tokens to be cleared, got %v", cli.GetUserInfo())
+	}
+}
+
+func TestLogout_FailureKeepsTokens(t *testing.T) {
+	var attempts int32
+	cli := newCountingClient(&attempts, errors.New("connection refused"), WithAccessToken("access"), WithRefreshToken("refresh"), WithMaxRetries(0))
+	if err := cli.Logout(context.Background()); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if cli.GetAccessToken() != "access" || cli.GetRefreshToken() != "refresh" {
+		t.Errorf("Expected tokens to be kept, got %v", cli.GetUserInfo())
+	}
+	if _, err := cli.FileList(context.Background(), 10, "", "", ""); errors.Is(err, exception.ErrUnauthorized) {
+		t.Errorf("Expected requests to go out, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+func TestGetFileByPath(t *testing.T) {
+	children := map[string][]map[string]interface{}{
+		"":   {{"id": "d1", "name": "docs", "kind": "drive#folder"}},
+		"d1": {{"id": "f1", "name": "a.txt", "kind": "drive#file"}},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		files := []interface{}{}
+		for _, f := range children[r.URL.Query().Get("parent_id")] {
+			files = append(files, f)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"files": files})
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+	ctx := context.Background()
+
+	if root, err := cli.GetFileByPath(ctx, "/"); err != nil || root["id"] != "" {
+		t.Errorf("Expected the root, got %v, %v", root, err)
+	}
+	if f, err := cli.GetFileByPath(ctx, "/docs/a.txt"); err != nil || f["id"] != "f1" {
+		t.Errorf("Expected f1, got %v, %v", f, err)
+	}
+	if _, err := cli.GetFileByPath(ctx, "docs/missing"); exception.GetErrorCode(err) != exception.ErrCodeFileNotFound {
+		t.Errorf("Expected ErrCodeFileNotFound, got %v", err)
+	}
+	if _, err := cli.GetFileByPath(ctx, "docs/a.txt/b"); exception.GetErrorCode(err) != exception.ErrCodeFileNotFound {
+		t.Errorf("Expected ErrCodeFileNotFound below a file, got %v", err)
+	}
+}
+
+func TestOpenFile(t *testing.T) {
+	const content = "0123456789"
+	ignoreRange := false
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/content/"):
+			if ignoreRange {
+				r.Header.Del("Range")
+			}
+			http.ServeContent(w, r, "f1", time.Time{}, strings.NewReader(content))
+		case r.URL.Path == "/drive/v1/files/missing":
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": "missing", "web_content_link": server.URL + "/gone"})
+		case r.URL.Path == "/drive/v1/files/f1":
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": "f1", "web_content_link": server.URL + "/content/f1"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+	ctx := context.Background()
+
+	for _, ignore := range []bool{false, true} {
+		ignoreRange = ignore
+		body, err := cli.OpenFile(ctx, "f1", 4)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		got, _ := io.ReadAll(body)
+		body.Close()
+		if string(got) != content[4:] {
+			t.Errorf("Ignoring range %v: expected %q, got %q", ignore, content[4:], got)
+		}
+	}
+
+	if _, err := cli.OpenFile(ctx, "missing", 0); exception.GetErrorCode(err) != exception.ErrCodeServerError {
+		t.Errorf("Expected ErrCodeServerError for a failed download, got %v", err)
+	}
+}
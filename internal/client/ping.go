@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+type PingResult struct {
+	Latency        time.Duration
+	StatusCode     int
+	TokenRefreshed bool
+}
+
+// Ping checks that the drive API is reachable and the access token is alive
+// with a single read-only about call. Failures carry ErrCodeNetworkError when
+// the API is unreachable, ErrCodeUnauthorized when the token is rejected and
+// cannot be refreshed, and ErrCodeServerError for 5xx responses. The result is
+// returned alongside the error whenever a response was received. Like other
+// requests, Ping fails after Logout and refreshes a token about to expire
+// first, which counts as a refresh in the result.
+func (c *Client) Ping(ctx context.Context) (*PingResult, error) {
+	result := &PingResult{}
+	if err := c.requireSession("/drive/v1/about"); err != nil {
+		return nil, err
+	}
+	expiresAt := c.authModule.GetTokenExpiresAt()
+	c.refreshIfExpiring(ctx)
+	result.TokenRefreshed = !c.authModule.GetTokenExpiresAt().Equal(expiresAt)
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.driveURL("/drive/v1/about"), nil)
+		if err != nil {
+			return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeCreateRequestFailed, err)
+		}
+		for key, value := range c.getHeaders() {
+			req.Header.Set(key, value)
+		}
+
+		start := time.Now()
+		resp, respBody, err := c.send(req)
+		result.Latency = time.Since(start)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeTimeout, err)
+			}
+			if exception.IsPikpakException(err) {
+				return nil, err
+			}
+			return nil, transportError(err)
+		}
+		result.StatusCode = resp.StatusCode
+
+		if isSuccessStatus(resp.StatusCode) {
+			return result, nil
+		}
+
+		respData := parseErrorBody(resp, respBody)
+
+		if isAuthFailure(resp.StatusCode, respData) {
+			if result.TokenRefreshed || c.authModule.GetRefreshToken() == "" {
+				return result, exception.NewPikpakExceptionWithError(exception.ErrCodeUnauthorized, responseError(resp.StatusCode, respData, respBody))
+			}
+			if err := c.RefreshAccessToken(ctx); err != nil {
+				return result, exception.NewPikpakExceptionWithError(exception.ErrCodeUnauthorized, err)
+			}
+			result.TokenRefreshed = true
+			continue
+		}
+
+		if resp.StatusCode >= 500 {
+			return result, exception.NewPikpakExceptionFull(exception.ErrCodeServerError, fmt.Sprintf("ping failed with status: %d", resp.StatusCode), responseError(resp.StatusCode, respData, respBody))
+		}
+		return result, responseError(resp.StatusCode, respData, respBody)
+	}
+}
+
+func isAuthFailure(statusCode int, respData map[string]interface{}) bool {
+	if statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden {
+		return true
+	}
+	errCode, ok := respData["error_code"].(float64)
+	return ok && int(errCode) == 16
+}
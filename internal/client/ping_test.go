@@ -0,0 +1,178 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+func TestPing_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/drive/v1/about" || r.Method != http.MethodGet {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"kind": "drive#about"})
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+
+	result, err := cli.Ping(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", result.StatusCode)
+	}
+	if result.Latency <= 0 {
+		t.Error("Expected positive latency")
+	}
+	if result.TokenRefreshed {
+		t.Error("Expected no token refresh")
+	}
+}
+
+func TestPing_RefreshesExpiredToken(t *testing.T) {
+	var aboutCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/auth/token" {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token":  "new_token",
+				"refresh_token": "new_refresh",
+				"sub":           "user_id",
+			})
+			return
+		}
+		if atomic.AddInt32(&aboutCalls, 1) == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error_code": 16, "error": "unauthenticated"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"kind": "drive#about"})
+	}))
+	defer server.Close()
+
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithAccessToken("old_token"),
+		WithRefreshToken("refresh_token"),
+	)
+
+	result, err := cli.Ping(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !result.TokenRefreshed {
+		t.Error("Expected token refresh to be reported")
+	}
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", result.StatusCode)
+	}
+}
+
+func TestPing_ErrorCodes(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       map[string]interface{}
+		wantCode   exception.ErrorCode
+		wantStatus int
+	}{
+		{"auth_failure", http.StatusUnauthorized, map[string]interface{}{"error_code": 16, "error": "unauthenticated"}, exception.ErrCodeUnauthorized, http.StatusUnauthorized},
+		{"server_error", http.StatusServiceUnavailable, map[string]interface{}{"error": "unavailable"}, exception.ErrCodeServerError, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				json.NewEncoder(w).Encode(tt.body)
+			}))
+			defer server.Close()
+
+			cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+
+			result, err := cli.Ping(context.Background())
+			if got := exception.GetErrorCode(err); got != tt.wantCode {
+				t.Errorf("Expected error code %d, got %d (%v)", tt.wantCode, got, err)
+			}
+			if result == nil || result.StatusCode != tt.wantStatus {
+				t.Errorf("Expected result with status %d, got %+v", tt.wantStatus, result)
+			}
+			if calls != 1 {
+				t.Errorf("Expected a single request, got %d", calls)
+			}
+		})
+	}
+}
+
+func TestPing_NetworkUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+
+	result, err := cli.Ping(context.Background())
+	if exception.GetErrorCode(err) != exception.ErrCodeNetworkError {
+		t.Errorf("Expected ErrCodeNetworkError, got %v", err)
+	}
+	if result != nil {
+		t.Errorf("Expected no result without a response, got %+v", result)
+	}
+}
+
+func TestPing_AfterLogout(t *testing.T) {
+	server := newStubServer(t, map[string]http.HandlerFunc{
+		"/v1/auth/revoke": stubJSON(map[string]interface{}{}),
+		"":                stubJSON(map[string]interface{}{"kind": "drive#about"}),
+	})
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+	if err := cli.Logout(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cli.Ping(context.Background()); exception.GetErrorCode(err) != exception.ErrCodeUnauthorized {
+		t.Errorf("Expected ErrCodeUnauthorized after logout, got %v", err)
+	}
+	if reqs := server.requests("/drive/v1/about"); len(reqs) != 0 {
+		t.Errorf("Expected no request after logout, got %d", len(reqs))
+	}
+}
+
+func TestPing_RefreshesExpiringToken(t *testing.T) {
+	server := newStubServer(t, map[string]http.HandlerFunc{
+		"/v1/auth/token": stubJSON(map[string]interface{}{"access_token": "new_token", "refresh_token": "refresh", "expires_in": 3600}),
+		"":               stubJSON(map[string]interface{}{"kind": "drive#about"}),
+	})
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	cli := NewClient(WithBaseURL(server.URL), WithRefreshToken("refresh"), WithTokenRefreshMargin(time.Minute))
+	cli.now = func() time.Time { return now }
+	ctx := context.Background()
+	if err := cli.RefreshAccessToken(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	now = start.Add(59 * time.Minute)
+	result, err := cli.Ping(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !result.TokenRefreshed || len(server.requests("/v1/auth/token")) != 2 {
+		t.Errorf("Expected the expiring token to be refreshed first, got %+v", result)
+	}
+	if len(server.requests("/drive/v1/about")) != 1 {
+		t.Errorf("Expected a single ping request, got %d", len(server.requests("/drive/v1/about")))
+	}
+}
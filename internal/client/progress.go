@@ -0,0 +1,103 @@
+package client
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Progress is a snapshot of a running transfer.
+type Progress struct {
+	// Name is the local path of a download or the file name of an upload.
+	Name        string
+	Transferred int64
+	// Total is the transfer size in bytes, or -1 when the server did not
+	// say.
+	Total int64
+	Done  bool
+}
+
+type ProgressFunc func(Progress)
+
+// progressInterval is the minimum time between two reports of a transfer.
+const progressInterval = 100 * time.Millisecond
+
+// WithProgress calls fn as DownloadToFile, Upload, UploadFile and
+// UploadReader move data: at most every 100ms per transfer, and once more
+// with Done set when a transfer completes. Concurrent transfers report
+// from their own goroutines.
+func WithProgress(fn ProgressFunc) Option {
+	return func(c *Client) {
+		c.progress = fn
+	}
+}
+
+// progressTracker throttles the reports of one transfer.
+type progressTracker struct {
+	mu   sync.Mutex
+	fn   ProgressFunc
+	p    Progress
+	last time.Time
+}
+
+// trackProgress returns a tracker for a transfer of total bytes that starts
+// at offset, or nil when no callback is set.
+func (c *Client) trackProgress(name string, offset, total int64) *progressTracker {
+	if c.progress == nil {
+		return nil
+	}
+	t := &progressTracker{fn: c.progress, p: Progress{Name: name, Transferred: offset, Total: total}}
+	t.fn(t.p)
+	t.last = time.Now()
+	return t
+}
+
+func (t *progressTracker) add(n int64) {
+	if t == nil || n == 0 {
+		return
+	}
+	t.mu.Lock()
+	t.p.Transferred += n
+	p := t.p
+	report := time.Since(t.last) >= progressInterval
+	if report {
+		t.last = time.Now()
+	}
+	t.mu.Unlock()
+	if report {
+		t.fn(p)
+	}
+}
+
+func (t *progressTracker) done() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.p.Done = true
+	if t.p.Total < 0 {
+		t.p.Total = t.p.Transferred
+	}
+	p := t.p
+	t.mu.Unlock()
+	t.fn(p)
+}
+
+// reader counts the bytes read through r.
+func (t *progressTracker) reader(r io.Reader) io.Reader {
+	if t == nil {
+		return r
+	}
+	return &progressReader{r: r, t: t}
+}
+
+type progressReader struct {
+	r io.Reader
+	t *progressTracker
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.t.add(int64(n))
+	return n, err
+}
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+const progressContent = "0123456789abcdefghij"
+
+func newDownloadServer(t *testing.T, ranges *[]string) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/content" {
+			mu.Lock()
+			*ranges = append(*ranges, r.Header.Get("Range"))
+			mu.Unlock()
+			var offset int
+			if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &offset); err == nil {
+				w.Header().Set("Content-Length", fmt.Sprint(len(progressContent)-offset))
+				w.WriteHeader(http.StatusPartialContent)
+				w.Write([]byte(progressContent[offset:]))
+				return
+			}
+			w.Header().Set("Content-Length", fmt.Sprint(len(progressContent)))
+			w.Write([]byte(progressContent))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":               "f1",
+			"web_content_link": server.URL + "/content",
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDownloadToFile_ReportsProgress(t *testing.T) {
+	var ranges []string
+	server := newDownloadServer(t, &ranges)
+
+	var reports []Progress
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithAccessToken("test_token"),
+		WithProgress(func(p Progress) { reports = append(reports, p) }),
+	)
+
+	dest := filepath.Join(t.TempDir(), "out.txt")
+	if err := cli.DownloadToFile(context.Background(), "f1", dest); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(reports) < 2 {
+		t.Fatalf("Expected a start and a done report, got %v", reports)
+	}
+	last := reports[len(reports)-1]
+	if !last.Done || last.Transferred != int64(len(progressContent)) || last.Total != int64(len(progressContent)) {
+		t.Errorf("Unexpected final report: %+v", last)
+	}
+	if last.Name != dest {
+		t.Errorf("Expected name %q, got %q", dest, last.Name)
+	}
+	if _, err := os.Stat(dest + ".part"); !os.IsNotExist(err) {
+		t.Error("Expected the .part file to be renamed")
+	}
+}
+
+func TestDownloadToFile_ResumesPartFile(t *testing.T) {
+	var ranges []string
+	server := newDownloadServer(t, &ranges)
+
+	var first Progress
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithAccessToken("test_token"),
+		WithProgress(func(p Progress) {
+			if first.Name == "" {
+				first = p
+			}
+		}),
+	)
+
+	dest := filepath.Join(t.TempDir(), "out.txt")
+	if err := os.WriteFile(dest+".part", []byte(progressContent[:8]), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.DownloadToFile(context.Background(), "f1", dest); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(ranges) != 1 || ranges[0] != "bytes=8-" {
+		t.Errorf("Expected a range request from byte 8, got %v", ranges)
+	}
+	if first.Transferred != 8 {
+		t.Errorf("Expected progress to start at the resumed offset, got %d", first.Transferred)
+	}
+	data, _ := os.ReadFile(dest)
+	if string(data) != progressContent {
+		t.Errorf("Expected %q, got %q", progressContent, data)
+	}
+}
+
+func TestDownloadToFile_KeepsPartFileOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/content" {
+			w.Header().Set("Content-Length", fmt.Sprint(len(progressContent)))
+			w.Write([]byte(progressContent[:4]))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":               "f1",
+			"web_content_link": server.URL + "/content",
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithAccessToken("test_token"),
+	)
+
+	dest := filepath.Join(t.TempDir(), "out.txt")
+	go func() {
+		// Interrupt once the first bytes have reached the .part file.
+		for {
+			if info, err := os.Stat(dest + ".part"); err == nil && info.Size() == 4 {
+				cancel()
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	err := cli.DownloadToFile(ctx, "f1", dest)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected a cancellation error, got %v", err)
+	}
+	data, err := os.ReadFile(dest + ".part")
+	if err != nil {
+		t.Fatalf("Expected the .part file to be kept, got %v", err)
+	}
+	if string(data) != progressContent[:4] {
+		t.Errorf("Expected the received bytes in the .part file, got %q", data)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Error("Expected no complete file after cancellation")
+	}
+}
@@ -0,0 +1,24 @@
+package client
+
+import (
+	"context"
+
+	"github.com/zhz8888/pikpakapi-go/internal/auth"
+)
+
+// Identity providers accepted by LoginWithProviderToken.
+const (
+	ProviderGoogle = auth.ProviderGoogle
+	ProviderApple  = auth.ProviderApple
+)
+
+// LoginWithProviderToken signs in with an ID token the user obtained from
+// provider, ProviderGoogle or ProviderApple, outside the library. It sets
+// the same tokens as Login and needs no username or password.
+func (c *Client) LoginWithProviderToken(ctx context.Context, provider string, idToken string) error {
+	if err := c.authModule.LoginWithProviderToken(ctx, provider, idToken); err != nil {
+		return err
+	}
+	c.loggedIn()
+	return nil
+}
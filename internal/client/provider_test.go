@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+// newProviderServer answers sign-in with signinResult.
+func newProviderServer(t *testing.T, signinResult map[string]interface{}) *stubServer {
+	return newStubServer(t, map[string]http.HandlerFunc{
+		"/v1/shield/captcha/init": stubJSON(map[string]interface{}{"captcha_token": "captcha"}),
+		"/v1/auth/signin":         stubJSON(signinResult),
+	})
+}
+
+// signinForm returns the form of the last sign-in, or nil.
+func signinForm(server *stubServer) url.Values {
+	reqs := server.requests("/v1/auth/signin")
+	if len(reqs) == 0 {
+		return nil
+	}
+	return reqs[len(reqs)-1].Form
+}
+
+func TestLoginWithProviderToken(t *testing.T) {
+	server := newProviderServer(t, map[string]interface{}{"access_token": "access", "refresh_token": "refresh", "sub": "user1"})
+	cli := NewClient(WithBaseURL(server.URL))
+
+	if err := cli.LoginWithProviderToken(context.Background(), ProviderGoogle, "google-id-token"); err != nil {
+		t.Fatal(err)
+	}
+	form := signinForm(server)
+	if form.Get("grant_type") != "provider" || form.Get("provider") != "google" || form.Get("id_token") != "google-id-token" || form.Get("captcha_token") != "captcha" {
+		t.Errorf("Expected a provider grant, got %v", form)
+	}
+	if form.Has("password") || form.Has("username") {
+		t.Errorf("Expected no credentials, got %v", form)
+	}
+	if cli.GetAccessToken() != "access" || cli.GetRefreshToken() != "refresh" || cli.GetUserID() != "user1" || cli.GetEncodedToken() == "" {
+		t.Errorf("Expected the fields Login sets, got %q %q %q %q", cli.GetAccessToken(), cli.GetRefreshToken(), cli.GetUserID(), cli.GetEncodedToken())
+	}
+}
+
+func TestLoginWithProviderToken_NoAccessToken(t *testing.T) {
+	server := newProviderServer(t, map[string]interface{}{"refresh_token": "refresh"})
+	cli := NewClient(WithBaseURL(server.URL))
+
+	err := cli.LoginWithProviderToken(context.Background(), ProviderApple, "apple-id-token")
+	if !exception.Is(err, exception.ErrCodeUnknownError) {
+		t.Errorf("Expected a login failure, got %v", err)
+	}
+	if cli.GetAccessToken() != "" || cli.GetRefreshToken() != "" || cli.GetEncodedToken() != "" {
+		t.Errorf("Expected no tokens, got %q %q %q", cli.GetAccessToken(), cli.GetRefreshToken(), cli.GetEncodedToken())
+	}
+}
+
+func TestLoginWithProviderToken_InvalidArguments(t *testing.T) {
+	server := newProviderServer(t, nil)
+	cli := NewClient(WithBaseURL(server.URL))
+
+	for _, tt := range []struct{ provider, token string }{{"myspace", "token"}, {ProviderGoogle, ""}} {
+		if err := cli.LoginWithProviderToken(context.Background(), tt.provider, tt.token); !errors.Is(err, exception.ErrInvalidParameter) {
+			t.Errorf("%q %q: expected ErrInvalidParameter, got %v", tt.provider, tt.token, err)
+		}
+	}
+	if form := signinForm(server); form != nil {
+		t.Errorf("Expected no sign-in, got %v", form)
+	}
+}
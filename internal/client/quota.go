@@ -0,0 +1,73 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+var quotaErrorMarkers = []string{
+	"file_space_not_enough",
+	"space_not_enough",
+	"space not enough",
+	"not enough space",
+	"storage_full",
+	"storage full",
+	"quota_exceeded",
+	"quota exceeded",
+}
+
+var (
+	neededBytesKeys    = []string{"needed_bytes", "need_space", "need_size", "required_size"}
+	availableBytesKeys = []string{"available_bytes", "available_space", "free_space", "remain_size"}
+)
+
+// isQuotaError reports whether a server error string or task message means
+// the drive is out of space.
+func isQuotaError(s string) bool {
+	s = strings.ToLower(s)
+	for _, marker := range quotaErrorMarkers {
+		if strings.Contains(s, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// quotaMessage appends the needed and available byte counts reported in
+// data, at the top level or in its details, to message.
+func quotaMessage(message string, data map[string]interface{}) string {
+	if message == "" {
+		message = exception.ErrCodeQuotaExceeded.String()
+	}
+	needed, hasNeeded := findBytes(data, neededBytesKeys)
+	available, hasAvailable := findBytes(data, availableBytesKeys)
+	switch {
+	case hasNeeded && hasAvailable:
+		return fmt.Sprintf("%s (needed %d bytes, available %d bytes)", message, needed, available)
+	case hasNeeded:
+		return fmt.Sprintf("%s (needed %d bytes)", message, needed)
+	case hasAvailable:
+		return fmt.Sprintf("%s (available %d bytes)", message, available)
+	default:
+		return message
+	}
+}
+
+func findBytes(data map[string]interface{}, keys []string) (int64, bool) {
+	for _, m := range detailMaps(data) {
+		for _, key := range keys {
+			switch v := m[key].(type) {
+			case float64:
+				return int64(v), true
+			case string:
+				if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+					return n, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+func TestQuotaExceeded_Fixtures(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		call     func(*Client) error
+		wantText string
+	}{
+		{
+			name:   "offline_download_with_details",
+			status: http.StatusForbidden,
+			body:   `{"error":"file_space_not_enough","error_code":8,"error_description":"Storage space is not enough","details":[{"@type":"type.googleapis.com/google.rpc.ErrorInfo","metadata":{"need_space":"5368709120","available_space":"1073741824"}}]}`,
+			call: func(c *Client) error {
+				_, err := c.OfflineDownload(context.Background(), "magnet:?xt=urn:btih:x", "", "x")
+				return err
+			},
+			wantText: "Storage space is not enough (needed 5368709120 bytes, available 1073741824 bytes)",
+		},
+		{
+			name:     "restore_without_sizes",
+			status:   http.StatusBadRequest,
+			body:     `{"error":"file_space_not_enough","error_code":8}`,
+			call:     func(c *Client) error { _, err := c.Restore(context.Background(), "s1", "", []string{"f1"}); return err },
+			wantText: "file_space_not_enough",
+		},
+		{
+			name:   "task_failed_for_space",
+			status: http.StatusOK,
+			body:   `{"task":{"id":"t1","phase":"PHASE_TYPE_ERROR","message":"Space not enough","needed_bytes":2048,"available_bytes":1024}}`,
+			call: func(c *Client) error {
+				_, err := c.RemoteDownload(context.Background(), "https://example.com/x")
+				return err
+			},
+			wantText: "Space not enough (needed 2048 bytes, available 1024 bytes)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithMaxRetries(0))
+
+			err := tt.call(cli)
+			if !errors.Is(err, exception.ErrQuotaExceeded) {
+				t.Fatalf("Expected ErrQuotaExceeded, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantText) {
+				t.Errorf("Expected message to contain %q, got %q", tt.wantText, err.Error())
+			}
+			if exception.IsRetryable(err) {
+				t.Error("Expected quota errors not to be retryable")
+			}
+		})
+	}
+}
+
+func TestQuotaExceeded_OtherTaskErrorsUntouched(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"task":{"id":"t1","phase":"PHASE_TYPE_ERROR","message":"Resource not found"}}`))
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+
+	if _, err := cli.OfflineDownload(context.Background(), "magnet:?xt=urn:btih:x", "", "x"); err != nil {
+		t.Errorf("Expected no error for non-quota task failure, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// WithRateLimit caps API requests at rps per second on average, allowing
+// bursts of up to burst requests. Every attempt counts, retries included;
+// download streams do not. Zero or negative rps means unlimited.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) {
+		if rps > 0 {
+			if burst < 1 {
+				burst = 1
+			}
+			c.rateLimiter = &rateLimiter{rate: rps, burst: float64(burst), tokens: float64(burst)}
+		} else {
+			c.rateLimiter = nil
+		}
+	}
+}
+
+// rateLimiter is a token bucket holding up to burst tokens, refilled at rate
+// per second.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes a token and returns how long to wait before using it. The
+// bucket may go negative, so that waiting callers queue in order.
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.last.IsZero() {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel returns a token reserved but not used.
+func (l *rateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens = math.Min(l.burst, l.tokens+1)
+}
+
+// waitRateLimit blocks until the rate limit lets another request through or
+// ctx is done.
+func (c *Client) waitRateLimit(ctx context.Context) error {
+	l := c.rateLimiter
+	if l == nil {
+		return nil
+	}
+	delay := l.reserve(time.Now())
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	}
+}
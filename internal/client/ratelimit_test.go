@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+func newFastServer(t *testing.T, requests *int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"files": []interface{}{}})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWithRateLimit(t *testing.T) {
+	var requests int32
+	server := newFastServer(t, &requests)
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithRateLimit(2, 1))
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		if _, err := cli.FileList(context.Background(), 10, "", "", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The first request passes at once, the other nine every half second.
+	if elapsed := time.Since(start); elapsed < 4400*time.Millisecond || elapsed > 6*time.Second {
+		t.Errorf("Expected 10 requests at 2 rps to take about 4.5s, took %s", elapsed)
+	}
+	if requests != 10 {
+		t.Errorf("Expected 10 requests, got %d", requests)
+	}
+}
+
+func TestWithRateLimit_Burst(t *testing.T) {
+	var requests int32
+	server := newFastServer(t, &requests)
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithRateLimit(1, 5))
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := cli.FileList(context.Background(), 10, "", "", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected a burst of 5 to pass at once, took %s", elapsed)
+	}
+}
+
+func TestWithRateLimit_ContextDeadline(t *testing.T) {
+	var requests int32
+	server := newFastServer(t, &requests)
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithRateLimit(0.1, 1))
+
+	if _, err := cli.FileList(context.Background(), 10, "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := cli.FileList(ctx, 10, "", "", "")
+	if !exception.Is(err, exception.ErrCodeTimeout) {
+		t.Errorf("Expected a timeout while waiting for the limiter, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the wait to end with ctx, took %s", elapsed)
+	}
+	if requests != 1 {
+		t.Errorf("Expected the second request not to be sent, got %d requests", requests)
+	}
+}
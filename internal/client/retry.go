@@ -0,0 +1,293 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+// RetryPolicy decides whether a failed attempt should be retried. statusCode
+// is zero when the attempt failed at the transport level with err; otherwise
+// err is the error built from the response.
+type RetryPolicy interface {
+	ShouldRetry(method string, statusCode int, err error) bool
+}
+
+// DefaultRetryPolicy retries network errors, 408, 429 and 5xx responses.
+// Non-idempotent requests are only retried when the failure guarantees the
+// server never processed them, unless RetryNonIdempotent is set: connection
+// errors before the request was sent, and 429, with which the server refuses
+// a request without acting on it. A 503 may come from a gateway after the
+// origin already handled the request, so it is not retried for them.
+type DefaultRetryPolicy struct {
+	RetryNonIdempotent bool
+}
+
+func (p DefaultRetryPolicy) ShouldRetry(method string, statusCode int, err error) bool {
+	safe := isIdempotentMethod(method) || p.RetryNonIdempotent
+	if statusCode == 0 {
+		return safe || isUnsentError(err)
+	}
+	retryable := exception.IsRetryableStatus(statusCode)
+	if err != nil {
+		retryable = exception.IsRetryable(err)
+	}
+	if !retryable {
+		return false
+	}
+	return safe || statusCode == http.StatusTooManyRequests
+}
+
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+func (c *Client) getRetryPolicy() RetryPolicy {
+	if c.retryPolicy != nil {
+		return c.retryPolicy
+	}
+	return DefaultRetryPolicy{RetryNonIdempotent: c.retryNonIdempotent}
+}
+
+// WithBackoffJitter turns full jitter on the retry backoff on or off. It is
+// on by default: the wait before retry n is drawn uniformly from zero to
+// the initial backoff times 2^(n-1), so that clients failing together do
+// not retry in lockstep. Off, the wait is exactly that upper bound. Either
+// way, a Retry-After header on the failed response is waited out at least.
+func WithBackoffJitter(enabled bool) Option {
+	return func(c *Client) {
+		c.backoffJitter = enabled
+	}
+}
+
+// backoff returns the wait before retry attempt, which is at least
+// retryAfter.
+func (c *Client) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	d := c.initialBackoff * time.Duration(1<<uint(attempt-1))
+	if c.backoffJitter && d > 0 {
+		d = time.Duration(rand.Int63n(int64(d) + 1))
+	}
+	if d < retryAfter {
+		d = retryAfter
+	}
+	return d
+}
+
+// parseRetryAfter reads a Retry-After header in either delay-seconds or
+// HTTP-date form. A date in the past yields zero.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if d := at.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// waitBackoff sleeps for d, or returns ctx.Err() as an ErrCodeTimeout
+// exception as soon as ctx is done.
+func waitBackoff(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return exception.NewPikpakExceptionWithError(exception.ErrCodeTimeout, ctx.Err())
+	}
+}
+
+// rewindBody gives req a fresh copy of its body, since an earlier attempt
+// consumed it.
+func rewindBody(req *http.Request) error {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	return nil
+}
+
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodPatch:
+		return true
+	default:
+		return false
+	}
+}
+
+// isUnsentError reports whether err guarantees the request never reached the
+// server, which makes it safe to retry even non-idempotent requests.
+func isUnsentError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	return false
+}
+
+// transportError classifies a failure that produced no response. Deadlines,
+// cancellation and network timeouts become ErrCodeTimeout; DNS, dial, TLS
+// and other connection failures become ErrCodeNetworkError. err stays
+// reachable through Unwrap.
+func transportError(err error) *exception.PikpakException {
+	if pe, ok := err.(*exception.PikpakException); ok {
+		return pe
+	}
+	code := exception.ErrCodeNetworkError
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) ||
+		(errors.As(err, &netErr) && netErr.Timeout()) {
+		code = exception.ErrCodeTimeout
+	}
+	return exception.NewPikpakExceptionWithError(code, err)
+}
+
+const serverErrFileNotFound = "file_not_found"
+
+func statusErrorCode(statusCode int) exception.ErrorCode {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return exception.ErrCodeInvalidParameter
+	case http.StatusUnauthorized:
+		return exception.ErrCodeInvalidAccessToken
+	case http.StatusForbidden:
+		return exception.ErrCodeInvalidCredentials
+	case http.StatusNotFound:
+		return exception.ErrCodeNotFound
+	case http.StatusRequestTimeout:
+		return exception.ErrCodeTimeout
+	case http.StatusConflict:
+		return exception.ErrCodeConflict
+	case http.StatusTooManyRequests:
+		return exception.ErrCodeTooManyRequests
+	case http.StatusInternalServerError:
+		return exception.ErrCodeInternalServerError
+	case http.StatusServiceUnavailable:
+		return exception.ErrCodeServiceUnavailable
+	default:
+		return exception.ErrCodeServerError
+	}
+}
+
+// withRequest records which call failed on err. Only the URL path is kept:
+// query strings may carry tokens. The exception is copied so shared sentinel
+// errors are never modified.
+func withRequest(err error, method, rawURL string) error {
+	pe, ok := err.(*exception.PikpakException)
+	if !ok || pe.Method != "" {
+		return err
+	}
+	annotated := *pe
+	annotated.Method = method
+	if u, parseErr := url.Parse(rawURL); parseErr == nil {
+		annotated.Path = u.Path
+	}
+	return &annotated
+}
+
+// responseError builds the error for a non-2xx response, preferring the
+// server's error description and keeping a snippet of the body.
+func responseError(statusCode int, respData map[string]interface{}, respBody []byte) *exception.PikpakException {
+	apiErr := &exception.PikpakException{
+		Code:       statusErrorCode(statusCode),
+		Err:        exception.NewHTTPError(statusCode, respBody),
+		HTTPStatus: statusCode,
+	}
+	if errCode, ok := respData["error_code"].(float64); ok {
+		apiErr.ServerCode = int(errCode)
+	}
+	apiErr.ServerError, _ = respData["error"].(string)
+	apiErr.Description, _ = respData["error_description"].(string)
+
+	if apiErr.ServerError == serverErrFileNotFound {
+		apiErr.Code = exception.ErrCodeFileNotFound
+	}
+
+	if apiErr.Description != "" {
+		apiErr.Message = apiErr.Description
+	} else if apiErr.ServerError != "" {
+		apiErr.Message = apiErr.ServerError
+	}
+
+	switch {
+	case isQuotaError(apiErr.ServerError):
+		apiErr.Code = exception.ErrCodeQuotaExceeded
+		apiErr.Message = quotaMessage(apiErr.Message, respData)
+	case isTaskDailyLimit(apiErr.ServerError):
+		apiErr.Code = exception.ErrCodeTaskDailyLimitExceeded
+		apiErr.ResetAt, _ = findResetTime(respData)
+	default:
+		if code, ok := verificationErrorCode(apiErr.ServerError); ok {
+			apiErr.Code = code
+		} else if code, ok := inviteErrorCode(apiErr.ServerError); ok {
+			apiErr.Code = code
+		}
+	}
+	return apiErr
+}
+
+// detailMaps returns data followed by each entry of its details array and
+// that entry's metadata, which is where the API puts structured extras.
+func detailMaps(data map[string]interface{}) []map[string]interface{} {
+	maps := []map[string]interface{}{data}
+	details, _ := data["details"].([]interface{})
+	for _, d := range details {
+		if detail, ok := d.(map[string]interface{}); ok {
+			maps = append(maps, detail)
+			if metadata, ok := detail["metadata"].(map[string]interface{}); ok {
+				maps = append(maps, metadata)
+			}
+		}
+	}
+	return maps
+}
+
+// parseErrorBody decodes an error response only when it is JSON, so HTML
+// error pages from proxies are reported by status rather than as parse errors.
+func parseErrorBody(resp *http.Response, respBody []byte) map[string]interface{} {
+	if !strings.Contains(resp.Header.Get("Content-Type"), "json") && !bytes.HasPrefix(bytes.TrimSpace(respBody), []byte("{")) {
+		return nil
+	}
+	var respData map[string]interface{}
+	if err := json.Unmarshal(respBody, &respData); err != nil {
+		return nil
+	}
+	return respData
+}
+
+func isSuccessStatus(statusCode int) bool {
+	return statusCode >= 200 && statusCode <= 299
+}
@@ -0,0 +1,987 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func newCountingClient(attempts *int32, err error, opts ...Option) *Client {
+	opts = append([]Option{
+		WithBaseURL("http://pikpak.test"),
+		WithAccessToken("test_token"),
+		WithMaxRetries(3),
+		WithInitialBackoff(time.Millisecond),
+	}, opts...)
+	cli := NewClient(opts...)
+	cli.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(attempts, 1)
+		return nil, err
+	})
+	return cli
+}
+
+func TestRetry_NonIdempotentNoRetryAfterWriteTimeout(t *testing.T) {
+	var attempts int32
+	writeTimeout := &net.OpError{Op: "write", Net: "tcp", Err: timeoutError{}}
+	cli := newCountingClient(&attempts, writeTimeout)
+
+	_, err := cli.OfflineDownload(context.Background(), "magnet:?xt=urn:btih:test", "", "test")
+	if err == nil {
+		t.Fatal("Expected error after write timeout")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected exactly 1 attempt for non-idempotent POST, got %d", attempts)
+	}
+	if exception.GetErrorCode(err) != exception.ErrCodeTimeout {
+		t.Errorf("Expected ErrCodeTimeout, got %v", exception.GetErrorCode(err))
+	}
+}
+
+func TestRetry_NonIdempotentOptIn(t *testing.T) {
+	var attempts int32
+	writeTimeout := &net.OpError{Op: "write", Net: "tcp", Err: timeoutError{}}
+	cli := newCountingClient(&attempts, writeTimeout, WithRetryNonIdempotent(true))
+
+	_, err := cli.OfflineDownload(context.Background(), "magnet:?xt=urn:btih:test", "", "test")
+	if err == nil {
+		t.Fatal("Expected error after exhausting retries")
+	}
+	if attempts != 4 {
+		t.Errorf("Expected 4 attempts with WithRetryNonIdempotent, got %d", attempts)
+	}
+	if exception.GetErrorCode(err) != exception.ErrCodeMaxRetriesExceeded {
+		t.Errorf("Expected ErrCodeMaxRetriesExceeded, got %v", exception.GetErrorCode(err))
+	}
+}
+
+func TestRetry_NonIdempotentRetriesUnsentErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"connection_refused", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}},
+		{"dns_failure", &net.DNSError{Err: "no such host", Name: "pikpak.test", IsNotFound: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			cli := newCountingClient(&attempts, tt.err)
+
+			_, err := cli.FileBatchShare(context.Background(), []string{"id1"}, false)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if attempts != 4 {
+				t.Errorf("Expected 4 attempts for unsent request, got %d", attempts)
+			}
+		})
+	}
+}
+
+func TestRetry_IdempotentRetriesAnyTransportError(t *testing.T) {
+	var attempts int32
+	writeTimeout := &net.OpError{Op: "read", Net: "tcp", Err: timeoutError{}}
+	cli := newCountingClient(&attempts, writeTimeout)
+
+	_, err := cli.GetAbout(context.Background())
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+	if attempts != 4 {
+		t.Errorf("Expected 4 attempts for GET, got %d", attempts)
+	}
+}
+
+func TestIsUnsentError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"dial", &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, true},
+		{"dns", &net.DNSError{Err: "no such host"}, true},
+		{"write", &net.OpError{Op: "write", Err: timeoutError{}}, false},
+		{"plain", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isUnsentError(tt.err); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func newStatusServer(t *testing.T, attempts *int32, statuses ...int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(attempts, 1))
+		status := statuses[len(statuses)-1]
+		if n <= len(statuses) {
+			status = statuses[n-1]
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": status})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRetry_StatusCodes(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		call         func(*Client) error
+		wantAttempts int32
+		wantErr      bool
+		wantCode     exception.ErrorCode
+	}{
+		{
+			name:         "get_404_not_retried",
+			statuses:     []int{http.StatusNotFound},
+			call:         func(c *Client) error { _, err := c.GetAbout(context.Background()); return err },
+			wantAttempts: 1,
+			wantErr:      true,
+			wantCode:     exception.ErrCodeNotFound,
+		},
+		{
+			name:         "get_400_not_retried",
+			statuses:     []int{http.StatusBadRequest},
+			call:         func(c *Client) error { _, err := c.GetAbout(context.Background()); return err },
+			wantAttempts: 1,
+			wantErr:      true,
+			wantCode:     exception.ErrCodeInvalidParameter,
+		},
+		{
+			name:         "get_503_retried_until_success",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			call:         func(c *Client) error { _, err := c.GetAbout(context.Background()); return err },
+			wantAttempts: 3,
+		},
+		{
+			name:         "get_503_exhausts_retries",
+			statuses:     []int{http.StatusServiceUnavailable},
+			call:         func(c *Client) error { _, err := c.GetAbout(context.Background()); return err },
+			wantAttempts: 4,
+			wantErr:      true,
+			wantCode:     exception.ErrCodeMaxRetriesExceeded,
+		},
+		{
+			name:         "get_429_retried",
+			statuses:     []int{http.StatusTooManyRequests, http.StatusOK},
+			call:         func(c *Client) error { _, err := c.GetAbout(context.Background()); return err },
+			wantAttempts: 2,
+		},
+		{
+			name:         "get_408_retried",
+			statuses:     []int{http.StatusRequestTimeout, http.StatusOK},
+			call:         func(c *Client) error { _, err := c.GetAbout(context.Background()); return err },
+			wantAttempts: 2,
+		},
+		{
+			name:     "post_500_not_retried",
+			statuses: []int{http.StatusInternalServerError},
+			call: func(c *Client) error {
+				_, err := c.FileBatchShare(context.Background(), []string{"id"}, false)
+				return err
+			},
+			wantAttempts: 1,
+			wantErr:      true,
+			wantCode:     exception.ErrCodeInternalServerError,
+		},
+		{
+			name:     "post_429_retried",
+			statuses: []int{http.StatusTooManyRequests, http.StatusOK},
+			call: func(c *Client) error {
+				_, err := c.FileBatchShare(context.Background(), []string{"id"}, false)
+				return err
+			},
+			wantAttempts: 2,
+		},
+		{
+			name:     "post_503_not_retried",
+			statuses: []int{http.StatusServiceUnavailable},
+			call: func(c *Client) error {
+				_, err := c.FileBatchShare(context.Background(), []string{"id"}, false)
+				return err
+			},
+			wantAttempts: 1,
+			wantErr:      true,
+			wantCode:     exception.ErrCodeServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			server := newStatusServer(t, &attempts, tt.statuses...)
+			cli := NewClient(
+				WithBaseURL(server.URL),
+				WithAccessToken("test_token"),
+				WithMaxRetries(3),
+				WithInitialBackoff(time.Millisecond),
+			)
+
+			err := tt.call(cli)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+				if got := exception.GetErrorCode(err); got != tt.wantCode {
+					t.Errorf("Expected error code %d, got %d (%v)", tt.wantCode, got, err)
+				}
+			} else if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.wantAttempts, attempts)
+			}
+		})
+	}
+}
+
+type neverRetry struct{}
+
+func (neverRetry) ShouldRetry(string, int, error) bool { return false }
+
+func TestRetry_CustomPolicy(t *testing.T) {
+	var attempts int32
+	server := newStatusServer(t, &attempts, http.StatusServiceUnavailable)
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithAccessToken("test_token"),
+		WithRetryPolicy(neverRetry{}),
+		WithInitialBackoff(time.Millisecond),
+	)
+
+	if _, err := cli.GetAbout(context.Background()); err == nil {
+		t.Fatal("Expected error")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected custom policy to prevent retries, got %d attempts", attempts)
+	}
+}
+
+func TestResponseError_PreservesServerFields(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantErr     error
+		serverCode  int
+		serverError string
+		description string
+		wantText    string
+	}{
+		{
+			name:        "file_not_found",
+			status:      http.StatusNotFound,
+			body:        `{"error":"file_not_found","error_code":3,"error_description":"File not found"}`,
+			wantErr:     exception.ErrFileNotFound,
+			serverCode:  3,
+			serverError: "file_not_found",
+			description: "File not found",
+			wantText:    "[1044] File not found (POST /drive/v1/files, HTTP 404, server 3 file_not_found)",
+		},
+		{
+			name:        "task_daily_create_limit",
+			status:      http.StatusBadRequest,
+			body:        `{"error":"task_daily_create_limit","error_code":10,"error_description":"Daily task limit reached"}`,
+			wantErr:     exception.ErrTaskDailyLimitExceeded,
+			serverCode:  10,
+			serverError: "task_daily_create_limit",
+			description: "Daily task limit reached",
+			wantText:    "[1046] Daily task limit reached (POST /drive/v1/files, HTTP 400, server 10 task_daily_create_limit)",
+		},
+		{
+			name:        "unauthenticated",
+			status:      http.StatusUnauthorized,
+			body:        `{"error":"unauthenticated","error_code":16}`,
+			wantErr:     exception.ErrInvalidAccessToken,
+			serverCode:  16,
+			serverError: "unauthenticated",
+			wantText:    "[1012] unauthenticated (POST /drive/v1/files, HTTP 401, server 16 unauthenticated)",
+		},
+		{
+			name:     "no_error_fields",
+			status:   http.StatusServiceUnavailable,
+			body:     `<html>maintenance</html>`,
+			wantErr:  exception.ErrServiceUnavailable,
+			wantText: "[1024] service unavailable (POST /drive/v1/files, HTTP 503): <html>maintenance</html>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			cli := NewClient(
+				WithBaseURL(server.URL),
+				WithAccessToken("test_token"),
+				WithMaxRetries(0),
+			)
+
+			_, err := cli.PostJSON(context.Background(), server.URL+"/drive/v1/files", map[string]string{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+			}
+
+			var pe *exception.PikpakException
+			if !errors.As(err, &pe) {
+				t.Fatalf("Expected PikpakException, got %T", err)
+			}
+			if pe.ServerCode != tt.serverCode || pe.ServerError != tt.serverError || pe.Description != tt.description {
+				t.Errorf("Expected server fields (%d, %q, %q), got (%d, %q, %q)",
+					tt.serverCode, tt.serverError, tt.description, pe.ServerCode, pe.ServerError, pe.Description)
+			}
+			if !strings.Contains(err.Error(), tt.wantText) {
+				t.Errorf("Expected error text to contain %q, got %q", tt.wantText, err.Error())
+			}
+		})
+	}
+}
+
+func TestDefaultRetryPolicy(t *testing.T) {
+	statusErr := func(status int) error {
+		return responseError(status, nil, nil)
+	}
+
+	tests := []struct {
+		name     string
+		policy   DefaultRetryPolicy
+		method   string
+		status   int
+		err      error
+		expected bool
+	}{
+		{"get_503", DefaultRetryPolicy{}, http.MethodGet, 503, statusErr(503), true},
+		{"get_404", DefaultRetryPolicy{}, http.MethodGet, 404, statusErr(404), false},
+		{"get_401", DefaultRetryPolicy{}, http.MethodGet, 401, statusErr(401), false},
+		{"get_status_without_err", DefaultRetryPolicy{}, http.MethodGet, 502, nil, true},
+		{"post_500", DefaultRetryPolicy{}, http.MethodPost, 500, statusErr(500), false},
+		{"post_429", DefaultRetryPolicy{}, http.MethodPost, 429, statusErr(429), true},
+		{"post_503", DefaultRetryPolicy{}, http.MethodPost, 503, statusErr(503), false},
+		{"post_500_opt_in", DefaultRetryPolicy{RetryNonIdempotent: true}, http.MethodPost, 500, statusErr(500), true},
+		{"post_transport", DefaultRetryPolicy{}, http.MethodPost, 0, &net.OpError{Op: "read", Err: timeoutError{}}, false},
+		{"post_unsent", DefaultRetryPolicy{}, http.MethodPost, 0, &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.ShouldRetry(tt.method, tt.status, tt.err); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestHTTPStatus_OnErrors(t *testing.T) {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithMaxRetries(0))
+
+			_, err := cli.PostJSON(context.Background(), server.URL+"/drive/v1/files", map[string]string{})
+			var pe *exception.PikpakException
+			if !errors.As(err, &pe) || pe.HTTPStatus != status {
+				t.Fatalf("Expected HTTPStatus %d on the exception, got %v", status, err)
+			}
+			if got := exception.HTTPStatus(err); got != status {
+				t.Errorf("Expected exception.HTTPStatus %d, got %d", status, got)
+			}
+			if !strings.Contains(err.Error(), fmt.Sprintf("HTTP %d", status)) {
+				t.Errorf("Expected status in error text, got %q", err.Error())
+			}
+		})
+	}
+
+	t.Run("network_error", func(t *testing.T) {
+		var attempts int32
+		cli := newCountingClient(&attempts, &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, WithMaxRetries(0))
+
+		_, err := cli.GetAbout(context.Background())
+		if err == nil {
+			t.Fatal("Expected error")
+		}
+		if got := exception.HTTPStatus(err); got != 0 {
+			t.Errorf("Expected no HTTP status for a network error, got %d", got)
+		}
+	})
+}
+
+func TestErrors_RecordRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithMaxRetries(0))
+	ctx := context.Background()
+
+	_, err := cli.GetJSON(ctx, server.URL+"/drive/v1/files?thumbnail_size=SIZE_LARGE", map[string]string{"access_token": "secret"})
+	var pe *exception.PikpakException
+	if !errors.As(err, &pe) {
+		t.Fatalf("Expected PikpakException, got %v", err)
+	}
+	if pe.Method != http.MethodGet || pe.Path != "/drive/v1/files" {
+		t.Errorf("Expected GET /drive/v1/files, got %s %s", pe.Method, pe.Path)
+	}
+	if strings.Contains(err.Error(), "secret") || strings.Contains(err.Error(), "thumbnail_size") {
+		t.Errorf("Expected query string to be stripped, got %q", err.Error())
+	}
+	if !strings.Contains(err.Error(), "GET /drive/v1/files") {
+		t.Errorf("Expected request in error text, got %q", err.Error())
+	}
+
+	_, err = cli.Delete(ctx, server.URL+"/drive/v1/tasks", map[string]string{"task_ids": "t1"})
+	if !errors.As(err, &pe) || pe.Method != http.MethodDelete || pe.Path != "/drive/v1/tasks" {
+		t.Errorf("Expected DELETE /drive/v1/tasks, got %v", err)
+	}
+}
+
+func TestWithRequest_DoesNotModifySentinels(t *testing.T) {
+	err := withRequest(exception.ErrFileNotFound, http.MethodGet, "https://example.com/drive/v1/files/f1?usage=FETCH")
+	if exception.ErrFileNotFound.Method != "" {
+		t.Fatal("Expected sentinel to stay untouched")
+	}
+
+	var pe *exception.PikpakException
+	if !errors.As(err, &pe) || pe.Path != "/drive/v1/files/f1" {
+		t.Errorf("Expected path without query, got %v", err)
+	}
+	if !errors.Is(err, exception.ErrFileNotFound) {
+		t.Error("Expected annotated error to match its sentinel")
+	}
+}
+
+func TestTransportError_Classification(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want exception.ErrorCode
+	}{
+		{"deadline", &url.Error{Op: "Get", URL: "http://pikpak.test", Err: context.DeadlineExceeded}, exception.ErrCodeTimeout},
+		{"canceled", &url.Error{Op: "Get", URL: "http://pikpak.test", Err: context.Canceled}, exception.ErrCodeTimeout},
+		{"net_timeout", &net.OpError{Op: "read", Net: "tcp", Err: timeoutError{}}, exception.ErrCodeTimeout},
+		{"dns_timeout", &net.DNSError{Name: "pikpak.test", Err: "i/o timeout", IsTimeout: true}, exception.ErrCodeTimeout},
+		{"dns_not_found", &net.DNSError{Name: "pikpak.test", Err: "no such host", IsNotFound: true}, exception.ErrCodeNetworkError},
+		{"connection_refused", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, exception.ErrCodeNetworkError},
+		{"tls", &url.Error{Op: "Get", URL: "https://pikpak.test", Err: tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}}, exception.ErrCodeNetworkError},
+		{"certificate", &url.Error{Op: "Get", URL: "https://pikpak.test", Err: x509.UnknownAuthorityError{}}, exception.ErrCodeNetworkError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := transportError(tt.err)
+			if got := exception.GetErrorCode(err); got != tt.want {
+				t.Errorf("Expected code %d, got %d", tt.want, got)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Error("Expected the cause to stay reachable")
+			}
+		})
+	}
+
+	pe := exception.NewPikpakException(exception.ErrCodeResponseTooLarge)
+	if transportError(pe) != pe {
+		t.Error("Expected existing exceptions to pass through")
+	}
+}
+
+func TestTransportError_RetriedTimeoutKeepsClassification(t *testing.T) {
+	var attempts int32
+	cli := newCountingClient(&attempts, &net.OpError{Op: "read", Net: "tcp", Err: timeoutError{}}, WithMaxRetries(1))
+
+	_, err := cli.GetAbout(context.Background())
+	if exception.GetErrorCode(err) != exception.ErrCodeMaxRetriesExceeded {
+		t.Fatalf("Expected ErrCodeMaxRetriesExceeded, got %v", err)
+	}
+	if !exception.Is(err, exception.ErrCodeTimeout) {
+		t.Errorf("Expected the last attempt to be classified as a timeout, got %v", err)
+	}
+}
+
+func TestRetry_CancelDuringBackoff(t *testing.T) {
+	var attempts int32
+	cli := newCountingClient(&attempts, timeoutError{}, WithInitialBackoff(time.Hour), WithBackoffJitter(false))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for atomic.LoadInt32(&attempts) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+	}()
+
+	start := time.Now()
+	_, err := cli.GetJSON(ctx, "http://pikpak.test/drive/v1/files", nil)
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Expected the backoff to end with the context, took %s", elapsed)
+	}
+	if exception.GetErrorCode(err) != exception.ErrCodeTimeout || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected ErrCodeTimeout wrapping context.Canceled, got %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("Expected no attempt after the cancellation, got %d", attempts)
+	}
+}
+
+func TestRetry_CancelDuringBackoffAfterTokenRefresh(t *testing.T) {
+	var refreshes int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/auth/token" {
+			atomic.AddInt32(&refreshes, 1)
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "new", "refresh_token": "refresh"})
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error_code": 16, "error": "unauthenticated"})
+	}))
+	defer server.Close()
+
+	// The retry with the new token waits out a backoff first; cancel once
+	// the refresh is done.
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("old"), WithRefreshToken("refresh"), WithInitialBackoff(time.Hour), WithBackoffJitter(false),
+		WithTokenRefreshCallback(func(*Client) { cancel() }))
+
+	start := time.Now()
+	_, err := cli.GetJSON(ctx, server.URL+"/drive/v1/files", nil)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the backoff to end with the context, took %s", elapsed)
+	}
+	if exception.GetErrorCode(err) != exception.ErrCodeTimeout || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected ErrCodeTimeout wrapping context.Canceled, got %v", err)
+	}
+	if refreshes != 1 {
+		t.Errorf("Expected one token refresh, got %d", refreshes)
+	}
+}
+
+func TestRetry_ResendsFullBody(t *testing.T) {
+	var bodies []string
+	respond := func(status int, body interface{}) *http.Response {
+		data, _ := json.Marshal(body)
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(string(data))),
+		}
+	}
+
+	cli := NewClient(WithBaseURL("http://pikpak.test"), WithAccessToken("old"), WithRefreshToken("refresh"), WithInitialBackoff(time.Millisecond))
+	cli.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/v1/auth/token" {
+			return respond(http.StatusOK, map[string]interface{}{"access_token": "new", "refresh_token": "refresh"}), nil
+		}
+		body, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(body))
+		switch len(bodies) {
+		case 1:
+			return respond(http.StatusTooManyRequests, map[string]interface{}{}), nil
+		case 2:
+			return respond(http.StatusUnauthorized, map[string]interface{}{"error_code": 16, "error": "unauthenticated"}), nil
+		default:
+			return respond(http.StatusOK, map[string]interface{}{"id": "f1"}), nil
+		}
+	})
+
+	payload := map[string]interface{}{"kind": "drive#folder", "name": "new folder", "parent_id": "p1"}
+	if _, err := cli.PostJSON(context.Background(), "http://pikpak.test/drive/v1/files", payload); err != nil {
+		t.Fatal(err)
+	}
+
+	want, _ := json.Marshal(payload)
+	if len(bodies) != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", len(bodies))
+	}
+	for i, body := range bodies {
+		if body != string(want) {
+			t.Errorf("Attempt %d sent %q, want %q", i+1, body, want)
+		}
+	}
+}
+
+func TestRetry_HonorsRetryAfter(t *testing.T) {
+	var times []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		w.Header().Set("Content-Type", "application/json")
+		if len(times) == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"files": []interface{}{}})
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithInitialBackoff(time.Millisecond))
+	if _, err := cli.GetJSON(context.Background(), server.URL+"/drive/v1/files", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(times) != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", len(times))
+	}
+	if wait := times[1].Sub(times[0]); wait < 1900*time.Millisecond || wait > 3*time.Second {
+		t.Errorf("Expected a wait of about 2s, got %s", wait)
+	}
+}
+
+func TestRetry_RetryAfterDateUsesClientClock(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var times []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		w.Header().Set("Content-Type", "application/json")
+		if len(times) == 1 {
+			w.Header().Set("Retry-After", now.Add(time.Second).Format(http.TimeFormat))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"files": []interface{}{}})
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithInitialBackoff(time.Millisecond))
+	cli.now = func() time.Time { return now }
+	if _, err := cli.GetJSON(context.Background(), server.URL+"/drive/v1/files", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(times) != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", len(times))
+	}
+	if wait := times[1].Sub(times[0]); wait < 900*time.Millisecond || wait > 2*time.Second {
+		t.Errorf("Expected the date to be read against the client clock, waited %s", wait)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"2", 2 * time.Second, true},
+		{" 120 ", 2 * time.Minute, true},
+		{"0", 0, true},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"", 0, false},
+		{"-1", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %s, %v, want %s, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	fixed := NewClient(WithInitialBackoff(time.Second), WithBackoffJitter(false))
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second} {
+		if got := fixed.backoff(attempt, 0); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+	if got := fixed.backoff(1, 5*time.Second); got != 5*time.Second {
+		t.Errorf("Expected Retry-After to lengthen the backoff, got %s", got)
+	}
+
+	jittered := NewClient(WithInitialBackoff(time.Second))
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		got := jittered.backoff(3, 0)
+		if got < 0 || got > 4*time.Second {
+			t.Fatalf("backoff(3) = %s, want within [0, 4s]", got)
+		}
+		seen[got] = true
+		if got := jittered.backoff(3, 5*time.Second); got != 5*time.Second {
+			t.Errorf("Expected Retry-After to bound the jittered backoff, got %s", got)
+		}
+	}
+	if len(seen) < 2 {
+		t.Error("Expected jitter to vary the backoff")
+	}
+}
+
+func TestCreateFolder_RetriesTransientError(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"file": map[string]interface{}{"id": "d1"}})
+	}))
+	defer server.Close()
+
+	// A 500 may come after the folder was created, so POSTs only retry it
+	// when asked to.
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithInitialBackoff(time.Millisecond), WithRetryNonIdempotent(true))
+	if _, err := cli.CreateFolder(context.Background(), "new", ""); err != nil {
+		t.Fatalf("Expected the retry to succeed, got %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestCreateFolder_RefreshesExpiredToken(t *testing.T) {
+	var refreshes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/auth/token" {
+			atomic.AddInt32(&refreshes, 1)
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "new", "refresh_token": "refresh"})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error_code": 16, "error": "unauthenticated"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"file": map[string]interface{}{"id": "d1"}})
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("expired"), WithRefreshToken("refresh"), WithInitialBackoff(time.Millisecond))
+	if _, err := cli.CreateFolder(context.Background(), "new", ""); err != nil {
+		t.Fatalf("Expected the refreshed request to succeed, got %v", err)
+	}
+	if refreshes != 1 {
+		t.Errorf("Expected one token refresh, got %d", refreshes)
+	}
+}
+
+func TestConcurrentRequests_RefreshOnce(t *testing.T) {
+	var refreshes, callbacks int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/auth/token" {
+			atomic.AddInt32(&refreshes, 1)
+			// Keep the refresh open while the other requests fail.
+			time.Sleep(20 * time.Millisecond)
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "new", "refresh_token": "rotated"})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error_code": 16, "error": "unauthenticated"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"files": []interface{}{}})
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("expired"), WithRefreshToken("refresh"), WithInitialBackoff(time.Millisecond),
+		WithTokenRefreshCallback(func(*Client) { atomic.AddInt32(&callbacks, 1) }))
+
+	const n = 50
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := cli.FileList(context.Background(), 10, "", "", "")
+			errs <- err
+		}()
+	}
+	for i := 0; i < n; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("Expected every request to succeed with the new token, got %v", err)
+		}
+	}
+	if refreshes != 1 {
+		t.Errorf("Expected exactly one token refresh, got %d", refreshes)
+	}
+	if callbacks != 1 {
+		t.Errorf("Expected the refresh callback once, got %d", callbacks)
+	}
+}
+
+func TestRefresh_UserIDReadConcurrently(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		time.Sleep(time.Millisecond)
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "new", "refresh_token": "rotated", "sub": "user1"})
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("old"), WithRefreshToken("refresh"))
+	// With a captcha token the User-Agent carries the user ID.
+	cli.authModule.SetCaptchaToken("captcha")
+
+	done := make(chan struct{})
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				cli.getHeaders()
+				cli.GetUserInfo()
+			}
+		}
+	}()
+	for i := 0; i < 5; i++ {
+		if err := cli.RefreshAccessToken(context.Background()); err != nil {
+			t.Error(err)
+		}
+	}
+	close(done)
+	<-readerDone
+
+	if cli.GetUserID() != "user1" {
+		t.Errorf("Expected the user ID from the refresh, got %q", cli.GetUserID())
+	}
+}
+
+func TestPostFormAndDelete_Retry(t *testing.T) {
+	var formAttempts, deleteAttempts int32
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		counter := &formAttempts
+		if r.Method == http.MethodDelete {
+			counter = &deleteAttempts
+		}
+		if atomic.AddInt32(counter, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if r.Method == http.MethodPost {
+			contentType = r.Header.Get("Content-Type")
+			r.ParseForm()
+			json.NewEncoder(w).Encode(map[string]interface{}{"echo": r.PostForm.Get("key")})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithInitialBackoff(time.Millisecond))
+	result, err := cli.PostForm(context.Background(), server.URL+"/v1/shield/form", map[string]string{"key": "value"})
+	if err != nil || result["echo"] != "value" {
+		t.Errorf("Expected the retried form to arrive whole, got %v, %v", result, err)
+	}
+	if contentType != "application/x-www-form-urlencoded" {
+		t.Errorf("Expected the form content type, got %q", contentType)
+	}
+	if _, err := cli.Delete(context.Background(), server.URL+"/drive/v1/tasks", map[string]string{"task_ids": "t1"}); err != nil {
+		t.Errorf("Expected the retried delete to succeed, got %v", err)
+	}
+	if formAttempts != 2 || deleteAttempts != 2 {
+		t.Errorf("Expected 2 attempts each, got %d and %d", formAttempts, deleteAttempts)
+	}
+}
+
+func TestRefreshAccessToken_DoesNotRecurse(t *testing.T) {
+	var refreshes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&refreshes, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error_code": 16, "error": "unauthenticated"})
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBaseURL(server.URL), WithRefreshToken("revoked"), WithInitialBackoff(time.Millisecond))
+	if err := cli.RefreshAccessToken(context.Background()); err == nil {
+		t.Fatal("Expected the refresh to fail")
+	}
+	if refreshes != 1 {
+		t.Errorf("Expected a single refresh request, got %d", refreshes)
+	}
+}
+
+func TestStatusErrors_Typed(t *testing.T) {
+	tests := []struct {
+		status       int
+		want         error
+		wantAttempts int32
+	}{
+		{http.StatusUnauthorized, exception.ErrUnauthorized, 1},
+		{http.StatusForbidden, exception.ErrForbidden, 1},
+		{http.StatusNotFound, exception.ErrNotFound, 1},
+		{http.StatusConflict, exception.ErrConflict, 1},
+		{http.StatusTooManyRequests, exception.ErrTooManyRequests, 3},
+		{http.StatusInternalServerError, exception.ErrInternalServerError, 3},
+		{http.StatusBadGateway, exception.ErrServerError, 3},
+		{http.StatusServiceUnavailable, exception.ErrServiceUnavailable, 3},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.status), func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&attempts, 1)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				json.NewEncoder(w).Encode(map[string]interface{}{"error": "some_error", "error_description": "described by the server"})
+			}))
+			defer server.Close()
+
+			cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithMaxRetries(2), WithInitialBackoff(time.Millisecond))
+			_, err := cli.GetAbout(context.Background())
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Expected errors.Is(err, %v), got %v", tt.want, err)
+			}
+			if exception.HTTPStatus(err) != tt.status {
+				t.Errorf("Expected HTTP status %d, got %d", tt.status, exception.HTTPStatus(err))
+			}
+			if !strings.Contains(err.Error(), "described by the server") {
+				t.Errorf("Expected the server's description in %v", err)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.wantAttempts, attempts)
+			}
+		})
+	}
+}
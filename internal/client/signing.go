@@ -0,0 +1,35 @@
+package client
+
+import (
+	"github.com/zhz8888/pikpakapi-go/internal/signer"
+)
+
+// SigningConfig is the app identity, salts and clock requests are signed
+// with. Start from DefaultSigningConfig and adjust it with its With methods.
+type SigningConfig = signer.Config
+
+// DefaultSigningConfig returns the signing config of the app release the
+// client was written against.
+func DefaultSigningConfig() SigningConfig {
+	return signer.DefaultConfig()
+}
+
+// WithSigningConfig signs requests with cfg instead of DefaultSigningConfig.
+// NewClientE rejects a config without a client ID, client version or
+// package name.
+func WithSigningConfig(cfg SigningConfig) Option {
+	return func(c *Client) {
+		c.signing.Store(cfg)
+	}
+}
+
+// SigningConfig returns the config requests are currently signed with.
+func (c *Client) SigningConfig() SigningConfig {
+	return c.signing.Load()
+}
+
+// SetSigningConfig swaps the signing config. It is safe to call while
+// requests are in flight; they pick up cfg from their next signature on.
+func (c *Client) SetSigningConfig(cfg SigningConfig) {
+	c.signing.Store(cfg)
+}
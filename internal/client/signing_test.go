@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSigningConfig_UsedAndSwapped(t *testing.T) {
+	const clock = int64(1700000000000)
+	var mu sync.Mutex
+	var captchaBody map[string]interface{}
+	var userAgents []string
+	captchaIssued := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/shield/captcha/init" {
+			json.NewDecoder(r.Body).Decode(&captchaBody)
+			captchaIssued = true
+			json.NewEncoder(w).Encode(map[string]interface{}{"captcha_token": "captcha"})
+			return
+		}
+		if !captchaIssued {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error_code": 9, "error": "captcha_invalid"})
+			return
+		}
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		json.NewEncoder(w).Encode(map[string]interface{}{"files": []interface{}{}})
+	}))
+	defer server.Close()
+
+	cfg := DefaultSigningConfig().
+		WithClock(func() int64 { return clock }).
+		WithClientID("custom-id").
+		WithClientVersion("1.50.0")
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithAccessToken("test_token"),
+		WithDeviceID("0123456789abcdef"),
+		WithMaxRetries(0),
+		WithSigningConfig(cfg),
+	)
+
+	if _, err := cli.FileList(context.Background(), 10, "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if captchaBody["client_id"] != "custom-id" {
+		t.Errorf("Expected the configured client_id, got %v", captchaBody["client_id"])
+	}
+	meta, _ := captchaBody["meta"].(map[string]interface{})
+	timestamp := strconv.FormatInt(clock, 10)
+	if meta["timestamp"] != timestamp || meta["client_version"] != "1.50.0" || meta["captcha_sign"] != cfg.CaptchaSign("0123456789abcdef", timestamp) {
+		t.Errorf("Expected meta signed with the config, got %v", meta)
+	}
+
+	cli.SetSigningConfig(cli.SigningConfig().WithClientVersion("1.51.0"))
+	if _, err := cli.FileList(context.Background(), 10, "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(userAgents) != 2 {
+		t.Fatalf("Expected 2 listings, got %d", len(userAgents))
+	}
+	if want := cfg.UserAgent("0123456789abcdef", ""); userAgents[0] != want {
+		t.Errorf("Expected User-Agent\n%s\ngot\n%s", want, userAgents[0])
+	}
+	if !strings.Contains(userAgents[1], "clientversion/1.51.0 ") || strings.Contains(userAgents[1], "1.50.0") {
+		t.Errorf("Expected the swapped version in %s", userAgents[1])
+	}
+}
+
+func TestWithSigningConfig_Invalid(t *testing.T) {
+	_, err := NewClientE(WithSigningConfig(DefaultSigningConfig().WithClientVersion("")))
+	if err == nil || !strings.Contains(err.Error(), "signing config") {
+		t.Errorf("Expected a signing config error, got %v", err)
+	}
+
+	cli := NewClient(WithSigningConfig(SigningConfig{}))
+	if cli.SigningConfig().ClientID != DefaultSigningConfig().ClientID {
+		t.Errorf("Expected the default signing config, got %+v", cli.SigningConfig())
+	}
+}
+
+func TestBuildUserAgent_FollowsIDs(t *testing.T) {
+	cli := NewClient(WithDeviceID("device1"), WithSigningConfig(DefaultSigningConfig().WithClock(func() int64 { return 1 })))
+	cli.authModule.SetCaptchaToken("captcha")
+
+	if got, want := cli.buildUserAgent(), cli.SigningConfig().UserAgent("device1", ""); got != want {
+		t.Errorf("Expected User-Agent\n%s\ngot\n%s", want, got)
+	}
+	cli.SetUserID("user1")
+	cli.SetDeviceID("device2")
+	if got, want := cli.buildUserAgent(), cli.SigningConfig().UserAgent("device2", "user1"); got != want {
+		t.Errorf("Expected User-Agent\n%s\ngot\n%s", want, got)
+	}
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// stubServer answers requests with the route for their path and records
+// them. The route for "" answers paths without their own route; without it
+// they get 404.
+type stubServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	received []stubRequest
+}
+
+// stubRequest is a request a stubServer received. Body holds the decoded
+// JSON body, or the first value of each field of a form body, which Form
+// holds in full.
+type stubRequest struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Body   map[string]interface{}
+	Form   url.Values
+}
+
+func newStubServer(t *testing.T, routes map[string]http.HandlerFunc) *stubServer {
+	t.Helper()
+	s := &stubServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(raw))
+		req := stubRequest{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query(), Body: map[string]interface{}{}}
+		if r.Header.Get("Content-Type") == "application/x-www-form-urlencoded" {
+			req.Form, _ = url.ParseQuery(string(raw))
+			for key := range req.Form {
+				req.Body[key] = req.Form.Get(key)
+			}
+		} else {
+			json.Unmarshal(raw, &req.Body)
+		}
+		s.mu.Lock()
+		s.received = append(s.received, req)
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		route, ok := routes[r.URL.Path]
+		if !ok {
+			route, ok = routes[""]
+		}
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		route(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// requests returns the requests received so far for path, or all of them
+// when path is empty.
+func (s *stubServer) requests(path string) []stubRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []stubRequest
+	for _, req := range s.received {
+		if path == "" || req.Path == path {
+			out = append(out, req)
+		}
+	}
+	return out
+}
+
+// lastBody returns the body of the last request for path, or nil.
+func (s *stubServer) lastBody(path string) map[string]interface{} {
+	reqs := s.requests(path)
+	if len(reqs) == 0 {
+		return nil
+	}
+	return reqs[len(reqs)-1].Body
+}
+
+// count returns how many requests with method were received.
+func (s *stubServer) count(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, req := range s.received {
+		if req.Method == method {
+			n++
+		}
+	}
+	return n
+}
+
+// stubJSON answers with v encoded as JSON.
+func stubJSON(v interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(v)
+	}
+}
+
+// stubError answers with status and a PikPak error body.
+func stubError(status int, serverError string, code int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": serverError, "error_code": code})
+	}
+}
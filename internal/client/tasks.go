@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+	"github.com/zhz8888/pikpakapi-go/internal/utils"
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
+)
+
+var resetTimeKeys = []string{"reset_time", "reset_at", "next_reset_time"}
+
+// isTaskDailyLimit reports whether a server error string means the account
+// has used up its offline task creations for the day.
+func isTaskDailyLimit(s string) bool {
+	return strings.HasPrefix(strings.ToLower(s), "task_daily_create_limit")
+}
+
+// findResetTime looks for the time a limit lifts, given either as RFC 3339
+// or as Unix seconds.
+func findResetTime(data map[string]interface{}) (time.Time, bool) {
+	for _, m := range detailMaps(data) {
+		for _, key := range resetTimeKeys {
+			switch v := m[key].(type) {
+			case float64:
+				return time.Unix(int64(v), 0), true
+			case string:
+				if t, err := time.Parse(time.RFC3339, v); err == nil {
+					return t, true
+				}
+				if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+					return time.Unix(n, 0), true
+				}
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// taskFailureError returns a typed error when a task creation response
+// reports a task that already failed for lack of space or because the daily
+// creation limit was hit.
+func taskFailureError(result map[string]interface{}) error {
+	task, ok := result["task"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if phase, _ := task["phase"].(string); enums.ParsePhaseType(phase) != enums.PhaseTypeError {
+		return nil
+	}
+	message, _ := task["message"].(string)
+	switch {
+	case isQuotaError(message):
+		return exception.NewPikpakExceptionWithMessage(exception.ErrCodeQuotaExceeded, quotaMessage(message, task))
+	case isTaskDailyLimit(message):
+		err := exception.NewPikpakExceptionWithMessage(exception.ErrCodeTaskDailyLimitExceeded, message)
+		err.ResetAt, _ = findResetTime(task)
+		return err
+	default:
+		return nil
+	}
+}
+
+// defaultBatchConcurrency is how many tasks OfflineDownloadBatch creates at
+// once when WithMaxConcurrentRequests is not set.
+const defaultBatchConcurrency = 4
+
+// OfflineBatchResult is the outcome of one URL passed to
+// OfflineDownloadBatch.
+type OfflineBatchResult struct {
+	URL string
+	// TaskID is the created task. For a duplicate it is the task created
+	// for the first occurrence, if any.
+	TaskID string
+	// Duplicate is set for a URL that repeats an earlier one in the batch,
+	// magnets being compared by info hash, and for one the server rejects
+	// as already added.
+	Duplicate bool
+	Result    map[string]interface{}
+	Err       error
+}
+
+// OfflineDownloadBatch creates an offline task for each URL in parentID, as
+// OfflineDownload does, running up to WithMaxConcurrentRequests of them at
+// once. Magnet links are validated before anything is sent. Results are in
+// the order of urls; a failed URL does not stop the others. When any URL
+// failed the error is an *exception.BatchError holding each failure by URL;
+// the results are returned either way.
+func (c *Client) OfflineDownloadBatch(ctx context.Context, urls []string, parentID string, opts ...DownloadOption) ([]OfflineBatchResult, error) {
+	results := make([]OfflineBatchResult, len(urls))
+	// original[i] is the index of the first occurrence of a duplicate.
+	original := make(map[int]int)
+	first := make(map[string]int)
+	var submit []int
+	for i, u := range urls {
+		results[i].URL = u
+		key := u
+		if utils.IsMagnet(u) {
+			m, err := utils.ParseMagnet(u)
+			if err != nil {
+				results[i].Err = exception.NewPikpakExceptionWithMessage(exception.ErrCodeInvalidURL, err.Error())
+				continue
+			}
+			key = "btih:" + m.InfoHash
+		}
+		if j, ok := first[key]; ok {
+			results[i].Duplicate = true
+			original[i] = j
+			continue
+		}
+		first[key] = i
+		submit = append(submit, i)
+	}
+
+	concurrency := defaultBatchConcurrency
+	if c.requestSem != nil {
+		concurrency = cap(c.requestSem)
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, i := range submit {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(r *OfflineBatchResult) {
+			defer func() { <-sem; wg.Done() }()
+			r.Result, r.Err = c.OfflineDownload(ctx, r.URL, parentID, "", opts...)
+			if errors.Is(r.Err, exception.ErrConflict) {
+				r.Duplicate, r.Err = true, nil
+			}
+			if task, ok := r.Result["task"].(map[string]interface{}); ok {
+				r.TaskID, _ = task["id"].(string)
+			}
+		}(&results[i])
+	}
+	wg.Wait()
+
+	for i, j := range original {
+		results[i].TaskID = results[j].TaskID
+	}
+
+	batchErr := exception.NewBatchError(len(urls))
+	for _, r := range results {
+		batchErr.Add(r.URL, r.Err)
+	}
+	return results, batchErr.Err()
+}
@@ -0,0 +1,189 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+func TestTaskDailyLimit_Fixtures(t *testing.T) {
+	resetAt := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantReset time.Time
+	}{
+		{
+			name:      "rfc3339_reset_in_details",
+			status:    http.StatusBadRequest,
+			body:      `{"error":"task_daily_create_limit","error_code":10,"error_description":"Daily limit reached","details":[{"metadata":{"reset_time":"2024-05-02T00:00:00Z"}}]}`,
+			wantReset: resetAt,
+		},
+		{
+			name:      "unix_reset_on_429",
+			status:    http.StatusTooManyRequests,
+			body:      `{"error":"task_daily_create_limit_vip","error_code":10,"reset_at":1714608000}`,
+			wantReset: resetAt,
+		},
+		{
+			name:   "no_reset_time",
+			status: http.StatusBadRequest,
+			body:   `{"error":"task_daily_create_limit","error_code":10}`,
+		},
+		{
+			name:      "task_phase_error",
+			status:    http.StatusOK,
+			body:      `{"task":{"id":"t1","phase":"PHASE_TYPE_ERROR","message":"task_daily_create_limit","reset_time":"2024-05-02T00:00:00Z"}}`,
+			wantReset: resetAt,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			cli := NewClient(
+				WithBaseURL(server.URL),
+				WithAccessToken("test_token"),
+				WithInitialBackoff(time.Millisecond),
+			)
+
+			_, err := cli.OfflineDownload(context.Background(), "magnet:?xt=urn:btih:x", "", "x")
+			if !errors.Is(err, exception.ErrTaskDailyLimitExceeded) {
+				t.Fatalf("Expected ErrTaskDailyLimitExceeded, got %v", err)
+			}
+
+			var pe *exception.PikpakException
+			errors.As(err, &pe)
+			if !pe.ResetAt.Equal(tt.wantReset) {
+				t.Errorf("Expected reset time %v, got %v", tt.wantReset, pe.ResetAt)
+			}
+			if calls != 1 {
+				t.Errorf("Expected the limit not to be retried, got %d calls", calls)
+			}
+		})
+	}
+}
+
+func TestOfflineDownloadBatch(t *testing.T) {
+	const hash = "c12fe1c06bba254a9dc9f519b335aa7c1367a88a"
+
+	var mu sync.Mutex
+	var submitted []string
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		var body struct {
+			URL struct {
+				URL string `json:"url"`
+			} `json:"url"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		submitted = append(submitted, body.URL.URL)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch body.URL.URL {
+		case "https://example.com/exists":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error":"file_duplicated"}`))
+		case "https://example.com/broken":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_argument"}`))
+		default:
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"task": map[string]interface{}{"id": "task-" + body.URL.URL[len(body.URL.URL)-1:]},
+			})
+		}
+	}))
+	defer server.Close()
+
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithAccessToken("test_token"),
+		WithMaxConcurrentRequests(2),
+	)
+
+	urls := []string{
+		"https://example.com/1",
+		"magnet:?xt=urn:btih:" + hash,
+		"https://example.com/2",
+		"magnet:?xt=urn:btih:YEX6DQDLXISUVHOJ6UM3GNNKPQJWPKEK&dn=same",
+		"magnet:?xt=urn:btih:nope",
+		"https://example.com/exists",
+		"https://example.com/broken",
+		"https://example.com/1",
+	}
+	results, err := cli.OfflineDownloadBatch(context.Background(), urls, "p1")
+	var batchErr *exception.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Expected a BatchError, got %v", err)
+	}
+	if batchErr.Total != len(urls) || batchErr.Failed() != 2 || batchErr.Items[0].ID != urls[4] || batchErr.Items[1].ID != urls[6] {
+		t.Errorf("Expected the bad magnet and the rejected URL to fail, got %+v", batchErr)
+	}
+	if !errors.Is(err, exception.ErrInvalidURL) {
+		t.Errorf("Expected errors.Is to match the failed items, got %v", err)
+	}
+
+	if len(results) != len(urls) {
+		t.Fatalf("Expected %d results, got %d", len(urls), len(results))
+	}
+	for i, r := range results {
+		if r.URL != urls[i] {
+			t.Errorf("Expected result %d for %s, got %s", i, urls[i], r.URL)
+		}
+	}
+	if results[0].TaskID != "task-1" || results[2].TaskID != "task-2" || results[1].TaskID != "task-"+hash[len(hash)-1:] {
+		t.Errorf("Unexpected task IDs %+v", results)
+	}
+	if !results[3].Duplicate || results[3].TaskID != results[1].TaskID {
+		t.Errorf("Expected the base32 magnet to duplicate the hex one, got %+v", results[3])
+	}
+	if !errors.Is(results[4].Err, exception.ErrInvalidURL) {
+		t.Errorf("Expected ErrInvalidURL for a bad magnet, got %v", results[4].Err)
+	}
+	if !results[5].Duplicate || results[5].Err != nil {
+		t.Errorf("Expected a conflict to be reported as a duplicate, got %+v", results[5])
+	}
+	if results[6].Err == nil || results[6].Duplicate {
+		t.Errorf("Expected an error for the rejected URL, got %+v", results[6])
+	}
+	if !results[7].Duplicate || results[7].TaskID != "task-1" {
+		t.Errorf("Expected the repeated URL to be a duplicate, got %+v", results[7])
+	}
+
+	if len(submitted) != 5 {
+		t.Errorf("Expected 5 submissions, got %v", submitted)
+	}
+	if maxInFlight > 2 {
+		t.Errorf("Expected at most 2 concurrent requests, got %d", maxInFlight)
+	}
+}
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"errors"
+
+	"github.com/zhz8888/pikpakapi-go/internal/token"
+)
+
+// WithTokenStore loads the tokens for account from store when the client is
+// created without tokens, and saves them after every login and refresh. An
+// empty account uses the username. A KeyringTokenStore warns through the
+// client's Logger when it falls back.
+func WithTokenStore(store token.TokenStore, account string) Option {
+	return func(c *Client) {
+		c.tokenStore = store
+		c.tokenAccount = account
+	}
+}
+
+func (c *Client) loadStoredToken() {
+	if c.tokenAccount == "" {
+		c.tokenAccount = c.username
+	}
+	if c.GetAccessToken() != "" || c.GetRefreshToken() != "" {
+		return
+	}
+
+	encoded, err := c.tokenStore.Load(c.tokenAccount)
+	if err != nil {
+		if !errors.Is(err, token.ErrTokenNotFound) {
+			c.logger.Errorf("Failed to load stored token: %v", err)
+		}
+		return
+	}
+	c.SetEncodedToken(encoded)
+	if err := c.DecodeToken(); err != nil {
+		c.logger.Errorf("Failed to decode stored token: %v", err)
+	}
+}
+
+func (c *Client) saveToken() {
+	if c.tokenStore == nil {
+		return
+	}
+	if err := c.EncodeToken(); err != nil {
+		c.logger.Errorf("Failed to encode token: %v", err)
+		return
+	}
+	if err := c.tokenStore.Save(c.tokenAccount, c.GetEncodedToken()); err != nil {
+		c.logger.Errorf("Failed to save token: %v", err)
+	}
+}
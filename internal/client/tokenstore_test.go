@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/zhz8888/pikpakapi-go/internal/token"
+)
+
+func TestWithTokenStore_LoadsAndSaves(t *testing.T) {
+	store := token.NewFileTokenStore(filepath.Join(t.TempDir(), "tokens.json"))
+	encoded, _ := token.Encode("stored_access", "stored_refresh")
+	store.Save("user@example.com", encoded)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "new_access",
+			"refresh_token": "new_refresh",
+			"sub":           "user_id",
+		})
+	}))
+	defer server.Close()
+
+	cli := NewClient(
+		WithBaseURL(server.URL),
+		WithUsername("user@example.com"),
+		WithTokenStore(store, ""),
+	)
+	if cli.GetAccessToken() != "stored_access" || cli.GetRefreshToken() != "stored_refresh" {
+		t.Fatalf("Expected stored tokens to be loaded, got %q %q", cli.GetAccessToken(), cli.GetRefreshToken())
+	}
+
+	if err := cli.RefreshAccessToken(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	saved, err := store.Load("user@example.com")
+	if err != nil {
+		t.Fatalf("Expected token to be saved, got %v", err)
+	}
+	data, err := token.Decode(saved)
+	if err != nil || data.AccessToken != "new_access" || data.RefreshToken != "new_refresh" {
+		t.Errorf("Expected refreshed tokens in the store, got %+v, %v", data, err)
+	}
+}
+
+func TestWithTokenStore_ExplicitTokensWin(t *testing.T) {
+	store := token.NewFileTokenStore(filepath.Join(t.TempDir(), "tokens.json"))
+	encoded, _ := token.Encode("stored_access", "stored_refresh")
+	store.Save("account", encoded)
+
+	cli := NewClient(WithAccessToken("explicit"), WithTokenStore(store, "account"))
+	if cli.GetAccessToken() != "explicit" {
+		t.Errorf("Expected explicit token to be kept, got %q", cli.GetAccessToken())
+	}
+}
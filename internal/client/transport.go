@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// DialContextFunc matches net.Dialer.DialContext.
+type DialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// WithDialContext replaces the dialer used for every outgoing connection.
+func WithDialContext(dial DialContextFunc) Option {
+	return func(c *Client) {
+		c.dialContext = dial
+	}
+}
+
+// WithHostIPOverride pins hostnames to fixed addresses without touching the
+// system resolver. Values may be a bare IP or an IP:port. Only the dial
+// address is rewritten, so TLS SNI and the Host header keep the real name.
+// When a proxy is configured the only address dialed is the proxy's, so an
+// entry applies there only if the map names the proxy host.
+func WithHostIPOverride(overrides map[string]string) Option {
+	return func(c *Client) {
+		c.hostOverrides = make(map[string]string, len(overrides))
+		for host, addr := range overrides {
+			c.hostOverrides[host] = addr
+		}
+	}
+}
+
+// WithProxy routes all requests through proxyURL instead of the proxy taken
+// from the environment.
+func WithProxy(proxyURL *url.URL) Option {
+	return func(c *Client) {
+		c.proxyURL = proxyURL
+	}
+}
+
+// configureTransport installs a transport carrying the dial, proxy and
+// bandwidth settings. The default transport is left alone when none are set.
+func (c *Client) configureTransport() {
+	if c.bandwidthLimit > 0 {
+		defer func() {
+			base := c.httpClient.Transport
+			if base == nil {
+				base = http.DefaultTransport
+			}
+			c.httpClient.Transport = &throttledTransport{base: base, limiter: &bandwidthLimiter{rate: c.bandwidthLimit}}
+		}()
+	}
+	if c.dialContext == nil && len(c.hostOverrides) == 0 && c.proxyURL == nil {
+		return
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if c.proxyURL != nil {
+		transport.Proxy = http.ProxyURL(c.proxyURL)
+	}
+
+	dial := c.dialContext
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: HTTPTimeout, KeepAlive: HTTPTimeout}).DialContext
+	}
+	if len(c.hostOverrides) > 0 {
+		dial = overrideDialAddr(dial, c.hostOverrides)
+	}
+	transport.DialContext = dial
+
+	c.httpClient.Transport = transport
+}
+
+// transferClient is the HTTP client for file content. It shares the
+// transport but not the timeout, which would cut off any transfer taking
+// longer than an API call; the caller's ctx bounds the transfer instead.
+func (c *Client) transferClient() *http.Client {
+	transfer := *c.httpClient
+	transfer.Timeout = 0
+	return &transfer
+}
+
+func overrideDialAddr(dial DialContextFunc, overrides map[string]string) DialContextFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return dial(ctx, network, addr)
+		}
+		target, ok := overrides[host]
+		if !ok {
+			return dial(ctx, network, addr)
+		}
+		if _, _, err := net.SplitHostPort(target); err != nil {
+			target = net.JoinHostPort(target, port)
+		}
+		return dial(ctx, network, target)
+	}
+}
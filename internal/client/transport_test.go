@@ -0,0 +1,260 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+type addrRecorder struct {
+	mu     sync.Mutex
+	addrs  []string
+	target string
+}
+
+func (a *addrRecorder) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	a.mu.Lock()
+	a.addrs = append(a.addrs, addr)
+	a.mu.Unlock()
+
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, network, a.target)
+}
+
+func (a *addrRecorder) dialed() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string(nil), a.addrs...)
+}
+
+func jsonHandler(record func(*http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		record(r)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"kind": "drive#about"})
+	}
+}
+
+func TestWithDialContext(t *testing.T) {
+	server := httptest.NewServer(jsonHandler(func(*http.Request) {}))
+	defer server.Close()
+
+	recorder := &addrRecorder{target: server.Listener.Addr().String()}
+	cli := NewClient(
+		WithBaseURL("http://drive.custom.test:8080"),
+		WithAccessToken("test_token"),
+		WithDialContext(recorder.DialContext),
+	)
+
+	if _, err := cli.GetAbout(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := recorder.dialed(); len(got) != 1 || got[0] != "drive.custom.test:8080" {
+		t.Errorf("Expected dial to drive.custom.test:8080, got %v", got)
+	}
+}
+
+func TestWithHostIPOverride_KeepsSNIAndHost(t *testing.T) {
+	var serverName, host string
+	server := httptest.NewTLSServer(jsonHandler(func(r *http.Request) {
+		serverName = r.TLS.ServerName
+		host = r.Host
+	}))
+	defer server.Close()
+
+	recorder := &addrRecorder{target: server.Listener.Addr().String()}
+	cli := NewClient(
+		WithAccessToken("test_token"),
+		WithDriveHosts("drive.pinned.test"),
+		WithDialContext(recorder.DialContext),
+		WithHostIPOverride(map[string]string{"drive.pinned.test": "192.0.2.10"}),
+	)
+	cli.httpClient.Transport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+
+	if _, err := cli.GetAbout(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := recorder.dialed(); len(got) != 1 || got[0] != "192.0.2.10:443" {
+		t.Errorf("Expected dial to 192.0.2.10:443, got %v", got)
+	}
+	if serverName != "drive.pinned.test" {
+		t.Errorf("Expected SNI 'drive.pinned.test', got %q", serverName)
+	}
+	if host != "drive.pinned.test" {
+		t.Errorf("Expected Host header 'drive.pinned.test', got %q", host)
+	}
+}
+
+func TestWithProxy_ComposesWithOverrides(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]string
+		expected  string
+	}{
+		{"target_override_ignored", map[string]string{"drive.pinned.test": "192.0.2.10"}, "proxy.test:3128"},
+		{"proxy_override_applied", map[string]string{"proxy.test": "192.0.2.20:8888"}, "192.0.2.20:8888"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requestURI string
+			proxy := httptest.NewServer(jsonHandler(func(r *http.Request) {
+				requestURI = r.RequestURI
+			}))
+			defer proxy.Close()
+
+			recorder := &addrRecorder{target: proxy.Listener.Addr().String()}
+			cli := NewClient(
+				WithBaseURL("http://drive.pinned.test"),
+				WithAccessToken("test_token"),
+				WithProxy(&url.URL{Scheme: "http", Host: "proxy.test:3128"}),
+				WithDialContext(recorder.DialContext),
+				WithHostIPOverride(tt.overrides),
+			)
+
+			if _, err := cli.GetAbout(context.Background()); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got := recorder.dialed(); len(got) != 1 || got[0] != tt.expected {
+				t.Errorf("Expected dial to %s, got %v", tt.expected, got)
+			}
+			if requestURI != "http://drive.pinned.test/drive/v1/about" {
+				t.Errorf("Expected proxied request for the real host, got %q", requestURI)
+			}
+		})
+	}
+}
+
+func TestConfigureTransport_DefaultUntouched(t *testing.T) {
+	cli := NewClient()
+	if cli.httpClient.Transport != nil {
+		t.Error("Expected default transport when no dial or proxy options are set")
+	}
+}
+
+func TestWithBandwidthLimit(t *testing.T) {
+	body := strings.Repeat("x", 64*1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithBandwidthLimit(128 * 1024))
+	start := time.Now()
+	resp, err := cli.httpClient.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if len(data) != len(body) {
+		t.Errorf("Expected the full body, got %d bytes", len(data))
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("Expected 64 KiB at 128 KiB/s to be paced, took %v", elapsed)
+	}
+}
+
+// slowServer serves the drive API after apiDelay, an upload endpoint after
+// uploadDelay and /content/f1 in five chunks 40ms apart.
+func slowServer(t *testing.T, apiDelay, uploadDelay time.Duration) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/drive/v1/files/f1":
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": "f1", "web_content_link": server.URL + "/content/f1"})
+		case "/drive/v1/files/upload/url":
+			json.NewEncoder(w).Encode(map[string]interface{}{"upload_url": server.URL + "/upload"})
+		case "/upload":
+			io.Copy(io.Discard, r.Body)
+			time.Sleep(uploadDelay)
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": "new"})
+		case "/content/f1":
+			for i := 0; i < 5; i++ {
+				w.Write([]byte("chunk"))
+				w.(http.Flusher).Flush()
+				time.Sleep(40 * time.Millisecond)
+			}
+		default:
+			select {
+			case <-time.After(apiDelay):
+			case <-r.Context().Done():
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"files": []interface{}{}})
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWithTimeout_BoundsAPIRequests(t *testing.T) {
+	server := slowServer(t, time.Second, 0)
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithMaxRetries(0), WithTimeout(50*time.Millisecond))
+
+	start := time.Now()
+	_, err := cli.FileList(context.Background(), 10, "", "", "")
+	if !exception.Is(err, exception.ErrCodeTimeout) {
+		t.Errorf("Expected ErrCodeTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the client timeout to end the request, took %s", elapsed)
+	}
+}
+
+func TestWithTimeout_ShorterContextDeadlineWins(t *testing.T) {
+	server := slowServer(t, time.Second, 0)
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithMaxRetries(0), WithTimeout(5*time.Second))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := cli.FileList(ctx, 10, "", "", "")
+	if !exception.Is(err, exception.ErrCodeTimeout) {
+		t.Errorf("Expected ErrCodeTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the ctx deadline to end the request, took %s", elapsed)
+	}
+}
+
+func TestWithTimeout_TransfersFollowContext(t *testing.T) {
+	server := slowServer(t, 0, 150*time.Millisecond)
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"), WithMaxRetries(0), WithTimeout(100*time.Millisecond))
+
+	// Both transfers outlast the client timeout.
+	body, err := cli.OpenFile(context.Background(), "f1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(body)
+	body.Close()
+	if err != nil || string(got) != strings.Repeat("chunk", 5) {
+		t.Errorf("Expected the whole stream, got %q, %v", got, err)
+	}
+	if _, err := cli.UploadReader(context.Background(), strings.NewReader("data"), "a.txt", 4, ""); err != nil {
+		t.Errorf("Expected the upload to outlast the client timeout, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Millisecond)
+	defer cancel()
+	body, err = cli.OpenFile(ctx, "f1", 0)
+	if err == nil {
+		_, err = io.ReadAll(body)
+		body.Close()
+	}
+	if err == nil {
+		t.Error("Expected the ctx deadline to end the stream")
+	}
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"strings"
+
+	"github.com/zhz8888/pikpakapi-go/internal/constants"
+)
+
+func WithDriveBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.driveBaseURL = baseURL
+	}
+}
+
+func WithUserBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.userBaseURL = baseURL
+	}
+}
+
+func normalizeBaseURL(baseURL string) string {
+	baseURL = strings.TrimSpace(baseURL)
+	if baseURL == "" {
+		return ""
+	}
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "https://" + baseURL
+	}
+	return strings.TrimRight(baseURL, "/")
+}
+
+func joinURL(baseURL string, path string) string {
+	if path == "" {
+		return baseURL
+	}
+	return baseURL + "/" + strings.TrimLeft(path, "/")
+}
+
+// driveBaseOverride returns the explicitly configured drive base URL, or an
+// empty string when requests should go to the active drive host.
+func (c *Client) driveBaseOverride() string {
+	if c.driveBaseURL != "" {
+		return c.driveBaseURL
+	}
+	return c.baseURL
+}
+
+func (c *Client) userBaseOverride() string {
+	if c.userBaseURL != "" {
+		return c.userBaseURL
+	}
+	return c.baseURL
+}
+
+func (c *Client) driveURL(path string) string {
+	baseURL := c.driveBaseOverride()
+	if baseURL == "" {
+		baseURL = "https://" + c.currentDriveHost()
+	}
+	return joinURL(baseURL, path)
+}
+
+func (c *Client) userURL(path string) string {
+	baseURL := c.userBaseOverride()
+	if baseURL == "" {
+		baseURL = "https://" + constants.UserHost
+	}
+	return joinURL(baseURL, path)
+}
@@ -0,0 +1,174 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type pathRecorder struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (p *pathRecorder) handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		p.paths = append(p.paths, r.URL.Path)
+		p.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"captcha_token":    "captcha_token",
+			"access_token":     "access_token",
+			"refresh_token":    "refresh_token",
+			"sub":              "user_id",
+			"web_content_link": "https://example.com/file",
+			"pass_code_token":  "pass_token",
+			"upload_url":       "https://upload.example.com",
+			"file_info":        map[string]interface{}{"id": "f1"},
+		})
+	}
+}
+
+func (p *pathRecorder) reset() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	paths := p.paths
+	p.paths = nil
+	return paths
+}
+
+func TestBaseURLOverrides_RouteEveryMethod(t *testing.T) {
+	driveRec := &pathRecorder{}
+	userRec := &pathRecorder{}
+	driveServer := httptest.NewServer(driveRec.handler())
+	defer driveServer.Close()
+	userServer := httptest.NewServer(userRec.handler())
+	defer userServer.Close()
+
+	cli := NewClient(
+		WithUsername("user@example.com"),
+		WithPassword("password"),
+		WithAccessToken("access_token"),
+		WithRefreshToken("refresh_token"),
+		WithDriveBaseURL(driveServer.URL),
+		WithUserBaseURL(userServer.URL),
+	)
+
+	ctx := context.Background()
+	shareURL := "https://mypikpak.com/share/link/share_id"
+
+	tests := []struct {
+		name   string
+		call   func() error
+		user   bool
+		expect string
+	}{
+		{"Login", func() error { return cli.Login(ctx) }, true, "/v1/auth/signin"},
+		{"RefreshAccessToken", func() error { return cli.RefreshAccessToken(ctx) }, true, "/v1/auth/token"},
+		{"FileList", func() error { _, err := cli.FileList(ctx, 10, "", "", ""); return err }, false, "/drive/v1/files"},
+		{"CreateFolder", func() error { _, err := cli.CreateFolder(ctx, "folder", ""); return err }, false, "/drive/v1/files"},
+		{"GetFileLink", func() error { _, err := cli.GetFileLink(ctx, "f1"); return err }, false, "/drive/v1/files/f1"},
+		{"Move", func() error { return cli.Move(ctx, "f1", "p1") }, false, "/drive/v1/files:batchMove"},
+		{"Copy", func() error { return cli.Copy(ctx, "f1", "p1") }, false, "/drive/v1/files:batchCopy"},
+		{"Rename", func() error { return cli.Rename(ctx, "f1", "name") }, false, "/drive/v1/files/f1"},
+		{"DeleteToTrash", func() error { _, err := cli.DeleteToTrash(ctx, []string{"f1"}); return err }, false, "/drive/v1/files:batchTrash"},
+		{"Untrash", func() error { _, err := cli.Untrash(ctx, []string{"f1"}); return err }, false, "/drive/v1/files:batchUntrash"},
+		{"DeleteForever", func() error { _, err := cli.DeleteForever(ctx, []string{"f1"}); return err }, false, "/drive/v1/files:batchDelete"},
+		{"GetAbout", func() error { _, err := cli.GetAbout(ctx); return err }, false, "/drive/v1/about"},
+		{"GetQuotaInfo", func() error { _, err := cli.GetQuotaInfo(ctx); return err }, false, "/drive/v1/about"},
+		{"GetStorageInfo", func() error { _, err := cli.GetStorageInfo(ctx); return err }, false, "/drive/v1/about"},
+		{"OfflineDownload", func() error { _, err := cli.OfflineDownload(ctx, "magnet:?xt=urn:btih:x", "", "x"); return err }, false, "/drive/v1/files"},
+		{"RemoteDownload", func() error { _, err := cli.RemoteDownload(ctx, "https://example.com/x"); return err }, false, "/drive/v1/files"},
+		{"OfflineList", func() error { _, err := cli.OfflineList(ctx, 10, "", nil); return err }, false, "/drive/v1/tasks"},
+		{"OfflineFileInfo", func() error { _, err := cli.OfflineFileInfo(ctx, "f1"); return err }, false, "/drive/v1/files/f1"},
+		{"OfflineTaskRetry", func() error { return cli.OfflineTaskRetry(ctx, "t1") }, false, "/drive/v1/task"},
+		{"DeleteTasks", func() error { return cli.DeleteTasks(ctx, []string{"t1"}, false) }, false, "/drive/v1/tasks"},
+		{"DeleteOfflineTasks", func() error { return cli.DeleteOfflineTasks(ctx, []string{"t1"}, false) }, false, "/drive/v1/tasks"},
+		{"GetTaskStatus", func() error { _, err := cli.GetTaskStatus(ctx, "t1", "f1"); return err }, false, "/drive/v1/files/f1"},
+		{"CaptureScreenshot", func() error { _, err := cli.CaptureScreenshot(ctx, "f1"); return err }, false, "/drive/v1/files:testScreenshot"},
+		{"FileBatchStar", func() error { return cli.FileBatchStar(ctx, []string{"f1"}, true) }, false, "/drive/v1/files:batchStar"},
+		{"FileStarList", func() error { _, err := cli.FileStarList(ctx, 10, ""); return err }, false, "/drive/v1/files"},
+		{"Events", func() error { _, err := cli.Events(ctx, 10, ""); return err }, false, "/drive/v1/events"},
+		{"FileBatchShare", func() error { _, err := cli.FileBatchShare(ctx, []string{"f1"}, false); return err }, false, "/drive/v1/files:batchShare"},
+		{"CreateShareLink", func() error { _, err := cli.CreateShareLink(ctx, "f1", 0, ""); return err }, false, "/drive/v1/share"},
+		{"GetShareList", func() error { _, err := cli.GetShareList(ctx, 10, ""); return err }, false, "/drive/v1/share/list"},
+		{"CancelShare", func() error { _, err := cli.CancelShare(ctx, "s1"); return err }, false, "/drive/v1/share/s1/cancel"},
+		{"GetShareInfo", func() error { _, err := cli.GetShareInfo(ctx, shareURL); return err }, false, "/share/v1/info"},
+		{"Restore", func() error { _, err := cli.Restore(ctx, "s1", "", []string{"f1"}); return err }, false, "/share/v1/file/restore"},
+		{"GetShareFileInfo", func() error { _, err := cli.GetShareFileInfo(ctx, shareURL, "pass"); return err }, false, "/drive/v1/share/file_info"},
+		{"GetShareFiles", func() error { _, err := cli.GetShareFiles(ctx, shareURL, ""); return err }, false, "/drive/v1/share/file/list"},
+		{"GetUploadURL", func() error { _, err := cli.GetUploadURL(ctx, "a.txt", 1, ""); return err }, false, "/drive/v1/files/upload/url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driveRec.reset()
+			userRec.reset()
+
+			if err := tt.call(); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			hit, other := driveRec.reset(), userRec.reset()
+			if tt.user {
+				hit, other = other, hit
+			}
+			if len(other) != 0 {
+				t.Errorf("Expected no requests to the other server, got %v", other)
+			}
+			if len(hit) == 0 || hit[len(hit)-1] != tt.expect {
+				t.Errorf("Expected last request path %q, got %v", tt.expect, hit)
+			}
+		})
+	}
+}
+
+func TestDriveURL_Normalization(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		path     string
+		expected string
+	}{
+		{"default_host", nil, "/drive/v1/about", "https://api-drive.mypikpak.com/drive/v1/about"},
+		{"base_url_without_scheme", []Option{WithBaseURL("mirror.example.com")}, "/drive/v1/about", "https://mirror.example.com/drive/v1/about"},
+		{"base_url_trailing_slash", []Option{WithBaseURL("http://localhost:8080/")}, "/drive/v1/about", "http://localhost:8080/drive/v1/about"},
+		{"path_without_slash", []Option{WithBaseURL("http://localhost:8080")}, "drive/v1/about", "http://localhost:8080/drive/v1/about"},
+		{"drive_override_wins", []Option{WithBaseURL("http://a"), WithDriveBaseURL("http://b")}, "/x", "http://b/x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := NewClient(tt.opts...)
+			if got := cli.driveURL(tt.path); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestUserURL_Normalization(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected string
+	}{
+		{"default_host", nil, "https://user.mypikpak.com/v1/auth/token"},
+		{"base_url_fallback", []Option{WithBaseURL("http://a")}, "http://a/v1/auth/token"},
+		{"user_override_wins", []Option{WithBaseURL("http://a"), WithUserBaseURL("b.example.com")}, "https://b.example.com/v1/auth/token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := NewClient(tt.opts...)
+			if got := cli.userURL("/v1/auth/token"); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
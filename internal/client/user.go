@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+// UserProfile is the signed-in account as the server describes it. Fields
+// the server leaves out are zero.
+type UserProfile struct {
+	UserID    string         `json:"sub"`
+	Name      string         `json:"name"`
+	Email     string         `json:"email"`
+	Phone     string         `json:"phone_number"`
+	Picture   string         `json:"picture"`
+	CreatedAt time.Time      `json:"created_at"`
+	Providers []UserProvider `json:"providers"`
+	Status    string         `json:"status"`
+}
+
+// UserProvider is a way of signing in linked to the account, such as
+// "email", "phone" or "google".
+type UserProvider struct {
+	ID             string `json:"id"`
+	ProviderUserID string `json:"provider_user_id"`
+	Name           string `json:"name"`
+}
+
+func (p *UserProfile) UnmarshalJSON(data []byte) error {
+	// created_at may be missing, which time.Time does not accept; the
+	// outer field shadows the embedded one.
+	type profile UserProfile
+	aux := struct {
+		*profile
+		CreatedAt string `json:"created_at"`
+	}{profile: (*profile)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.CreatedAt == "" {
+		return nil
+	}
+	createdAt, err := time.Parse(time.RFC3339, aux.CreatedAt)
+	if err != nil {
+		return err
+	}
+	p.CreatedAt = createdAt
+	return nil
+}
+
+// GetMe asks the user service for the profile of the signed-in account,
+// unlike GetUserInfo, which only reports what the client holds locally.
+func (c *Client) GetMe(ctx context.Context) (*UserProfile, error) {
+	respBody, err := c.doRequest(ctx, http.MethodGet, c.userURL("/v1/user/me"), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeUserProfile(respBody)
+}
+
+func decodeUserProfile(respBody []byte) (*UserProfile, error) {
+	profile := &UserProfile{}
+	if err := json.Unmarshal(respBody, profile); err != nil {
+		return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeUnmarshalFailed, err)
+	}
+	return profile, nil
+}
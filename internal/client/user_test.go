@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+func TestDecodeUserProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    UserProfile
+		wantErr bool
+	}{
+		{
+			name: "email account",
+			body: `{"sub":"ZrW1a2b3c4d5","name":"alice","picture":"https://example.com/avatar.png","email":"alice@example.com","providers":[{"id":"email","provider_user_id":"alice@example.com","name":"alice@example.com"}],"password":"SET","status":"ACTIVE","created_at":"2022-03-17T08:12:45.123Z","password_updated_at":"2022-03-17T08:12:45.123Z","phone_number":""}`,
+			want: UserProfile{
+				UserID:    "ZrW1a2b3c4d5",
+				Name:      "alice",
+				Email:     "alice@example.com",
+				Picture:   "https://example.com/avatar.png",
+				CreatedAt: time.Date(2022, 3, 17, 8, 12, 45, 123000000, time.UTC),
+				Providers: []UserProvider{{ID: "email", ProviderUserID: "alice@example.com", Name: "alice@example.com"}},
+				Status:    "ACTIVE",
+			},
+		},
+		{
+			name: "phone and google account",
+			body: `{"sub":"u2","name":"bob","phone_number":"+86 138****0000","providers":[{"id":"phone","provider_user_id":"+86 13800000000"},{"id":"google","provider_user_id":"1234567890","name":"Bob"}],"status":"ACTIVE","created_at":"2023-11-02T15:04:05+08:00"}`,
+			want: UserProfile{
+				UserID:    "u2",
+				Name:      "bob",
+				Phone:     "+86 138****0000",
+				CreatedAt: time.Date(2023, 11, 2, 7, 4, 5, 0, time.UTC),
+				Providers: []UserProvider{{ID: "phone", ProviderUserID: "+86 13800000000"}, {ID: "google", ProviderUserID: "1234567890", Name: "Bob"}},
+				Status:    "ACTIVE",
+			},
+		},
+		{
+			name: "only required fields",
+			body: `{"sub":"u3"}`,
+			want: UserProfile{UserID: "u3"},
+		},
+		{
+			name:    "malformed created_at",
+			body:    `{"sub":"u4","created_at":"yesterday"}`,
+			wantErr: true,
+		},
+		{
+			name:    "not JSON",
+			body:    `<html>`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeUserProfile([]byte(tt.body))
+			if tt.wantErr {
+				if !exception.Is(err, exception.ErrCodeUnmarshalFailed) {
+					t.Errorf("Expected ErrCodeUnmarshalFailed, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.CreatedAt.Equal(tt.want.CreatedAt) {
+				t.Errorf("Expected CreatedAt %v, got %v", tt.want.CreatedAt, got.CreatedAt)
+			}
+			got.CreatedAt, tt.want.CreatedAt = time.Time{}, time.Time{}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, *got)
+			}
+		})
+	}
+}
+
+func TestGetMe(t *testing.T) {
+	var path, authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, authorization = r.URL.Path, r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"sub":"u1","name":"alice","status":"ACTIVE"}`))
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithUserBaseURL(server.URL), WithAccessToken("access"))
+	profile, err := cli.GetMe(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/v1/user/me" || authorization != "Bearer access" {
+		t.Errorf("Expected an authorized request to /v1/user/me, got %q %q", path, authorization)
+	}
+	if profile.UserID != "u1" || profile.Name != "alice" || profile.Status != "ACTIVE" {
+		t.Errorf("Unexpected profile %+v", profile)
+	}
+}
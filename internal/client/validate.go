@@ -0,0 +1,157 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+	"github.com/zhz8888/pikpakapi-go/internal/signer"
+)
+
+const (
+	defaultMaxRetries     = 3
+	defaultInitialBackoff = 3 * time.Second
+)
+
+// NewClientE is NewClient for callers that want bad option values reported
+// instead of replaced: a negative retry count, a non-positive backoff or
+// timeout, a malformed base URL, drive host or download host, a proxy with
+// a scheme other than http, https or socks5, and so on. Every violation is
+// an ErrCodeInvalidParameter exception; all of them are returned together
+// as one joined error.
+func NewClientE(opts ...Option) (*Client, error) {
+	c := newClient(opts)
+	if err := c.validateOptions(); err != nil {
+		return nil, err
+	}
+	c.init()
+	return c, nil
+}
+
+// validateOptions checks the values set by the options, resetting each
+// invalid one to its default, and normalizes the base URLs.
+func (c *Client) validateOptions() error {
+	var errs []error
+	invalid := func(format string, args ...interface{}) {
+		errs = append(errs, exception.NewPikpakExceptionWithMessage(exception.ErrCodeInvalidParameter, fmt.Sprintf(format, args...)))
+	}
+
+	if c.maxRetries < 0 {
+		invalid("invalid max retries %d: must not be negative", c.maxRetries)
+		c.maxRetries = defaultMaxRetries
+	}
+	if c.initialBackoff <= 0 {
+		invalid("invalid initial backoff %s: must be positive", c.initialBackoff)
+		c.initialBackoff = defaultInitialBackoff
+	}
+	if c.httpClient.Timeout < 0 {
+		invalid("invalid timeout %s: must not be negative", c.httpClient.Timeout)
+		c.httpClient.Timeout = HTTPTimeout
+	}
+
+	for _, base := range []struct {
+		name string
+		url  *string
+	}{
+		{"base URL", &c.baseURL},
+		{"drive base URL", &c.driveBaseURL},
+		{"user base URL", &c.userBaseURL},
+	} {
+		normalized, err := parseBaseURL(*base.url)
+		if err != nil {
+			invalid("invalid %s %q: %v", base.name, *base.url, err)
+		}
+		*base.url = normalized
+	}
+
+	if c.proxyURL != nil {
+		switch c.proxyURL.Scheme {
+		case "http", "https", "socks5", "socks5h":
+			if c.proxyURL.Host == "" {
+				invalid("invalid proxy URL %q: missing host", c.proxyURL)
+				c.proxyURL = nil
+			}
+		default:
+			invalid("invalid proxy URL %q: scheme must be http, https, socks5 or socks5h", c.proxyURL)
+			c.proxyURL = nil
+		}
+	}
+
+	for _, host := range c.driveHosts {
+		if !validHost(host) {
+			invalid("invalid drive host %q: want host[:port]", host)
+			c.driveHosts = defaultDriveHosts()
+			break
+		}
+	}
+	if c.downloadHost != "" && !validHost(c.downloadHost) {
+		invalid("invalid download host %q: want host[:port]", c.downloadHost)
+		c.downloadHost = ""
+	}
+	for host, addr := range c.hostOverrides {
+		if !validIPOverride(addr) {
+			invalid("invalid IP override %q for %s: want an IP or IP:port", addr, host)
+			delete(c.hostOverrides, host)
+		}
+	}
+
+	if !c.preferredLink.valid() {
+		invalid("invalid link preference %q: want original, transcoded or auto", c.preferredLink)
+		c.preferredLink = ""
+	}
+	if c.thumbnailSize != "" && !c.thumbnailSize.IsValid() {
+		invalid("invalid thumbnail size %q", c.thumbnailSize)
+	}
+
+	if c.tokenRefreshMargin < 0 {
+		invalid("invalid token refresh margin %s: must not be negative", c.tokenRefreshMargin)
+		c.tokenRefreshMargin = DefaultTokenRefreshMargin
+	}
+
+	if cfg := c.signing.Load(); cfg.ClientID == "" || cfg.ClientVersion == "" || cfg.PackageName == "" {
+		invalid("invalid signing config: client ID, client version and package name are required")
+		c.signing.Store(signer.DefaultConfig())
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+// parseBaseURL normalizes baseURL, defaulting the scheme to https, and
+// checks that the result is an http or https URL with a host. An invalid
+// URL yields "".
+func parseBaseURL(baseURL string) (string, error) {
+	normalized := normalizeBaseURL(baseURL)
+	if normalized == "" {
+		return "", nil
+	}
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("scheme must be http or https")
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("missing host")
+	}
+	return normalized, nil
+}
+
+func validHost(host string) bool {
+	return host != "" && !strings.ContainsAny(host, "/?#@ ")
+}
+
+func validIPOverride(addr string) bool {
+	if net.ParseIP(addr) != nil {
+		return true
+	}
+	ip, port, err := net.SplitHostPort(addr)
+	return err == nil && port != "" && net.ParseIP(ip) != nil
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+func TestNewClientE_Validation(t *testing.T) {
+	mustParse := func(raw string) *url.URL {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr string
+	}{
+		{"defaults", nil, ""},
+		{"valid values", []Option{
+			WithMaxRetries(0),
+			WithInitialBackoff(time.Millisecond),
+			WithTimeout(0),
+			WithBaseURL("example.com/"),
+			WithProxy(mustParse("socks5://127.0.0.1:1080")),
+			WithDriveHosts("api.example.com:8443"),
+			WithDownloadHost("cdn.example.com"),
+			WithHostIPOverride(map[string]string{"api.example.com": "10.0.0.1:443"}),
+			WithPreferredLink(LinkOriginal),
+		}, ""},
+		{"negative retries", []Option{WithMaxRetries(-1)}, "max retries"},
+		{"zero backoff", []Option{WithInitialBackoff(0)}, "initial backoff"},
+		{"negative backoff", []Option{WithInitialBackoff(-time.Second)}, "initial backoff"},
+		{"negative timeout", []Option{WithTimeout(-time.Second)}, "timeout"},
+		{"base URL scheme", []Option{WithBaseURL("ftp://example.com")}, "base URL"},
+		{"base URL host", []Option{WithDriveBaseURL("http://")}, "drive base URL"},
+		{"base URL parse", []Option{WithUserBaseURL("http://exa mple.com")}, "user base URL"},
+		{"proxy scheme", []Option{WithProxy(mustParse("gopher://proxy:70"))}, "proxy URL"},
+		{"proxy host", []Option{WithProxy(mustParse("http://"))}, "proxy URL"},
+		{"drive host", []Option{WithDriveHosts("https://api.example.com")}, "drive host"},
+		{"download host", []Option{WithDownloadHost("cdn.example.com/path")}, "download host"},
+		{"IP override", []Option{WithHostIPOverride(map[string]string{"api.example.com": "not-an-ip"})}, "IP override"},
+		{"link preference", []Option{WithPreferredLink("best")}, "link preference"},
+		{"thumbnail size", []Option{WithThumbnailSize("SIZE_HUGE")}, "thumbnail size"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, err := NewClientE(tt.opts...)
+			if tt.wantErr == "" {
+				if err != nil || cli == nil {
+					t.Fatalf("Expected a client, got %v", err)
+				}
+				return
+			}
+			if cli != nil {
+				t.Error("Expected no client on error")
+			}
+			if !errors.Is(err, exception.ErrInvalidParameter) {
+				t.Errorf("Expected ErrInvalidParameter, got %v", err)
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error about %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNewClientE_JoinsErrors(t *testing.T) {
+	_, err := NewClientE(WithMaxRetries(-1), WithInitialBackoff(0), WithBaseURL("ftp://example.com"))
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	for _, want := range []string{"max retries", "initial backoff", "base URL"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to mention %q, got %v", want, err)
+		}
+	}
+	if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != 3 {
+		t.Errorf("Expected 3 joined errors, got %d", n)
+	}
+}
+
+func TestNewClient_FallsBackToDefaults(t *testing.T) {
+	cli := NewClient(
+		WithMaxRetries(-1),
+		WithInitialBackoff(-time.Second),
+		WithTimeout(-time.Second),
+		WithBaseURL("ftp://example.com"),
+		WithProxy(&url.URL{Scheme: "gopher", Host: "proxy:70"}),
+		WithDriveHosts(""),
+		WithPreferredLink("best"),
+	)
+
+	if cli.maxRetries != defaultMaxRetries || cli.initialBackoff != defaultInitialBackoff {
+		t.Errorf("Expected default retries, got %d and %s", cli.maxRetries, cli.initialBackoff)
+	}
+	if cli.httpClient.Timeout != HTTPTimeout {
+		t.Errorf("Expected default timeout, got %s", cli.httpClient.Timeout)
+	}
+	if cli.baseURL != "" || cli.proxyURL != nil || cli.preferredLink != "" {
+		t.Errorf("Expected invalid values dropped, got %q %v %q", cli.baseURL, cli.proxyURL, cli.preferredLink)
+	}
+	if got := cli.driveURL("/drive/v1/files"); got != "https://api-drive.mypikpak.com/drive/v1/files" {
+		t.Errorf("Expected the default drive host, got %s", got)
+	}
+}
+
+func TestNewClientE_NormalizesBaseURL(t *testing.T) {
+	cli, err := NewClientE(WithBaseURL(" example.com/ "))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cli.baseURL != "https://example.com" {
+		t.Errorf("Expected normalized base URL, got %q", cli.baseURL)
+	}
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"strings"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+// SendVerificationCode asks PikPak to text a sign-in code to phone, for
+// LoginWithCode.
+func (c *Client) SendVerificationCode(ctx context.Context, phone string) error {
+	return c.authModule.SendVerificationCode(ctx, phone)
+}
+
+// LoginWithCode signs in with the code SendVerificationCode texted to phone,
+// without a username or password. A wrong code fails with
+// ErrVerificationCodeInvalid and an expired one with
+// ErrVerificationCodeExpired.
+func (c *Client) LoginWithCode(ctx context.Context, phone string, code string) error {
+	if err := c.authModule.LoginWithCode(ctx, phone, code); err != nil {
+		return err
+	}
+	c.loggedIn()
+	return nil
+}
+
+// verificationErrorCode maps the server's verification code errors, such as
+// verification_code_invalid or verification_expired.
+func verificationErrorCode(serverError string) (exception.ErrorCode, bool) {
+	s := strings.ToLower(serverError)
+	if !strings.Contains(s, "verification") {
+		return 0, false
+	}
+	switch {
+	case strings.Contains(s, "expired"):
+		return exception.ErrCodeVerificationCodeExpired, true
+	case strings.Contains(s, "invalid"), strings.Contains(s, "wrong"), strings.Contains(s, "mismatch"):
+		return exception.ErrCodeVerificationCodeInvalid, true
+	}
+	return 0, false
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+// newVerificationServer stubs the SMS sign-in endpoints. verifyError, when
+// set, is the error the code verification answers with.
+func newVerificationServer(t *testing.T, verifyError string) *stubServer {
+	verify := stubJSON(map[string]interface{}{"verification_token": "vtoken"})
+	if verifyError != "" {
+		verify = stubError(http.StatusBadRequest, verifyError, 4)
+	}
+	return newStubServer(t, map[string]http.HandlerFunc{
+		"/v1/shield/captcha/init":      stubJSON(map[string]interface{}{"captcha_token": "captcha"}),
+		"/v1/auth/verification":        stubJSON(map[string]interface{}{"verification_id": "vid", "expires_in": 300}),
+		"/v1/auth/verification/verify": verify,
+		"/v1/auth/signin":              stubJSON(map[string]interface{}{"access_token": "access", "refresh_token": "refresh", "sub": "user1", "expires_in": 7200}),
+	})
+}
+
+func TestLoginWithCode(t *testing.T) {
+	server := newVerificationServer(t, "")
+	cli := NewClient(WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	if err := cli.SendVerificationCode(ctx, "+86 13800000000"); err != nil {
+		t.Fatal(err)
+	}
+	if sent := server.lastBody("/v1/auth/verification"); sent["phone_number"] != "+86 13800000000" {
+		t.Errorf("Expected the code to be sent to the phone, got %v", sent)
+	}
+
+	if err := cli.LoginWithCode(ctx, "+86 13800000000", "123456"); err != nil {
+		t.Fatal(err)
+	}
+	if verify := server.lastBody("/v1/auth/verification/verify"); verify["verification_id"] != "vid" || verify["verification_code"] != "123456" {
+		t.Errorf("Expected the code to be verified, got %v", verify)
+	}
+	meta, _ := server.lastBody("/v1/shield/captcha/init")["meta"].(map[string]interface{})
+	if meta["phone_number"] != "+86 13800000000" {
+		t.Errorf("Expected the sign-in captcha to carry the phone number, got %v", meta)
+	}
+	signin := server.lastBody("/v1/auth/signin")
+	if signin["verification_token"] != "vtoken" || signin["verification_code"] != "123456" || signin["captcha_token"] != "captcha" {
+		t.Errorf("Expected sign-in with the verification token, got %v", signin)
+	}
+	if _, ok := signin["password"]; ok {
+		t.Errorf("Expected no password, got %v", signin)
+	}
+
+	if cli.GetAccessToken() != "access" || cli.GetRefreshToken() != "refresh" || cli.GetUserID() != "user1" || cli.GetEncodedToken() == "" {
+		t.Errorf("Expected the fields Login sets, got %q %q %q %q", cli.GetAccessToken(), cli.GetRefreshToken(), cli.GetUserID(), cli.GetEncodedToken())
+	}
+	if cli.TokenExpiresAt().IsZero() {
+		t.Error("Expected the token expiry to be recorded")
+	}
+}
+
+func TestLoginWithCode_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		verifyError string
+		want        error
+	}{
+		{"wrong_code", "verification_code_invalid", exception.ErrVerificationCodeInvalid},
+		{"expired_code", "verification_code_expired", exception.ErrVerificationCodeExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newVerificationServer(t, tt.verifyError)
+			cli := NewClient(WithBaseURL(server.URL))
+			if err := cli.SendVerificationCode(context.Background(), "13800000000"); err != nil {
+				t.Fatal(err)
+			}
+			err := cli.LoginWithCode(context.Background(), "13800000000", "000000")
+			if !errors.Is(err, tt.want) || !errors.Is(err, exception.ErrInvalidParameter) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+			if len(server.requests("/v1/auth/signin")) != 0 {
+				t.Error("Expected no sign-in with a rejected code")
+			}
+		})
+	}
+
+	server := newVerificationServer(t, "")
+	cli := NewClient(WithBaseURL(server.URL))
+	if err := cli.LoginWithCode(context.Background(), "13800000000", "123456"); !errors.Is(err, exception.ErrInvalidParameter) {
+		t.Errorf("Expected ErrInvalidParameter without a sent code, got %v", err)
+	}
+	if reqs := server.requests(""); len(reqs) != 0 {
+		t.Errorf("Expected no request, got %v", reqs)
+	}
+}
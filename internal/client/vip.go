@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+// Known VipInfo.Type values.
+const (
+	VipTypeNone     = "novip"
+	VipTypePlatinum = "platinum"
+)
+
+// VipInfo is the account's premium membership.
+type VipInfo struct {
+	// Type is VipTypeNone for free accounts, or the membership level.
+	Type   string
+	Status string
+	// Expiry is when the membership ends, or zero when there is none.
+	Expiry time.Time
+	// Raw is the whole response, for fields VipInfo does not cover.
+	Raw map[string]interface{}
+}
+
+// IsVip reports whether the account has a membership that has not expired.
+func (v *VipInfo) IsVip() bool {
+	if v.Type == "" || v.Type == VipTypeNone {
+		return false
+	}
+	return v.Expiry.IsZero() || v.Expiry.After(time.Now())
+}
+
+// GetVipInfo returns the premium membership of the account, which decides
+// whether transcoded links and the larger offline download quota are
+// available.
+func (c *Client) GetVipInfo(ctx context.Context) (*VipInfo, error) {
+	result, err := c.GetJSON(ctx, c.driveURL("/drive/v1/privilege/vip"), nil)
+	if err != nil {
+		return nil, err
+	}
+	return parseVipInfo(result)
+}
+
+func parseVipInfo(result map[string]interface{}) (*VipInfo, error) {
+	info := &VipInfo{Raw: result}
+	data, ok := result["data"].(map[string]interface{})
+	if !ok {
+		data = result
+	}
+	if vipType, ok := data["type"].(string); ok {
+		info.Type = vipType
+	}
+	if status, ok := data["status"].(string); ok {
+		info.Status = status
+	}
+	if expire, ok := data["expire"].(string); ok && expire != "" {
+		expiry, err := time.Parse(time.RFC3339, expire)
+		if err != nil {
+			return nil, exception.NewPikpakExceptionWithError(exception.ErrCodeUnmarshalFailed, err)
+		}
+		info.Expiry = expiry
+	}
+	return info, nil
+}
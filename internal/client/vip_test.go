@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+func TestParseVipInfo(t *testing.T) {
+	tests := []struct {
+		fixture string
+		typ     string
+		status  string
+		expiry  time.Time
+	}{
+		{"vip_free.json", VipTypeNone, "invalid", time.Time{}},
+		{"vip_platinum.json", VipTypePlatinum, "ok", time.Date(2025, 6, 30, 15, 59, 59, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := decodeJSONBody(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			info, err := parseVipInfo(result)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Type != tt.typ || info.Status != tt.status || !info.Expiry.Equal(tt.expiry) {
+				t.Errorf("Expected %s %s %v, got %s %s %v", tt.typ, tt.status, tt.expiry, info.Type, info.Status, info.Expiry)
+			}
+			if info.Raw["result"] != "ACCEPTED" {
+				t.Errorf("Expected the raw payload, got %v", info.Raw)
+			}
+		})
+	}
+}
+
+func TestParseVipInfo_BadExpire(t *testing.T) {
+	_, err := parseVipInfo(map[string]interface{}{"data": map[string]interface{}{"type": "platinum", "expire": "soon"}})
+	if !exception.Is(err, exception.ErrCodeUnmarshalFailed) {
+		t.Errorf("Expected ErrCodeUnmarshalFailed, got %v", err)
+	}
+}
+
+func TestVipInfo_IsVip(t *testing.T) {
+	tests := []struct {
+		name string
+		info VipInfo
+		want bool
+	}{
+		{"free", VipInfo{Type: VipTypeNone}, false},
+		{"empty", VipInfo{}, false},
+		{"platinum", VipInfo{Type: VipTypePlatinum, Expiry: time.Now().Add(24 * time.Hour)}, true},
+		{"expired", VipInfo{Type: VipTypePlatinum, Expiry: time.Now().Add(-time.Hour)}, false},
+		{"no expiry", VipInfo{Type: VipTypePlatinum}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.info.IsVip(); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestGetVipInfo(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "vip_platinum.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	cli := NewClient(WithDriveBaseURL(server.URL), WithAccessToken("access"))
+	info, err := cli.GetVipInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/drive/v1/privilege/vip" {
+		t.Errorf("Expected the privilege endpoint, got %q", path)
+	}
+	if info.Type != VipTypePlatinum {
+		t.Errorf("Expected a platinum membership, got %+v", info)
+	}
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
+)
+
+// DefaultTaskPollInterval is how often WatchTasks and WaitForTask poll when
+// given a zero interval.
+const DefaultTaskPollInterval = 3 * time.Second
+
+var allTaskPhases = []enums.PhaseType{
+	enums.PhaseTypePending,
+	enums.PhaseTypeRunning,
+	enums.PhaseTypeComplete,
+	enums.PhaseTypeError,
+}
+
+// WatchTasks polls the offline tasks in taskIDs, or the pending and running
+// ones when taskIDs is empty, every interval and calls fn with their latest
+// state, in a stable order. It returns nil once every watched task is
+// complete or failed, ErrNotFound when a watched task does not exist or is
+// deleted, and ctx.Err() when ctx is done first.
+func (c *Client) WatchTasks(ctx context.Context, taskIDs []string, interval time.Duration, fn func(tasks []map[string]interface{})) error {
+	if interval <= 0 {
+		interval = DefaultTaskPollInterval
+	}
+
+	ids := taskIDs
+	if len(ids) == 0 {
+		tasks, err := c.listTasks(ctx, []enums.PhaseType{enums.PhaseTypePending, enums.PhaseTypeRunning})
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			if id, _ := task["id"].(string); id != "" {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			fn(nil)
+			return nil
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		tasks, err := c.listTasks(ctx, allTaskPhases)
+		if err != nil {
+			return err
+		}
+		byID := make(map[string]map[string]interface{}, len(tasks))
+		for _, task := range tasks {
+			if id, _ := task["id"].(string); id != "" {
+				byID[id] = task
+			}
+		}
+
+		watched := make([]map[string]interface{}, len(ids))
+		done := true
+		for i, id := range ids {
+			task, ok := byID[id]
+			if !ok {
+				return exception.NewPikpakExceptionWithMessage(exception.ErrCodeNotFound, fmt.Sprintf("task %s not found", id))
+			}
+			watched[i] = task
+			phase, _ := task["phase"].(string)
+			if !enums.ParsePhaseType(phase).IsTerminal() {
+				done = false
+			}
+		}
+		fn(watched)
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitForTask blocks until the offline task taskID is complete or failed and
+// returns its final state. A failed task is returned together with an
+// ErrCodeDownloadFailed error carrying the server's message.
+func (c *Client) WaitForTask(ctx context.Context, taskID string, interval time.Duration) (map[string]interface{}, error) {
+	var last map[string]interface{}
+	err := c.WatchTasks(ctx, []string{taskID}, interval, func(tasks []map[string]interface{}) {
+		last = tasks[0]
+	})
+	if err != nil {
+		return last, err
+	}
+	if phase, _ := last["phase"].(string); enums.ParsePhaseType(phase) == enums.PhaseTypeError {
+		message, _ := last["message"].(string)
+		return last, exception.NewPikpakExceptionWithMessage(exception.ErrCodeDownloadFailed, fmt.Sprintf("task %s failed: %s", taskID, message))
+	}
+	return last, nil
+}
+
+// listTasks returns every offline task in phases, following page tokens.
+func (c *Client) listTasks(ctx context.Context, phases []enums.PhaseType) ([]map[string]interface{}, error) {
+	var tasks []map[string]interface{}
+	pageToken := ""
+	for {
+		result, err := c.OfflineListPhases(ctx, 0, pageToken, phases)
+		if err != nil {
+			return nil, err
+		}
+		list, _ := result["tasks"].([]interface{})
+		for _, t := range list {
+			if task, ok := t.(map[string]interface{}); ok {
+				tasks = append(tasks, task)
+			}
+		}
+		pageToken, _ = result["next_page_token"].(string)
+		if pageToken == "" {
+			return tasks, nil
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+)
+
+// newTaskScriptServer serves the offline task list, returning script[n] on
+// the n-th request and the last entry after that.
+func newTaskScriptServer(t *testing.T, script ...[]map[string]interface{}) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1)) - 1
+		if n >= len(script) {
+			n = len(script) - 1
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"tasks": script[n]})
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestWatchTasks(t *testing.T) {
+	server, _ := newTaskScriptServer(t,
+		[]map[string]interface{}{
+			{"id": "t1", "phase": "PHASE_TYPE_RUNNING", "progress": 10},
+			{"id": "t2", "phase": "PHASE_TYPE_PENDING", "progress": 0},
+		},
+		[]map[string]interface{}{
+			{"id": "t1", "phase": "PHASE_TYPE_COMPLETE", "progress": 100},
+			{"id": "t2", "phase": "PHASE_TYPE_RUNNING", "progress": 50},
+		},
+		[]map[string]interface{}{
+			{"id": "t2", "phase": "PHASE_TYPE_ERROR", "progress": 50},
+			{"id": "t1", "phase": "PHASE_TYPE_COMPLETE", "progress": 100},
+		},
+	)
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+
+	var updates [][]map[string]interface{}
+	err := cli.WatchTasks(context.Background(), []string{"t1", "t2"}, time.Millisecond, func(tasks []map[string]interface{}) {
+		updates = append(updates, tasks)
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(updates) != 3 {
+		t.Fatalf("Expected 3 updates, got %d", len(updates))
+	}
+	last := updates[2]
+	if last[0]["id"] != "t1" || last[1]["id"] != "t2" || last[1]["phase"] != "PHASE_TYPE_ERROR" {
+		t.Errorf("Expected tasks in the order given, got %v", last)
+	}
+}
+
+func TestWatchTasks_Running(t *testing.T) {
+	server, _ := newTaskScriptServer(t,
+		[]map[string]interface{}{{"id": "t1", "phase": "PHASE_TYPE_RUNNING"}},
+		[]map[string]interface{}{{"id": "t1", "phase": "PHASE_TYPE_COMPLETE"}, {"id": "old", "phase": "PHASE_TYPE_COMPLETE"}},
+	)
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+
+	var last []map[string]interface{}
+	err := cli.WatchTasks(context.Background(), nil, time.Millisecond, func(tasks []map[string]interface{}) {
+		last = tasks
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(last) != 1 || last[0]["id"] != "t1" {
+		t.Errorf("Expected only the running task to be watched, got %v", last)
+	}
+}
+
+func TestWatchTasks_NotFound(t *testing.T) {
+	server, _ := newTaskScriptServer(t, []map[string]interface{}{{"id": "t1", "phase": "PHASE_TYPE_RUNNING"}})
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+
+	err := cli.WatchTasks(context.Background(), []string{"t9"}, time.Millisecond, func([]map[string]interface{}) {})
+	if !errors.Is(err, exception.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestWaitForTask(t *testing.T) {
+	server, calls := newTaskScriptServer(t,
+		[]map[string]interface{}{{"id": "t1", "phase": "PHASE_TYPE_RUNNING"}},
+		[]map[string]interface{}{{"id": "t1", "phase": "PHASE_TYPE_ERROR", "message": "resource not found"}},
+	)
+	cli := NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+
+	task, err := cli.WaitForTask(context.Background(), "t1", time.Millisecond)
+	if exception.GetErrorCode(err) != exception.ErrCodeDownloadFailed {
+		t.Fatalf("Expected ErrCodeDownloadFailed, got %v", err)
+	}
+	if task["message"] != "resource not found" || *calls != 2 {
+		t.Errorf("Expected the final task after 2 polls, got %v after %d", task, *calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	server, _ = newTaskScriptServer(t, []map[string]interface{}{{"id": "t1", "phase": "PHASE_TYPE_RUNNING"}})
+	cli = NewClient(WithBaseURL(server.URL), WithAccessToken("test_token"))
+	if _, err := cli.WaitForTask(ctx, "t1", time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 )
 
 type Config struct {
@@ -18,6 +19,29 @@ type Config struct {
 	DeviceID     string `json:"device_id"`
 	CaptchaToken string `json:"captcha_token"`
 	UserID       string `json:"user_id"`
+
+	// Client behavior settings. Zero values keep the client defaults, except
+	// for MaxRetries, which is a pointer so that an explicit 0 turns retries
+	// off; nil keeps the default.
+	ProxyURL          string `json:"proxy_url,omitempty"`
+	DriveBaseURL      string `json:"drive_base_url,omitempty"`
+	UserBaseURL       string `json:"user_base_url,omitempty"`
+	MaxRetries        *int   `json:"max_retries,omitempty"`
+	InitialBackoffMS  int64  `json:"initial_backoff_ms,omitempty"`
+	HTTPTimeoutMS     int64  `json:"http_timeout_ms,omitempty"`
+	BandwidthLimitBPS int64  `json:"bandwidth_limit_bps,omitempty"`
+
+	// PreferredLink is "original", "transcoded" or "auto".
+	PreferredLink        string `json:"preferred_link,omitempty"`
+	DownloadHostOverride string `json:"download_host_override,omitempty"`
+
+	// profile is the profile the config was loaded from or saved as, which
+	// SaveDefault and SaveConfigAtomic replace in a profiles file.
+	profile string
+
+	// env records the fields applyEnv overwrote. It is a pointer so that
+	// Config stays comparable.
+	env *envOverrides
 }
 
 var (
@@ -179,38 +203,286 @@ func (b *ConfigBuilder) Validate() []error {
 	return errs
 }
 
+// ConfigEnvVar names the environment variable LoadConfig checks for the path
+// of the config file before its default search paths.
+const ConfigEnvVar = "PIKPAK_CONFIG"
+
+// LoadConfig returns the first readable config among the file named by
+// PIKPAK_CONFIG, config.json and .pikpakapi.json in the working directory,
+// DefaultPath and the legacy ~/.pikpakapi.json. Missing files are
+// skipped; when none can be read it starts from an empty Config. Set PIKPAK_*
+// variables then override the file, so the precedence is environment, then
+// file, then defaults. An encrypted file stops the search with
+// ErrPassphraseRequired; read it with LoadEncrypted.
+//
+// A file that exists but cannot be read or parsed is also skipped, but its
+// error, naming the path and the position of any JSON error, is returned
+// together with the Config that was loaded instead. The Config is usable
+// either way.
 func LoadConfig() (*Config, error) {
 	configPaths := []string{
 		"config.json",
 		".pikpakapi.json",
-		filepath.Join(os.Getenv("HOME"), ".pikpakapi.json"),
+	}
+	if path, err := DefaultPath(); err == nil {
+		configPaths = append(configPaths, path)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		configPaths = append(configPaths, filepath.Join(home, ".pikpakapi.json"))
+	}
+	if path := os.Getenv(ConfigEnvVar); path != "" {
+		configPaths = append([]string{path}, configPaths...)
 	}
 
-	var cfg *Config
+	cfg := &Config{}
+	var errs []error
 	for _, path := range configPaths {
-		data, err := os.ReadFile(path)
+		fileCfg, err := LoadConfigFrom(path)
+		if errors.Is(err, ErrPassphraseRequired) {
+			return nil, err
+		}
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
 		if err != nil {
+			errs = append(errs, err)
 			continue
 		}
+		cfg = fileCfg
+		break
+	}
+
+	applyEnv(cfg)
+	return cfg, errors.Join(errs...)
+}
+
+// Environment variables read by FromEnv and LoadConfig.
+const (
+	EnvUsername     = "PIKPAK_USERNAME"
+	EnvPassword     = "PIKPAK_PASSWORD"
+	EnvAccessToken  = "PIKPAK_ACCESS_TOKEN"
+	EnvRefreshToken = "PIKPAK_REFRESH_TOKEN"
+	EnvEncodedToken = "PIKPAK_ENCODED_TOKEN"
+	EnvDeviceID     = "PIKPAK_DEVICE_ID"
+	EnvProxy        = "PIKPAK_PROXY"
+	// EnvBaseURL sets both DriveBaseURL and UserBaseURL, like
+	// client.WithBaseURL.
+	EnvBaseURL = "PIKPAK_BASE_URL"
+)
+
+// FromEnv builds a Config from the PIKPAK_* environment variables alone.
+func FromEnv() (*Config, error) {
+	cfg := &Config{}
+	applyEnv(cfg)
+	return cfg, nil
+}
+
+// envField is a field of a Config and the environment variable that sets it.
+type envField struct {
+	name  string
+	field *string
+}
 
-		var c Config
-		if err := json.Unmarshal(data, &c); err != nil {
+// envFields lists the fields of cfg the environment variables set.
+func envFields(cfg *Config) []envField {
+	return []envField{
+		{EnvUsername, &cfg.Username},
+		{EnvPassword, &cfg.Password},
+		{EnvAccessToken, &cfg.AccessToken},
+		{EnvRefreshToken, &cfg.RefreshToken},
+		{EnvEncodedToken, &cfg.EncodedToken},
+		{EnvDeviceID, &cfg.DeviceID},
+		{EnvProxy, &cfg.ProxyURL},
+		{EnvBaseURL, &cfg.DriveBaseURL},
+		{EnvBaseURL, &cfg.UserBaseURL},
+	}
+}
+
+// envOverrides holds, by index in envFields, the value applyEnv set and the
+// value the field had before.
+type envOverrides struct {
+	values map[int]envOverride
+}
+
+type envOverride struct {
+	env, file string
+}
+
+// applyEnv overwrites fields of cfg with the environment variables that are
+// set and non-empty, remembering the values they replaced for forSave.
+func applyEnv(cfg *Config) {
+	for i, f := range envFields(cfg) {
+		value := os.Getenv(f.name)
+		if value == "" {
 			continue
 		}
+		if cfg.env == nil {
+			cfg.env = &envOverrides{values: map[int]envOverride{}}
+		}
+		file := *f.field
+		if prev, ok := cfg.env.values[i]; ok {
+			file = prev.file
+		}
+		cfg.env.values[i] = envOverride{env: value, file: file}
+		*f.field = value
+	}
+}
 
-		cfg = &c
-		break
+// forSave returns the config to write to disk: cfg with every field that
+// still holds its environment value reset to the value from the file, so
+// secrets kept in the environment do not end up on disk. Fields changed
+// since, such as rotated tokens, are written.
+func (cfg *Config) forSave() *Config {
+	out := *cfg
+	out.env = nil
+	if cfg.env == nil {
+		return &out
 	}
+	fields := envFields(&out)
+	for i, o := range cfg.env.values {
+		if field := fields[i].field; *field == o.env {
+			*field = o.file
+		}
+	}
+	return &out
+}
 
-	if cfg == nil {
-		cfg = &Config{}
+// LoadConfigFrom reads the config at path, or its default profile when the
+// file holds profiles. Unlike LoadConfig it reports a missing or malformed
+// file as an error, and an encrypted one as ErrPassphraseRequired.
+func LoadConfigFrom(path string) (*Config, error) {
+	f, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
 	}
 
+	profiles, name := f.profiles()
+	cfg, ok := profiles[name]
+	if !ok || cfg == nil {
+		return nil, fmt.Errorf("%s: %w: %s", path, ErrProfileNotFound, name)
+	}
+	if len(f.Profiles) > 0 {
+		cfg.profile = name
+	}
 	return cfg, nil
 }
 
+// DefaultPath is pikpakapi/config.json under the per-user config directory:
+// $XDG_CONFIG_HOME or ~/.config on Linux and other Unix systems, %AppData% on
+// Windows and ~/Library/Application Support on macOS. LoadConfig searches it
+// and SaveDefault writes to it.
+func DefaultPath() (string, error) {
+	return defaultPath(runtime.GOOS, os.Getenv)
+}
+
+// DefaultConfigPath returns DefaultPath.
+//
+// Deprecated: Use DefaultPath.
+func DefaultConfigPath() (string, error) {
+	return DefaultPath()
+}
+
+// defaultPath follows os.UserConfigDir for goos, reading the environment
+// through getenv.
+func defaultPath(goos string, getenv func(string) string) (string, error) {
+	var dir string
+	switch goos {
+	case "windows":
+		dir = getenv("AppData")
+		if dir == "" {
+			return "", errors.New("%AppData% is not defined")
+		}
+	case "darwin", "ios":
+		home := getenv("HOME")
+		if home == "" {
+			return "", errors.New("$HOME is not defined")
+		}
+		dir = filepath.Join(home, "Library", "Application Support")
+	case "plan9":
+		home := getenv("home")
+		if home == "" {
+			return "", errors.New("$home is not defined")
+		}
+		dir = filepath.Join(home, "lib")
+	default:
+		dir = getenv("XDG_CONFIG_HOME")
+		if dir == "" {
+			home := getenv("HOME")
+			if home == "" {
+				return "", errors.New("neither $XDG_CONFIG_HOME nor $HOME are defined")
+			}
+			dir = filepath.Join(home, ".config")
+		} else if !filepath.IsAbs(dir) {
+			return "", errors.New("path in $XDG_CONFIG_HOME is relative")
+		}
+	}
+	return filepath.Join(dir, "pikpakapi", "config.json"), nil
+}
+
+// SaveDefault writes cfg atomically to DefaultPath, creating its directory
+// with mode 0700. Like SaveConfigAtomic it keeps the other profiles of a
+// profiles file.
+func SaveDefault(cfg *Config) error {
+	path, err := DefaultPath()
+	if err != nil {
+		return fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return SaveConfigAtomic(cfg, path)
+}
+
+// SaveConfigAtomic writes cfg to a temporary file next to path and renames
+// it into place, so readers never see a partially written config. The file
+// gets mode 0600. Only cfg's profile is replaced: the one it was loaded from
+// or saved as, else the file's default profile. A flat config file is
+// migrated to profiles first, as with SaveProfile. A file that cannot be
+// read or parsed, or is encrypted, is left alone and its error returned.
+// Fields that still hold the value of a PIKPAK_* variable are written with
+// the value they had in the file.
+func SaveConfigAtomic(cfg *Config, path string) error {
+	f, err := readConfigFile(path)
+	if err == nil {
+		name := cfg.profile
+		if name == "" {
+			_, name = f.profiles()
+		}
+		return writeProfile(path, f, name, cfg)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cfg.forSave(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return writeFileAtomic(path, data)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
 func SaveConfig(cfg *Config, path string) error {
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	data, err := json.MarshalIndent(cfg.forSave(), "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
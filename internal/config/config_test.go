@@ -1,9 +1,13 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -106,12 +110,51 @@ func TestLoadConfig_InvalidJSON(t *testing.T) {
 	defer os.Chdir(originalWd)
 
 	cfg, err := LoadConfig()
-	if err != nil {
-		t.Fatalf("LoadConfig() returned unexpected error: %v", err)
+	if err == nil {
+		t.Fatal("Expected an error for invalid JSON")
+	}
+	if !strings.Contains(err.Error(), "config.json:1:2") {
+		t.Errorf("Expected the path and error position in %q", err)
 	}
 
-	if cfg.Username != "" {
-		t.Errorf("Username = %q, want empty string for invalid JSON", cfg.Username)
+	if cfg == nil || cfg.Username != "" {
+		t.Errorf("Expected an empty config alongside the error, got %+v", cfg)
+	}
+}
+
+func TestLoadConfig_InvalidJSONFallsThrough(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "config.json"), []byte("{\n  \"username\": 42\n}"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, ".pikpakapi.json"), []byte(`{"username": "fallback@example.com"}`), 0644)
+
+	originalWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalWd)
+
+	cfg, err := LoadConfig()
+	if err == nil || !strings.Contains(err.Error(), "config.json:2:") {
+		t.Errorf("Expected the type error with its position, got %v", err)
+	}
+	if cfg.Username != "fallback@example.com" {
+		t.Errorf("Username = %q, want the next readable config", cfg.Username)
+	}
+}
+
+func TestLoadConfig_PermissionDenied(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("file permissions are not enforced for root")
+	}
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+	os.WriteFile(configPath, []byte(`{"username": "test@example.com"}`), 0000)
+
+	originalWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalWd)
+
+	_, err := LoadConfig()
+	if !errors.Is(err, os.ErrPermission) {
+		t.Errorf("Expected a permission error, got %v", err)
 	}
 }
 
@@ -363,3 +406,422 @@ func TestSaveConfig_PreservesJSONFormatting(t *testing.T) {
 		t.Errorf("Config should start with opening brace and newline, got: %q", content[:3])
 	}
 }
+
+func TestLoadConfig_EnvVar(t *testing.T) {
+	tmpDir := t.TempDir()
+	envPath := filepath.Join(tmpDir, "daemon.json")
+	os.WriteFile(envPath, []byte(`{"username": "env@example.com"}`), 0644)
+
+	workDir := t.TempDir()
+	os.WriteFile(filepath.Join(workDir, "config.json"), []byte(`{"username": "cwd@example.com"}`), 0644)
+
+	originalWd, _ := os.Getwd()
+	os.Chdir(workDir)
+	defer os.Chdir(originalWd)
+
+	t.Setenv(ConfigEnvVar, envPath)
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() returned unexpected error: %v", err)
+	}
+	if cfg.Username != "env@example.com" {
+		t.Errorf("Username = %q, want the file named by %s", cfg.Username, ConfigEnvVar)
+	}
+
+	t.Setenv(ConfigEnvVar, filepath.Join(tmpDir, "missing.json"))
+	cfg, err = LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() returned unexpected error: %v", err)
+	}
+	if cfg.Username != "cwd@example.com" {
+		t.Errorf("Username = %q, want fallback to the search paths", cfg.Username)
+	}
+}
+
+func TestLoadConfigFrom(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "pikpak.json")
+	os.WriteFile(configPath, []byte(`{"username": "test@example.com", "device_id": "device_id_abc"}`), 0644)
+
+	cfg, err := LoadConfigFrom(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfigFrom() returned unexpected error: %v", err)
+	}
+	if cfg.Username != "test@example.com" || cfg.DeviceID != "device_id_abc" {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoadConfigFrom_BehaviorSettings(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "pikpak.json")
+	os.WriteFile(configPath, []byte(`{
+  "username": "test@example.com",
+  "proxy_url": "socks5://127.0.0.1:1080",
+  "max_retries": 5,
+  "http_timeout_ms": 1500,
+  "bandwidth_limit_bps": 1048576,
+  "added_in_a_later_version": {"nested": true}
+}`), 0644)
+
+	cfg, err := LoadConfigFrom(configPath)
+	if err != nil {
+		t.Fatalf("Expected unknown fields to be ignored, got %v", err)
+	}
+	if cfg.ProxyURL != "socks5://127.0.0.1:1080" || cfg.MaxRetries == nil || *cfg.MaxRetries != 5 || cfg.HTTPTimeoutMS != 1500 || cfg.BandwidthLimitBPS != 1048576 {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+
+	data, _ := json.Marshal(&Config{Username: "test@example.com"})
+	if strings.Contains(string(data), "max_retries") || strings.Contains(string(data), "proxy_url") {
+		t.Errorf("Expected unset behavior settings to be omitted, got %s", data)
+	}
+}
+
+func TestLoadConfigFrom_Errors(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	_, err := LoadConfigFrom(filepath.Join(tmpDir, "missing.json"))
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist, got %v", err)
+	}
+
+	badPath := filepath.Join(tmpDir, "bad.json")
+	os.WriteFile(badPath, []byte("{invalid json data"), 0644)
+	_, err = LoadConfigFrom(badPath)
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Errorf("Expected *json.SyntaxError, got %v", err)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv(EnvUsername, "env@example.com")
+	t.Setenv(EnvPassword, "env_password")
+	t.Setenv(EnvAccessToken, "env_access")
+	t.Setenv(EnvRefreshToken, "env_refresh")
+	t.Setenv(EnvEncodedToken, "env_encoded")
+	t.Setenv(EnvDeviceID, "env_device")
+
+	cfg, err := FromEnv()
+	if err != nil {
+		t.Fatalf("FromEnv() returned unexpected error: %v", err)
+	}
+	want := Config{
+		Username:     "env@example.com",
+		Password:     "env_password",
+		AccessToken:  "env_access",
+		RefreshToken: "env_refresh",
+		EncodedToken: "env_encoded",
+		DeviceID:     "env_device",
+	}
+	got := *cfg
+	got.env = nil
+	if got != want {
+		t.Errorf("FromEnv() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadConfig_EnvOverridesFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "pikpak.json")
+	os.WriteFile(configPath, []byte(`{"username": "file@example.com", "password": "file_password", "device_id": "file_device"}`), 0644)
+	t.Setenv(ConfigEnvVar, configPath)
+
+	t.Setenv(EnvPassword, "env_password")
+	t.Setenv(EnvRefreshToken, "env_refresh")
+	t.Setenv(EnvDeviceID, "")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() returned unexpected error: %v", err)
+	}
+	if cfg.Username != "file@example.com" {
+		t.Errorf("Username = %q, want the file value", cfg.Username)
+	}
+	if cfg.Password != "env_password" || cfg.RefreshToken != "env_refresh" {
+		t.Errorf("Expected env values to override the file, got %+v", cfg)
+	}
+	if cfg.DeviceID != "file_device" {
+		t.Errorf("DeviceID = %q, want empty env values to be ignored", cfg.DeviceID)
+	}
+	if cfg.AccessToken != "" {
+		t.Errorf("AccessToken = %q, want default", cfg.AccessToken)
+	}
+}
+
+func TestLoadConfig_EnvBehaviorSettings(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "pikpak.json")
+	os.WriteFile(configPath, []byte(`{"proxy_url": "http://file-proxy:8080", "drive_base_url": "https://drive.file.example", "user_base_url": "https://user.file.example", "max_retries": 5}`), 0600)
+	t.Setenv(ConfigEnvVar, configPath)
+	t.Setenv(EnvProxy, "")
+	t.Setenv(EnvBaseURL, "")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ProxyURL != "http://file-proxy:8080" || cfg.DriveBaseURL != "https://drive.file.example" || cfg.UserBaseURL != "https://user.file.example" {
+		t.Errorf("Expected the file values without environment variables, got %+v", cfg)
+	}
+
+	t.Setenv(EnvProxy, "socks5://env-proxy:1080")
+	t.Setenv(EnvBaseURL, "https://env.example")
+	cfg, err = LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ProxyURL != "socks5://env-proxy:1080" {
+		t.Errorf("ProxyURL = %q, want the environment value", cfg.ProxyURL)
+	}
+	if cfg.DriveBaseURL != "https://env.example" || cfg.UserBaseURL != "https://env.example" {
+		t.Errorf("Expected PIKPAK_BASE_URL to set both base URLs, got %q and %q", cfg.DriveBaseURL, cfg.UserBaseURL)
+	}
+	if cfg.MaxRetries == nil || *cfg.MaxRetries != 5 {
+		t.Errorf("MaxRetries = %v, want the file value", cfg.MaxRetries)
+	}
+
+	t.Setenv(ConfigEnvVar, filepath.Join(t.TempDir(), "missing.json"))
+	cfg, _ = FromEnv()
+	if cfg.ProxyURL != "socks5://env-proxy:1080" || cfg.DriveBaseURL != "https://env.example" {
+		t.Errorf("Expected FromEnv to read the behavior settings, got %+v", cfg)
+	}
+}
+
+func TestSaveConfigAtomic_KeepsEnvValuesOffDisk(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "pikpak.json")
+	os.WriteFile(configPath, []byte(`{"username": "file@example.com", "password": "file_password"}`), 0600)
+	t.Setenv(ConfigEnvVar, configPath)
+	t.Setenv(EnvPassword, "env_password")
+	t.Setenv(EnvAccessToken, "env_access")
+	t.Setenv(EnvRefreshToken, "env_refresh")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The refresh token was rotated; the access token is still the one
+	// from the environment.
+	cfg.RefreshToken = "rotated_refresh"
+	cfg.UserID = "user_id"
+	if err := SaveConfigAtomic(cfg, configPath); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	if strings.Contains(string(data), "env_") {
+		t.Errorf("Expected no environment values on disk, got %s", data)
+	}
+	saved, err := LoadConfigFrom(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Password != "file_password" || saved.AccessToken != "" {
+		t.Errorf("Expected the file values for fields from the environment, got %+v", saved)
+	}
+	if saved.RefreshToken != "rotated_refresh" || saved.UserID != "user_id" {
+		t.Errorf("Expected changed fields to be saved, got %+v", saved)
+	}
+	if cfg.Password != "env_password" {
+		t.Errorf("Expected the in-memory config to keep the environment values, got %+v", cfg)
+	}
+}
+
+func TestDefaultConfigPath_Discovery(t *testing.T) {
+	homeDir := t.TempDir()
+	configHome := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	originalWd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(originalWd)
+
+	os.WriteFile(filepath.Join(homeDir, ".pikpakapi.json"), []byte(`{"username": "legacy@example.com"}`), 0644)
+
+	cfg, _ := LoadConfig()
+	if cfg.Username != "legacy@example.com" {
+		t.Errorf("Username = %q, want the legacy home config", cfg.Username)
+	}
+
+	os.MkdirAll(filepath.Join(configHome, "pikpakapi"), 0700)
+	os.WriteFile(filepath.Join(configHome, "pikpakapi", "config.json"), []byte(`{"username": "xdg@example.com"}`), 0644)
+
+	cfg, _ = LoadConfig()
+	if cfg.Username != "xdg@example.com" {
+		t.Errorf("Username = %q, want the user config dir to win over the legacy path", cfg.Username)
+	}
+}
+
+func TestSaveDefault_CreatesDirectory(t *testing.T) {
+	configHome := filepath.Join(t.TempDir(), "config")
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	if err := SaveDefault(&Config{Username: "test@example.com"}); err != nil {
+		t.Fatalf("SaveDefault() returned unexpected error: %v", err)
+	}
+
+	dir := filepath.Join(configHome, "pikpakapi")
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatalf("Expected config directory to be created: %v", err)
+	}
+	if info.Mode().Perm() != 0700 {
+		t.Errorf("Directory mode = %v, want 0700", info.Mode().Perm())
+	}
+
+	path, _ := DefaultPath()
+	if path != filepath.Join(dir, "config.json") {
+		t.Errorf("DefaultPath() = %q", path)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the config to be written with mode 0600, got %v, %v", info, err)
+	}
+	cfg, err := LoadConfigFrom(path)
+	if err != nil || cfg.Username != "test@example.com" {
+		t.Errorf("Expected saved config to load back, got %+v, %v", cfg, err)
+	}
+}
+
+func TestSaveDefault_KeepsOtherProfiles(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(ConfigEnvVar, "")
+
+	if err := SaveProfile("personal", &Config{Username: "me@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveProfile("work", &Config{Username: "work@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveDefault(&Config{Username: "me@example.com", AccessToken: "new_access"}); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := ListProfiles()
+	if err != nil || len(names) != 2 {
+		t.Fatalf("Expected both profiles to be kept, got %v, %v", names, err)
+	}
+	personal, _ := LoadProfile("personal")
+	work, _ := LoadProfile("work")
+	if personal == nil || personal.AccessToken != "new_access" {
+		t.Errorf("Expected the default profile to be replaced, got %+v", personal)
+	}
+	if work == nil || work.Username != "work@example.com" {
+		t.Errorf("Expected the work profile to be kept, got %+v", work)
+	}
+
+	// A config loaded from a profile is written back to that profile.
+	work.AccessToken = "work_access"
+	if err := SaveDefault(work); err != nil {
+		t.Fatal(err)
+	}
+	personal, _ = LoadProfile("personal")
+	work, _ = LoadProfile("work")
+	if work.AccessToken != "work_access" || personal.AccessToken != "new_access" {
+		t.Errorf("Expected only the work profile to change, got %+v and %+v", work, personal)
+	}
+}
+
+func TestSaveConfigAtomic(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+	os.WriteFile(configPath, []byte(`{"username": "old@example.com", "password": "a much longer old password"}`), 0644)
+
+	if err := SaveConfigAtomic(&Config{Username: "new@example.com"}, configPath); err != nil {
+		t.Fatalf("SaveConfigAtomic() returned unexpected error: %v", err)
+	}
+
+	cfg, err := LoadConfigFrom(configPath)
+	if err != nil || cfg.Username != "new@example.com" || cfg.Password != "" {
+		t.Errorf("Expected the new config, got %+v, %v", cfg, err)
+	}
+
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 1 {
+		t.Errorf("Expected no temporary files to be left, got %d entries", len(entries))
+	}
+
+	if err := SaveConfigAtomic(&Config{}, filepath.Join(tmpDir, "missing", "config.json")); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+func TestSaveConfigAtomic_MigratesFlatFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(configPath, []byte(`{"username": "old@example.com"}`), 0600)
+
+	if err := SaveConfigAtomic(&Config{Username: "new@example.com"}, configPath); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	var raw map[string]json.RawMessage
+	json.Unmarshal(data, &raw)
+	if _, flat := raw["username"]; flat || string(raw["default_profile"]) != `"default"` {
+		t.Errorf("Expected the flat file to be migrated to the default profile, got %s", data)
+	}
+	cfg, err := LoadConfigFrom(configPath)
+	if err != nil || cfg.Username != "new@example.com" {
+		t.Errorf("Expected the new config as the default profile, got %+v, %v", cfg, err)
+	}
+}
+
+func TestSaveConfigAtomic_KeepsUnreadableFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		wantErr error
+	}{
+		{"malformed", []byte(`{"profiles": {"work": `), nil},
+		{"encrypted", nil, ErrPassphraseRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.json")
+			if tt.content != nil {
+				os.WriteFile(configPath, tt.content, 0600)
+			} else if err := SaveEncrypted(&Config{Username: "me@example.com"}, configPath, "secret"); err != nil {
+				t.Fatal(err)
+			}
+			before, _ := os.ReadFile(configPath)
+
+			err := SaveConfigAtomic(&Config{Username: "new@example.com"}, configPath)
+			if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("Expected an error, got %v", err)
+			}
+			if after, _ := os.ReadFile(configPath); !bytes.Equal(before, after) {
+				t.Error("Expected the file to be left alone")
+			}
+		})
+	}
+}
+
+func TestDefaultPath_PerOS(t *testing.T) {
+	tests := []struct {
+		goos string
+		env  map[string]string
+		want string
+	}{
+		{"linux", map[string]string{"XDG_CONFIG_HOME": "/xdg", "HOME": "/home/user"}, filepath.Join("/xdg", "pikpakapi", "config.json")},
+		{"linux", map[string]string{"HOME": "/home/user"}, filepath.Join("/home/user", ".config", "pikpakapi", "config.json")},
+		{"darwin", map[string]string{"HOME": "/Users/user", "XDG_CONFIG_HOME": "/xdg"}, filepath.Join("/Users/user", "Library", "Application Support", "pikpakapi", "config.json")},
+		{"windows", map[string]string{"AppData": `C:\Users\user\AppData\Roaming`}, filepath.Join(`C:\Users\user\AppData\Roaming`, "pikpakapi", "config.json")},
+	}
+
+	for _, tt := range tests {
+		getenv := func(key string) string { return tt.env[key] }
+		got, err := defaultPath(tt.goos, getenv)
+		if err != nil || got != tt.want {
+			t.Errorf("defaultPath(%q, %v) = %q, %v, want %q", tt.goos, tt.env, got, err, tt.want)
+		}
+	}
+
+	for _, goos := range []string{"linux", "darwin", "windows"} {
+		if _, err := defaultPath(goos, func(string) string { return "" }); err == nil {
+			t.Errorf("defaultPath(%q) expected an error without the environment", goos)
+		}
+	}
+	if _, err := defaultPath("linux", func(key string) string { return map[string]string{"XDG_CONFIG_HOME": "relative"}[key] }); err == nil {
+		t.Error("Expected a relative $XDG_CONFIG_HOME to be rejected")
+	}
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	ErrPassphraseRequired = errors.New("config is encrypted: passphrase required")
+	ErrWrongPassphrase    = errors.New("wrong passphrase for encrypted config")
+	ErrCorruptConfig      = errors.New("encrypted config is corrupt")
+)
+
+// Encrypted configs start with encryptedMagic and a version byte. Version 1
+// is followed by the scrypt parameters (log2 N, r, p), a 16 byte salt, a 16
+// byte passphrase check and the AES-256-GCM nonce; the rest is the sealed
+// JSON. The whole header is authenticated as additional data.
+const (
+	encryptedMagic   = "PIKPAKENC"
+	encryptedVersion = 1
+
+	saltSize  = 16
+	checkSize = 16
+	nonceSize = 12
+
+	headerSize = len(encryptedMagic) + 4 + saltSize + checkSize + nonceSize
+
+	// A file's parameters are checked against these bounds before deriving
+	// its key, so a damaged header cannot make scrypt allocate gigabytes or
+	// spin for minutes: scrypt needs about 128·r·(N+p) bytes.
+	maxScryptLogN   = 20
+	maxScryptP      = 16
+	maxScryptMemory = 256 << 20
+)
+
+// scryptLogN, scryptR and scryptP are the key derivation cost for new files.
+var (
+	scryptLogN = 15
+	scryptR    = 8
+	scryptP    = 1
+)
+
+// deriveKeys returns the AES key and the check value that tells a wrong
+// passphrase apart from a damaged file.
+func deriveKeys(passphrase string, salt []byte, logN, r, p int) (key, check []byte, err error) {
+	material, err := scrypt.Key([]byte(passphrase), salt, 1<<logN, r, p, 64)
+	if err != nil {
+		return nil, nil, err
+	}
+	sum := sha256.Sum256(material[32:])
+	return material[:32], sum[:checkSize], nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SaveEncrypted writes cfg to path encrypted with a key derived from
+// passphrase. Like SaveConfigAtomic it replaces the file atomically with one
+// of mode 0600.
+func SaveEncrypted(cfg *Config, path string, passphrase string) error {
+	plaintext, err := json.Marshal(cfg.forSave())
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	header := make([]byte, 0, headerSize)
+	header = append(header, encryptedMagic...)
+	header = append(header, encryptedVersion, byte(scryptLogN), byte(scryptR), byte(scryptP))
+
+	salt := make([]byte, saltSize)
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	key, check, err := deriveKeys(passphrase, salt, scryptLogN, scryptR, scryptP)
+	if err != nil {
+		return fmt.Errorf("failed to derive key: %w", err)
+	}
+	header = append(header, salt...)
+	header = append(header, check...)
+	header = append(header, nonce...)
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt config: %w", err)
+	}
+	data := gcm.Seal(header, nonce, plaintext, header)
+
+	return writeFileAtomic(path, data)
+}
+
+// LoadEncrypted reads a config written by SaveEncrypted. It returns
+// ErrWrongPassphrase when passphrase does not match and ErrCorruptConfig when
+// the file is damaged or was modified.
+func LoadEncrypted(path string, passphrase string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if !isEncrypted(data) {
+		return nil, fmt.Errorf("%s: %w: missing header", path, ErrCorruptConfig)
+	}
+	if len(data) < headerSize {
+		return nil, fmt.Errorf("%s: %w: truncated header", path, ErrCorruptConfig)
+	}
+
+	params := data[len(encryptedMagic):]
+	if version := params[0]; version != encryptedVersion {
+		return nil, fmt.Errorf("%s: %w: unsupported version %d", path, ErrCorruptConfig, version)
+	}
+	logN, r, p := int(params[1]), int(params[2]), int(params[3])
+	if logN < 1 || logN > maxScryptLogN || r == 0 || p == 0 || p > maxScryptP {
+		return nil, fmt.Errorf("%s: %w: invalid key derivation parameters", path, ErrCorruptConfig)
+	}
+	if 128*r*((1<<logN)+p) > maxScryptMemory {
+		return nil, fmt.Errorf("%s: %w: key derivation parameters exceed the memory limit", path, ErrCorruptConfig)
+	}
+
+	rest := params[4:]
+	salt := rest[:saltSize]
+	storedCheck := rest[saltSize : saltSize+checkSize]
+	nonce := rest[saltSize+checkSize : saltSize+checkSize+nonceSize]
+
+	key, check, err := deriveKeys(passphrase, salt, logN, r, p)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %v", path, ErrCorruptConfig, err)
+	}
+	if subtle.ConstantTimeCompare(check, storedCheck) != 1 {
+		return nil, fmt.Errorf("%s: %w", path, ErrWrongPassphrase)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt config: %w", err)
+	}
+	plaintext, err := gcm.Open(nil, nonce, data[headerSize:], data[:headerSize])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: authentication failed", path, ErrCorruptConfig)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(plaintext, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w: %v", path, ErrCorruptConfig, err)
+	}
+	return &cfg, nil
+}
+
+func isEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(encryptedMagic))
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func useFastScrypt(t *testing.T) {
+	t.Helper()
+	original := scryptLogN
+	scryptLogN = 10
+	t.Cleanup(func() { scryptLogN = original })
+}
+
+func TestEncryptedConfig_RoundTrip(t *testing.T) {
+	useFastScrypt(t)
+	path := filepath.Join(t.TempDir(), "config.enc")
+	cfg := &Config{Username: "test@example.com", RefreshToken: "refresh_token_456"}
+
+	if err := SaveEncrypted(cfg, path, "correct horse"); err != nil {
+		t.Fatalf("SaveEncrypted() returned unexpected error: %v", err)
+	}
+
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("File mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	data, _ := os.ReadFile(path)
+	if bytes.Contains(data, []byte("refresh_token_456")) {
+		t.Error("Expected the refresh token not to be stored in plaintext")
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Expected no temporary files to be left, got %d entries", len(entries))
+	}
+
+	loaded, err := LoadEncrypted(path, "correct horse")
+	if err != nil {
+		t.Fatalf("LoadEncrypted() returned unexpected error: %v", err)
+	}
+	if *loaded != *cfg {
+		t.Errorf("LoadEncrypted() = %+v, want %+v", *loaded, *cfg)
+	}
+}
+
+// testdata/config.enc was written by SaveEncrypted with log N 10 and the key
+// derived by golang.org/x/crypto/scrypt. It pins the file format and the key
+// derivation.
+func TestLoadEncrypted_Fixture(t *testing.T) {
+	cfg, err := LoadEncrypted(filepath.Join("testdata", "config.enc"), "fixture passphrase")
+	if err != nil {
+		t.Fatalf("LoadEncrypted() returned unexpected error: %v", err)
+	}
+	if cfg.Username != "fixture@example.com" || cfg.RefreshToken != "fixture_refresh" {
+		t.Errorf("LoadEncrypted() = %+v, want the fixture account", *cfg)
+	}
+	if _, err := LoadEncrypted(filepath.Join("testdata", "config.enc"), "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Expected ErrWrongPassphrase, got %v", err)
+	}
+}
+
+func TestEncryptedConfig_WrongPassphrase(t *testing.T) {
+	useFastScrypt(t)
+	path := filepath.Join(t.TempDir(), "config.enc")
+	SaveEncrypted(&Config{Username: "test@example.com"}, path, "correct horse")
+
+	_, err := LoadEncrypted(path, "battery staple")
+	if !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Expected ErrWrongPassphrase, got %v", err)
+	}
+	if errors.Is(err, ErrCorruptConfig) {
+		t.Error("Expected a wrong passphrase not to be reported as corruption")
+	}
+}
+
+func TestEncryptedConfig_TamperDetection(t *testing.T) {
+	useFastScrypt(t)
+	path := filepath.Join(t.TempDir(), "config.enc")
+	SaveEncrypted(&Config{Username: "test@example.com"}, path, "correct horse")
+	original, _ := os.ReadFile(path)
+
+	tests := []struct {
+		name   string
+		offset int
+	}{
+		{"ciphertext", headerSize + 2},
+		{"tag", len(original) - 1},
+		{"nonce", headerSize - 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := append([]byte(nil), original...)
+			data[tt.offset] ^= 0x01
+			os.WriteFile(path, data, 0600)
+
+			_, err := LoadEncrypted(path, "correct horse")
+			if !errors.Is(err, ErrCorruptConfig) {
+				t.Errorf("Expected ErrCorruptConfig, got %v", err)
+			}
+		})
+	}
+
+	os.WriteFile(path, original[:headerSize-4], 0600)
+	if _, err := LoadEncrypted(path, "correct horse"); !errors.Is(err, ErrCorruptConfig) {
+		t.Errorf("Expected ErrCorruptConfig for a truncated file, got %v", err)
+	}
+
+	data := append([]byte(nil), original...)
+	data[len(encryptedMagic)] = 99
+	os.WriteFile(path, data, 0600)
+	if _, err := LoadEncrypted(path, "correct horse"); !errors.Is(err, ErrCorruptConfig) {
+		t.Errorf("Expected ErrCorruptConfig for an unknown version, got %v", err)
+	}
+
+	for _, params := range [][3]byte{{maxScryptLogN, 255, 1}, {10, 8, 255}} {
+		data := append([]byte(nil), original...)
+		copy(data[len(encryptedMagic)+1:], params[:])
+		os.WriteFile(path, data, 0600)
+		if _, err := LoadEncrypted(path, "correct horse"); !errors.Is(err, ErrCorruptConfig) {
+			t.Errorf("Expected ErrCorruptConfig for scrypt parameters %v, got %v", params, err)
+		}
+	}
+}
+
+func TestLoadConfig_EncryptedRequiresPassphrase(t *testing.T) {
+	useFastScrypt(t)
+	path := filepath.Join(t.TempDir(), "config.enc")
+	SaveEncrypted(&Config{Username: "test@example.com"}, path, "correct horse")
+	t.Setenv(ConfigEnvVar, path)
+
+	if _, err := LoadConfig(); !errors.Is(err, ErrPassphraseRequired) {
+		t.Errorf("Expected LoadConfig to return ErrPassphraseRequired, got %v", err)
+	}
+	if _, err := LoadConfigFrom(path); !errors.Is(err, ErrPassphraseRequired) {
+		t.Errorf("Expected LoadConfigFrom to return ErrPassphraseRequired, got %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// DefaultProfile is the profile a flat, single account config becomes when
+// it is migrated to the profiles format.
+const DefaultProfile = "default"
+
+var ErrProfileNotFound = errors.New("profile not found")
+
+// configFile is the on-disk format. A file either holds a single Config at
+// the top level (the legacy format) or named Profiles.
+type configFile struct {
+	Config
+	Profiles       map[string]*Config `json:"profiles,omitempty"`
+	DefaultProfile string             `json:"default_profile,omitempty"`
+}
+
+// profiles returns the profiles in f, treating a flat config as the single
+// DefaultProfile.
+func (f *configFile) profiles() (map[string]*Config, string) {
+	if len(f.Profiles) > 0 {
+		name := f.DefaultProfile
+		if name == "" {
+			name = DefaultProfile
+		}
+		return f.Profiles, name
+	}
+	flat := f.Config
+	return map[string]*Config{DefaultProfile: &flat}, DefaultProfile
+}
+
+func parseConfigFile(path string, data []byte) (*configFile, error) {
+	var f configFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s%s: %w", path, errorPosition(data, err), err)
+	}
+	return &f, nil
+}
+
+// errorPosition returns ":line:column" for JSON errors that carry a byte
+// offset into data, or "" for those that do not.
+func errorPosition(data []byte, err error) string {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return ""
+	}
+	// Offset counts the bytes read before the error, including the byte
+	// that caused it.
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset > 0 {
+		offset--
+	}
+
+	line, col := 1, 1
+	for _, b := range data[:offset] {
+		if b == '\n' {
+			line, col = line+1, 1
+		} else {
+			col++
+		}
+	}
+	return fmt.Sprintf(":%d:%d", line, col)
+}
+
+func readConfigFile(path string) (*configFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if isEncrypted(data) {
+		return nil, fmt.Errorf("%s: %w", path, ErrPassphraseRequired)
+	}
+	return parseConfigFile(path, data)
+}
+
+// profilesPath is the file holding profiles: the one named by PIKPAK_CONFIG,
+// else DefaultPath.
+func profilesPath() (string, error) {
+	if path := os.Getenv(ConfigEnvVar); path != "" {
+		return path, nil
+	}
+	return DefaultPath()
+}
+
+// LoadProfile returns the named profile. An empty name selects the file's
+// default_profile. A flat config file is read as the single "default"
+// profile.
+func LoadProfile(name string) (*Config, error) {
+	path, err := profilesPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, defaultName := f.profiles()
+	if name == "" {
+		name = defaultName
+	}
+	cfg, ok := profiles[name]
+	if !ok || cfg == nil {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+	cfg.profile = name
+	applyEnv(cfg)
+	return cfg, nil
+}
+
+// DefaultProfileName returns the name LoadProfile("") selects: the file's
+// default_profile, or DefaultProfile when there is no file yet or it holds a
+// flat config.
+func DefaultProfileName() (string, error) {
+	path, err := profilesPath()
+	if err != nil {
+		return "", err
+	}
+	f, err := readConfigFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultProfile, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	_, name := f.profiles()
+	return name, nil
+}
+
+// ListProfiles returns the profile names in the config file, sorted.
+func ListProfiles() ([]string, error) {
+	path, err := profilesPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := readConfigFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, _ := f.profiles()
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// SaveProfile stores cfg as the named profile, creating the file if needed.
+// A flat config file is migrated first: its account becomes the "default"
+// profile and stays the default.
+func SaveProfile(name string, cfg *Config) error {
+	if name == "" {
+		return fmt.Errorf("profile name is required")
+	}
+	path, err := profilesPath()
+	if err != nil {
+		return err
+	}
+
+	f, err := readConfigFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		f = &configFile{DefaultProfile: name}
+	case err != nil:
+		return err
+	}
+	return writeProfile(path, f, name, cfg)
+}
+
+// writeProfile stores cfg as the named profile of f, which was read from
+// path, and writes the file back. A flat config in f becomes the "default"
+// profile.
+func writeProfile(path string, f *configFile, name string, cfg *Config) error {
+	profiles, defaultName := f.profiles()
+	if len(f.Profiles) == 0 && f.Config == (Config{}) {
+		profiles, defaultName = map[string]*Config{}, name
+	}
+	cfg.profile = name
+	profiles[name] = cfg.forSave()
+
+	out := struct {
+		Profiles       map[string]*Config `json:"profiles"`
+		DefaultProfile string             `json:"default_profile"`
+	}{profiles, defaultName}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return writeFileAtomic(path, data)
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func useConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if content != "" {
+		os.WriteFile(path, []byte(content), 0644)
+	}
+	t.Setenv(ConfigEnvVar, path)
+	return path
+}
+
+func TestProfiles_LegacyRead(t *testing.T) {
+	useConfigFile(t, `{"username": "legacy@example.com", "password": "password"}`)
+
+	names, err := ListProfiles()
+	if err != nil || !reflect.DeepEqual(names, []string{DefaultProfile}) {
+		t.Errorf("ListProfiles() = %v, %v, want [default]", names, err)
+	}
+
+	for _, name := range []string{"", DefaultProfile} {
+		cfg, err := LoadProfile(name)
+		if err != nil || cfg.Username != "legacy@example.com" {
+			t.Errorf("LoadProfile(%q) = %+v, %v", name, cfg, err)
+		}
+	}
+
+	if _, err := LoadProfile("work"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("Expected ErrProfileNotFound, got %v", err)
+	}
+}
+
+func TestProfiles_CRUD(t *testing.T) {
+	path := useConfigFile(t, "")
+
+	if names, err := ListProfiles(); err != nil || len(names) != 0 {
+		t.Errorf("Expected no profiles without a file, got %v, %v", names, err)
+	}
+
+	if err := SaveProfile("personal", &Config{Username: "me@example.com"}); err != nil {
+		t.Fatalf("SaveProfile() returned unexpected error: %v", err)
+	}
+	if err := SaveProfile("work", &Config{Username: "me@work.example.com"}); err != nil {
+		t.Fatalf("SaveProfile() returned unexpected error: %v", err)
+	}
+	SaveProfile("work", &Config{Username: "me@work.example.com", RefreshToken: "refresh"})
+
+	names, _ := ListProfiles()
+	if !reflect.DeepEqual(names, []string{"personal", "work"}) {
+		t.Errorf("ListProfiles() = %v", names)
+	}
+
+	cfg, _ := LoadProfile("")
+	if cfg == nil || cfg.Username != "me@example.com" {
+		t.Errorf("Expected the first saved profile to be the default, got %+v", cfg)
+	}
+	cfg, _ = LoadProfile("work")
+	if cfg == nil || cfg.RefreshToken != "refresh" {
+		t.Errorf("Expected the updated work profile, got %+v", cfg)
+	}
+
+	loaded, err := LoadConfigFrom(path)
+	if err != nil || loaded.Username != "me@example.com" {
+		t.Errorf("Expected LoadConfigFrom to read the default profile, got %+v, %v", loaded, err)
+	}
+
+	if err := SaveProfile("", &Config{}); err == nil {
+		t.Error("Expected an error for an empty profile name")
+	}
+}
+
+func TestProfiles_MigrateFlatFormat(t *testing.T) {
+	path := useConfigFile(t, `{"username": "legacy@example.com", "refresh_token": "legacy_refresh"}`)
+
+	if err := SaveProfile("work", &Config{Username: "me@work.example.com"}); err != nil {
+		t.Fatalf("SaveProfile() returned unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	var raw map[string]json.RawMessage
+	json.Unmarshal(data, &raw)
+	if _, flat := raw["username"]; flat {
+		t.Error("Expected flat fields to be removed after migration")
+	}
+	if string(raw["default_profile"]) != `"default"` {
+		t.Errorf("Expected the legacy account to stay the default, got %s", raw["default_profile"])
+	}
+
+	cfg, err := LoadProfile(DefaultProfile)
+	if err != nil || cfg.Username != "legacy@example.com" || cfg.RefreshToken != "legacy_refresh" {
+		t.Errorf("Expected the legacy config as the default profile, got %+v, %v", cfg, err)
+	}
+	if cfg, _ := LoadProfile("work"); cfg == nil || cfg.Username != "me@work.example.com" {
+		t.Errorf("Expected the new profile, got %+v", cfg)
+	}
+}
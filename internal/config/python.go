@@ -0,0 +1,117 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/zhz8888/pikpakapi-go/internal/token"
+)
+
+// pythonFields maps the keys of a Python pikpakapi dump, including those in
+// its nested "token" object, onto Config fields. Keys mapped to nil are
+// known but carry nothing we keep.
+var pythonFields = map[string]func(*Config) *string{
+	"username":      func(c *Config) *string { return &c.Username },
+	"password":      func(c *Config) *string { return &c.Password },
+	"access_token":  func(c *Config) *string { return &c.AccessToken },
+	"refresh_token": func(c *Config) *string { return &c.RefreshToken },
+	"encoded_token": func(c *Config) *string { return &c.EncodedToken },
+	"user_id":       func(c *Config) *string { return &c.UserID },
+	"sub":           func(c *Config) *string { return &c.UserID },
+	"device_id":     func(c *Config) *string { return &c.DeviceID },
+	"captcha_token": func(c *Config) *string { return &c.CaptchaToken },
+	"token_type":    nil,
+	"expires_in":    nil,
+}
+
+// ImportError lists what ImportPython could not map. The Config returned
+// with it holds everything that could.
+type ImportError struct {
+	Path    string
+	Unknown []string
+	Missing []string
+}
+
+func (e *ImportError) Error() string {
+	var parts []string
+	if len(e.Unknown) > 0 {
+		parts = append(parts, "unknown fields: "+strings.Join(e.Unknown, ", "))
+	}
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing fields: "+strings.Join(e.Missing, ", "))
+	}
+	return fmt.Sprintf("import %s: %s", e.Path, strings.Join(parts, "; "))
+}
+
+// ImportPython reads a config saved by the Python pikpakapi library. Tokens
+// may be top-level, nested in a "token" object or only present as the
+// encoded token, which uses the same format as ours. When fields are unknown
+// or required ones are missing, the imported Config is returned together
+// with an *ImportError describing them.
+func ImportPython(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s%s: %w", path, errorPosition(data, err), err)
+	}
+
+	cfg := &Config{}
+	importErr := &ImportError{Path: path}
+	importPythonFields(cfg, raw, "", importErr)
+
+	if cfg.EncodedToken != "" {
+		decoded, err := token.Decode(cfg.EncodedToken)
+		if err != nil {
+			return nil, fmt.Errorf("import %s: invalid encoded_token: %w", path, err)
+		}
+		if cfg.AccessToken == "" && cfg.RefreshToken == "" {
+			cfg.AccessToken, cfg.RefreshToken = decoded.AccessToken, decoded.RefreshToken
+		}
+	}
+
+	if cfg.Username == "" {
+		importErr.Missing = append(importErr.Missing, "username")
+	}
+	if cfg.Password == "" && cfg.RefreshToken == "" {
+		importErr.Missing = append(importErr.Missing, "password or refresh_token")
+	}
+
+	if len(importErr.Unknown) > 0 || len(importErr.Missing) > 0 {
+		sort.Strings(importErr.Unknown)
+		return cfg, importErr
+	}
+	return cfg, nil
+}
+
+func importPythonFields(cfg *Config, raw map[string]json.RawMessage, prefix string, importErr *ImportError) {
+	var nested map[string]json.RawMessage
+	for key, value := range raw {
+		if key == "token" && prefix == "" && json.Unmarshal(value, &nested) == nil {
+			continue
+		}
+
+		field, known := pythonFields[key]
+		if !known {
+			importErr.Unknown = append(importErr.Unknown, prefix+key)
+			continue
+		}
+		var s string
+		if field == nil || string(value) == "null" || json.Unmarshal(value, &s) != nil || s == "" {
+			continue
+		}
+		if target := field(cfg); *target == "" {
+			*target = s
+		}
+	}
+
+	// Top-level fields win over the nested token object.
+	if nested != nil {
+		importPythonFields(cfg, nested, "token.", importErr)
+	}
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/zhz8888/pikpakapi-go/internal/token"
+)
+
+// pythonDump is a config as saved by the Python pikpakapi library, with the
+// login response kept as a nested token object.
+const pythonDump = `{
+  "username": "test@example.com",
+  "password": "password",
+  "encoded_token": "eyJhY2Nlc3NfdG9rZW4iOiAiYWNjZXNzX3Rva2VuX2FiYyIsICJyZWZyZXNoX3Rva2VuIjogInJlZnJlc2hfdG9rZW5fYWJjIn0=",
+  "device_id": "0123456789abcdef0123456789abcdef",
+  "captcha_token": "ck0.captcha",
+  "token": {
+    "token_type": "Bearer",
+    "access_token": "access_token_abc",
+    "refresh_token": "refresh_token_abc",
+    "expires_in": 7200,
+    "sub": "user_id_abc"
+  }
+}`
+
+func writePythonDump(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "pikpak.json")
+	os.WriteFile(path, []byte(content), 0644)
+	return path
+}
+
+func TestImportPython(t *testing.T) {
+	cfg, err := ImportPython(writePythonDump(t, pythonDump))
+	if err != nil {
+		t.Fatalf("ImportPython() returned unexpected error: %v", err)
+	}
+
+	want := &Config{
+		Username:     "test@example.com",
+		Password:     "password",
+		AccessToken:  "access_token_abc",
+		RefreshToken: "refresh_token_abc",
+		EncodedToken: "eyJhY2Nlc3NfdG9rZW4iOiAiYWNjZXNzX3Rva2VuX2FiYyIsICJyZWZyZXNoX3Rva2VuIjogInJlZnJlc2hfdG9rZW5fYWJjIn0=",
+		DeviceID:     "0123456789abcdef0123456789abcdef",
+		CaptchaToken: "ck0.captcha",
+		UserID:       "user_id_abc",
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("ImportPython() = %+v, want %+v", cfg, want)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := SaveConfig(cfg, path); err != nil {
+		t.Fatalf("SaveConfig() returned unexpected error: %v", err)
+	}
+	loaded, err := LoadConfigFrom(path)
+	if err != nil || !reflect.DeepEqual(loaded, want) {
+		t.Errorf("Round trip = %+v, %v, want %+v", loaded, err, want)
+	}
+}
+
+func TestImportPython_EncodedTokenOnly(t *testing.T) {
+	encoded, _ := token.Encode("access", "refresh")
+	cfg, err := ImportPython(writePythonDump(t, `{"username": "test@example.com", "encoded_token": "`+encoded+`", "user_id": "user_id_abc"}`))
+	if err != nil {
+		t.Fatalf("ImportPython() returned unexpected error: %v", err)
+	}
+	if cfg.AccessToken != "access" || cfg.RefreshToken != "refresh" || cfg.UserID != "user_id_abc" {
+		t.Errorf("Expected tokens from the encoded token, got %+v", cfg)
+	}
+}
+
+func TestImportPython_UnknownAndMissing(t *testing.T) {
+	cfg, err := ImportPython(writePythonDump(t, `{
+  "password": "password",
+  "httpx_client_args": {"proxy": "http://127.0.0.1:7890"},
+  "token": {"access_token": "access", "scope": "user"}
+}`))
+
+	var importErr *ImportError
+	if !errors.As(err, &importErr) {
+		t.Fatalf("Expected *ImportError, got %v", err)
+	}
+	if !reflect.DeepEqual(importErr.Unknown, []string{"httpx_client_args", "token.scope"}) {
+		t.Errorf("Unknown = %v", importErr.Unknown)
+	}
+	if !reflect.DeepEqual(importErr.Missing, []string{"username"}) {
+		t.Errorf("Missing = %v", importErr.Missing)
+	}
+	if cfg == nil || cfg.Password != "password" || cfg.AccessToken != "access" {
+		t.Errorf("Expected the mapped fields alongside the error, got %+v", cfg)
+	}
+}
+
+func TestImportPython_Errors(t *testing.T) {
+	if _, err := ImportPython(filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a not-exist error, got %v", err)
+	}
+	if _, err := ImportPython(writePythonDump(t, `{"username": `)); err == nil {
+		t.Error("Expected a parse error")
+	}
+	if _, err := ImportPython(writePythonDump(t, `{"username": "test@example.com", "encoded_token": "not-base64!"}`)); err == nil {
+		t.Error("Expected an error for an invalid encoded token")
+	}
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// watchPollInterval is how often Watch checks the config file for changes.
+var watchPollInterval = time.Second
+
+// fileStamp identifies one version of a file by its size and mtime.
+type fileStamp struct {
+	exists  bool
+	size    int64
+	modTime int64
+}
+
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{exists: true, size: info.Size(), modTime: info.ModTime().UnixNano()}
+}
+
+// Watch polls the config at path and calls onChange with the new config,
+// as read by LoadConfigFrom, each time its content changes. A change is only
+// read once the file has stayed the same for a full poll interval, so a burst
+// of writes produces one call; states that fail to parse, such as a
+// half-written file, are skipped. The file must be readable when Watch is
+// called. Polling runs in its own goroutine until ctx is done.
+func Watch(ctx context.Context, path string, onChange func(*Config)) error {
+	last, err := LoadConfigFrom(path)
+	if err != nil {
+		return err
+	}
+	seen := statFile(path)
+
+	go func() {
+		ticker := time.NewTicker(watchPollInterval)
+		defer ticker.Stop()
+
+		pending := seen
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			stamp := statFile(path)
+			if stamp == seen {
+				pending = seen
+				continue
+			}
+			if stamp != pending {
+				pending = stamp
+				continue
+			}
+
+			seen = stamp
+			cfg, err := LoadConfigFrom(path)
+			if err != nil || *cfg == *last {
+				continue
+			}
+			last = cfg
+			if ctx.Err() == nil {
+				onChange(cfg)
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func useFastWatch(t *testing.T) {
+	t.Helper()
+	old := watchPollInterval
+	watchPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { watchPollInterval = old })
+}
+
+func TestWatch(t *testing.T) {
+	useFastWatch(t)
+	path := filepath.Join(t.TempDir(), "config.json")
+	SaveConfig(&Config{RefreshToken: "old_refresh"}, path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan *Config, 10)
+	if err := Watch(ctx, path, func(cfg *Config) { changes <- cfg }); err != nil {
+		t.Fatalf("Watch() returned unexpected error: %v", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	os.WriteFile(path, []byte(`{"refresh_token": "half`), 0644)
+	SaveConfig(&Config{RefreshToken: "intermediate"}, path)
+	SaveConfig(&Config{RefreshToken: "new_refresh", AccessToken: "new_access"}, path)
+
+	select {
+	case cfg := <-changes:
+		if cfg.RefreshToken != "new_refresh" || cfg.AccessToken != "new_access" {
+			t.Errorf("Expected the final config, got %+v", cfg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a change notification")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if len(changes) != 0 {
+		t.Errorf("Expected exactly one notification, got %d more", len(changes))
+	}
+
+	os.WriteFile(path, []byte(`{not json`), 0644)
+	time.Sleep(100 * time.Millisecond)
+	if len(changes) != 0 {
+		t.Error("Expected an unparseable config to be ignored")
+	}
+
+	cancel()
+	time.Sleep(30 * time.Millisecond)
+	SaveConfig(&Config{RefreshToken: "after_cancel"}, path)
+	time.Sleep(100 * time.Millisecond)
+	if len(changes) != 0 {
+		t.Error("Expected no notifications after ctx is done")
+	}
+}
+
+func TestWatch_MissingFile(t *testing.T) {
+	if err := Watch(context.Background(), filepath.Join(t.TempDir(), "missing.json"), func(*Config) {}); err == nil {
+		t.Error("Expected an error for a missing config")
+	}
+}
@@ -8,5 +8,6 @@ const (
 	SDKVersion    = "2.0.4.204000"
 	AppName       = PackageName
 	APIHost       = "api-drive.mypikpak.com"
+	APIHostNet    = "api-drive.mypikpak.net"
 	UserHost      = "user.mypikpak.com"
 )
@@ -7,12 +7,15 @@ import (
 
 	"github.com/zhz8888/pikpakapi-go/internal/constants"
 	"github.com/zhz8888/pikpakapi-go/internal/exception"
+	"github.com/zhz8888/pikpakapi-go/internal/file"
+	"github.com/zhz8888/pikpakapi-go/internal/query"
 	"github.com/zhz8888/pikpakapi-go/pkg/enums"
 )
 
 type Download struct {
-	httpClient HTTPClient
-	baseURL    string
+	httpClient    HTTPClient
+	baseURL       string
+	thumbnailSize enums.ThumbnailSize
 }
 
 type HTTPClient interface {
@@ -42,6 +45,14 @@ func WithDownloadBaseURL(baseURL string) DownloadOption {
 	}
 }
 
+// WithDownloadThumbnailSize sets the thumbnail size OfflineFileInfo requests
+// in place of its default.
+func WithDownloadThumbnailSize(size enums.ThumbnailSize) DownloadOption {
+	return func(d *Download) {
+		d.thumbnailSize = size
+	}
+}
+
 func (d *Download) SetHTTPClient(client HTTPClient) {
 	d.httpClient = client
 }
@@ -53,7 +64,7 @@ func (d *Download) getBaseURL() string {
 	return "https://" + constants.APIHost
 }
 
-func (d *Download) OfflineDownload(ctx context.Context, fileURL string, parentID string, name string) (map[string]interface{}, error) {
+func (d *Download) OfflineDownload(ctx context.Context, fileURL string, parentID string, name string, folderType enums.FolderType) (map[string]interface{}, error) {
 	if fileURL == "" {
 		return nil, exception.NewPikpakExceptionWithMessage(exception.ErrCodeInvalidURL, "file url is required")
 	}
@@ -61,17 +72,14 @@ func (d *Download) OfflineDownload(ctx context.Context, fileURL string, parentID
 	URL := d.getBaseURL() + "/drive/v1/files"
 
 	downloadData := map[string]interface{}{
-		"kind":        "drive#file",
+		"kind":        enums.FileKindFile,
 		"name":        name,
-		"upload_type": "UPLOAD_TYPE_URL",
+		"upload_type": enums.UploadTypeURL,
 		"url":         map[string]string{"url": fileURL},
+		"folder_type": folderType,
 	}
-
 	if parentID != "" {
 		downloadData["parent_id"] = parentID
-		downloadData["folder_type"] = ""
-	} else {
-		downloadData["folder_type"] = "DOWNLOAD"
 	}
 
 	return d.httpClient.PostJSON(ctx, URL, downloadData)
@@ -91,7 +99,9 @@ func (d *Download) CaptureScreenshot(ctx context.Context, fileID string) (map[st
 	return d.httpClient.PostJSON(ctx, URL, data)
 }
 
-func (d *Download) RemoteDownload(ctx context.Context, fileURL string) (map[string]interface{}, error) {
+// RemoteDownload creates a URL download task. folder_type is sent only when
+// folderType is given, leaving the server to pick the folder otherwise.
+func (d *Download) RemoteDownload(ctx context.Context, fileURL string, folderType ...enums.FolderType) (map[string]interface{}, error) {
 	if fileURL == "" {
 		return nil, exception.ErrInvalidURL
 	}
@@ -99,57 +109,49 @@ func (d *Download) RemoteDownload(ctx context.Context, fileURL string) (map[stri
 	URL := d.getBaseURL() + "/drive/v1/files"
 
 	data := map[string]interface{}{
-		"kind":        "drive#task",
-		"upload_type": "UPLOAD_TYPE_URL",
+		"kind":        enums.FileKindTask,
+		"upload_type": enums.UploadTypeURL,
 		"url":         map[string]string{"url": fileURL},
 	}
+	if len(folderType) > 0 {
+		data["folder_type"] = folderType[0]
+	}
 
 	return d.httpClient.PostJSON(ctx, URL, data)
 }
 
-func (d *Download) OfflineList(ctx context.Context, size int, nextPageToken string, phases []string) (map[string]interface{}, error) {
+func (d *Download) OfflineList(ctx context.Context, size int, nextPageToken string, phases []enums.PhaseType) (map[string]interface{}, error) {
 	if size == 0 {
 		size = 10000
 	}
 
 	if phases == nil {
-		phases = []string{"PHASE_TYPE_RUNNING", "PHASE_TYPE_ERROR"}
+		phases = []enums.PhaseType{enums.PhaseTypeRunning, enums.PhaseTypeError}
+	}
+	values := make([]string, len(phases))
+	for i, phase := range phases {
+		values[i] = phase.String()
 	}
 
 	URL := d.getBaseURL() + "/drive/v1/tasks"
 
-	filters := fmt.Sprintf(`{"phase":{"in":"%s"}}`, strings.Join(phases, ","))
-
-	params := map[string]string{
-		"limit":   fmt.Sprintf("%d", size),
-		"filters": filters,
-	}
-
-	if nextPageToken != "" {
-		params["page_token"] = nextPageToken
-	}
+	params := query.List(size, nextPageToken).
+		Set("type", enums.TaskTypeOffline.String()).
+		Filters(query.NewFilters().In("phase", values...))
 
 	return d.httpClient.GetJSON(ctx, URL, params)
 }
 
 func (d *Download) DeleteOfflineTasks(ctx context.Context, taskIDs []string, deleteFiles bool) error {
-	URL := d.getBaseURL() + "/drive/v1/tasks"
-
-	params := map[string]string{
-		"task_ids":     strings.Join(taskIDs, ","),
-		"delete_files": fmt.Sprintf("%t", deleteFiles),
-	}
-
-	_, err := d.httpClient.Delete(ctx, URL, params)
-	return err
+	return d.DeleteTasks(ctx, taskIDs, deleteFiles)
 }
 
 func (d *Download) OfflineTaskRetry(ctx context.Context, taskID string) (map[string]interface{}, error) {
 	URL := d.getBaseURL() + "/drive/v1/task"
 
 	data := map[string]interface{}{
-		"type":        "offline",
-		"create_type": "RETRY",
+		"type":        enums.TaskTypeOffline,
+		"create_type": enums.TaskCreateTypeRetry,
 		"id":          taskID,
 	}
 
@@ -175,7 +177,7 @@ func (d *Download) GetTaskStatus(ctx context.Context, taskID string, fileID stri
 	}
 
 	if phase, ok := fileInfo["phase"].(string); ok {
-		return enums.ParseDownloadStatus(phase), nil
+		return enums.DownloadStatusFromPhase(enums.ParsePhaseType(phase)), nil
 	}
 
 	return enums.DownloadStatusNotFound, nil
@@ -186,7 +188,12 @@ func (d *Download) OfflineFileInfo(ctx context.Context, fileID string) (map[stri
 		return nil, exception.ErrInvalidFileID
 	}
 
+	thumbnailSize, err := file.ResolveThumbnailSize(d.thumbnailSize, enums.ThumbnailSizeLarge)
+	if err != nil {
+		return nil, err
+	}
+
 	URL := d.getBaseURL() + "/drive/v1/files/" + fileID
 
-	return d.httpClient.GetJSON(ctx, URL, nil)
+	return d.httpClient.GetJSON(ctx, URL, map[string]string{"thumbnail_size": thumbnailSize.String()})
 }
@@ -0,0 +1,109 @@
+package download_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zhz8888/pikpakapi-go/internal/download"
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
+	"github.com/zhz8888/pikpakapi-go/pkg/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+func newDownload(httpClient download.HTTPClient) *download.Download {
+	d := download.NewDownload(download.WithDownloadBaseURL("https://api.test"))
+	d.SetHTTPClient(httpClient)
+	return d
+}
+
+func TestOfflineListDefaults(t *testing.T) {
+	httpClient := mocks.NewMockDownloadHTTPClient(gomock.NewController(t))
+	var params map[string]string
+	httpClient.EXPECT().GetJSON(gomock.Any(), "https://api.test/drive/v1/tasks", gomock.Any()).
+		DoAndReturn(func(ctx context.Context, url string, p map[string]string) (map[string]interface{}, error) {
+			params = p
+			return map[string]interface{}{"tasks": []interface{}{}}, nil
+		})
+
+	if _, err := newDownload(httpClient).OfflineList(context.Background(), 0, "", nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if params["limit"] != "10000" || params["type"] != enums.TaskTypeOffline.String() {
+		t.Errorf("Unexpected params %v", params)
+	}
+	if !strings.Contains(params["filters"], "PHASE_TYPE_RUNNING") || !strings.Contains(params["filters"], "PHASE_TYPE_ERROR") {
+		t.Errorf("Expected the running and failed phases by default, got %s", params["filters"])
+	}
+}
+
+func TestDeleteTasks(t *testing.T) {
+	httpClient := mocks.NewMockDownloadHTTPClient(gomock.NewController(t))
+	httpClient.EXPECT().Delete(gomock.Any(), gomock.Any(), map[string]string{"task_ids": "t1,t2", "delete_files": "true"}).
+		Return(nil, nil)
+
+	if err := newDownload(httpClient).DeleteOfflineTasks(context.Background(), []string{"t1", "t2"}, true); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestGetTaskStatus(t *testing.T) {
+	tests := []struct {
+		phase string
+		want  enums.DownloadStatus
+	}{
+		{"PHASE_TYPE_PENDING", enums.DownloadStatusNotDownloading},
+		{"PHASE_TYPE_RUNNING", enums.DownloadStatusDownloading},
+		{"PHASE_TYPE_COMPLETE", enums.DownloadStatusDone},
+		{"PHASE_TYPE_ERROR", enums.DownloadStatusError},
+		{"", enums.DownloadStatusNotFound},
+	}
+
+	for _, tt := range tests {
+		httpClient := mocks.NewMockDownloadHTTPClient(gomock.NewController(t))
+		httpClient.EXPECT().GetJSON(gomock.Any(), "https://api.test/drive/v1/files/f1", gomock.Any()).
+			Return(map[string]interface{}{"phase": tt.phase}, nil)
+		got, err := newDownload(httpClient).GetTaskStatus(context.Background(), "t1", "f1")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("Phase %q: expected %v, got %v", tt.phase, tt.want, got)
+		}
+	}
+}
+
+func TestOfflineDownloadValidatesBeforeRequest(t *testing.T) {
+	// No expected calls: a request fails the test.
+	d := newDownload(mocks.NewMockDownloadHTTPClient(gomock.NewController(t)))
+
+	if _, err := d.OfflineDownload(context.Background(), "", "", "", enums.FolderTypeDownload); exception.GetErrorCode(err) != exception.ErrCodeInvalidURL {
+		t.Errorf("Expected ErrCodeInvalidURL, got %v", err)
+	}
+	if _, err := d.OfflineFileInfo(context.Background(), ""); exception.GetErrorCode(err) != exception.ErrCodeInvalidFileID {
+		t.Errorf("Expected ErrCodeInvalidFileID, got %v", err)
+	}
+}
+
+func TestOfflineDownloadParent(t *testing.T) {
+	httpClient := mocks.NewMockDownloadHTTPClient(gomock.NewController(t))
+	var bodies []interface{}
+	httpClient.EXPECT().PostJSON(gomock.Any(), gomock.Any(), gomock.Any()).Times(2).
+		DoAndReturn(func(ctx context.Context, url string, data interface{}) (map[string]interface{}, error) {
+			bodies = append(bodies, data)
+			return map[string]interface{}{}, nil
+		})
+	d := newDownload(httpClient)
+
+	d.OfflineDownload(context.Background(), "magnet:?xt=urn:btih:abc", "", "a", enums.FolderTypeDownload)
+	d.OfflineDownload(context.Background(), "magnet:?xt=urn:btih:abc", "p1", "a", enums.FolderTypeNormal)
+
+	if _, ok := bodies[0].(map[string]interface{})["parent_id"]; ok {
+		t.Error("Expected no parent_id without a parent")
+	}
+	if parent := bodies[1].(map[string]interface{})["parent_id"]; parent != "p1" {
+		t.Errorf("Expected parent_id p1, got %v", parent)
+	}
+}
@@ -2,8 +2,12 @@ package event
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"reflect"
+	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,6 +18,7 @@ const (
 	EventLoginFailure       EventType = "login_failure"
 	EventTokenRefreshed     EventType = "token_refreshed"
 	EventTokenRefreshFailed EventType = "token_refresh_failed"
+	EventLogout             EventType = "logout"
 	EventDownloadStarted    EventType = "download_started"
 	EventDownloadProgress   EventType = "download_progress"
 	EventDownloadCompleted  EventType = "download_completed"
@@ -27,6 +32,9 @@ const (
 	EventShareCreated       EventType = "share_created"
 	EventShareDeleted       EventType = "share_deleted"
 	EventError              EventType = "error"
+
+	// EventAll subscribes to every event type. See SubscribeAll.
+	EventAll EventType = "*"
 )
 
 type Event struct {
@@ -34,45 +42,288 @@ type Event struct {
 	Timestamp int64
 	Data      map[string]interface{}
 	Error     error
+	// Payload holds a typed description of the event, such as a TaskEvent,
+	// when the publisher provides one.
+	Payload interface{}
 }
 
 type EventHandler func(event Event)
 
+// ContextEventHandler is an EventHandler that also receives the bus context,
+// which is cancelled when the bus is closed.
+type ContextEventHandler func(ctx context.Context, event Event)
+
+// ErrBusClosed is returned by Publish after the bus is closed.
+var ErrBusClosed = errors.New("event bus is closed")
+
 type handlerWrapper struct {
 	id      int
 	handler EventHandler
+	match   func(Event) bool
+	queue   *dispatchQueue
+}
+
+// dispatchQueue delivers one subscriber's events in publish order. A
+// goroutine runs only while the queue has events.
+type dispatchQueue struct {
+	mu      sync.Mutex
+	events  []Event
+	running bool
+}
+
+func (q *dispatchQueue) push(event Event, handler EventHandler) {
+	q.mu.Lock()
+	q.events = append(q.events, event)
+	if q.running {
+		q.mu.Unlock()
+		return
+	}
+	q.running = true
+	q.mu.Unlock()
+
+	go q.drain(handler)
+}
+
+func (q *dispatchQueue) drain(handler EventHandler) {
+	for {
+		q.mu.Lock()
+		if len(q.events) == 0 {
+			q.running = false
+			q.mu.Unlock()
+			return
+		}
+		event := q.events[0]
+		q.events[0] = Event{}
+		q.events = q.events[1:]
+		q.mu.Unlock()
+
+		handler(event)
+	}
 }
 
+// BusOption configures an EventBus.
+type BusOption func(*EventBus)
+
+// WithSyncDelivery makes Publish call the handlers itself, in subscription
+// order, and return once they have all returned.
+func WithSyncDelivery() BusOption {
+	return func(eb *EventBus) {
+		eb.syncDelivery = true
+	}
+}
+
+// PanicHandler is called with the event a handler panicked on, the value
+// passed to panic and the handler's stack.
+type PanicHandler func(event Event, recovered interface{}, stack []byte)
+
+// WithPanicHandler replaces the bus's handling of panicking handlers, which
+// by default publishes an EventError. A PanicHandler that panics itself
+// crashes the process.
+func WithPanicHandler(handler PanicHandler) BusOption {
+	return func(eb *EventBus) {
+		eb.panicHandler = handler
+	}
+}
+
+// WithBusLogger sends to logf the panics the bus cannot publish as an
+// EventError: those of EventError handlers and those recovered after the
+// bus was closed. A client Logger's Errorf fits. By default they are
+// dropped.
+func WithBusLogger(logf func(format string, args ...interface{})) BusOption {
+	return func(eb *EventBus) {
+		eb.logf = logf
+	}
+}
+
+// EventBus delivers published events to the handlers subscribed to their
+// type. By default each handler runs on its own goroutine and receives
+// events in the order they were published.
 type EventBus struct {
 	handlers      map[EventType][]handlerWrapper
 	nextHandlerID int
+	syncDelivery  bool
+	panicHandler  PanicHandler
+	logf          func(format string, args ...interface{})
+	closed        bool
+	inFlight      sync.WaitGroup
+	chans         map[int]*chanSubscription
+	history       *history
 	mu            sync.RWMutex
 	ctx           context.Context
 	cancel        context.CancelFunc
 }
 
-func NewEventBus() *EventBus {
+func NewEventBus(opts ...BusOption) *EventBus {
 	ctx, cancel := context.WithCancel(context.Background())
-	return &EventBus{
+	eb := &EventBus{
 		handlers:      make(map[EventType][]handlerWrapper),
+		chans:         make(map[int]*chanSubscription),
 		nextHandlerID: 1,
 		ctx:           ctx,
 		cancel:        cancel,
 	}
+	for _, opt := range opts {
+		opt(eb)
+	}
+	return eb
 }
 
 func (eb *EventBus) Subscribe(eventType EventType, handler EventHandler) int {
+	return eb.subscribe(eventType, nil, func(int) EventHandler { return handler })
+}
+
+// SubscribeFiltered subscribes handler to the events of eventType for which
+// match returns true. match is called by Publish, before the event is queued
+// for the handler, so it should be cheap and must not call the bus. Use
+// EventAll for matching events of every type.
+func (eb *EventBus) SubscribeFiltered(eventType EventType, match func(Event) bool, handler EventHandler) int {
+	return eb.subscribe(eventType, match, func(int) EventHandler { return handler })
+}
+
+// FilterByTaskID matches events for taskID, read from a TaskEvent payload
+// or else the task_id key of Data.
+func FilterByTaskID(taskID string) func(Event) bool {
+	return func(event Event) bool {
+		return event.taskID() == taskID
+	}
+}
+
+// FilterByFileID matches events for fileID, read from a TaskEvent or
+// TransferEvent payload or else the file_id key of Data.
+func FilterByFileID(fileID string) func(Event) bool {
+	return func(event Event) bool {
+		return event.fileID() == fileID
+	}
+}
+
+// subscribe adds the handler built by newHandler, which is given the new
+// subscription's id.
+func (eb *EventBus) subscribe(eventType EventType, match func(Event) bool, newHandler func(id int) EventHandler) int {
 	eb.mu.Lock()
 	defer eb.mu.Unlock()
+	return eb.subscribeLocked(eventType, match, newHandler)
+}
+
+func (eb *EventBus) subscribeLocked(eventType EventType, match func(Event) bool, newHandler func(id int) EventHandler) int {
+	id := eb.nextHandlerID
 	eb.handlers[eventType] = append(eb.handlers[eventType], handlerWrapper{
-		id:      eb.nextHandlerID,
-		handler: handler,
+		id:      id,
+		handler: newHandler(id),
+		match:   match,
+		queue:   &dispatchQueue{},
 	})
-	id := eb.nextHandlerID
 	eb.nextHandlerID++
 	return id
 }
 
+// SubscribeCtx subscribes a handler that is passed the bus context, so it
+// can abort long-running work when the bus is closed.
+func (eb *EventBus) SubscribeCtx(eventType EventType, handler ContextEventHandler) int {
+	return eb.Subscribe(eventType, func(event Event) {
+		handler(eb.ctx, event)
+	})
+}
+
+// SubscribeOnce subscribes handler for the next event of eventType only.
+func (eb *EventBus) SubscribeOnce(eventType EventType, handler EventHandler) int {
+	return eb.SubscribeOnceWithFilter(eventType, nil, handler)
+}
+
+// SubscribeOnceWithFilter subscribes handler for the next event of
+// eventType for which match returns true; a nil match accepts every event.
+// The handler runs at most once, even when matching events are published
+// concurrently, and is unsubscribed before it runs.
+func (eb *EventBus) SubscribeOnceWithFilter(eventType EventType, match func(Event) bool, handler EventHandler) int {
+	return eb.subscribe(eventType, nil, func(id int) EventHandler {
+		var fired atomic.Bool
+		return func(event Event) {
+			if match != nil && !match(event) {
+				return
+			}
+			if !fired.CompareAndSwap(false, true) {
+				return
+			}
+			eb.UnsubscribeByID(eventType, id)
+			handler(event)
+		}
+	})
+}
+
+// SubscribeChan subscribes a channel with room for buffer events (at least
+// one) to eventType. Publish never waits for the reader: when the channel is
+// full the oldest buffered event is dropped to make room. The channel is
+// closed by the returned unsubscribe function or when the bus is closed.
+func (eb *EventBus) SubscribeChan(eventType EventType, buffer int) (<-chan Event, func()) {
+	if buffer < 1 {
+		buffer = 1
+	}
+	sub := &chanSubscription{ch: make(chan Event, buffer)}
+	id := eb.subscribe(eventType, nil, func(id int) EventHandler {
+		eb.chans[id] = sub
+		return sub.send
+	})
+
+	unsubscribe := func() {
+		eb.UnsubscribeByID(eventType, id)
+		eb.mu.Lock()
+		delete(eb.chans, id)
+		eb.mu.Unlock()
+		sub.close()
+	}
+	return sub.ch, unsubscribe
+}
+
+type chanSubscription struct {
+	mu     sync.Mutex
+	ch     chan Event
+	closed bool
+}
+
+func (s *chanSubscription) send(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	for {
+		select {
+		case s.ch <- event:
+			return
+		default:
+		}
+		select {
+		case <-s.ch:
+		default:
+		}
+	}
+}
+
+func (s *chanSubscription) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
+
+func (eb *EventBus) closeChans() {
+	eb.mu.Lock()
+	chans := eb.chans
+	eb.chans = make(map[int]*chanSubscription)
+	eb.mu.Unlock()
+
+	for _, sub := range chans {
+		sub.close()
+	}
+}
+
+// SubscribeAll subscribes handler to events of every type. It returns an id
+// for UnsubscribeByID with EventAll.
+func (eb *EventBus) SubscribeAll(handler EventHandler) int {
+	return eb.Subscribe(EventAll, handler)
+}
+
 func (eb *EventBus) UnsubscribeByID(eventType EventType, id int) {
 	eb.mu.Lock()
 	defer eb.mu.Unlock()
@@ -97,21 +348,132 @@ func (eb *EventBus) Unsubscribe(eventType EventType, handler EventHandler) {
 	}
 }
 
-func (eb *EventBus) Publish(event Event) {
+// Publish delivers event to the handlers subscribed to its type. It returns
+// ErrBusClosed, delivering nothing, once Close or CloseAndWait was called.
+func (eb *EventBus) Publish(event Event) error {
 	eb.mu.RLock()
-	defer eb.mu.RUnlock()
-
+	if eb.closed {
+		eb.mu.RUnlock()
+		return ErrBusClosed
+	}
 	event.Timestamp = CurrentTimestamp()
-	wrappers := eb.handlers[event.Type]
+	if eb.history != nil {
+		eb.history.record(event)
+	}
+	wrappers := append([]handlerWrapper(nil), eb.handlers[event.Type]...)
+	if event.Type != EventAll {
+		wrappers = append(wrappers, eb.handlers[EventAll]...)
+	}
+	eb.inFlight.Add(len(wrappers))
+	eb.mu.RUnlock()
+
 	for _, wrapper := range wrappers {
-		go wrapper.handler(event)
+		if wrapper.match != nil && !wrapper.match(event) {
+			eb.inFlight.Done()
+			continue
+		}
+		handler := wrapper.handler
+		if eb.syncDelivery {
+			eb.invoke(handler, event)
+		} else {
+			wrapper.queue.push(event, eb.queued(handler))
+		}
 	}
+	return nil
 }
 
+// queued wraps handler for a dispatch queue: once the bus context is
+// cancelled, events still queued are dropped.
+func (eb *EventBus) queued(handler EventHandler) EventHandler {
+	return func(event Event) {
+		if eb.ctx.Err() != nil {
+			eb.inFlight.Done()
+			return
+		}
+		eb.invoke(handler, event)
+	}
+}
+
+func (eb *EventBus) invoke(handler EventHandler, event Event) {
+	defer eb.inFlight.Done()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			stack := debug.Stack()
+			if eb.panicHandler != nil {
+				eb.panicHandler(event, recovered, stack)
+				return
+			}
+			eb.publishPanic(event, recovered, stack)
+		}
+	}()
+	handler(event)
+}
+
+// publishPanic reports a recovered panic as an EventError. A panic in an
+// EventError handler is only logged so that it cannot loop.
+func (eb *EventBus) publishPanic(event Event, recovered interface{}, stack []byte) {
+	if event.Type == EventError {
+		eb.logPanic(event, recovered, stack)
+		return
+	}
+	err := eb.Publish(Event{
+		Type: EventError,
+		Data: map[string]interface{}{
+			"event_type": event.Type,
+			"panic":      recovered,
+			"stack":      string(stack),
+		},
+		Error: fmt.Errorf("event handler for %s panicked: %v", event.Type, recovered),
+	})
+	if err != nil {
+		eb.logPanic(event, recovered, stack)
+	}
+}
+
+func (eb *EventBus) logPanic(event Event, recovered interface{}, stack []byte) {
+	if eb.logf != nil {
+		eb.logf("Event handler for %s panicked: %v\n%s", event.Type, recovered, stack)
+	}
+}
+
+// Close stops the bus from accepting events and cancels its context without
+// waiting for events already published; events still queued are dropped.
 func (eb *EventBus) Close() {
+	eb.stopPublishing()
+	eb.closeChans()
 	eb.cancel()
 }
 
+// CloseAndWait stops the bus from accepting events, waits until the events
+// already published have been handled or ctx is done, and then cancels the
+// bus context, so that handlers still running can abort and queued events
+// are dropped. It returns ctx.Err() if handlers were still running. It must
+// not be called from a handler.
+func (eb *EventBus) CloseAndWait(ctx context.Context) error {
+	eb.stopPublishing()
+	defer eb.cancel()
+	defer eb.closeChans()
+
+	done := make(chan struct{})
+	go func() {
+		eb.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (eb *EventBus) stopPublishing() {
+	eb.mu.Lock()
+	eb.closed = true
+	eb.mu.Unlock()
+}
+
 func CurrentTimestamp() int64 {
 	return time.Now().UnixMilli()
 }
@@ -1,12 +1,18 @@
 package event
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
 
 func TestEventBus_Subscribe(t *testing.T) {
-	bus := NewEventBus()
+	bus := NewEventBus(WithSyncDelivery())
 	defer bus.Close()
 
 	var received bool
@@ -27,7 +33,7 @@ func TestEventBus_Subscribe(t *testing.T) {
 }
 
 func TestEventBus_MultipleHandlers(t *testing.T) {
-	bus := NewEventBus()
+	bus := NewEventBus(WithSyncDelivery())
 	defer bus.Close()
 
 	count := 0
@@ -51,7 +57,7 @@ func TestEventBus_MultipleHandlers(t *testing.T) {
 }
 
 func TestEventBus_Unsubscribe(t *testing.T) {
-	bus := NewEventBus()
+	bus := NewEventBus(WithSyncDelivery())
 	defer bus.Close()
 
 	count := 0
@@ -73,3 +79,499 @@ func TestEventBus_Unsubscribe(t *testing.T) {
 		t.Error("Unsubscribed handler should not be called")
 	}
 }
+
+func TestEventBus_AsyncDelivery(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Close()
+
+	done := make(chan Event, 1)
+	bus.Subscribe(EventLoginSuccess, func(event Event) {
+		done <- event
+	})
+
+	bus.Publish(Event{Type: EventLoginSuccess})
+
+	select {
+	case event := <-done:
+		if event.Timestamp == 0 {
+			t.Error("Expected Publish to set the timestamp")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Event handler was not called")
+	}
+}
+
+func TestEventBus_SyncDeliveryOrder(t *testing.T) {
+	bus := NewEventBus(WithSyncDelivery())
+	defer bus.Close()
+
+	var calls []string
+	bus.Subscribe(EventFileCreated, func(event Event) {
+		calls = append(calls, "first")
+	})
+	bus.Subscribe(EventFileCreated, func(event Event) {
+		calls = append(calls, "second")
+	})
+
+	bus.Publish(Event{Type: EventFileCreated})
+
+	if len(calls) != 2 || calls[0] != "first" || calls[1] != "second" {
+		t.Errorf("Expected handlers in subscription order, got %v", calls)
+	}
+}
+
+func TestEventBus_Ordering(t *testing.T) {
+	const n = 100
+
+	for _, tt := range []struct {
+		name string
+		opts []BusOption
+	}{
+		{"async", nil},
+		{"sync", []BusOption{WithSyncDelivery()}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := NewEventBus(tt.opts...)
+			defer bus.Close()
+
+			var wg sync.WaitGroup
+			received := make([][]int, 3)
+			for i := range received {
+				i := i
+				wg.Add(n)
+				bus.Subscribe(EventDownloadProgress, func(event Event) {
+					received[i] = append(received[i], event.Data["seq"].(int))
+					wg.Done()
+				})
+			}
+
+			for seq := 0; seq < n; seq++ {
+				bus.Publish(Event{Type: EventDownloadProgress, Data: map[string]interface{}{"seq": seq}})
+			}
+			wg.Wait()
+
+			for i, seqs := range received {
+				for want, got := range seqs {
+					if got != want {
+						t.Fatalf("Subscriber %d: event %d has seq %d", i, want, got)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEventBus_HandlerPanic(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opts []BusOption
+	}{
+		{"async", nil},
+		{"sync", []BusOption{WithSyncDelivery()}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := NewEventBus(tt.opts...)
+			defer bus.Close()
+
+			errs := make(chan Event, 1)
+			bus.Subscribe(EventError, func(event Event) {
+				errs <- event
+			})
+			delivered := make(chan struct{}, 1)
+			bus.Subscribe(EventFileDeleted, func(event Event) {
+				panic("boom")
+			})
+			bus.Subscribe(EventFileDeleted, func(event Event) {
+				delivered <- struct{}{}
+			})
+
+			bus.Publish(Event{Type: EventFileDeleted})
+
+			select {
+			case <-delivered:
+			case <-time.After(time.Second):
+				t.Fatal("Handler after the panicking one was not called")
+			}
+			select {
+			case event := <-errs:
+				if event.Data["event_type"] != EventFileDeleted {
+					t.Errorf("Expected event_type %s, got %v", EventFileDeleted, event.Data["event_type"])
+				}
+				if stack, _ := event.Data["stack"].(string); stack == "" {
+					t.Error("Expected a stack in the error event")
+				}
+				if event.Error == nil {
+					t.Error("Expected Error to be set")
+				}
+			case <-time.After(time.Second):
+				t.Fatal("Error event was not published")
+			}
+		})
+	}
+}
+
+func TestEventBus_WithPanicHandler(t *testing.T) {
+	var got interface{}
+	bus := NewEventBus(WithSyncDelivery(), WithPanicHandler(func(event Event, recovered interface{}, stack []byte) {
+		got = recovered
+	}))
+	defer bus.Close()
+
+	errorEvents := 0
+	bus.Subscribe(EventError, func(event Event) {
+		errorEvents++
+	})
+	bus.Subscribe(EventShareCreated, func(event Event) {
+		panic("boom")
+	})
+
+	bus.Publish(Event{Type: EventShareCreated})
+
+	if got != "boom" {
+		t.Errorf("Expected panic handler to receive boom, got %v", got)
+	}
+	if errorEvents != 0 {
+		t.Errorf("Expected no error event with a panic handler, got %d", errorEvents)
+	}
+}
+
+func TestEventBus_WithBusLogger(t *testing.T) {
+	var logged []string
+	bus := NewEventBus(WithSyncDelivery(), WithBusLogger(func(format string, args ...interface{}) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}))
+	defer bus.Close()
+
+	bus.Subscribe(EventError, func(event Event) {
+		panic("error handler boom")
+	})
+	bus.Publish(Event{Type: EventError})
+
+	if len(logged) != 1 || !strings.Contains(logged[0], "error handler boom") {
+		t.Errorf("Expected the EventError handler's panic to be logged, got %q", logged)
+	}
+}
+
+func TestEventBus_CloseAndWait(t *testing.T) {
+	bus := NewEventBus()
+
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	bus.Subscribe(EventUploadStarted, func(event Event) {
+		<-release
+		close(finished)
+	})
+	bus.Publish(Event{Type: EventUploadStarted})
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- bus.CloseAndWait(context.Background())
+	}()
+
+	select {
+	case <-closed:
+		t.Fatal("CloseAndWait returned while a handler was running")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-closed; err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	select {
+	case <-finished:
+	default:
+		t.Error("Expected the handler to finish before CloseAndWait returned")
+	}
+
+	if err := bus.Publish(Event{Type: EventUploadStarted}); !errors.Is(err, ErrBusClosed) {
+		t.Errorf("Expected ErrBusClosed, got %v", err)
+	}
+}
+
+func TestEventBus_CloseAndWaitDeadline(t *testing.T) {
+	bus := NewEventBus()
+
+	release := make(chan struct{})
+	defer close(release)
+	bus.Subscribe(EventUploadStarted, func(event Event) {
+		<-release
+	})
+	bus.Publish(Event{Type: EventUploadStarted})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := bus.CloseAndWait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestEventBus_PublishAfterClose(t *testing.T) {
+	bus := NewEventBus(WithSyncDelivery())
+
+	called := false
+	bus.Subscribe(EventLoginSuccess, func(event Event) {
+		called = true
+	})
+	bus.Close()
+
+	if err := bus.Publish(Event{Type: EventLoginSuccess}); !errors.Is(err, ErrBusClosed) {
+		t.Errorf("Expected ErrBusClosed, got %v", err)
+	}
+	if called {
+		t.Error("Handler should not be called after Close")
+	}
+}
+
+func TestEventBus_SubscribeAll(t *testing.T) {
+	bus := NewEventBus(WithSyncDelivery())
+	defer bus.Close()
+
+	var all, typed []EventType
+	id := bus.SubscribeAll(func(event Event) {
+		all = append(all, event.Type)
+	})
+	bus.Subscribe(EventFileCreated, func(event Event) {
+		typed = append(typed, event.Type)
+	})
+
+	published := []EventType{EventLoginSuccess, EventFileCreated, EventDownloadFailed}
+	for _, eventType := range published {
+		bus.Publish(Event{Type: eventType})
+	}
+
+	if len(all) != len(published) {
+		t.Fatalf("Expected wildcard handler to see %v, got %v", published, all)
+	}
+	for i := range published {
+		if all[i] != published[i] {
+			t.Errorf("Wildcard event %d: expected %s, got %s", i, published[i], all[i])
+		}
+	}
+	if len(typed) != 1 || typed[0] != EventFileCreated {
+		t.Errorf("Expected typed handler to see only %s, got %v", EventFileCreated, typed)
+	}
+
+	bus.UnsubscribeByID(EventAll, id)
+	bus.Publish(Event{Type: EventLoginSuccess})
+	if len(all) != len(published) {
+		t.Error("Unsubscribed wildcard handler should not be called")
+	}
+}
+
+func TestEventBus_SubscribeOnceConcurrent(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opts []BusOption
+	}{
+		{"async", nil},
+		{"sync", []BusOption{WithSyncDelivery()}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := NewEventBus(tt.opts...)
+
+			var calls atomic.Int32
+			bus.SubscribeOnce(EventDownloadCompleted, func(event Event) {
+				calls.Add(1)
+			})
+
+			var wg sync.WaitGroup
+			for i := 0; i < 50; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					bus.Publish(Event{Type: EventDownloadCompleted})
+				}()
+			}
+			wg.Wait()
+			if err := bus.CloseAndWait(context.Background()); err != nil {
+				t.Fatalf("CloseAndWait: %v", err)
+			}
+
+			if got := calls.Load(); got != 1 {
+				t.Errorf("Expected handler to run once, ran %d times", got)
+			}
+			bus.mu.RLock()
+			remaining := len(bus.handlers[EventDownloadCompleted])
+			bus.mu.RUnlock()
+			if remaining != 0 {
+				t.Errorf("Expected the handler to be unsubscribed, %d left", remaining)
+			}
+		})
+	}
+}
+
+func TestEventBus_SubscribeOnceWithFilter(t *testing.T) {
+	bus := NewEventBus(WithSyncDelivery())
+	defer bus.Close()
+
+	var got []string
+	bus.SubscribeOnceWithFilter(EventDownloadCompleted, func(event Event) bool {
+		return event.Data["task_id"] == "task_2"
+	}, func(event Event) {
+		got = append(got, event.Data["task_id"].(string))
+	})
+
+	for _, taskID := range []string{"task_1", "task_2", "task_3", "task_2"} {
+		bus.Publish(Event{Type: EventDownloadCompleted, Data: map[string]interface{}{"task_id": taskID}})
+	}
+
+	if len(got) != 1 || got[0] != "task_2" {
+		t.Errorf("Expected only the first task_2 event, got %v", got)
+	}
+}
+
+func TestEventBus_SubscribeChan(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Close()
+
+	events, unsubscribe := bus.SubscribeChan(EventFileCreated, 4)
+	bus.Publish(Event{Type: EventFileCreated, Data: map[string]interface{}{"file_id": "f1"}})
+
+	select {
+	case event := <-events:
+		if event.Data["file_id"] != "f1" {
+			t.Errorf("Expected file_id f1, got %v", event.Data["file_id"])
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Event was not delivered to the channel")
+	}
+
+	unsubscribe()
+	if _, ok := <-events; ok {
+		t.Error("Expected the channel to be closed after unsubscribe")
+	}
+	unsubscribe()
+	bus.Publish(Event{Type: EventFileCreated})
+}
+
+func TestEventBus_SubscribeChanDropsOldest(t *testing.T) {
+	bus := NewEventBus(WithSyncDelivery())
+	defer bus.Close()
+
+	events, _ := bus.SubscribeChan(EventDownloadProgress, 2)
+	for seq := 0; seq < 5; seq++ {
+		bus.Publish(Event{Type: EventDownloadProgress, Data: map[string]interface{}{"seq": seq}})
+	}
+
+	for _, want := range []int{3, 4} {
+		event := <-events
+		if got := event.Data["seq"].(int); got != want {
+			t.Errorf("Expected seq %d, got %d", want, got)
+		}
+	}
+	select {
+	case event := <-events:
+		t.Errorf("Expected no more events, got %v", event.Data["seq"])
+	default:
+	}
+}
+
+func TestEventBus_SubscribeChanClosedWithBus(t *testing.T) {
+	bus := NewEventBus()
+	events, unsubscribe := bus.SubscribeChan(EventLoginSuccess, 1)
+
+	bus.Publish(Event{Type: EventLoginSuccess})
+	if err := bus.CloseAndWait(context.Background()); err != nil {
+		t.Fatalf("CloseAndWait: %v", err)
+	}
+
+	if _, ok := <-events; !ok {
+		t.Fatal("Expected the queued event before the channel closed")
+	}
+	if _, ok := <-events; ok {
+		t.Error("Expected the channel to be closed with the bus")
+	}
+	unsubscribe()
+}
+
+func TestEventBus_SubscribeFiltered(t *testing.T) {
+	bus := NewEventBus(WithSyncDelivery())
+	defer bus.Close()
+
+	var task2 []EventType
+	bus.SubscribeFiltered(EventDownloadProgress, FilterByTaskID("task_2"), func(event Event) {
+		task2 = append(task2, event.Type)
+	})
+	var fileX []EventType
+	bus.SubscribeFiltered(EventAll, FilterByFileID("file_x"), func(event Event) {
+		fileX = append(fileX, event.Type)
+	})
+
+	bus.Publish(Event{Type: EventDownloadProgress, Data: map[string]interface{}{"task_id": "task_1", "file_id": "file_x"}})
+	bus.Publish(Event{Type: EventDownloadProgress, Data: map[string]interface{}{"task_id": "task_2"}})
+	bus.Publish(Event{Type: EventFileDeleted, Data: map[string]interface{}{"file_id": "file_x"}})
+	bus.Publish(Event{Type: EventFileDeleted, Data: map[string]interface{}{"file_id": "file_y"}})
+	bus.Publish(Event{Type: EventLoginSuccess})
+
+	if len(task2) != 1 {
+		t.Errorf("Expected one task_2 event, got %v", task2)
+	}
+	if len(fileX) != 2 || fileX[0] != EventDownloadProgress || fileX[1] != EventFileDeleted {
+		t.Errorf("Expected file_x progress and delete events, got %v", fileX)
+	}
+}
+
+func TestEventBus_FilteredEventsDoNotBlockClose(t *testing.T) {
+	bus := NewEventBus()
+	bus.SubscribeFiltered(EventFileCreated, func(Event) bool { return false }, func(event Event) {
+		t.Error("Filtered handler should not be called")
+	})
+	bus.Publish(Event{Type: EventFileCreated})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := bus.CloseAndWait(ctx); err != nil {
+		t.Errorf("Expected CloseAndWait to return, got %v", err)
+	}
+}
+
+func TestEventBus_SubscribeCtx(t *testing.T) {
+	bus := NewEventBus()
+
+	started := make(chan struct{})
+	aborted := make(chan struct{})
+	bus.SubscribeCtx(EventUploadStarted, func(ctx context.Context, event Event) {
+		close(started)
+		<-ctx.Done()
+		close(aborted)
+	})
+	bus.Publish(Event{Type: EventUploadStarted})
+	<-started
+
+	bus.Close()
+
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the handler context to be cancelled on Close")
+	}
+}
+
+func TestEventBus_QueuedEventsDroppedOnCancel(t *testing.T) {
+	bus := NewEventBus()
+
+	release := make(chan struct{})
+	var calls atomic.Int32
+	bus.Subscribe(EventDownloadProgress, func(event Event) {
+		calls.Add(1)
+		<-release
+	})
+	for seq := 0; seq < 5; seq++ {
+		bus.Publish(progress(seq))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := bus.CloseAndWait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected DeadlineExceeded, got %v", err)
+	}
+	close(release)
+
+	if err := bus.CloseAndWait(context.Background()); err != nil {
+		t.Fatalf("CloseAndWait: %v", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Expected queued events to be dropped after cancel, handler ran %d times", got)
+	}
+}
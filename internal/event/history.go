@@ -0,0 +1,103 @@
+package event
+
+import (
+	"sync"
+	"time"
+)
+
+// WithHistory keeps the last size published events, of all types, for
+// ReplayTo and SubscribeWithReplay.
+func WithHistory(size int) BusOption {
+	return func(eb *EventBus) {
+		if size > 0 {
+			eb.history = &history{events: make([]Event, 0, size)}
+		}
+	}
+}
+
+// history is a ring buffer of the most recent events. seq counts every
+// event ever recorded, so callers can ask for what came after a snapshot.
+type history struct {
+	mu     sync.Mutex
+	events []Event
+	start  int
+	seq    uint64
+}
+
+func (h *history) record(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.events) < cap(h.events) {
+		h.events = append(h.events, event)
+	} else {
+		h.events[h.start] = event
+		h.start = (h.start + 1) % len(h.events)
+	}
+	h.seq++
+}
+
+// after returns, oldest first, the retained events recorded after seq, and
+// the current seq.
+func (h *history) after(seq uint64) ([]Event, uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := h.seq - seq
+	if n > uint64(len(h.events)) {
+		n = uint64(len(h.events))
+	}
+	out := make([]Event, 0, n)
+	for i := len(h.events) - int(n); i < len(h.events); i++ {
+		out = append(out, h.events[(h.start+i)%len(h.events)])
+	}
+	return out, h.seq
+}
+
+// ReplayTo calls handler, on the calling goroutine, with each retained
+// event published at or after since, oldest first. It does nothing without
+// WithHistory.
+func (eb *EventBus) ReplayTo(handler EventHandler, since time.Time) {
+	if eb.history == nil {
+		return
+	}
+	events, _ := eb.history.after(0)
+	eb.replay(events, EventAll, since, handler)
+}
+
+// SubscribeWithReplay subscribes handler to eventType after first calling
+// it, on the calling goroutine, with the retained events of that type
+// published at or after since. Every replayed event is handled before any
+// live one, and no event is both replayed and delivered live.
+func (eb *EventBus) SubscribeWithReplay(eventType EventType, since time.Time, handler EventHandler) int {
+	if eb.history == nil {
+		return eb.Subscribe(eventType, handler)
+	}
+
+	var seq uint64
+	for {
+		var events []Event
+		events, seq = eb.history.after(seq)
+		eb.replay(events, eventType, since, handler)
+
+		eb.mu.Lock()
+		if events, _ := eb.history.after(seq); len(events) == 0 {
+			id := eb.subscribeLocked(eventType, nil, func(int) EventHandler { return handler })
+			eb.mu.Unlock()
+			return id
+		}
+		eb.mu.Unlock()
+	}
+}
+
+func (eb *EventBus) replay(events []Event, eventType EventType, since time.Time, handler EventHandler) {
+	sinceMillis := since.UnixMilli()
+	for _, event := range events {
+		if eventType != EventAll && event.Type != eventType {
+			continue
+		}
+		if event.Timestamp < sinceMillis {
+			continue
+		}
+		eb.inFlight.Add(1)
+		eb.invoke(handler, event)
+	}
+}
@@ -0,0 +1,98 @@
+package event
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func progress(seq int) Event {
+	return Event{Type: EventDownloadProgress, Data: map[string]interface{}{"seq": seq}}
+}
+
+func TestHistory_Retention(t *testing.T) {
+	bus := NewEventBus(WithSyncDelivery(), WithHistory(5))
+	defer bus.Close()
+
+	for seq := 0; seq < 12; seq++ {
+		bus.Publish(progress(seq))
+	}
+
+	if got := len(bus.history.events); got != 5 {
+		t.Errorf("Expected 5 retained events, got %d", got)
+	}
+
+	var replayed []int
+	bus.ReplayTo(func(event Event) {
+		replayed = append(replayed, event.Data["seq"].(int))
+	}, time.Time{})
+
+	want := []int{7, 8, 9, 10, 11}
+	if len(replayed) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, replayed)
+	}
+	for i := range want {
+		if replayed[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, replayed)
+			break
+		}
+	}
+
+	count := 0
+	bus.ReplayTo(func(event Event) { count++ }, time.Now().Add(time.Hour))
+	if count != 0 {
+		t.Errorf("Expected no events after since, got %d", count)
+	}
+}
+
+func TestHistory_NoHistory(t *testing.T) {
+	bus := NewEventBus(WithSyncDelivery())
+	defer bus.Close()
+
+	bus.Publish(progress(0))
+	bus.ReplayTo(func(event Event) {
+		t.Error("Expected nothing to replay without WithHistory")
+	}, time.Time{})
+}
+
+func TestHistory_SubscribeWithReplay(t *testing.T) {
+	const total = 200
+	bus := NewEventBus(WithHistory(total))
+
+	bus.Publish(Event{Type: EventLoginSuccess})
+	for seq := 0; seq < 10; seq++ {
+		bus.Publish(progress(seq))
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for seq := 10; seq < total; seq++ {
+			bus.Publish(progress(seq))
+		}
+	}()
+
+	var mu sync.Mutex
+	var received []int
+	bus.SubscribeWithReplay(EventDownloadProgress, time.Time{}, func(event Event) {
+		mu.Lock()
+		received = append(received, event.Data["seq"].(int))
+		mu.Unlock()
+	})
+
+	wg.Wait()
+	if err := bus.CloseAndWait(context.Background()); err != nil {
+		t.Fatalf("CloseAndWait: %v", err)
+	}
+
+	if len(received) != total {
+		t.Fatalf("Expected %d events, got %d", total, len(received))
+	}
+	for i, seq := range received {
+		if seq != i {
+			t.Fatalf("Event %d has seq %d", i, seq)
+		}
+	}
+}
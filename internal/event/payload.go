@@ -0,0 +1,62 @@
+package event
+
+import (
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
+)
+
+// LoginEvent is the Payload of EventLoginSuccess, EventLoginFailure and
+// EventLogout.
+type LoginEvent struct {
+	Username string
+	UserID   string
+}
+
+// TokenEvent is the Payload of EventTokenRefreshed and
+// EventTokenRefreshFailed.
+type TokenEvent struct {
+	ExpiresAt time.Time
+}
+
+// TaskEvent is the Payload of the EventDownload* events for offline tasks.
+type TaskEvent struct {
+	TaskID   string
+	FileID   string
+	Name     string
+	Phase    enums.PhaseType
+	Progress int
+}
+
+// TransferEvent is the Payload of the EventUpload* events and of download
+// events for local transfers.
+type TransferEvent struct {
+	FileID string
+	Bytes  int64
+	Total  int64
+}
+
+// ShareEvent is the Payload of EventShareCreated and EventShareDeleted.
+type ShareEvent struct {
+	ShareID string
+	URL     string
+}
+
+func (e Event) taskID() string {
+	if p, ok := e.Payload.(TaskEvent); ok {
+		return p.TaskID
+	}
+	taskID, _ := e.Data["task_id"].(string)
+	return taskID
+}
+
+func (e Event) fileID() string {
+	switch p := e.Payload.(type) {
+	case TaskEvent:
+		return p.FileID
+	case TransferEvent:
+		return p.FileID
+	}
+	fileID, _ := e.Data["file_id"].(string)
+	return fileID
+}
@@ -0,0 +1,69 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
+)
+
+func TestEvent_Payload(t *testing.T) {
+	bus := NewEventBus(WithSyncDelivery())
+	defer bus.Close()
+
+	var kinds []string
+	bus.SubscribeAll(func(event Event) {
+		switch p := event.Payload.(type) {
+		case LoginEvent:
+			kinds = append(kinds, "login:"+p.Username)
+		case TaskEvent:
+			kinds = append(kinds, "task:"+p.TaskID+":"+p.Phase.String())
+		case TransferEvent:
+			kinds = append(kinds, "transfer:"+p.FileID)
+		case ShareEvent:
+			kinds = append(kinds, "share:"+p.ShareID)
+		case nil:
+			kinds = append(kinds, "none")
+		}
+	})
+
+	bus.Publish(Event{Type: EventLoginSuccess, Payload: LoginEvent{Username: "alice"}})
+	bus.Publish(Event{Type: EventDownloadCompleted, Payload: TaskEvent{TaskID: "t1", Phase: enums.PhaseTypeComplete}})
+	bus.Publish(Event{Type: EventUploadProgress, Payload: TransferEvent{FileID: "f1", Bytes: 10, Total: 20}})
+	bus.Publish(Event{Type: EventShareCreated, Payload: ShareEvent{ShareID: "s1"}})
+	bus.Publish(Event{Type: EventFileCreated, Data: map[string]interface{}{"file_id": "f2"}})
+
+	want := []string{"login:alice", "task:t1:PHASE_TYPE_COMPLETE", "transfer:f1", "share:s1", "none"}
+	if len(kinds) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, kinds)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Errorf("Event %d: expected %s, got %s", i, want[i], kinds[i])
+		}
+	}
+}
+
+func TestFilters_Payload(t *testing.T) {
+	tests := []struct {
+		name   string
+		event  Event
+		filter func(Event) bool
+		want   bool
+	}{
+		{"task payload", Event{Payload: TaskEvent{TaskID: "t1"}}, FilterByTaskID("t1"), true},
+		{"task payload other", Event{Payload: TaskEvent{TaskID: "t2"}}, FilterByTaskID("t1"), false},
+		{"task data", Event{Data: map[string]interface{}{"task_id": "t1"}}, FilterByTaskID("t1"), true},
+		{"task file", Event{Payload: TaskEvent{FileID: "f1"}}, FilterByFileID("f1"), true},
+		{"transfer file", Event{Payload: TransferEvent{FileID: "f1"}}, FilterByFileID("f1"), true},
+		{"share file", Event{Payload: ShareEvent{ShareID: "f1"}}, FilterByFileID("f1"), false},
+		{"file data", Event{Data: map[string]interface{}{"file_id": "f1"}}, FilterByFileID("f1"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter(tt.event); got != tt.want {
+				t.Errorf("filter = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WebhookSignatureHeader carries the hex HMAC-SHA256 of the request body,
+// prefixed with "sha256=", when the sink has a secret.
+const WebhookSignatureHeader = "X-Pikpak-Signature"
+
+// WebhookOption configures a WebhookSink.
+type WebhookOption func(*WebhookSink)
+
+// WithWebhookEventTypes limits the sink to the given event types. By
+// default it forwards every event.
+func WithWebhookEventTypes(types ...EventType) WebhookOption {
+	return func(s *WebhookSink) {
+		s.types = make(map[EventType]bool, len(types))
+		for _, t := range types {
+			s.types[t] = true
+		}
+	}
+}
+
+// WithWebhookSecret signs every request with secret; see
+// WebhookSignatureHeader.
+func WithWebhookSecret(secret string) WebhookOption {
+	return func(s *WebhookSink) {
+		s.secret = []byte(secret)
+	}
+}
+
+// WithWebhookRetries sets how many times a request that failed with a
+// network error, 429 or 5xx is retried, and the wait before the first
+// retry, which doubles after each one. The default is 3 retries from 500ms.
+func WithWebhookRetries(maxRetries int, initialBackoff time.Duration) WebhookOption {
+	return func(s *WebhookSink) {
+		s.maxRetries = maxRetries
+		s.initialBackoff = initialBackoff
+	}
+}
+
+// WithWebhookBatch sends events as a JSON array of up to maxEvents, posted
+// when it is full or window after its first event. Without it each event is
+// posted on its own as a JSON object.
+func WithWebhookBatch(maxEvents int, window time.Duration) WebhookOption {
+	return func(s *WebhookSink) {
+		s.batchSize = maxEvents
+		s.batchWindow = window
+	}
+}
+
+// WithWebhookHTTPClient sets the client used to post events.
+func WithWebhookHTTPClient(client *http.Client) WebhookOption {
+	return func(s *WebhookSink) {
+		s.httpClient = client
+	}
+}
+
+// WithWebhookErrorHandler is called with every request that could not be
+// delivered, after its retries. Failures are also logged through the logger
+// of the bus the sink is attached to (see WithBusLogger) and counted in
+// Stats. The errors name the webhook by scheme and host only, since its path
+// or query often carries a token.
+func WithWebhookErrorHandler(handler func(error)) WebhookOption {
+	return func(s *WebhookSink) {
+		s.onError = handler
+	}
+}
+
+// WebhookSink posts bus events to a URL as JSON.
+type WebhookSink struct {
+	url            string
+	types          map[EventType]bool
+	secret         []byte
+	maxRetries     int
+	initialBackoff time.Duration
+	batchSize      int
+	batchWindow    time.Duration
+	httpClient     *http.Client
+	onError        func(error)
+
+	delivered atomic.Int64
+	failed    atomic.Int64
+
+	mu      sync.Mutex
+	pending []webhookEvent
+	timer   *time.Timer
+	logf    func(format string, args ...interface{})
+}
+
+// WebhookStats counts the requests a WebhookSink made, after retries.
+type WebhookStats struct {
+	Delivered int64
+	Failed    int64
+}
+
+type webhookEvent struct {
+	Type      EventType              `json:"type"`
+	Timestamp int64                  `json:"timestamp"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	Payload   interface{}            `json:"payload,omitempty"`
+}
+
+func NewWebhookSink(url string, opts ...WebhookOption) *WebhookSink {
+	s := &WebhookSink{
+		url:            url,
+		maxRetries:     3,
+		initialBackoff: 500 * time.Millisecond,
+		httpClient:     &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Attach subscribes the sink to bus and returns a function that
+// unsubscribes it and posts any batched events. Requests are made from the
+// bus's dispatch, so with WithSyncDelivery they delay Publish. Failed
+// requests are logged through the bus's logger.
+func (s *WebhookSink) Attach(bus *EventBus) func() {
+	s.mu.Lock()
+	if bus.logf != nil {
+		s.logf = bus.logf
+	}
+	s.mu.Unlock()
+	id := bus.SubscribeFiltered(EventAll, s.accepts, func(event Event) {
+		s.handle(bus.ctx, event)
+	})
+	return func() {
+		bus.UnsubscribeByID(EventAll, id)
+		// The bus may be closed already, which cancels its context.
+		s.Flush(context.WithoutCancel(bus.ctx))
+	}
+}
+
+// Stats returns how many requests were delivered and how many failed.
+func (s *WebhookSink) Stats() WebhookStats {
+	return WebhookStats{Delivered: s.delivered.Load(), Failed: s.failed.Load()}
+}
+
+// Flush posts any batched events now.
+func (s *WebhookSink) Flush(ctx context.Context) {
+	s.mu.Lock()
+	batch := s.takeBatch()
+	s.mu.Unlock()
+	if len(batch) > 0 {
+		s.send(ctx, batch)
+	}
+}
+
+func (s *WebhookSink) accepts(event Event) bool {
+	return s.types == nil || s.types[event.Type]
+}
+
+func (s *WebhookSink) handle(ctx context.Context, event Event) {
+	e := webhookEvent{
+		Type:      event.Type,
+		Timestamp: event.Timestamp,
+		Data:      event.Data,
+		Payload:   event.Payload,
+	}
+	if event.Error != nil {
+		e.Error = event.Error.Error()
+	}
+
+	if s.batchSize <= 1 {
+		s.send(ctx, e)
+		return
+	}
+
+	s.mu.Lock()
+	s.pending = append(s.pending, e)
+	if len(s.pending) < s.batchSize {
+		if s.timer == nil {
+			s.timer = time.AfterFunc(s.batchWindow, func() { s.Flush(ctx) })
+		}
+		s.mu.Unlock()
+		return
+	}
+	batch := s.takeBatch()
+	s.mu.Unlock()
+	s.send(ctx, batch)
+}
+
+// takeBatch must be called with s.mu held.
+func (s *WebhookSink) takeBatch() []webhookEvent {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	batch := s.pending
+	s.pending = nil
+	return batch
+}
+
+func (s *WebhookSink) send(ctx context.Context, v interface{}) {
+	body, err := json.Marshal(v)
+	if err == nil {
+		err = s.post(ctx, body)
+	}
+	if err == nil {
+		s.delivered.Add(1)
+		return
+	}
+	s.failed.Add(1)
+	err = fmt.Errorf("webhook delivery to %s failed: %w", redactWebhookURL(s.url), err)
+	s.mu.Lock()
+	logf := s.logf
+	s.mu.Unlock()
+	if logf != nil {
+		logf("%v", err)
+	}
+	if s.onError != nil {
+		s.onError(err)
+	}
+}
+
+// redactWebhookURL returns the scheme and host of rawURL.
+func redactWebhookURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "webhook URL"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+func (s *WebhookSink) post(ctx context.Context, body []byte) error {
+	backoff := s.initialBackoff
+	var lastErr error
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		retry, err := s.postOnce(ctx, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return lastErr
+}
+
+func (s *WebhookSink) postOnce(ctx context.Context, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, errors.New("invalid webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.secret != nil {
+		mac := hmac.New(sha256.New, s.secret)
+		mac.Write(body)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			redacted := *uerr
+			redacted.URL = redactWebhookURL(uerr.URL)
+			err = &redacted
+		}
+		return ctx.Err() == nil, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+}
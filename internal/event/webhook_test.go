@@ -0,0 +1,221 @@
+package event
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type webhookReceiver struct {
+	mu       sync.Mutex
+	bodies   [][]byte
+	headers  []http.Header
+	failures int
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failures > 0 {
+		r.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	r.bodies = append(r.bodies, body)
+	r.headers = append(r.headers, req.Header.Clone())
+}
+
+func (r *webhookReceiver) received() ([][]byte, []http.Header) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([][]byte(nil), r.bodies...), append([]http.Header(nil), r.headers...)
+}
+
+func TestWebhookSink_PostsSignedEvents(t *testing.T) {
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	bus := NewEventBus(WithSyncDelivery())
+	defer bus.Close()
+	sink := NewWebhookSink(server.URL,
+		WithWebhookSecret("secret"),
+		WithWebhookEventTypes(EventDownloadCompleted))
+	sink.Attach(bus)
+
+	bus.Publish(Event{Type: EventLoginSuccess})
+	bus.Publish(Event{
+		Type:    EventDownloadCompleted,
+		Data:    map[string]interface{}{"task_id": "t1"},
+		Payload: TaskEvent{TaskID: "t1", Name: "movie.mkv"},
+	})
+
+	bodies, headers := receiver.received()
+	if len(bodies) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(bodies))
+	}
+
+	var got struct {
+		Type    EventType              `json:"type"`
+		Data    map[string]interface{} `json:"data"`
+		Payload TaskEvent              `json:"payload"`
+	}
+	if err := json.Unmarshal(bodies[0], &got); err != nil {
+		t.Fatalf("Invalid JSON %s: %v", bodies[0], err)
+	}
+	if got.Type != EventDownloadCompleted || got.Data["task_id"] != "t1" || got.Payload.Name != "movie.mkv" {
+		t.Errorf("Unexpected body %s", bodies[0])
+	}
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(bodies[0])
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if sig := headers[0].Get(WebhookSignatureHeader); sig != want {
+		t.Errorf("Expected signature %s, got %s", want, sig)
+	}
+}
+
+func TestWebhookSink_RetriesServerErrors(t *testing.T) {
+	receiver := &webhookReceiver{failures: 2}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	bus := NewEventBus(WithSyncDelivery())
+	defer bus.Close()
+	var errs []error
+	sink := NewWebhookSink(server.URL,
+		WithWebhookRetries(2, time.Millisecond),
+		WithWebhookErrorHandler(func(err error) { errs = append(errs, err) }))
+	sink.Attach(bus)
+
+	bus.Publish(Event{Type: EventUploadCompleted})
+
+	if bodies, _ := receiver.received(); len(bodies) != 1 {
+		t.Errorf("Expected delivery after retries, got %d requests", len(bodies))
+	}
+	if len(errs) != 0 {
+		t.Errorf("Expected no errors, got %v", errs)
+	}
+
+	receiver.mu.Lock()
+	receiver.failures = 3
+	receiver.mu.Unlock()
+	bus.Publish(Event{Type: EventUploadCompleted})
+	if len(errs) != 1 {
+		t.Errorf("Expected one error after retries ran out, got %v", errs)
+	}
+}
+
+func TestWebhookSink_FailuresLoggedAndCounted(t *testing.T) {
+	receiver := &webhookReceiver{failures: 1}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	var logs []string
+	bus := NewEventBus(WithSyncDelivery(), WithBusLogger(func(format string, args ...interface{}) {
+		logs = append(logs, fmt.Sprintf(format, args...))
+	}))
+	defer bus.Close()
+	sink := NewWebhookSink(server.URL, WithWebhookRetries(0, time.Millisecond))
+	sink.Attach(bus)
+
+	bus.Publish(Event{Type: EventUploadCompleted})
+	bus.Publish(Event{Type: EventUploadCompleted})
+
+	if len(logs) != 1 || !strings.Contains(logs[0], "status 500") {
+		t.Errorf("Expected the failure to be logged, got %q", logs)
+	}
+	if stats := sink.Stats(); stats.Delivered != 1 || stats.Failed != 1 {
+		t.Errorf("Expected one delivered and one failed request, got %+v", stats)
+	}
+}
+
+func TestWebhookSink_ErrorsRedactURL(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	addr := server.URL
+	server.Close()
+
+	bus := NewEventBus(WithSyncDelivery())
+	defer bus.Close()
+	var errs []error
+	sink := NewWebhookSink(addr+"/bot123456:SECRET/sendMessage?chat_id=42",
+		WithWebhookRetries(0, time.Millisecond),
+		WithWebhookErrorHandler(func(err error) { errs = append(errs, err) }))
+	sink.Attach(bus)
+
+	bus.Publish(Event{Type: EventUploadCompleted})
+
+	if len(errs) != 1 {
+		t.Fatalf("Expected one delivery error, got %v", errs)
+	}
+	msg := errs[0].Error()
+	if strings.Contains(msg, "SECRET") || strings.Contains(msg, "chat_id") {
+		t.Errorf("Expected the URL path and query to be redacted, got %q", msg)
+	}
+	if !strings.Contains(msg, addr) {
+		t.Errorf("Expected the error to name the webhook host, got %q", msg)
+	}
+}
+
+func TestWebhookSink_Batch(t *testing.T) {
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	bus := NewEventBus(WithSyncDelivery())
+	defer bus.Close()
+	sink := NewWebhookSink(server.URL, WithWebhookBatch(3, time.Hour))
+	detach := sink.Attach(bus)
+
+	for i := 0; i < 4; i++ {
+		bus.Publish(Event{Type: EventDownloadProgress})
+	}
+	// Detaching posts the pending event.
+	detach()
+
+	bodies, _ := receiver.received()
+	if len(bodies) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(bodies))
+	}
+	for i, want := range []int{3, 1} {
+		var batch []map[string]interface{}
+		if err := json.Unmarshal(bodies[i], &batch); err != nil {
+			t.Fatalf("Invalid JSON %s: %v", bodies[i], err)
+		}
+		if len(batch) != want {
+			t.Errorf("Request %d: expected %d events, got %d", i, want, len(batch))
+		}
+	}
+}
+
+func TestWebhookSink_BatchWindow(t *testing.T) {
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	bus := NewEventBus(WithSyncDelivery())
+	defer bus.Close()
+	NewWebhookSink(server.URL, WithWebhookBatch(10, 10*time.Millisecond)).Attach(bus)
+
+	bus.Publish(Event{Type: EventFileCreated})
+	bus.Publish(Event{Type: EventFileDeleted})
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if bodies, _ := receiver.received(); len(bodies) == 1 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Error("Expected the batch to be posted after the window")
+}
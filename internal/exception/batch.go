@@ -0,0 +1,102 @@
+package exception
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ItemError is the failure of one item in a batch operation.
+type ItemError struct {
+	ID  string
+	Err error
+}
+
+// BatchError reports the items of a batch operation that failed. Total is the
+// number of items attempted. errors.Is and errors.As match against every item.
+type BatchError struct {
+	Total int
+	Items []ItemError
+}
+
+// NewBatchError starts an empty BatchError for total items. Failures are
+// recorded with Add and the result read with Err.
+func NewBatchError(total int) *BatchError {
+	return &BatchError{Total: total}
+}
+
+func (e *BatchError) Add(id string, err error) {
+	if err != nil {
+		e.Items = append(e.Items, ItemError{ID: id, Err: err})
+	}
+}
+
+func (e *BatchError) Failed() int {
+	return len(e.Items)
+}
+
+// Err returns e when any item failed and nil otherwise.
+func (e *BatchError) Err() error {
+	if len(e.Items) == 0 {
+		return nil
+	}
+	return e
+}
+
+// maxBatchErrorItems bounds how many item failures Error lists.
+const maxBatchErrorItems = 3
+
+func (e *BatchError) Error() string {
+	text := fmt.Sprintf("%d of %d items failed", len(e.Items), e.Total)
+
+	var items []string
+	for i, item := range e.Items {
+		if i == maxBatchErrorItems {
+			items = append(items, fmt.Sprintf("and %d more", len(e.Items)-maxBatchErrorItems))
+			break
+		}
+		items = append(items, fmt.Sprintf("%s: %v", item.ID, item.Err))
+	}
+	if len(items) > 0 {
+		text += ": " + strings.Join(items, "; ")
+	}
+	return text
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Items))
+	for i, item := range e.Items {
+		errs[i] = item.Err
+	}
+	return errs
+}
+
+type jsonItemError struct {
+	ID    string          `json:"id"`
+	Error json.RawMessage `json:"error"`
+}
+
+// MarshalJSON writes item failures that are PikpakExceptions in their JSON
+// form and any other error as its message string.
+func (e *BatchError) MarshalJSON() ([]byte, error) {
+	items := make([]jsonItemError, len(e.Items))
+	for i, item := range e.Items {
+		var data []byte
+		var err error
+		if pe, ok := item.Err.(*PikpakException); ok {
+			data, err = json.Marshal(pe)
+		} else {
+			data, err = json.Marshal(item.Err.Error())
+		}
+		if err != nil {
+			return nil, err
+		}
+		items[i] = jsonItemError{ID: item.ID, Error: data}
+	}
+
+	return json.Marshal(struct {
+		Total  int             `json:"total"`
+		Failed int             `json:"failed"`
+		Items  []jsonItemError `json:"items"`
+	}{e.Total, len(e.Items), items})
+}
@@ -0,0 +1,76 @@
+package exception
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestBatchError_Summary(t *testing.T) {
+	batch := NewBatchError(250)
+	batch.Add("f1", nil)
+	if batch.Err() != nil {
+		t.Fatal("Expected no error without failures")
+	}
+
+	batch.Add("f2", ErrFileNotFound)
+	batch.Add("f3", &PikpakException{Code: ErrCodeServerError, Message: "server error", Method: "POST", Path: "/drive/v1/files:batchTrash"})
+	batch.Add("f4", errors.New("boom"))
+
+	err := batch.Err()
+	if err == nil {
+		t.Fatal("Expected error with failures")
+	}
+	want := "3 of 250 items failed: f2: [1044] file not found; f3: [1017] server error (POST /drive/v1/files:batchTrash); f4: boom"
+	if err.Error() != want {
+		t.Errorf("Expected %q, got %q", want, err.Error())
+	}
+	if batch.Failed() != 3 {
+		t.Errorf("Expected 3 failures, got %d", batch.Failed())
+	}
+
+	for i := 5; i < 10; i++ {
+		batch.Add(fmt.Sprintf("f%d", i), errors.New("boom"))
+	}
+	if got := batch.Error(); got[len(got)-len("and 5 more"):] != "and 5 more" {
+		t.Errorf("Expected long batches to be truncated, got %q", got)
+	}
+}
+
+func TestBatchError_Is(t *testing.T) {
+	batch := NewBatchError(2)
+	batch.Add("f1", fmt.Errorf("trash: %w", ErrQuotaExceeded))
+	batch.Add("f2", NewPikpakExceptionWithMessage(ErrCodeNetworkError, "dial failed"))
+	err := fmt.Errorf("cleanup: %w", batch.Err())
+
+	if !errors.Is(err, ErrQuotaExceeded) || !errors.Is(err, ErrNetworkError) {
+		t.Error("Expected errors.Is to match any item")
+	}
+	if errors.Is(err, ErrFileNotFound) {
+		t.Error("Expected errors.Is not to match codes no item has")
+	}
+	if !Is(err, ErrCodeNetworkError) {
+		t.Error("Expected exception.Is to match any item")
+	}
+
+	var be *BatchError
+	if !errors.As(err, &be) || be.Total != 2 {
+		t.Errorf("Expected errors.As to find the batch error, got %v", err)
+	}
+}
+
+func TestBatchError_MarshalJSON(t *testing.T) {
+	batch := NewBatchError(3)
+	batch.Add("f1", NewPikpakException(ErrCodeFileNotFound))
+	batch.Add("f2", errors.New("boom"))
+
+	data, err := json.Marshal(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"total":3,"failed":2,"items":[{"id":"f1","error":{"code":1044,"message":"file not found","retryable":false}},{"id":"f2","error":"boom"}]}`
+	if string(data) != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, data)
+	}
+}
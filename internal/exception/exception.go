@@ -3,6 +3,9 @@ package exception
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
 )
 
 type ErrorCode int
@@ -23,7 +26,7 @@ const (
 	ErrCodeInvalidAccessToken
 	ErrCodeInvalidCredentials
 	ErrCodeInvalidShareURL
-	ErrCodeInvalidPassCode
+	ErrCodeSharePasswordWrong
 	ErrCodeNetworkError
 	ErrCodeServerError
 	ErrCodeTimeout
@@ -50,8 +53,26 @@ const (
 	ErrCodeCreateDirectoryFailed
 	ErrCodeCreateFileFailed
 	ErrCodeWriteFileFailed
+	ErrCodeResponseTooLarge
+	ErrCodeTooManyRequests
+	ErrCodeFileNotFound
+	ErrCodeQuotaExceeded
+	ErrCodeTaskDailyLimitExceeded
+	ErrCodeCaptchaRequired
+	ErrCodeShareExpired
+	ErrCodePremiumRequired
+	ErrCodeMissingRefreshToken
+	ErrCodeVerificationCodeInvalid
+	ErrCodeVerificationCodeExpired
+	ErrCodeInviteCodeInvalid
+	ErrCodeInviteCodeUsed
 )
 
+// ErrCodeInvalidPassCode is the former name of ErrCodeSharePasswordWrong.
+//
+// Deprecated: use ErrCodeSharePasswordWrong.
+const ErrCodeInvalidPassCode = ErrCodeSharePasswordWrong
+
 func (e ErrorCode) String() string {
 	switch e {
 	case ErrCodeSuccess:
@@ -84,8 +105,8 @@ func (e ErrorCode) String() string {
 		return "invalid credentials"
 	case ErrCodeInvalidShareURL:
 		return "invalid share url"
-	case ErrCodeInvalidPassCode:
-		return "invalid pass code"
+	case ErrCodeSharePasswordWrong:
+		return "share password wrong"
 	case ErrCodeNetworkError:
 		return "network error"
 	case ErrCodeServerError:
@@ -138,6 +159,32 @@ func (e ErrorCode) String() string {
 		return "create file failed"
 	case ErrCodeWriteFileFailed:
 		return "write file failed"
+	case ErrCodeResponseTooLarge:
+		return "response too large"
+	case ErrCodeTooManyRequests:
+		return "too many requests"
+	case ErrCodeFileNotFound:
+		return "file not found"
+	case ErrCodeQuotaExceeded:
+		return "storage quota exceeded"
+	case ErrCodeTaskDailyLimitExceeded:
+		return "daily task creation limit exceeded"
+	case ErrCodeCaptchaRequired:
+		return "interactive captcha required"
+	case ErrCodeShareExpired:
+		return "share expired"
+	case ErrCodePremiumRequired:
+		return "premium membership required"
+	case ErrCodeMissingRefreshToken:
+		return "refresh token required"
+	case ErrCodeVerificationCodeInvalid:
+		return "verification code is wrong"
+	case ErrCodeVerificationCodeExpired:
+		return "verification code expired"
+	case ErrCodeInviteCodeInvalid:
+		return "invite code is invalid"
+	case ErrCodeInviteCodeUsed:
+		return "invite code already used"
 	default:
 		return "unknown error"
 	}
@@ -147,36 +194,232 @@ func (e ErrorCode) Message() string {
 	return e.String()
 }
 
+// PikpakException is the error type returned by the client. Code is the
+// local ErrorCode used for errors.Is matching. ServerCode, ServerError and
+// Description carry the error_code, error and error_description fields of the
+// API response when the failure came from the server, and HTTPStatus its
+// status code. Method and Path name the request that failed; Path never
+// includes the query string. ResetAt is set when the server says when a limit
+// lifts.
 type PikpakException struct {
-	Code    ErrorCode
-	Message string
-	Err     error
+	Code        ErrorCode
+	Message     string
+	Err         error
+	HTTPStatus  int
+	Method      string
+	Path        string
+	ServerCode  int
+	ServerError string
+	Description string
+	ResetAt     time.Time
 }
 
 func (e *PikpakException) Error() string {
-	if e.Err != nil {
-		if e.Message != "" {
-			return fmt.Sprintf("[%d] %s: %v", e.Code, e.Message, e.Err)
-		}
-		return fmt.Sprintf("[%d] %s: %v", e.Code, e.Code.String(), e.Err)
+	message := e.Message
+	if message == "" {
+		message = e.Code.String()
+	}
+	text := fmt.Sprintf("[%d] %s", e.Code, message)
+
+	var details []string
+	if e.Method != "" {
+		details = append(details, e.Method+" "+e.Path)
 	}
-	if e.Message != "" {
-		return fmt.Sprintf("[%d] %s", e.Code, e.Message)
+	if e.HTTPStatus != 0 {
+		details = append(details, fmt.Sprintf("HTTP %d", e.HTTPStatus))
 	}
-	return fmt.Sprintf("[%d] %s", e.Code, e.Code.String())
+	if e.ServerCode != 0 || e.ServerError != "" {
+		details = append(details, fmt.Sprintf("server %d %s", e.ServerCode, e.ServerError))
+	}
+	if len(details) > 0 {
+		text += " (" + strings.Join(details, ", ") + ")"
+	}
+
+	if he, ok := e.Err.(*HTTPError); ok && he.StatusCode == e.HTTPStatus {
+		if he.Body != "" {
+			text += ": " + he.Body
+		}
+	} else if e.Err != nil {
+		text += fmt.Sprintf(": %v", e.Err)
+	}
+	return text
 }
 
 func (e *PikpakException) Unwrap() error {
 	return e.Err
 }
 
+// Is matches a target with the same Code. An exception built from an HTTP
+// response also matches the code of its status class, so that a 401 is
+// ErrUnauthorized and a 404 ErrNotFound whatever more specific Code it
+// carries.
 func (e *PikpakException) Is(target error) bool {
-	if t, ok := target.(*PikpakException); ok {
-		return e.Code == t.Code
+	t, ok := target.(*PikpakException)
+	if !ok {
+		return false
+	}
+	if e.Code == t.Code {
+		return true
+	}
+	code, ok := statusClassCode(e.HTTPStatus)
+	return ok && code == t.Code
+}
+
+// statusClassCode returns the code of the HTTP status class of status.
+func statusClassCode(status int) (ErrorCode, bool) {
+	switch {
+	case status == http.StatusBadRequest:
+		return ErrCodeInvalidParameter, true
+	case status == http.StatusUnauthorized:
+		return ErrCodeUnauthorized, true
+	case status == http.StatusForbidden:
+		return ErrCodeForbidden, true
+	case status == http.StatusNotFound:
+		return ErrCodeNotFound, true
+	case status == http.StatusRequestTimeout:
+		return ErrCodeTimeout, true
+	case status == http.StatusConflict:
+		return ErrCodeConflict, true
+	case status == http.StatusTooManyRequests:
+		return ErrCodeTooManyRequests, true
+	case status == http.StatusInternalServerError:
+		return ErrCodeInternalServerError, true
+	case status == http.StatusServiceUnavailable:
+		return ErrCodeServiceUnavailable, true
+	case status >= 500 && status <= 599:
+		return ErrCodeServerError, true
+	}
+	return 0, false
+}
+
+// CaptchaRequiredError is returned when the server asks for a captcha that
+// has to be solved by a person. URL is the challenge page to show the user;
+// Action and DeviceID identify the request the captcha was issued for.
+// ExpiresAt is when the challenge lapses, zero when the server gave none.
+type CaptchaRequiredError struct {
+	*PikpakException
+	URL       string
+	Action    string
+	DeviceID  string
+	ExpiresAt time.Time
+}
+
+func NewCaptchaRequiredError(url, action, deviceID string) *CaptchaRequiredError {
+	return &CaptchaRequiredError{
+		PikpakException: NewPikpakException(ErrCodeCaptchaRequired),
+		URL:             url,
+		Action:          action,
+		DeviceID:        deviceID,
+	}
+}
+
+func (e *CaptchaRequiredError) Unwrap() error {
+	return e.PikpakException
+}
+
+// PremiumRequiredError is returned when every available link for a file is
+// reserved for premium accounts. VipTypes lists the memberships that unlock
+// it, as reported by the server.
+type PremiumRequiredError struct {
+	*PikpakException
+	VipTypes []string
+}
+
+func NewPremiumRequiredError(vipTypes []string) *PremiumRequiredError {
+	e := &PremiumRequiredError{
+		PikpakException: NewPikpakException(ErrCodePremiumRequired),
+		VipTypes:        vipTypes,
+	}
+	if len(vipTypes) > 0 {
+		e.Message = fmt.Sprintf("%s (vip types: %s)", e.Message, strings.Join(vipTypes, ", "))
+	}
+	return e
+}
+
+func (e *PremiumRequiredError) Unwrap() error {
+	return e.PikpakException
+}
+
+// MaxBodySnippet bounds how much of a failed response body HTTPError keeps.
+const MaxBodySnippet = 512
+
+// HTTPError records a non-2xx response. Body holds at most MaxBodySnippet
+// bytes of the response.
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func NewHTTPError(statusCode int, body []byte) *HTTPError {
+	snippet := string(body)
+	if len(body) > MaxBodySnippet {
+		snippet = string(body[:MaxBodySnippet]) + "..."
+	}
+	return &HTTPError{StatusCode: statusCode, Body: snippet}
+}
+
+func (e *HTTPError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// HTTPStatus returns the HTTP status of the first PikpakException or
+// HTTPError in err's chain that has one, or zero for failures that never got
+// a response.
+func HTTPStatus(err error) int {
+	for err != nil {
+		switch e := err.(type) {
+		case *PikpakException:
+			if e.HTTPStatus != 0 {
+				return e.HTTPStatus
+			}
+		case *HTTPError:
+			return e.StatusCode
+		}
+		err = errors.Unwrap(err)
+	}
+	return 0
+}
+
+// Retryable reports whether the failure is transient: network errors,
+// timeouts, 408, 429 and 5xx responses. Auth, not found and invalid
+// parameter failures are not. When the error records an HTTP status, the
+// status decides.
+func (e *PikpakException) Retryable() bool {
+	switch e.Code {
+	case ErrCodeFileNotFound, ErrCodeQuotaExceeded, ErrCodeTaskDailyLimitExceeded,
+		ErrCodeShareExpired, ErrCodeSharePasswordWrong, ErrCodePremiumRequired:
+		return false
+	}
+	if status := HTTPStatus(e); status != 0 {
+		return IsRetryableStatus(status)
+	}
+	switch e.Code {
+	case ErrCodeNetworkError, ErrCodeTimeout, ErrCodeTooManyRequests, ErrCodeServerError,
+		ErrCodeInternalServerError, ErrCodeServiceUnavailable, ErrCodeReadResponseFailed:
+		return true
+	case ErrCodeMaxRetriesExceeded, ErrCodeMaxRetriesReached:
+		return IsRetryable(e.Err)
+	default:
+		return false
+	}
+}
+
+// IsRetryable reports whether err wraps a retryable PikpakException.
+func IsRetryable(err error) bool {
+	var pe *PikpakException
+	if errors.As(err, &pe) {
+		return pe.Retryable()
 	}
 	return false
 }
 
+func IsRetryableStatus(statusCode int) bool {
+	return statusCode == 408 || statusCode == 429 || (statusCode >= 500 && statusCode <= 599)
+}
+
 func NewPikpakException(code ErrorCode) *PikpakException {
 	return &PikpakException{Code: code, Message: code.String()}
 }
@@ -193,11 +436,22 @@ func NewPikpakExceptionFull(code ErrorCode, message string, err error) *PikpakEx
 	return &PikpakException{Code: code, Message: message, Err: err}
 }
 
+// NewPikpakError wraps err with a free-form message for failures that have no
+// dedicated ErrorCode. It carries ErrCodeUnknownError.
+func NewPikpakError(message string, err error) *PikpakException {
+	return &PikpakException{Code: ErrCodeUnknownError, Message: message, Err: err}
+}
+
 func IsPikpakException(err error) bool {
 	var pe *PikpakException
 	return errors.As(err, &pe)
 }
 
+// Is reports whether any PikpakException in err's chain carries code.
+func Is(err error, code ErrorCode) bool {
+	return errors.Is(err, &PikpakException{Code: code})
+}
+
 func GetErrorCode(err error) ErrorCode {
 	var pe *PikpakException
 	if errors.As(err, &pe) {
@@ -218,10 +472,18 @@ var (
 	ErrInvalidFileName          = NewPikpakException(ErrCodeInvalidFileName)
 	ErrEmptyFileIDs             = NewPikpakException(ErrCodeEmptyFileIDs)
 	ErrInvalidURL               = NewPikpakException(ErrCodeInvalidURL)
+	ErrInvalidParameter         = NewPikpakException(ErrCodeInvalidParameter)
 	ErrInvalidAccessToken       = NewPikpakException(ErrCodeInvalidAccessToken)
 	ErrInvalidCredentials       = NewPikpakException(ErrCodeInvalidCredentials)
 	ErrInvalidShareURL          = NewPikpakException(ErrCodeInvalidShareURL)
-	ErrInvalidPassCode          = NewPikpakException(ErrCodeInvalidPassCode)
+	ErrSharePasswordWrong       = NewPikpakException(ErrCodeSharePasswordWrong)
+	ErrShareExpired             = NewPikpakException(ErrCodeShareExpired)
+	ErrPremiumRequired          = NewPikpakException(ErrCodePremiumRequired)
+	ErrMissingRefreshToken      = NewPikpakException(ErrCodeMissingRefreshToken)
+	ErrVerificationCodeInvalid  = NewPikpakException(ErrCodeVerificationCodeInvalid)
+	ErrVerificationCodeExpired  = NewPikpakException(ErrCodeVerificationCodeExpired)
+	ErrInviteCodeInvalid        = NewPikpakException(ErrCodeInviteCodeInvalid)
+	ErrInviteCodeUsed           = NewPikpakException(ErrCodeInviteCodeUsed)
 	ErrNetworkError             = NewPikpakException(ErrCodeNetworkError)
 	ErrServerError              = NewPikpakException(ErrCodeServerError)
 	ErrTimeout                  = NewPikpakException(ErrCodeTimeout)
@@ -231,4 +493,15 @@ var (
 	ErrConflict                 = NewPikpakException(ErrCodeConflict)
 	ErrInternalServerError      = NewPikpakException(ErrCodeInternalServerError)
 	ErrServiceUnavailable       = NewPikpakException(ErrCodeServiceUnavailable)
+	ErrResponseTooLarge         = NewPikpakException(ErrCodeResponseTooLarge)
+	ErrTooManyRequests          = NewPikpakException(ErrCodeTooManyRequests)
+	ErrFileNotFound             = NewPikpakException(ErrCodeFileNotFound)
+	ErrQuotaExceeded            = NewPikpakException(ErrCodeQuotaExceeded)
+	ErrTaskDailyLimitExceeded   = NewPikpakException(ErrCodeTaskDailyLimitExceeded)
+	ErrCaptchaRequired          = NewPikpakException(ErrCodeCaptchaRequired)
 )
+
+// ErrInvalidPassCode is the former name of ErrSharePasswordWrong.
+//
+// Deprecated: use ErrSharePasswordWrong.
+var ErrInvalidPassCode = ErrSharePasswordWrong
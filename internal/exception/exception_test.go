@@ -1,7 +1,10 @@
 package exception
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net"
 	"testing"
 )
 
@@ -51,3 +54,275 @@ func TestErrorVariables(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPError(t *testing.T) {
+	body := make([]byte, MaxBodySnippet+100)
+	for i := range body {
+		body[i] = 'x'
+	}
+
+	he := NewHTTPError(502, body)
+	if len(he.Body) != MaxBodySnippet+len("...") {
+		t.Errorf("Expected body truncated to %d bytes, got %d", MaxBodySnippet, len(he.Body))
+	}
+
+	err := NewPikpakExceptionWithError(ErrCodeServerError, NewHTTPError(502, []byte("<html>bad gateway</html>")))
+	if got := HTTPStatus(err); got != 502 {
+		t.Errorf("Expected status 502, got %d", got)
+	}
+	if err.Error() != "[1017] server error: HTTP 502: <html>bad gateway</html>" {
+		t.Errorf("Unexpected error string: %s", err.Error())
+	}
+	if HTTPStatus(errors.New("plain")) != 0 {
+		t.Error("Expected zero status for errors without HTTPError")
+	}
+}
+
+func TestConstructors(t *testing.T) {
+	cause := errors.New("cause")
+
+	tests := []struct {
+		name     string
+		err      *PikpakException
+		code     ErrorCode
+		expected string
+		wrapped  error
+	}{
+		{"code_only", NewPikpakException(ErrCodeNotFound), ErrCodeNotFound, "[1021] not found", nil},
+		{"with_message", NewPikpakExceptionWithMessage(ErrCodeNotFound, "file missing"), ErrCodeNotFound, "[1021] file missing", nil},
+		{"with_error", NewPikpakExceptionWithError(ErrCodeNetworkError, cause), ErrCodeNetworkError, "[1016] network error: cause", cause},
+		{"full", NewPikpakExceptionFull(ErrCodeMarshalFailed, "failed to marshal request data", cause), ErrCodeMarshalFailed, "[1027] failed to marshal request data: cause", cause},
+		{"string_message", NewPikpakError("failed to marshal request data", cause), ErrCodeUnknownError, "[1006] failed to marshal request data: cause", cause},
+		{"string_message_no_cause", NewPikpakError("something odd", nil), ErrCodeUnknownError, "[1006] something odd", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err.Code != tt.code {
+				t.Errorf("Expected code %d, got %d", tt.code, tt.err.Code)
+			}
+			if tt.err.Error() != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, tt.err.Error())
+			}
+			if !errors.Is(tt.err, NewPikpakException(tt.code)) {
+				t.Error("Expected errors.Is to match on code")
+			}
+			if tt.wrapped != nil && !errors.Is(tt.err, tt.wrapped) {
+				t.Error("Expected wrapped error to be reachable")
+			}
+		})
+	}
+}
+
+func TestPikpakException_ServerFields(t *testing.T) {
+	e := &PikpakException{
+		Code:        ErrCodeNotFound,
+		Message:     "File not found",
+		ServerCode:  3,
+		ServerError: "file_not_found",
+		Description: "File not found",
+	}
+	if e.Error() != "[1021] File not found (server 3 file_not_found)" {
+		t.Errorf("Unexpected error string: %s", e.Error())
+	}
+	if !errors.Is(e, ErrNotFound) {
+		t.Error("Expected local code to drive errors.Is")
+	}
+}
+
+func TestRetryable_EveryErrorCode(t *testing.T) {
+	retryable := map[ErrorCode]bool{
+		ErrCodeNetworkError:        true,
+		ErrCodeTimeout:             true,
+		ErrCodeTooManyRequests:     true,
+		ErrCodeServerError:         true,
+		ErrCodeInternalServerError: true,
+		ErrCodeServiceUnavailable:  true,
+		ErrCodeReadResponseFailed:  true,
+	}
+
+	for code := ErrCodeSuccess; code <= ErrCodePremiumRequired; code++ {
+		t.Run(code.String(), func(t *testing.T) {
+			if got := NewPikpakException(code).Retryable(); got != retryable[code] {
+				t.Errorf("Expected Retryable()=%v for code %d, got %v", retryable[code], code, got)
+			}
+		})
+	}
+}
+
+func TestRetryable_HTTPStatusAndWrapping(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"status_429", NewPikpakExceptionWithError(ErrCodeServerError, NewHTTPError(429, nil)), true},
+		{"status_502", NewPikpakExceptionWithError(ErrCodeServerError, NewHTTPError(502, nil)), true},
+		{"status_404", NewPikpakExceptionWithError(ErrCodeServerError, NewHTTPError(404, nil)), false},
+		{"status_401", NewPikpakExceptionWithError(ErrCodeInvalidAccessToken, NewHTTPError(401, nil)), false},
+		{"max_retries_transient", NewPikpakExceptionWithError(ErrCodeMaxRetriesExceeded, NewPikpakException(ErrCodeNetworkError)), true},
+		{"max_retries_permanent", NewPikpakExceptionWithError(ErrCodeMaxRetriesExceeded, NewPikpakException(ErrCodeNotFound)), false},
+		{"wrapped", fmt.Errorf("listing: %w", ErrTimeout), true},
+		{"plain", errors.New("boom"), false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPikpakException_HTTPStatusInError(t *testing.T) {
+	e := &PikpakException{Code: ErrCodeNotFound, HTTPStatus: 404, Err: NewHTTPError(404, []byte(`{}`))}
+	if e.Error() != "[1021] not found (HTTP 404): {}" {
+		t.Errorf("Unexpected error string: %s", e.Error())
+	}
+	if HTTPStatus(fmt.Errorf("wrapped: %w", e)) != 404 {
+		t.Error("Expected HTTPStatus to unwrap")
+	}
+}
+
+func TestPikpakException_RequestInError(t *testing.T) {
+	e := &PikpakException{Code: ErrCodeMaxRetriesExceeded, Method: "POST", Path: "/drive/v1/files", HTTPStatus: 500}
+	if e.Error() != "[1031] max retries exceeded (POST /drive/v1/files, HTTP 500)" {
+		t.Errorf("Unexpected error string: %s", e.Error())
+	}
+}
+
+func TestCaptchaRequiredError(t *testing.T) {
+	var challenge struct {
+		URL         string `json:"url"`
+		CaptchaCode string `json:"captcha_token"`
+	}
+	fixture := `{"captcha_token":"ck0.abc","expires_in":300,"url":"https://user.mypikpak.com/captcha/v2/spritePuzzle.html?action=POST%3A%2Fv1%2Fauth%2Fsignin"}`
+	if err := json.Unmarshal([]byte(fixture), &challenge); err != nil {
+		t.Fatal(err)
+	}
+
+	var err error = fmt.Errorf("login: %w", NewCaptchaRequiredError(challenge.URL, "POST:/v1/auth/signin", "device_id"))
+
+	var ce *CaptchaRequiredError
+	if !errors.As(err, &ce) {
+		t.Fatalf("Expected CaptchaRequiredError, got %T", err)
+	}
+	if ce.URL != challenge.URL || ce.Action != "POST:/v1/auth/signin" || ce.DeviceID != "device_id" {
+		t.Errorf("Unexpected challenge details: %+v", ce)
+	}
+	if !errors.Is(err, ErrCaptchaRequired) {
+		t.Error("Expected errors.Is to match ErrCaptchaRequired")
+	}
+	if GetErrorCode(err) != ErrCodeCaptchaRequired {
+		t.Errorf("Expected ErrCodeCaptchaRequired, got %d", GetErrorCode(err))
+	}
+	if IsRetryable(err) {
+		t.Error("Expected captcha challenges not to be retryable")
+	}
+}
+
+func TestErrorsIs_ThroughWrapping(t *testing.T) {
+	netErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	tests := []struct {
+		name string
+		err  error
+		code ErrorCode
+	}{
+		{"fresh_same_code", NewPikpakException(ErrCodeNetworkError), ErrCodeNetworkError},
+		{"with_message", NewPikpakExceptionWithMessage(ErrCodeNetworkError, "dial failed"), ErrCodeNetworkError},
+		{"fmt_wrapped", fmt.Errorf("list files: %w", NewPikpakException(ErrCodeNotFound)), ErrCodeNotFound},
+		{"wrapping_net_error", NewPikpakExceptionWithError(ErrCodeNetworkError, netErr), ErrCodeNetworkError},
+		{"multiple_layers", fmt.Errorf("sync: %w", fmt.Errorf("page 2: %w", NewPikpakExceptionWithError(ErrCodeTimeout, netErr))), ErrCodeTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, NewPikpakException(tt.code)) {
+				t.Errorf("Expected errors.Is to match code %d", tt.code)
+			}
+			if !Is(tt.err, tt.code) {
+				t.Errorf("Expected Is to match code %d", tt.code)
+			}
+			if Is(tt.err, ErrCodeConflict) {
+				t.Error("Expected Is not to match another code")
+			}
+			if got := GetErrorCode(tt.err); got != tt.code {
+				t.Errorf("Expected GetErrorCode %d, got %d", tt.code, got)
+			}
+			var pe *PikpakException
+			if !errors.As(tt.err, &pe) || pe.Code != tt.code {
+				t.Errorf("Expected errors.As to find code %d", tt.code)
+			}
+		})
+	}
+
+	wrapped := NewPikpakExceptionWithError(ErrCodeNetworkError, netErr)
+	var opErr *net.OpError
+	if !errors.As(wrapped, &opErr) || opErr != netErr {
+		t.Error("Expected errors.As to reach the wrapped net error")
+	}
+
+	outer := NewPikpakExceptionWithError(ErrCodeMaxRetriesExceeded, fmt.Errorf("last: %w", ErrFileNotFound))
+	if !Is(outer, ErrCodeFileNotFound) || !errors.Is(outer, ErrFileNotFound) {
+		t.Error("Expected inner codes to match below an outer exception")
+	}
+	if GetErrorCode(outer) != ErrCodeMaxRetriesExceeded {
+		t.Errorf("Expected GetErrorCode to report the outermost code, got %d", GetErrorCode(outer))
+	}
+	if Is(errors.New("plain"), ErrCodeUnknownError) || Is(nil, ErrCodeUnknownError) {
+		t.Error("Expected non-Pikpak errors not to match")
+	}
+}
+
+func TestErrorsIs_StatusClass(t *testing.T) {
+	expired := &PikpakException{Code: ErrCodeInvalidAccessToken, HTTPStatus: 401}
+	if !errors.Is(expired, ErrInvalidAccessToken) || !errors.Is(expired, ErrUnauthorized) {
+		t.Error("Expected a 401 to match its own code and ErrUnauthorized")
+	}
+	if errors.Is(expired, ErrForbidden) {
+		t.Error("Expected a 401 not to match ErrForbidden")
+	}
+
+	missing := &PikpakException{Code: ErrCodeFileNotFound, HTTPStatus: 404}
+	if !errors.Is(missing, ErrFileNotFound) || !errors.Is(missing, ErrNotFound) {
+		t.Error("Expected a missing file to match ErrFileNotFound and ErrNotFound")
+	}
+	if !errors.Is(&PikpakException{Code: ErrCodeServerError, HTTPStatus: 504}, ErrServerError) {
+		t.Error("Expected a 504 to match ErrServerError")
+	}
+	if errors.Is(NewPikpakException(ErrCodeInvalidAccessToken), ErrUnauthorized) {
+		t.Error("Expected an exception without a status to match its code only")
+	}
+}
+
+func TestShareErrors(t *testing.T) {
+	if ErrShareExpired.Error() != "[1048] share expired" {
+		t.Errorf("Unexpected error string: %s", ErrShareExpired.Error())
+	}
+	if ErrSharePasswordWrong.Error() != "[1015] share password wrong" {
+		t.Errorf("Unexpected error string: %s", ErrSharePasswordWrong.Error())
+	}
+
+	err := fmt.Errorf("open share: %w", NewPikpakExceptionWithMessage(ErrCodeSharePasswordWrong, "passcode rejected"))
+	if !errors.Is(err, ErrSharePasswordWrong) || !errors.Is(err, ErrInvalidPassCode) {
+		t.Error("Expected the wrong passcode error to match both names")
+	}
+	if errors.Is(err, ErrShareExpired) {
+		t.Error("Expected wrong passcode not to match ErrShareExpired")
+	}
+	if !errors.Is(fmt.Errorf("restore: %w", ErrShareExpired), ErrShareExpired) {
+		t.Error("Expected errors.Is to match ErrShareExpired")
+	}
+
+	for _, e := range []*PikpakException{
+		{Code: ErrCodeShareExpired, HTTPStatus: 503},
+		{Code: ErrCodeSharePasswordWrong, HTTPStatus: 500},
+	} {
+		if e.Retryable() {
+			t.Errorf("Expected code %d not to be retryable", e.Code)
+		}
+	}
+}
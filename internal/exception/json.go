@@ -0,0 +1,73 @@
+package exception
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// jsonException is the wire form of PikpakException. Wrapped errors are
+// flattened into Cause.
+type jsonException struct {
+	Code        ErrorCode  `json:"code"`
+	Message     string     `json:"message,omitempty"`
+	ServerCode  int        `json:"server_code,omitempty"`
+	ServerError string     `json:"server_error,omitempty"`
+	Description string     `json:"description,omitempty"`
+	HTTPStatus  int        `json:"http_status,omitempty"`
+	Method      string     `json:"method,omitempty"`
+	Path        string     `json:"path,omitempty"`
+	ResetAt     *time.Time `json:"reset_at,omitempty"`
+	Retryable   bool       `json:"retryable"`
+	Cause       string     `json:"cause,omitempty"`
+}
+
+func (e *PikpakException) MarshalJSON() ([]byte, error) {
+	out := jsonException{
+		Code:        e.Code,
+		Message:     e.Message,
+		ServerCode:  e.ServerCode,
+		ServerError: e.ServerError,
+		Description: e.Description,
+		HTTPStatus:  e.HTTPStatus,
+		Method:      e.Method,
+		Path:        e.Path,
+		Retryable:   e.Retryable(),
+	}
+	if out.Message == "" {
+		out.Message = e.Code.String()
+	}
+	if !e.ResetAt.IsZero() {
+		out.ResetAt = &e.ResetAt
+	}
+	if e.Err != nil {
+		out.Cause = e.Err.Error()
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON restores an exception written by MarshalJSON. The cause comes
+// back as a plain error holding its text; retryable is derived, not read.
+func (e *PikpakException) UnmarshalJSON(data []byte) error {
+	var in jsonException
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*e = PikpakException{
+		Code:        in.Code,
+		Message:     in.Message,
+		ServerCode:  in.ServerCode,
+		ServerError: in.ServerError,
+		Description: in.Description,
+		HTTPStatus:  in.HTTPStatus,
+		Method:      in.Method,
+		Path:        in.Path,
+	}
+	if in.ResetAt != nil {
+		e.ResetAt = *in.ResetAt
+	}
+	if in.Cause != "" {
+		e.Err = errors.New(in.Cause)
+	}
+	return nil
+}
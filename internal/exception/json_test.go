@@ -0,0 +1,75 @@
+package exception
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+const goldenExceptionJSON = `{"code":1012,"message":"unauthenticated","server_code":16,"server_error":"unauthenticated","http_status":401,"method":"GET","path":"/drive/v1/files","retryable":false,"cause":"HTTP 401: {\"error_code\":16}"}`
+
+func TestPikpakException_MarshalJSONGolden(t *testing.T) {
+	e := &PikpakException{
+		Code:        ErrCodeInvalidAccessToken,
+		Message:     "unauthenticated",
+		Err:         NewHTTPError(401, []byte(`{"error_code":16}`)),
+		HTTPStatus:  401,
+		Method:      "GET",
+		Path:        "/drive/v1/files",
+		ServerCode:  16,
+		ServerError: "unauthenticated",
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != goldenExceptionJSON {
+		t.Errorf("Expected\n%s\ngot\n%s", goldenExceptionJSON, data)
+	}
+}
+
+func TestPikpakException_MarshalJSONOmitsZeroFields(t *testing.T) {
+	data, err := json.Marshal(NewPikpakException(ErrCodeTimeout))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"code":1018,"message":"timeout","retryable":true}` {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+}
+
+func TestPikpakException_JSONRoundTrip(t *testing.T) {
+	resetAt := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	original := &PikpakException{
+		Code:       ErrCodeMaxRetriesExceeded,
+		Message:    "max retries exceeded",
+		Err:        NewPikpakExceptionWithError(ErrCodeNetworkError, &net.OpError{Op: "dial", Err: errors.New("connection refused")}),
+		HTTPStatus: 503,
+		ResetAt:    resetAt,
+	}
+
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded PikpakException
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Code != original.Code || decoded.HTTPStatus != 503 || !decoded.ResetAt.Equal(resetAt) {
+		t.Errorf("Round trip lost fields: %+v", decoded)
+	}
+	if decoded.Err == nil || decoded.Err.Error() != original.Err.Error() {
+		t.Errorf("Expected cause %q, got %v", original.Err.Error(), decoded.Err)
+	}
+	if decoded.Error() != original.Error() {
+		t.Errorf("Expected %q, got %q", original.Error(), decoded.Error())
+	}
+	if !errors.Is(&decoded, NewPikpakException(ErrCodeMaxRetriesExceeded)) {
+		t.Error("Expected decoded exception to match its sentinel")
+	}
+}
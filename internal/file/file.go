@@ -6,6 +6,8 @@ import (
 
 	"github.com/zhz8888/pikpakapi-go/internal/constants"
 	"github.com/zhz8888/pikpakapi-go/internal/exception"
+	"github.com/zhz8888/pikpakapi-go/internal/query"
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
 )
 
 const (
@@ -13,9 +15,10 @@ const (
 )
 
 type File struct {
-	httpClient   HTTPClient
-	baseURL      string
-	tokenRefresh func(ctx context.Context) error
+	httpClient    HTTPClient
+	baseURL       string
+	tokenRefresh  func(ctx context.Context) error
+	thumbnailSize enums.ThumbnailSize
 }
 
 type HTTPClient interface {
@@ -60,27 +63,37 @@ func (f *File) getBaseURL() string {
 	return DriveAPIHost
 }
 
-func (f *File) GetFileLink(ctx context.Context, fileID string) (string, error) {
+// GetFileLink returns a playable media rendition of fileID if there is one,
+// else the original download link. With preferOriginal the original link
+// comes first.
+func (f *File) GetFileLink(ctx context.Context, fileID string, preferOriginal bool) (string, error) {
+	thumbnailSize, err := ResolveThumbnailSize(f.thumbnailSize, enums.ThumbnailSizeLarge)
+	if err != nil {
+		return "", err
+	}
+
 	baseURL := f.getBaseURL()
 	resp, err := f.httpClient.GetJSON(ctx, fmt.Sprintf("%s/drive/v1/files/%s", baseURL, fileID), map[string]string{
 		"_magic":         "2021",
 		"usage":          "CACHE",
-		"thumbnail_size": "SIZE_LARGE",
+		"thumbnail_size": thumbnailSize.String(),
 	})
 	if err != nil {
 		return "", err
 	}
 
-	url := resp["web_content_link"].(string)
+	url, _ := resp["web_content_link"].(string)
+	if preferOriginal && url != "" {
+		return url, nil
+	}
 
-	if medias, ok := resp["medias"].([]interface{}); ok && len(medias) > 0 {
-		if media, ok := medias[0].(map[string]interface{}); ok {
-			if link, ok := media["link"].(map[string]interface{}); ok {
-				if linkUrl, ok := link["url"].(string); ok && linkUrl != "" {
-					url = linkUrl
-				}
-			}
-		}
+	medias, _ := resp["medias"].([]interface{})
+	mediaURL, err := MediaLink(medias)
+	if mediaURL != "" {
+		return mediaURL, nil
+	}
+	if url == "" && err != nil {
+		return "", err
 	}
 
 	return url, nil
@@ -136,7 +149,7 @@ func (f *File) CreateFolder(ctx context.Context, name string, parentID string) (
 	}
 
 	data := map[string]interface{}{
-		"kind":      "drive#folder",
+		"kind":      enums.FileKindFolder,
 		"name":      name,
 		"parent_id": parentID,
 	}
@@ -164,6 +177,12 @@ func (f *File) Untrash(ctx context.Context, ids []string) (map[string]interface{
 	return f.httpClient.PostJSON(ctx, fmt.Sprintf("%s/drive/v1/files:batchUntrash", f.getBaseURL()), data)
 }
 
+// EmptyTrash permanently deletes everything in the trash.
+func (f *File) EmptyTrash(ctx context.Context) error {
+	_, err := f.httpClient.PatchJSON(ctx, fmt.Sprintf("%s/drive/v1/files/trash:empty", f.getBaseURL()), map[string]interface{}{})
+	return err
+}
+
 func (f *File) DeleteForever(ctx context.Context, ids []string) (map[string]interface{}, error) {
 	if len(ids) == 0 {
 		return nil, exception.ErrEmptyFileIDs
@@ -176,30 +195,32 @@ func (f *File) DeleteForever(ctx context.Context, ids []string) (map[string]inte
 	return f.httpClient.PostJSON(ctx, fmt.Sprintf("%s/drive/v1/files:batchDelete", f.getBaseURL()), data)
 }
 
-func (f *File) FileList(ctx context.Context, size int, parentID string, nextPageToken string, query string) (map[string]interface{}, error) {
+func (f *File) FileList(ctx context.Context, size int, parentID string, nextPageToken string, search string, sortField enums.SortField, sortOrder enums.SortOrder) (map[string]interface{}, error) {
 	if size == 0 {
 		size = 100
 	}
-
-	filters := `{"trashed":{"eq":false},"phase":{"eq":"PHASE_TYPE_COMPLETE"}}`
-
-	params := map[string]string{
-		"parent_id":      parentID,
-		"thumbnail_size": "SIZE_MEDIUM",
-		"limit":          fmt.Sprintf("%d", size),
-		"with_audit":     "true",
-		"filters":        filters,
+	thumbnailSize, err := ResolveThumbnailSize(f.thumbnailSize, enums.ThumbnailSizeMedium)
+	if err != nil {
+		return nil, err
 	}
 
-	if nextPageToken != "" {
-		params["page_token"] = nextPageToken
-	}
+	params := query.List(size, nextPageToken).
+		ThumbnailSize(thumbnailSize).
+		Filters(query.NewFilters().Eq("trashed", false).Eq("phase", enums.PhaseTypeComplete.String())).
+		Set("query", search).
+		Sort(sortField, sortOrder)
+	params["parent_id"] = parentID
+	params["with_audit"] = "true"
 
-	if query != "" {
-		params["query"] = query
+	return f.httpClient.GetJSON(ctx, fmt.Sprintf("%s/drive/v1/files", f.getBaseURL()), params)
+}
+
+func (f *File) GetFileDetails(ctx context.Context, fileID string) (map[string]interface{}, error) {
+	if fileID == "" {
+		return nil, exception.ErrInvalidFileID
 	}
 
-	return f.httpClient.GetJSON(ctx, fmt.Sprintf("%s/drive/v1/files", f.getBaseURL()), params)
+	return f.httpClient.GetJSON(ctx, fmt.Sprintf("%s/drive/v1/files/%s", f.getBaseURL(), fileID), nil)
 }
 
 func (f *File) GetAbout(ctx context.Context) (map[string]interface{}, error) {
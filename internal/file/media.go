@@ -0,0 +1,50 @@
+package file
+
+import "github.com/zhz8888/pikpakapi-go/internal/exception"
+
+// MediaLink returns the url of the first rendition in medias that the account
+// can play. Renditions marked need_more_quota are skipped; when they are all
+// that is left the result is a PremiumRequiredError naming the VIP types that
+// unlock them. An empty url with a nil error means no rendition has a link.
+func MediaLink(medias []interface{}) (string, error) {
+	var vipTypes []string
+	seen := map[string]bool{}
+	gated, valid := false, false
+
+	for _, m := range medias {
+		media, ok := m.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		valid = true
+
+		link, ok := media["link"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		url, _ := link["url"].(string)
+		if url == "" {
+			continue
+		}
+
+		if needMoreQuota, _ := media["need_more_quota"].(bool); !needMoreQuota {
+			return url, nil
+		}
+		gated = true
+		types, _ := media["vip_types"].([]interface{})
+		for _, t := range types {
+			if vipType, ok := t.(string); ok && vipType != "" && !seen[vipType] {
+				seen[vipType] = true
+				vipTypes = append(vipTypes, vipType)
+			}
+		}
+	}
+
+	if gated {
+		return "", exception.NewPremiumRequiredError(vipTypes)
+	}
+	if len(medias) > 0 && !valid {
+		return "", exception.NewPikpakExceptionWithMessage(exception.ErrCodeInvalidMediaFormat, "invalid media format")
+	}
+	return "", nil
+}
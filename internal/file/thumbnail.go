@@ -0,0 +1,29 @@
+package file
+
+import (
+	"fmt"
+
+	"github.com/zhz8888/pikpakapi-go/internal/exception"
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
+)
+
+// WithFileThumbnailSize sets the thumbnail size requested by every call in
+// place of each endpoint's default.
+func WithFileThumbnailSize(size enums.ThumbnailSize) FileOption {
+	return func(f *File) {
+		f.thumbnailSize = size
+	}
+}
+
+// ResolveThumbnailSize returns size, or def when size is empty. A size the
+// API does not accept is an ErrCodeInvalidParameter error, so callers fail
+// before sending the request.
+func ResolveThumbnailSize(size, def enums.ThumbnailSize) (enums.ThumbnailSize, error) {
+	if size == "" {
+		return def, nil
+	}
+	if !size.IsValid() {
+		return "", exception.NewPikpakExceptionWithMessage(exception.ErrCodeInvalidParameter, fmt.Sprintf("invalid thumbnail size %q", size))
+	}
+	return size, nil
+}
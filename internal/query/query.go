@@ -0,0 +1,104 @@
+// Package query builds the query parameters shared by the drive list
+// endpoints so every caller encodes limits, page tokens and filters the same
+// way.
+package query
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
+)
+
+type condition struct {
+	field string
+	op    string
+	value interface{}
+}
+
+// Filters is the JSON filter expression sent as the filters parameter.
+// Conditions are encoded in the order they were added.
+type Filters struct {
+	conditions []condition
+}
+
+func NewFilters() *Filters {
+	return &Filters{}
+}
+
+func (f *Filters) Eq(field string, value interface{}) *Filters {
+	f.conditions = append(f.conditions, condition{field: field, op: "eq", value: value})
+	return f
+}
+
+// In matches any of values. The API expects them as one comma separated
+// string rather than a JSON array.
+func (f *Filters) In(field string, values ...string) *Filters {
+	f.conditions = append(f.conditions, condition{field: field, op: "in", value: strings.Join(values, ",")})
+	return f
+}
+
+func (f *Filters) String() string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, c := range f.conditions {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		field, _ := json.Marshal(c.field)
+		op, _ := json.Marshal(c.op)
+		value, err := json.Marshal(c.value)
+		if err != nil {
+			value = []byte("null")
+		}
+		b.Write(field)
+		b.WriteString(":{")
+		b.Write(op)
+		b.WriteByte(':')
+		b.Write(value)
+		b.WriteByte('}')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// Params is a set of query parameters for a list request.
+type Params map[string]string
+
+// List starts a parameter set with the page size and, when set, the page
+// token.
+func List(limit int, pageToken string) Params {
+	p := Params{"limit": strconv.Itoa(limit)}
+	if pageToken != "" {
+		p["page_token"] = pageToken
+	}
+	return p
+}
+
+func (p Params) ThumbnailSize(size enums.ThumbnailSize) Params {
+	p["thumbnail_size"] = size.String()
+	return p
+}
+
+// Sort orders the listing by field in the given order. It adds nothing when
+// field is empty.
+func (p Params) Sort(field enums.SortField, order enums.SortOrder) Params {
+	if field != "" {
+		p["order_by"] = field.String() + " " + order.String()
+	}
+	return p
+}
+
+func (p Params) Filters(f *Filters) Params {
+	p["filters"] = f.String()
+	return p
+}
+
+// Set adds key when value is non-empty.
+func (p Params) Set(key, value string) Params {
+	if value != "" {
+		p[key] = value
+	}
+	return p
+}
@@ -0,0 +1,142 @@
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
+)
+
+func encode(params map[string]string) string {
+	q := url.Values{}
+	for key, value := range params {
+		q.Set(key, value)
+	}
+	return q.Encode()
+}
+
+// The legacy maps below are the literals the list methods built before this
+// package existed; the encoded query strings must not change.
+func TestParams_MatchLegacyEncoding(t *testing.T) {
+	phases := []string{"PHASE_TYPE_RUNNING", "PHASE_TYPE_ERROR"}
+
+	fileList := List(100, "").
+		ThumbnailSize(enums.ThumbnailSizeMedium).
+		Filters(NewFilters().Eq("trashed", false).Eq("phase", "PHASE_TYPE_COMPLETE")).
+		Set("query", "")
+	fileList["parent_id"] = ""
+	fileList["with_audit"] = "true"
+
+	starList := List(50, "token").ThumbnailSize(enums.ThumbnailSizeLarge)
+	starList["starred"] = "true"
+
+	tests := []struct {
+		name   string
+		got    Params
+		legacy map[string]string
+	}{
+		{
+			name: "file_list",
+			got:  fileList,
+			legacy: map[string]string{
+				"parent_id":      "",
+				"thumbnail_size": "SIZE_MEDIUM",
+				"limit":          fmt.Sprintf("%d", 100),
+				"with_audit":     "true",
+				"filters":        `{"trashed":{"eq":false},"phase":{"eq":"PHASE_TYPE_COMPLETE"}}`,
+			},
+		},
+		{
+			name: "file_star_list",
+			got:  starList,
+			legacy: map[string]string{
+				"limit":          "50",
+				"starred":        "true",
+				"thumbnail_size": "SIZE_LARGE",
+				"page_token":     "token",
+			},
+		},
+		{
+			name: "offline_list",
+			got:  List(10000, "").Filters(NewFilters().In("phase", phases...)),
+			legacy: map[string]string{
+				"limit":   fmt.Sprintf("%d", 10000),
+				"filters": fmt.Sprintf(`{"phase":{"in":"%s"}}`, strings.Join(phases, ",")),
+			},
+		},
+		{
+			name: "events",
+			got:  List(100, "").ThumbnailSize(enums.ThumbnailSizeMedium).Set("next_page_token", "next"),
+			legacy: map[string]string{
+				"thumbnail_size":  "SIZE_MEDIUM",
+				"limit":           fmt.Sprintf("%d", 100),
+				"next_page_token": "next",
+			},
+		},
+		{
+			name:   "share_list",
+			got:    List(50, ""),
+			legacy: map[string]string{"limit": "50"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, want := encode(tt.got), encode(tt.legacy); got != want {
+				t.Errorf("Expected %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestFilters_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		filters  *Filters
+		expected string
+	}{
+		{"empty", NewFilters(), `{}`},
+		{"eq_bool", NewFilters().Eq("trashed", false), `{"trashed":{"eq":false}}`},
+		{"in_joined", NewFilters().In("phase", "A", "B"), `{"phase":{"in":"A,B"}}`},
+		{"escaped", NewFilters().Eq("name", `a"b`), `{"name":{"eq":"a\"b"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filters.String(); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParams_Sort(t *testing.T) {
+	tests := []struct {
+		field    enums.SortField
+		order    enums.SortOrder
+		expected string
+	}{
+		{enums.SortFieldName, enums.SortOrderAsc, "name ASC"},
+		{enums.SortFieldName, enums.SortOrderDesc, "name DESC"},
+		{enums.SortFieldSize, enums.SortOrderAsc, "size ASC"},
+		{enums.SortFieldSize, enums.SortOrderDesc, "size DESC"},
+		{enums.SortFieldCreatedTime, enums.SortOrderAsc, "created_time ASC"},
+		{enums.SortFieldCreatedTime, enums.SortOrderDesc, "created_time DESC"},
+		{enums.SortFieldModifiedTime, enums.SortOrderAsc, "modified_time ASC"},
+		{enums.SortFieldModifiedTime, enums.SortOrderDesc, "modified_time DESC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := List(10, "").Sort(tt.field, tt.order)["order_by"]; got != tt.expected {
+				t.Errorf("order_by = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+
+	if _, ok := List(10, "").Sort("", enums.SortOrderDesc)["order_by"]; ok {
+		t.Error("expected no order_by without a field")
+	}
+}
@@ -2,6 +2,9 @@ package signer
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/zhz8888/pikpakapi-go/internal/constants"
@@ -14,7 +17,7 @@ const (
 	PackageName   = constants.PackageName
 )
 
-var salts = []string{
+var defaultSalts = []string{
 	"Gez0T9ijiI9WCeTsKSg3SMlx",
 	"zQdbalsolyb1R/",
 	"ftOjr52zt51JD68C3s",
@@ -32,18 +35,220 @@ var salts = []string{
 	"zVof5yaJkPe3VFpadPof",
 }
 
-func GetTimestamp() int64 {
+// Config holds the app identity and salts the requests are signed with, so
+// that they can follow a new PikPak release without a code change.
+type Config struct {
+	// Clock returns the current Unix time in milliseconds. Nil means the
+	// system clock.
+	Clock         func() int64
+	ClientID      string
+	ClientVersion string
+	PackageName   string
+	SDKVersion    string
+	Salts         []string
+}
+
+// DefaultConfig returns the identity of the Android app release the client
+// was written against.
+func DefaultConfig() Config {
+	return Config{
+		ClientID:      constants.ClientID,
+		ClientVersion: constants.ClientVersion,
+		PackageName:   constants.PackageName,
+		SDKVersion:    constants.SDKVersion,
+		Salts:         append([]string(nil), defaultSalts...),
+	}
+}
+
+func (c Config) WithClock(clock func() int64) Config {
+	c.Clock = clock
+	return c
+}
+
+func (c Config) WithClientID(clientID string) Config {
+	c.ClientID = clientID
+	return c
+}
+
+func (c Config) WithClientVersion(version string) Config {
+	c.ClientVersion = version
+	return c
+}
+
+func (c Config) WithPackageName(packageName string) Config {
+	c.PackageName = packageName
+	return c
+}
+
+func (c Config) WithSDKVersion(version string) Config {
+	c.SDKVersion = version
+	return c
+}
+
+// WithSalts replaces the captcha sign salts, which are applied in order.
+func (c Config) WithSalts(salts ...string) Config {
+	c.Salts = append([]string(nil), salts...)
+	return c
+}
+
+// Timestamp returns the current Unix time in milliseconds from the clock.
+func (c Config) Timestamp() int64 {
+	if c.Clock != nil {
+		return c.Clock()
+	}
 	return time.Now().UnixMilli()
 }
 
-func CaptchaSign(deviceID string, timestamp string) string {
-	sign := ClientID + ClientVersion + PackageName + deviceID + timestamp
-	for _, salt := range salts {
+// CaptchaSign returns the captcha_sign of a captcha init request made by
+// deviceID at timestamp.
+func (c Config) CaptchaSign(deviceID string, timestamp string) string {
+	sign := c.ClientID + c.ClientVersion + c.PackageName + deviceID + timestamp
+	for _, salt := range c.Salts {
 		sign = crypto.MD5Hash(sign + salt)
 	}
 	return fmt.Sprintf("1.%s", sign)
 }
 
+// DeviceSign returns the device sign of deviceID for the package.
+func (c Config) DeviceSign(deviceID string) string {
+	return GenerateDeviceSign(deviceID, c.PackageName)
+}
+
+// UserAgent returns the app User-Agent for deviceID and userID, stamped
+// with the current time.
+func (c Config) UserAgent(deviceID string, userID string) string {
+	return c.userAgentParts(deviceID, userID, c.DeviceSign(deviceID)).build(c.Timestamp())
+}
+
+// userAgent is a User-Agent split around its datetime field, the only part
+// that changes between requests.
+type userAgent struct {
+	prefix, suffix string
+}
+
+func (ua userAgent) build(timestamp int64) string {
+	b := make([]byte, 0, len(ua.prefix)+len(ua.suffix)+13)
+	b = append(b, ua.prefix...)
+	b = strconv.AppendInt(b, timestamp, 10)
+	b = append(b, ua.suffix...)
+	return string(b)
+}
+
+func (c Config) userAgentParts(deviceID, userID, deviceSign string) userAgent {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("ANDROID-%s/%s ", c.PackageName, c.ClientVersion))
+	sb.WriteString("protocolVersion/200 ")
+	sb.WriteString("accesstype/ ")
+	sb.WriteString(fmt.Sprintf("clientid/%s ", c.ClientID))
+	sb.WriteString(fmt.Sprintf("clientversion/%s ", c.ClientVersion))
+	sb.WriteString("action_type/ ")
+	sb.WriteString("networktype/WIFI ")
+	sb.WriteString("sessionid/ ")
+	sb.WriteString(fmt.Sprintf("deviceid/%s ", deviceID))
+	sb.WriteString("providername/NONE ")
+	sb.WriteString(fmt.Sprintf("devicesign/%s ", deviceSign))
+	sb.WriteString("refresh_token/ ")
+	sb.WriteString(fmt.Sprintf("sdkversion/%s ", c.SDKVersion))
+	sb.WriteString("datetime/")
+	prefix := sb.String()
+
+	sb.Reset()
+	sb.WriteString(" ")
+	sb.WriteString(fmt.Sprintf("usrno/%s ", userID))
+	sb.WriteString(fmt.Sprintf("appname/%s ", c.PackageName))
+	sb.WriteString("session_origin/ ")
+	sb.WriteString("grant_type/ ")
+	sb.WriteString("appid/ ")
+	sb.WriteString("clientip/ ")
+	sb.WriteString("devicename/Xiaomi_M2004j7ac ")
+	sb.WriteString("osversion/13 ")
+	sb.WriteString("platformversion/10 ")
+	sb.WriteString("accessmode/ ")
+	sb.WriteString("devicemodel/M2004J7AC")
+
+	return userAgent{prefix: prefix, suffix: sb.String()}
+}
+
+// Holder shares a Config that can be swapped while requests read it. It
+// caches the device sign and User-Agent of the current config, so that only
+// the datetime field is filled in per request.
+type Holder struct {
+	cfg        atomic.Pointer[Config]
+	deviceSign atomic.Pointer[cachedDeviceSign]
+	userAgent  atomic.Pointer[cachedUserAgent]
+}
+
+type cachedDeviceSign struct {
+	deviceID, packageName string
+	sign                  string
+}
+
+type cachedUserAgent struct {
+	cfg              *Config
+	deviceID, userID string
+	ua               userAgent
+}
+
+func NewHolder(cfg Config) *Holder {
+	h := &Holder{}
+	h.Store(cfg)
+	return h
+}
+
+func (h *Holder) Load() Config {
+	return *h.cfg.Load()
+}
+
+// Store swaps the config. The cached User-Agent is rebuilt on next use.
+func (h *Holder) Store(cfg Config) {
+	h.cfg.Store(&cfg)
+}
+
+// Invalidate drops the cached device sign and User-Agent. Lookups with a
+// different device or user ID rebuild them anyway; Invalidate makes the
+// next request rebuild them unconditionally.
+func (h *Holder) Invalidate() {
+	h.deviceSign.Store(nil)
+	h.userAgent.Store(nil)
+}
+
+// DeviceSign returns the device sign of deviceID for the current package,
+// computing it only when either changed.
+func (h *Holder) DeviceSign(deviceID string) string {
+	packageName := h.cfg.Load().PackageName
+	if cached := h.deviceSign.Load(); cached != nil && cached.deviceID == deviceID && cached.packageName == packageName {
+		return cached.sign
+	}
+	sign := GenerateDeviceSign(deviceID, packageName)
+	h.deviceSign.Store(&cachedDeviceSign{deviceID: deviceID, packageName: packageName, sign: sign})
+	return sign
+}
+
+// UserAgent is Config.UserAgent of the current config, assembled once per
+// config, deviceID and userID.
+func (h *Holder) UserAgent(deviceID string, userID string) string {
+	cfg := h.cfg.Load()
+	cached := h.userAgent.Load()
+	if cached == nil || cached.cfg != cfg || cached.deviceID != deviceID || cached.userID != userID {
+		cached = &cachedUserAgent{
+			cfg:      cfg,
+			deviceID: deviceID,
+			userID:   userID,
+			ua:       cfg.userAgentParts(deviceID, userID, h.DeviceSign(deviceID)),
+		}
+		h.userAgent.Store(cached)
+	}
+	return cached.ua.build(cfg.Timestamp())
+}
+
+func GetTimestamp() int64 {
+	return time.Now().UnixMilli()
+}
+
+func CaptchaSign(deviceID string, timestamp string) string {
+	return DefaultConfig().CaptchaSign(deviceID, timestamp)
+}
+
 func GenerateDeviceSign(deviceID string, packageName string) string {
 	signatureBase := deviceID + packageName + "1appkey"
 
@@ -52,3 +257,8 @@ func GenerateDeviceSign(deviceID string, packageName string) string {
 
 	return fmt.Sprintf("div101.%s%s", deviceID, md5Result)
 }
+
+// BuildCustomUserAgent is DefaultConfig().UserAgent.
+func BuildCustomUserAgent(deviceID string, userID string) string {
+	return DefaultConfig().UserAgent(deviceID, userID)
+}
@@ -1,8 +1,10 @@
 package signer
 
 import (
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -117,7 +119,7 @@ func TestConstants(t *testing.T) {
 }
 
 func TestSalts_Length(t *testing.T) {
-	if len(salts) == 0 {
+	if len(defaultSalts) == 0 {
 		t.Error("Salts slice should not be empty")
 	}
 }
@@ -167,3 +169,176 @@ func TestGetTimestamp_ReasonableRange(t *testing.T) {
 		t.Errorf("GetTimestamp() = %s, length should be 10-14 digits (Unix timestamp in milliseconds)", now)
 	}
 }
+
+const (
+	goldenDeviceID  = "0123456789abcdef"
+	goldenTimestamp = int64(1700000000000)
+)
+
+func fixedClock() int64 { return goldenTimestamp }
+
+func TestConfig_GoldenSignatures(t *testing.T) {
+	cfg := DefaultConfig().WithClock(fixedClock)
+	timestamp := strconv.FormatInt(cfg.Timestamp(), 10)
+
+	if got, want := cfg.CaptchaSign(goldenDeviceID, timestamp), "1.73d4b9a2cb12f546e9702b86f4b20549"; got != want {
+		t.Errorf("CaptchaSign() = %s, want %s", got, want)
+	}
+	if got := CaptchaSign(goldenDeviceID, timestamp); got != cfg.CaptchaSign(goldenDeviceID, timestamp) {
+		t.Errorf("CaptchaSign() = %s, want the DefaultConfig sign", got)
+	}
+	if got, want := cfg.DeviceSign(goldenDeviceID), "div101.0123456789abcdefb1a3e686f8699342011ea71cfca3983f"; got != want {
+		t.Errorf("DeviceSign() = %s, want %s", got, want)
+	}
+
+	want := "ANDROID-com.pikcloud.pikpak/1.47.1 protocolVersion/200 accesstype/ clientid/YNxT9w7GMdWvEOKa " +
+		"clientversion/1.47.1 action_type/ networktype/WIFI sessionid/ deviceid/0123456789abcdef providername/NONE " +
+		"devicesign/div101.0123456789abcdefb1a3e686f8699342011ea71cfca3983f refresh_token/ sdkversion/2.0.4.204000 " +
+		"datetime/1700000000000 usrno/user1 appname/com.pikcloud.pikpak session_origin/ grant_type/ appid/ clientip/ " +
+		"devicename/Xiaomi_M2004j7ac osversion/13 platformversion/10 accessmode/ devicemodel/M2004J7AC"
+	if got := cfg.UserAgent(goldenDeviceID, "user1"); got != want {
+		t.Errorf("UserAgent() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestConfig_VersionSwap(t *testing.T) {
+	holder := NewHolder(DefaultConfig().WithClock(fixedClock))
+	before := holder.Load()
+	holder.Store(before.WithClientVersion("1.99.0"))
+	after := holder.Load()
+
+	timestamp := strconv.FormatInt(goldenTimestamp, 10)
+	if got, want := after.CaptchaSign(goldenDeviceID, timestamp), "1.b2fae820f29e0237a2b8e2a51ed2864d"; got != want {
+		t.Errorf("CaptchaSign() after the swap = %s, want %s", got, want)
+	}
+
+	ua := after.UserAgent(goldenDeviceID, "user1")
+	for _, part := range []string{"ANDROID-com.pikcloud.pikpak/1.99.0 ", "clientversion/1.99.0 "} {
+		if !strings.Contains(ua, part) {
+			t.Errorf("UserAgent() after the swap = %s, want %q", ua, part)
+		}
+	}
+	if strings.Contains(ua, ClientVersion) {
+		t.Errorf("UserAgent() after the swap still carries %s", ClientVersion)
+	}
+	if before.ClientVersion != ClientVersion {
+		t.Errorf("Store() changed an earlier Load() to %s", before.ClientVersion)
+	}
+}
+
+func TestConfig_Setters(t *testing.T) {
+	salts := []string{"a", "b"}
+	cfg := DefaultConfig().
+		WithClientID("id").
+		WithPackageName("com.example").
+		WithSDKVersion("9").
+		WithSalts(salts...)
+	salts[0] = "changed"
+
+	if cfg.ClientID != "id" || cfg.PackageName != "com.example" || cfg.SDKVersion != "9" || cfg.Salts[0] != "a" {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+	if want := GenerateDeviceSign(goldenDeviceID, "com.example"); cfg.DeviceSign(goldenDeviceID) != want {
+		t.Errorf("DeviceSign() = %s, want %s", cfg.DeviceSign(goldenDeviceID), want)
+	}
+	if got := cfg.WithSalts().CaptchaSign(goldenDeviceID, "1"); got != "1.id"+ClientVersion+"com.example"+goldenDeviceID+"1" {
+		t.Errorf("CaptchaSign() without salts = %s", got)
+	}
+
+	cfg.Salts[0] = "x"
+	if DefaultConfig().Salts[0] != defaultSalts[0] {
+		t.Error("DefaultConfig() shares its salts with earlier configs")
+	}
+}
+
+func TestHolder_ConcurrentSwap(t *testing.T) {
+	holder := NewHolder(DefaultConfig())
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			holder.Store(DefaultConfig().WithClientVersion(strconv.Itoa(i)))
+		}(i)
+		go func() {
+			defer wg.Done()
+			if holder.Load().ClientID != ClientID {
+				t.Error("Load() returned a partial config")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+var datetimeField = regexp.MustCompile(`datetime/\d+ `)
+
+func TestHolder_UserAgentMatchesFresh(t *testing.T) {
+	holder := NewHolder(DefaultConfig())
+	for _, ids := range [][2]string{{"device1", ""}, {"device1", "user1"}, {"device2", "user1"}, {"device2", "user1"}} {
+		cached := holder.UserAgent(ids[0], ids[1])
+		fresh := holder.Load().UserAgent(ids[0], ids[1])
+		if datetimeField.ReplaceAllString(cached, "") != datetimeField.ReplaceAllString(fresh, "") {
+			t.Errorf("UserAgent(%q, %q) =\n%s\nwant\n%s", ids[0], ids[1], cached, fresh)
+		}
+	}
+
+	fixed := NewHolder(DefaultConfig().WithClock(fixedClock))
+	fixed.UserAgent(goldenDeviceID, "user1")
+	if got, want := fixed.UserAgent(goldenDeviceID, "user1"), fixed.Load().UserAgent(goldenDeviceID, "user1"); got != want {
+		t.Errorf("UserAgent() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHolder_UserAgentRefreshesDatetime(t *testing.T) {
+	now := int64(1)
+	holder := NewHolder(DefaultConfig().WithClock(func() int64 { return now }))
+	if ua := holder.UserAgent("device", "user"); !strings.Contains(ua, " datetime/1 ") {
+		t.Errorf("UserAgent() = %s, want datetime/1", ua)
+	}
+	now = 2
+	if ua := holder.UserAgent("device", "user"); !strings.Contains(ua, " datetime/2 ") {
+		t.Errorf("UserAgent() = %s, want datetime/2", ua)
+	}
+}
+
+func TestHolder_CacheInvalidation(t *testing.T) {
+	holder := NewHolder(DefaultConfig().WithClock(fixedClock))
+	first := holder.UserAgent("device1", "user1")
+
+	if ua := holder.UserAgent("device1", "user2"); !strings.Contains(ua, "usrno/user2 ") {
+		t.Errorf("Expected a new user ID to rebuild the User-Agent, got %s", ua)
+	}
+	if ua := holder.UserAgent("device2", "user2"); !strings.Contains(ua, "devicesign/"+GenerateDeviceSign("device2", PackageName)+" ") {
+		t.Errorf("Expected a new device ID to rebuild the device sign, got %s", ua)
+	}
+
+	holder.Store(holder.Load().WithPackageName("com.example"))
+	if got, want := holder.DeviceSign("device2"), GenerateDeviceSign("device2", "com.example"); got != want {
+		t.Errorf("DeviceSign() after a package swap = %s, want %s", got, want)
+	}
+	if ua := holder.UserAgent("device2", "user2"); !strings.Contains(ua, "appname/com.example ") {
+		t.Errorf("Expected a config swap to rebuild the User-Agent, got %s", ua)
+	}
+
+	holder.Store(DefaultConfig().WithClock(fixedClock))
+	holder.Invalidate()
+	if got := holder.UserAgent("device1", "user1"); got != first {
+		t.Errorf("UserAgent() after Invalidate =\n%s\nwant\n%s", got, first)
+	}
+}
+
+func BenchmarkConfig_UserAgent(b *testing.B) {
+	cfg := DefaultConfig()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		cfg.UserAgent(goldenDeviceID, "user1")
+	}
+}
+
+func BenchmarkHolder_UserAgent(b *testing.B) {
+	holder := NewHolder(DefaultConfig())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		holder.UserAgent(goldenDeviceID, "user1")
+	}
+}
@@ -0,0 +1,132 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/zalando/go-keyring"
+)
+
+// KeyringService is the service name tokens are stored under in the system
+// keyring. The account name is the PikPak username.
+const KeyringService = "pikpakapi"
+
+var ErrKeyringUnavailable = errors.New("system keyring unavailable")
+
+// Keyring is a secret store keyed by service and user. Get returns
+// ErrTokenNotFound for missing entries and every method returns
+// ErrKeyringUnavailable when there is no keyring to talk to.
+type Keyring interface {
+	Get(service, user string) (string, error)
+	Set(service, user, secret string) error
+	Delete(service, user string) error
+}
+
+// systemKeyring is the keyring of the operating system through
+// github.com/zalando/go-keyring: the login keychain on macOS, the Secret
+// Service (GNOME Keyring, KWallet) over D-Bus on Linux and the Credential
+// Manager on Windows.
+type systemKeyring struct{}
+
+// SystemKeyring returns the keyring of the operating system.
+func SystemKeyring() Keyring {
+	return systemKeyring{}
+}
+
+// keyringError maps the errors of go-keyring to ErrTokenNotFound and
+// ErrKeyringUnavailable. Any other failure, such as a missing D-Bus session
+// on a headless server, means the keyring cannot be used.
+func keyringError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, keyring.ErrNotFound):
+		return ErrTokenNotFound
+	case errors.Is(err, keyring.ErrSetDataTooBig):
+		return err
+	default:
+		return fmt.Errorf("%w: %v", ErrKeyringUnavailable, err)
+	}
+}
+
+func (systemKeyring) Get(service, user string) (string, error) {
+	secret, err := keyring.Get(service, user)
+	return secret, keyringError(err)
+}
+
+func (systemKeyring) Set(service, user, secret string) error {
+	return keyringError(keyring.Set(service, user, secret))
+}
+
+func (systemKeyring) Delete(service, user string) error {
+	err := keyringError(keyring.Delete(service, user))
+	if errors.Is(err, ErrTokenNotFound) {
+		return nil
+	}
+	return err
+}
+
+// Logger receives the store's warnings. The client's Logger satisfies it.
+type Logger interface {
+	Warnf(format string, args ...interface{})
+}
+
+// KeyringTokenStore stores tokens in a Keyring. When the keyring is
+// unavailable, as on headless servers, it warns once through its Logger and
+// uses fallback instead.
+type KeyringTokenStore struct {
+	keyring  Keyring
+	fallback TokenStore
+	logger   Logger
+	warnOnce sync.Once
+}
+
+// NewKeyringTokenStore stores tokens in keyring, normally SystemKeyring().
+// fallback may be nil, in which case an unavailable keyring is an error.
+func NewKeyringTokenStore(keyring Keyring, fallback TokenStore) *KeyringTokenStore {
+	return &KeyringTokenStore{keyring: keyring, fallback: fallback}
+}
+
+// SetLogger sets the Logger that is warned when the store falls back. The
+// client sets its own Logger on a KeyringTokenStore passed to
+// WithTokenStore; without one the warning is dropped.
+func (s *KeyringTokenStore) SetLogger(logger Logger) {
+	s.logger = logger
+}
+
+func (s *KeyringTokenStore) Load(account string) (string, error) {
+	encoded, err := s.keyring.Get(KeyringService, account)
+	if s.useFallback(err) {
+		return s.fallback.Load(account)
+	}
+	return encoded, err
+}
+
+func (s *KeyringTokenStore) Save(account, encoded string) error {
+	err := s.keyring.Set(KeyringService, account, encoded)
+	if s.useFallback(err) {
+		return s.fallback.Save(account, encoded)
+	}
+	return err
+}
+
+func (s *KeyringTokenStore) Delete(account string) error {
+	err := s.keyring.Delete(KeyringService, account)
+	if s.useFallback(err) {
+		return s.fallback.Delete(account)
+	}
+	return err
+}
+
+func (s *KeyringTokenStore) useFallback(err error) bool {
+	if !errors.Is(err, ErrKeyringUnavailable) || s.fallback == nil {
+		return false
+	}
+	s.warnOnce.Do(func() {
+		if s.logger != nil {
+			s.logger.Warnf("%v, storing tokens in the fallback store", err)
+		}
+	})
+	return true
+}
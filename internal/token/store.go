@@ -0,0 +1,99 @@
+package token
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var ErrTokenNotFound = errors.New("token not found")
+
+// TokenStore persists encoded tokens, as produced by Encode, per account.
+// Load returns ErrTokenNotFound when nothing is stored for account.
+type TokenStore interface {
+	Load(account string) (string, error)
+	Save(account, encoded string) error
+	Delete(account string) error
+}
+
+// FileTokenStore keeps tokens for all accounts in one JSON file, written with
+// mode 0600.
+type FileTokenStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+func (s *FileTokenStore) Load(account string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	encoded, ok := tokens[account]
+	if !ok {
+		return "", ErrTokenNotFound
+	}
+	return encoded, nil
+}
+
+func (s *FileTokenStore) Save(account, encoded string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	tokens[account] = encoded
+	return s.write(tokens)
+}
+
+func (s *FileTokenStore) Delete(account string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	delete(tokens, account)
+	return s.write(tokens)
+}
+
+func (s *FileTokenStore) read() (map[string]string, error) {
+	tokens := map[string]string{}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token store: %w", err)
+	}
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse token store: %w", err)
+	}
+	return tokens, nil
+}
+
+func (s *FileTokenStore) write(tokens map[string]string) error {
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal token store: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create token store directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write token store: %w", err)
+	}
+	return nil
+}
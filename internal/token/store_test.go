@@ -0,0 +1,115 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestFileTokenStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens", "tokens.json")
+	store := NewFileTokenStore(path)
+
+	if _, err := store.Load("user@example.com"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("Expected ErrTokenNotFound, got %v", err)
+	}
+
+	store.Save("user@example.com", "encoded_1")
+	store.Save("other@example.com", "encoded_2")
+
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected token file with mode 0600, got %v, %v", info, err)
+	}
+
+	encoded, err := NewFileTokenStore(path).Load("user@example.com")
+	if err != nil || encoded != "encoded_1" {
+		t.Errorf("Expected encoded_1, got %q, %v", encoded, err)
+	}
+
+	store.Delete("user@example.com")
+	if _, err := store.Load("user@example.com"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("Expected ErrTokenNotFound after delete, got %v", err)
+	}
+	if encoded, _ := store.Load("other@example.com"); encoded != "encoded_2" {
+		t.Errorf("Expected other accounts to be kept, got %q", encoded)
+	}
+}
+
+func TestKeyringTokenStore(t *testing.T) {
+	keyring.MockInit()
+	fallback := NewFileTokenStore(filepath.Join(t.TempDir(), "tokens.json"))
+	store := NewKeyringTokenStore(SystemKeyring(), fallback)
+
+	if _, err := store.Load("user@example.com"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("Expected ErrTokenNotFound, got %v", err)
+	}
+	if err := store.Save("user@example.com", "encoded"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if secret, _ := keyring.Get(KeyringService, "user@example.com"); secret != "encoded" {
+		t.Error("Expected the token to be stored under the pikpakapi service")
+	}
+	if _, err := fallback.Load("user@example.com"); !errors.Is(err, ErrTokenNotFound) {
+		t.Error("Expected the fallback to stay unused while the keyring works")
+	}
+
+	encoded, err := store.Load("user@example.com")
+	if err != nil || encoded != "encoded" {
+		t.Errorf("Expected encoded, got %q, %v", encoded, err)
+	}
+	store.Delete("user@example.com")
+	if _, err := store.Load("user@example.com"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("Expected ErrTokenNotFound after delete, got %v", err)
+	}
+	if err := store.Delete("user@example.com"); err != nil {
+		t.Errorf("Expected deleting a missing token to succeed, got %v", err)
+	}
+}
+
+func TestKeyringTokenStore_FallsBackWithoutKeyring(t *testing.T) {
+	keyring.MockInitWithError(errors.New("no D-Bus session"))
+	fallback := NewFileTokenStore(filepath.Join(t.TempDir(), "tokens.json"))
+	store := NewKeyringTokenStore(SystemKeyring(), fallback)
+
+	if err := store.Save("user@example.com", "encoded"); err != nil {
+		t.Fatalf("Expected fallback save, got %v", err)
+	}
+	if encoded, err := fallback.Load("user@example.com"); err != nil || encoded != "encoded" {
+		t.Errorf("Expected the token in the fallback store, got %q, %v", encoded, err)
+	}
+	if encoded, err := store.Load("user@example.com"); err != nil || encoded != "encoded" {
+		t.Errorf("Expected fallback load, got %q, %v", encoded, err)
+	}
+
+	noFallback := NewKeyringTokenStore(SystemKeyring(), nil)
+	if err := noFallback.Save("user@example.com", "encoded"); !errors.Is(err, ErrKeyringUnavailable) {
+		t.Errorf("Expected ErrKeyringUnavailable without a fallback, got %v", err)
+	}
+}
+
+type warnLogger struct {
+	warnings []string
+}
+
+func (l *warnLogger) Warnf(format string, args ...interface{}) {
+	l.warnings = append(l.warnings, fmt.Sprintf(format, args...))
+}
+
+func TestKeyringTokenStore_WarnsOnceThroughLogger(t *testing.T) {
+	keyring.MockInitWithError(errors.New("no D-Bus session"))
+	store := NewKeyringTokenStore(SystemKeyring(), NewFileTokenStore(filepath.Join(t.TempDir(), "tokens.json")))
+	logger := &warnLogger{}
+	store.SetLogger(logger)
+
+	store.Save("user@example.com", "encoded")
+	store.Load("user@example.com")
+	if len(logger.warnings) != 1 || !strings.Contains(logger.warnings[0], "fallback") {
+		t.Errorf("Expected one fallback warning, got %q", logger.warnings)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build tools
+
+// Package tools pins the versions of the code generators go generate runs.
+package tools
+
+import (
+	_ "go.uber.org/mock/mockgen"
+)
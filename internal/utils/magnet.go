@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Magnet is a parsed BitTorrent magnet link.
+type Magnet struct {
+	// InfoHash is the lower case hex BTIH, also when the link gives it in
+	// base32.
+	InfoHash string
+	Name     string
+	Trackers []string
+}
+
+// IsMagnet reports whether s uses the magnet: scheme.
+func IsMagnet(s string) bool {
+	return len(s) >= 7 && strings.EqualFold(s[:7], "magnet:")
+}
+
+// ParseMagnet parses a magnet link with a urn:btih exact topic.
+func ParseMagnet(s string) (*Magnet, error) {
+	if !IsMagnet(s) {
+		return nil, fmt.Errorf("not a magnet link: %q", s)
+	}
+	query := s[len("magnet:"):]
+	query = strings.TrimPrefix(query, "?")
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid magnet link: %w", err)
+	}
+
+	m := &Magnet{Name: values.Get("dn"), Trackers: values["tr"]}
+	for _, xt := range values["xt"] {
+		if len(xt) < 9 || !strings.EqualFold(xt[:9], "urn:btih:") {
+			continue
+		}
+		if m.InfoHash, err = parseInfoHash(xt[9:]); err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("magnet link has no urn:btih info hash")
+}
+
+func parseInfoHash(s string) (string, error) {
+	switch len(s) {
+	case 40:
+		if _, err := hex.DecodeString(s); err == nil {
+			return strings.ToLower(s), nil
+		}
+	case 32:
+		if b, err := base32.StdEncoding.DecodeString(strings.ToUpper(s)); err == nil {
+			return hex.EncodeToString(b), nil
+		}
+	}
+	return "", fmt.Errorf("invalid magnet info hash %q", s)
+}
@@ -0,0 +1,46 @@
+package utils
+
+import "testing"
+
+func TestParseMagnet(t *testing.T) {
+	const hash = "c12fe1c06bba254a9dc9f519b335aa7c1367a88a"
+
+	tests := []struct {
+		name     string
+		link     string
+		wantHash string
+		wantErr  bool
+	}{
+		{"hex", "magnet:?xt=urn:btih:" + hash + "&dn=ubuntu.iso&tr=udp%3A%2F%2Ftracker.example%3A80", hash, false},
+		{"upper case hex", "magnet:?xt=urn:btih:C12FE1C06BBA254A9DC9F519B335AA7C1367A88A", hash, false},
+		{"base32", "magnet:?xt=urn:btih:YEX6DQDLXISUVHOJ6UM3GNNKPQJWPKEK", hash, false},
+		{"other topics first", "magnet:?xt=urn:ed2k:abc&xt=urn:btih:" + hash, hash, false},
+		{"no btih", "magnet:?xt=urn:ed2k:abc", "", true},
+		{"short hash", "magnet:?xt=urn:btih:abc", "", true},
+		{"not hex", "magnet:?xt=urn:btih:" + "z" + hash[1:], "", true},
+		{"not a magnet", "https://example.com/a.torrent", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ParseMagnet(tt.link)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %+v", m)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if m.InfoHash != tt.wantHash {
+				t.Errorf("Expected hash %s, got %s", tt.wantHash, m.InfoHash)
+			}
+		})
+	}
+
+	m, _ := ParseMagnet("magnet:?xt=urn:btih:" + hash + "&dn=ubuntu.iso&tr=udp%3A%2F%2Ftracker.example%3A80")
+	if m.Name != "ubuntu.iso" || len(m.Trackers) != 1 || m.Trackers[0] != "udp://tracker.example:80" {
+		t.Errorf("Unexpected magnet %+v", m)
+	}
+}
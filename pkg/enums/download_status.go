@@ -1,6 +1,9 @@
 package enums
 
-import "strings"
+import (
+	"encoding/json"
+	"strings"
+)
 
 type DownloadStatus string
 
@@ -16,8 +19,23 @@ func (s DownloadStatus) String() string {
 	return string(s)
 }
 
+// IsTerminal reports whether a task in status s will not change again.
+func (s DownloadStatus) IsTerminal() bool {
+	return s == DownloadStatusDone || s == DownloadStatusError || s == DownloadStatusNotFound
+}
+
+// IsActive reports whether a task in status s is queued or downloading.
+func (s DownloadStatus) IsActive() bool {
+	return s == DownloadStatusNotDownloading || s == DownloadStatusDownloading
+}
+
+// ParseDownloadStatus ignores case and surrounding space, and also accepts
+// the PHASE_TYPE_* names, mapped as DownloadStatusFromPhase does. It returns
+// DownloadStatusNotFound for unknown statuses; use RawDownloadStatus to keep
+// the original string.
 func ParseDownloadStatus(status string) DownloadStatus {
-	switch status {
+	normalized := strings.ToLower(strings.TrimSpace(status))
+	switch normalized {
 	case "not_downloading":
 		return DownloadStatusNotDownloading
 	case "downloading":
@@ -28,55 +46,78 @@ func ParseDownloadStatus(status string) DownloadStatus {
 		return DownloadStatusError
 	case "not_found":
 		return DownloadStatusNotFound
+	}
+	if strings.HasPrefix(normalized, "phase_type_") {
+		return DownloadStatusFromPhase(PhaseType(strings.ToUpper(normalized)))
+	}
+	return DownloadStatusNotFound
+}
+
+// DownloadStatusFromPhase maps the phase of an offline task to its download
+// status. Phases that are neither queued, running nor finished map to
+// DownloadStatusNotFound.
+func DownloadStatusFromPhase(phase PhaseType) DownloadStatus {
+	switch phase {
+	case PhaseTypePending, DownloadPhaseWaiting, DownloadPhasePaused:
+		return DownloadStatusNotDownloading
+	case PhaseTypeRunning, DownloadPhaseChecking, DownloadPhaseExtracting, DownloadPhaseConverting:
+		return DownloadStatusDownloading
+	case PhaseTypeComplete:
+		return DownloadStatusDone
+	case PhaseTypeError:
+		return DownloadStatusError
 	default:
 		return DownloadStatusNotFound
 	}
 }
 
-type DownloadPhase string
+// RawDownloadStatus is a DownloadStatus that remembers the string it was
+// decoded from, so unknown statuses survive a JSON round trip.
+type RawDownloadStatus struct {
+	Status DownloadStatus
+	Raw    string
+}
 
-const (
-	DownloadPhaseRunning   DownloadPhase = "PHASE_TYPE_RUNNING"
-	DownloadPhaseError     DownloadPhase = "PHASE_TYPE_ERROR"
-	DownloadPhaseComplete  DownloadPhase = "PHASE_TYPE_COMPLETE"
-	DownloadPhasePending   DownloadPhase = "PHASE_TYPE_PENDING"
-	DownloadPhasePaused    DownloadPhase = "PHASE_TYPE_PAUSED"
-	DownloadPhaseWaiting   DownloadPhase = "PHASE_TYPE_WAITING"
-	DownloadPhaseExtracting DownloadPhase = "PHASE_TYPE_EXTRACTING"
-	DownloadPhaseConverting DownloadPhase = "PHASE_TYPE_CONVERTING"
-	DownloadPhaseTe601     DownloadPhase = "PHASE_TYPE_TE601"
-	DownloadPhaseChecking  DownloadPhase = "PHASE_TYPE_CHECKING"
-)
+func (r *RawDownloadStatus) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	r.Raw = raw
+	r.Status = ParseDownloadStatus(raw)
+	return nil
+}
 
-func (p DownloadPhase) String() string {
-	return string(p)
+func (r RawDownloadStatus) MarshalJSON() ([]byte, error) {
+	if r.Raw != "" {
+		return json.Marshal(r.Raw)
+	}
+	return json.Marshal(string(r.Status))
 }
 
+// DownloadPhase is the former name of PhaseType.
+//
+// Deprecated: Use PhaseType.
+type DownloadPhase = PhaseType
+
+const (
+	DownloadPhaseRunning    PhaseType = PhaseTypeRunning
+	DownloadPhaseError      PhaseType = PhaseTypeError
+	DownloadPhaseComplete   PhaseType = PhaseTypeComplete
+	DownloadPhasePending    PhaseType = PhaseTypePending
+	DownloadPhasePaused     PhaseType = "PHASE_TYPE_PAUSED"
+	DownloadPhaseWaiting    PhaseType = "PHASE_TYPE_WAITING"
+	DownloadPhaseExtracting PhaseType = "PHASE_TYPE_EXTRACTING"
+	DownloadPhaseConverting PhaseType = "PHASE_TYPE_CONVERTING"
+	DownloadPhaseTe601      PhaseType = "PHASE_TYPE_TE601"
+	DownloadPhaseChecking   PhaseType = "PHASE_TYPE_CHECKING"
+)
+
+// ParseDownloadPhase is ParsePhaseType.
+//
+// Deprecated: Use ParsePhaseType.
 func ParseDownloadPhase(phase string) DownloadPhase {
-	switch phase {
-	case "PHASE_TYPE_RUNNING":
-		return DownloadPhaseRunning
-	case "PHASE_TYPE_ERROR":
-		return DownloadPhaseError
-	case "PHASE_TYPE_COMPLETE":
-		return DownloadPhaseComplete
-	case "PHASE_TYPE_PENDING":
-		return DownloadPhasePending
-	case "PHASE_TYPE_PAUSED":
-		return DownloadPhasePaused
-	case "PHASE_TYPE_WAITING":
-		return DownloadPhaseWaiting
-	case "PHASE_TYPE_EXTRACTING":
-		return DownloadPhaseExtracting
-	case "PHASE_TYPE_CONVERTING":
-		return DownloadPhaseConverting
-	case "PHASE_TYPE_TE601":
-		return DownloadPhaseTe601
-	case "PHASE_TYPE_CHECKING":
-		return DownloadPhaseChecking
-	default:
-		return DownloadPhase(phase)
-	}
+	return ParsePhaseType(phase)
 }
 
 type FileKind string
@@ -84,12 +125,17 @@ type FileKind string
 const (
 	FileKindFile   FileKind = "drive#file"
 	FileKindFolder FileKind = "drive#folder"
+	FileKindTask   FileKind = "drive#task"
 )
 
 func (k FileKind) IsFolder() bool {
 	return k == FileKindFolder
 }
 
+func (k FileKind) IsFile() bool {
+	return k == FileKindFile
+}
+
 func (k FileKind) String() string {
 	return string(k)
 }
@@ -100,29 +146,27 @@ func ParseFileKind(kind string) FileKind {
 		return FileKindFile
 	case "drive#folder":
 		return FileKindFolder
+	case "drive#task":
+		return FileKindTask
 	default:
 		return FileKind(kind)
 	}
 }
 
 func (s *DownloadStatus) UnmarshalJSON(data []byte) error {
-	unquoted := strings.Trim(string(data), `"`)
-	*s = ParseDownloadStatus(unquoted)
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*s = ParseDownloadStatus(raw)
 	return nil
 }
 
 func (s DownloadStatus) MarshalJSON() ([]byte, error) {
-	return []byte(`"` + string(s) + `"`), nil
-}
-
-func (p *DownloadPhase) UnmarshalJSON(data []byte) error {
-	unquoted := strings.Trim(string(data), `"`)
-	*p = ParseDownloadPhase(unquoted)
-	return nil
-}
-
-func (p DownloadPhase) MarshalJSON() ([]byte, error) {
-	return []byte(`"` + string(p) + `"`), nil
+	if s == "" {
+		return json.Marshal(string(DownloadStatusNotFound))
+	}
+	return json.Marshal(string(s))
 }
 
 func (k *FileKind) UnmarshalJSON(data []byte) error {
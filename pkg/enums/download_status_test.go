@@ -1,6 +1,7 @@
 package enums
 
 import (
+	"encoding/json"
 	"testing"
 )
 
@@ -37,6 +38,17 @@ func TestParseDownloadStatus(t *testing.T) {
 		{"not_found", DownloadStatusNotFound},
 		{"unknown", DownloadStatusNotFound},
 		{"", DownloadStatusNotFound},
+		{"DONE", DownloadStatusDone},
+		{"Downloading", DownloadStatusDownloading},
+		{"  error\n", DownloadStatusError},
+		{"Not_Downloading", DownloadStatusNotDownloading},
+		{"PHASE_TYPE_PENDING", DownloadStatusNotDownloading},
+		{"PHASE_TYPE_RUNNING", DownloadStatusDownloading},
+		{"PHASE_TYPE_COMPLETE", DownloadStatusDone},
+		{"phase_type_error", DownloadStatusError},
+		{" Phase_Type_Complete ", DownloadStatusDone},
+		{"PHASE_TYPE_NOT_FOUND", DownloadStatusNotFound},
+		{"PHASE_TYPE_", DownloadStatusNotFound},
 	}
 
 	for _, tt := range tests {
@@ -198,6 +210,7 @@ func TestFileKind_IsFolder(t *testing.T) {
 	}{
 		{FileKindFolder, true},
 		{FileKindFile, false},
+		{FileKindTask, false},
 		{FileKind("unknown"), false},
 		{FileKind(""), false},
 	}
@@ -211,6 +224,26 @@ func TestFileKind_IsFolder(t *testing.T) {
 	}
 }
 
+func TestFileKind_IsFile(t *testing.T) {
+	tests := []struct {
+		kind     FileKind
+		expected bool
+	}{
+		{FileKindFile, true},
+		{FileKindFolder, false},
+		{FileKindTask, false},
+		{FileKind("unknown"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.kind), func(t *testing.T) {
+			if got := tt.kind.IsFile(); got != tt.expected {
+				t.Errorf("IsFile() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestFileKind_String(t *testing.T) {
 	tests := []struct {
 		kind     FileKind
@@ -218,6 +251,7 @@ func TestFileKind_String(t *testing.T) {
 	}{
 		{FileKindFile, "drive#file"},
 		{FileKindFolder, "drive#folder"},
+		{FileKindTask, "drive#task"},
 	}
 
 	for _, tt := range tests {
@@ -236,6 +270,7 @@ func TestParseFileKind(t *testing.T) {
 	}{
 		{"drive#file", FileKindFile},
 		{"drive#folder", FileKindFolder},
+		{"drive#task", FileKindTask},
 		{"unknown_kind", FileKind("unknown_kind")},
 	}
 
@@ -330,3 +365,125 @@ func TestFileKind_Compare(t *testing.T) {
 		t.Error("Different FileKind values should not be equal")
 	}
 }
+
+func TestDownloadStatus_JSONRoundTrip(t *testing.T) {
+	for _, status := range []DownloadStatus{
+		DownloadStatusNotDownloading,
+		DownloadStatusDownloading,
+		DownloadStatusDone,
+		DownloadStatusError,
+		DownloadStatusNotFound,
+	} {
+		data, err := json.Marshal(status)
+		if err != nil {
+			t.Fatalf("Marshal(%s) error = %v", status, err)
+		}
+		var got DownloadStatus
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", data, err)
+		}
+		if got != status {
+			t.Errorf("round trip of %s = %s", status, got)
+		}
+	}
+
+	var unknown DownloadStatus
+	if err := json.Unmarshal([]byte(`"paused"`), &unknown); err != nil {
+		t.Fatalf("Unmarshal error = %v", err)
+	}
+	if unknown != DownloadStatusNotFound {
+		t.Errorf("unknown status = %s, want %s", unknown, DownloadStatusNotFound)
+	}
+
+	if err := json.Unmarshal([]byte(`1`), &unknown); err == nil {
+		t.Error("expected error for non-string status")
+	}
+}
+
+func TestRawDownloadStatus_JSON(t *testing.T) {
+	var v struct {
+		Status RawDownloadStatus `json:"status"`
+	}
+	if err := json.Unmarshal([]byte(`{"status":"paused"}`), &v); err != nil {
+		t.Fatalf("Unmarshal error = %v", err)
+	}
+	if v.Status.Status != DownloadStatusNotFound || v.Status.Raw != "paused" {
+		t.Errorf("got %+v", v.Status)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal error = %v", err)
+	}
+	if string(data) != `{"status":"paused"}` {
+		t.Errorf("Marshal = %s", data)
+	}
+
+	data, err = json.Marshal(RawDownloadStatus{Status: DownloadStatusDone})
+	if err != nil {
+		t.Fatalf("Marshal error = %v", err)
+	}
+	if string(data) != `"done"` {
+		t.Errorf("Marshal = %s, want \"done\"", data)
+	}
+}
+
+func TestDownloadStatus_IsTerminalIsActive(t *testing.T) {
+	tests := []struct {
+		status   DownloadStatus
+		terminal bool
+		active   bool
+	}{
+		{DownloadStatusNotDownloading, false, true},
+		{DownloadStatusDownloading, false, true},
+		{DownloadStatusDone, true, false},
+		{DownloadStatusError, true, false},
+		{DownloadStatusNotFound, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status.String(), func(t *testing.T) {
+			if got := tt.status.IsTerminal(); got != tt.terminal {
+				t.Errorf("IsTerminal() = %v, want %v", got, tt.terminal)
+			}
+			if got := tt.status.IsActive(); got != tt.active {
+				t.Errorf("IsActive() = %v, want %v", got, tt.active)
+			}
+		})
+	}
+}
+
+func TestDownloadStatusFromPhase(t *testing.T) {
+	tests := []struct {
+		phase    PhaseType
+		expected DownloadStatus
+	}{
+		{PhaseTypePending, DownloadStatusNotDownloading},
+		{PhaseTypeRunning, DownloadStatusDownloading},
+		{PhaseTypeComplete, DownloadStatusDone},
+		{PhaseTypeError, DownloadStatusError},
+		{"PHASE_TYPE_NOT_FOUND", DownloadStatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.phase.String(), func(t *testing.T) {
+			if got := DownloadStatusFromPhase(tt.phase); got != tt.expected {
+				t.Errorf("DownloadStatusFromPhase(%s) = %s, want %s", tt.phase, got, tt.expected)
+			}
+		})
+	}
+}
+
+func FuzzParseDownloadStatus(f *testing.F) {
+	for _, seed := range []string{"done", "DONE", " PHASE_TYPE_RUNNING ", "phase_type_", "", "\x00"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		status := ParseDownloadStatus(input)
+		switch status {
+		case DownloadStatusNotDownloading, DownloadStatusDownloading, DownloadStatusDone,
+			DownloadStatusError, DownloadStatusNotFound:
+		default:
+			t.Errorf("ParseDownloadStatus(%q) = %q, not a known status", input, status)
+		}
+	})
+}
@@ -0,0 +1,26 @@
+package enums
+
+// FolderType is the folder_type of an offline download request. It picks
+// where the server puts the downloaded file.
+type FolderType string
+
+const (
+	// FolderTypeNormal saves into the parent_id given with the request.
+	FolderTypeNormal FolderType = ""
+	// FolderTypeDownload saves into the account's "My Pack" download
+	// folder, and is used when no parent is given.
+	FolderTypeDownload FolderType = "DOWNLOAD"
+)
+
+func (t FolderType) String() string {
+	return string(t)
+}
+
+// DefaultFolderType is FolderTypeDownload without a parent folder and
+// FolderTypeNormal with one.
+func DefaultFolderType(parentID string) FolderType {
+	if parentID == "" {
+		return FolderTypeDownload
+	}
+	return FolderTypeNormal
+}
@@ -0,0 +1,12 @@
+package enums
+
+import "testing"
+
+func TestDefaultFolderType(t *testing.T) {
+	if got := DefaultFolderType(""); got != FolderTypeDownload || got.String() != "DOWNLOAD" {
+		t.Errorf("DefaultFolderType(\"\") = %q, want DOWNLOAD", got)
+	}
+	if got := DefaultFolderType("parent_id"); got != FolderTypeNormal || got.String() != "" {
+		t.Errorf("DefaultFolderType(parent) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,75 @@
+package enums
+
+import "strings"
+
+// PhaseType is the phase of an offline task, also used to filter files by
+// the task that created them. Phases this package does not know are kept
+// as they are.
+type PhaseType string
+
+const (
+	PhaseTypePending  PhaseType = "PHASE_TYPE_PENDING"
+	PhaseTypeRunning  PhaseType = "PHASE_TYPE_RUNNING"
+	PhaseTypeComplete PhaseType = "PHASE_TYPE_COMPLETE"
+	PhaseTypeError    PhaseType = "PHASE_TYPE_ERROR"
+)
+
+const phaseTypePrefix = "PHASE_TYPE_"
+
+var knownPhaseTypes = map[PhaseType]bool{
+	PhaseTypePending:        true,
+	PhaseTypeRunning:        true,
+	PhaseTypeComplete:       true,
+	PhaseTypeError:          true,
+	DownloadPhasePaused:     true,
+	DownloadPhaseWaiting:    true,
+	DownloadPhaseExtracting: true,
+	DownloadPhaseConverting: true,
+	DownloadPhaseTe601:      true,
+	DownloadPhaseChecking:   true,
+}
+
+func (p PhaseType) String() string {
+	return string(p)
+}
+
+// IsTerminal reports whether a task in phase p will not change again.
+func (p PhaseType) IsTerminal() bool {
+	return p == PhaseTypeComplete || p == PhaseTypeError
+}
+
+// ParsePhaseType accepts the server's names and, ignoring case, their short
+// forms such as "running". Unknown phases are returned unchanged.
+func ParsePhaseType(phase string) PhaseType {
+	normalized := strings.ToUpper(strings.TrimSpace(phase))
+	if !strings.HasPrefix(normalized, phaseTypePrefix) {
+		normalized = phaseTypePrefix + normalized
+	}
+	if p := PhaseType(normalized); knownPhaseTypes[p] {
+		return p
+	}
+	return PhaseType(phase)
+}
+
+// PhaseTypes converts phase names, as passed to OfflineList, to PhaseType
+// values for OfflineListPhases. A nil slice stays nil.
+func PhaseTypes(phases ...string) []PhaseType {
+	if phases == nil {
+		return nil
+	}
+	out := make([]PhaseType, len(phases))
+	for i, phase := range phases {
+		out[i] = ParsePhaseType(phase)
+	}
+	return out
+}
+
+func (p *PhaseType) UnmarshalJSON(data []byte) error {
+	unquoted := strings.Trim(string(data), `"`)
+	*p = ParsePhaseType(unquoted)
+	return nil
+}
+
+func (p PhaseType) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + string(p) + `"`), nil
+}
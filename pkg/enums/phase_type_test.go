@@ -0,0 +1,85 @@
+package enums
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParsePhaseType(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected PhaseType
+	}{
+		{"PHASE_TYPE_PENDING", PhaseTypePending},
+		{"PHASE_TYPE_RUNNING", PhaseTypeRunning},
+		{"PHASE_TYPE_COMPLETE", PhaseTypeComplete},
+		{"PHASE_TYPE_ERROR", PhaseTypeError},
+		{"PHASE_TYPE_PAUSED", DownloadPhasePaused},
+		{"running", PhaseTypeRunning},
+		{" Complete ", PhaseTypeComplete},
+		{"phase_type_error", PhaseTypeError},
+		{"PHASE_TYPE_ARCHIVED", PhaseType("PHASE_TYPE_ARCHIVED")},
+		{"unknown_phase", PhaseType("unknown_phase")},
+		{"", PhaseType("")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := ParsePhaseType(tt.input); got != tt.expected {
+				t.Errorf("ParsePhaseType(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPhaseType_IsTerminal(t *testing.T) {
+	tests := []struct {
+		phase    PhaseType
+		expected bool
+	}{
+		{PhaseTypePending, false},
+		{PhaseTypeRunning, false},
+		{PhaseTypeComplete, true},
+		{PhaseTypeError, true},
+		{PhaseType("PHASE_TYPE_ARCHIVED"), false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.phase.IsTerminal(); got != tt.expected {
+			t.Errorf("%s.IsTerminal() = %v, want %v", tt.phase, got, tt.expected)
+		}
+	}
+}
+
+func TestPhaseType_JSON(t *testing.T) {
+	var task struct {
+		Phase PhaseType `json:"phase"`
+	}
+	if err := json.Unmarshal([]byte(`{"phase": "PHASE_TYPE_COMPLETE"}`), &task); err != nil || task.Phase != PhaseTypeComplete {
+		t.Errorf("Unmarshal = %q, %v", task.Phase, err)
+	}
+	if err := json.Unmarshal([]byte(`{"phase": "PHASE_TYPE_NEW"}`), &task); err != nil || task.Phase != "PHASE_TYPE_NEW" {
+		t.Errorf("Expected unknown phases to be kept, got %q, %v", task.Phase, err)
+	}
+
+	data, err := json.Marshal([]PhaseType{PhaseTypeRunning, "PHASE_TYPE_NEW"})
+	if err != nil || string(data) != `["PHASE_TYPE_RUNNING","PHASE_TYPE_NEW"]` {
+		t.Errorf("Marshal = %s, %v", data, err)
+	}
+}
+
+func TestPhaseTypes(t *testing.T) {
+	if got := PhaseTypes(); got != nil {
+		t.Errorf("PhaseTypes() = %v, want nil", got)
+	}
+	got := PhaseTypes("PHASE_TYPE_RUNNING", "complete", "PHASE_TYPE_NEW")
+	want := []PhaseType{PhaseTypeRunning, PhaseTypeComplete, "PHASE_TYPE_NEW"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PhaseTypes() = %v, want %v", got, want)
+	}
+	var legacy []string
+	if got := PhaseTypes(legacy...); got != nil {
+		t.Errorf("Expected a nil legacy slice to stay nil, got %v", got)
+	}
+}
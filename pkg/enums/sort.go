@@ -0,0 +1,39 @@
+package enums
+
+// SortField is a field a file listing can be ordered by.
+type SortField string
+
+const (
+	SortFieldName         SortField = "name"
+	SortFieldSize         SortField = "size"
+	SortFieldCreatedTime  SortField = "created_time"
+	SortFieldModifiedTime SortField = "modified_time"
+)
+
+func (f SortField) String() string {
+	return string(f)
+}
+
+func (f SortField) IsValid() bool {
+	switch f {
+	case SortFieldName, SortFieldSize, SortFieldCreatedTime, SortFieldModifiedTime:
+		return true
+	}
+	return false
+}
+
+// SortOrder is the direction of a file listing's order.
+type SortOrder string
+
+const (
+	SortOrderAsc  SortOrder = "ASC"
+	SortOrderDesc SortOrder = "DESC"
+)
+
+func (o SortOrder) String() string {
+	return string(o)
+}
+
+func (o SortOrder) IsValid() bool {
+	return o == SortOrderAsc || o == SortOrderDesc
+}
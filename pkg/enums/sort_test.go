@@ -0,0 +1,45 @@
+package enums
+
+import "testing"
+
+func TestSortField_IsValid(t *testing.T) {
+	tests := []struct {
+		field    SortField
+		expected bool
+	}{
+		{SortFieldName, true},
+		{SortFieldSize, true},
+		{SortFieldCreatedTime, true},
+		{SortFieldModifiedTime, true},
+		{"", false},
+		{"NAME", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.field), func(t *testing.T) {
+			if got := tt.field.IsValid(); got != tt.expected {
+				t.Errorf("IsValid() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSortOrder_IsValid(t *testing.T) {
+	tests := []struct {
+		order    SortOrder
+		expected bool
+	}{
+		{SortOrderAsc, true},
+		{SortOrderDesc, true},
+		{"", false},
+		{"asc", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.order), func(t *testing.T) {
+			if got := tt.order.IsValid(); got != tt.expected {
+				t.Errorf("IsValid() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
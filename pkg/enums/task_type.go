@@ -0,0 +1,29 @@
+package enums
+
+// TaskType is the type of a task on the /drive/v1/tasks endpoints. Types this
+// package does not know are kept as they are.
+type TaskType string
+
+const (
+	TaskTypeOffline TaskType = "offline"
+)
+
+func (t TaskType) String() string {
+	return string(t)
+}
+
+func ParseTaskType(taskType string) TaskType {
+	return TaskType(taskType)
+}
+
+// TaskCreateType is the create_type of a request that creates a task from an
+// existing one.
+type TaskCreateType string
+
+const (
+	TaskCreateTypeRetry TaskCreateType = "RETRY"
+)
+
+func (t TaskCreateType) String() string {
+	return string(t)
+}
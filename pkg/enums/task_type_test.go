@@ -0,0 +1,37 @@
+package enums
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTaskType_String(t *testing.T) {
+	if TaskTypeOffline.String() != "offline" {
+		t.Errorf("TaskTypeOffline = %s, want offline", TaskTypeOffline)
+	}
+	if TaskCreateTypeRetry.String() != "RETRY" {
+		t.Errorf("TaskCreateTypeRetry = %s, want RETRY", TaskCreateTypeRetry)
+	}
+}
+
+func TestParseTaskType(t *testing.T) {
+	if got := ParseTaskType("offline"); got != TaskTypeOffline {
+		t.Errorf("ParseTaskType(offline) = %s", got)
+	}
+	if got := ParseTaskType("decompress"); got != TaskType("decompress") {
+		t.Errorf("ParseTaskType(decompress) = %s", got)
+	}
+}
+
+func TestTaskType_RequestBody(t *testing.T) {
+	data, err := json.Marshal(map[string]interface{}{
+		"type":        TaskTypeOffline,
+		"create_type": TaskCreateTypeRetry,
+	})
+	if err != nil {
+		t.Fatalf("Marshal error = %v", err)
+	}
+	if string(data) != `{"create_type":"RETRY","type":"offline"}` {
+		t.Errorf("Marshal = %s", data)
+	}
+}
@@ -0,0 +1,52 @@
+package enums
+
+import "strings"
+
+// ThumbnailSize is the size of the thumbnail links returned with file
+// metadata.
+type ThumbnailSize string
+
+const (
+	ThumbnailSizeSmall  ThumbnailSize = "SIZE_SMALL"
+	ThumbnailSizeMedium ThumbnailSize = "SIZE_MEDIUM"
+	ThumbnailSizeLarge  ThumbnailSize = "SIZE_LARGE"
+
+	ThumbnailSizeDefault = ThumbnailSizeMedium
+)
+
+func (s ThumbnailSize) String() string {
+	return string(s)
+}
+
+// IsValid reports whether s is one of the sizes the API accepts.
+func (s ThumbnailSize) IsValid() bool {
+	switch s {
+	case ThumbnailSizeSmall, ThumbnailSizeMedium, ThumbnailSizeLarge:
+		return true
+	}
+	return false
+}
+
+// ParseThumbnailSize accepts the API's names and, ignoring case, their short
+// forms such as "large". Unknown sizes are returned unchanged and fail
+// IsValid.
+func ParseThumbnailSize(size string) ThumbnailSize {
+	normalized := strings.ToUpper(strings.TrimSpace(size))
+	if !strings.HasPrefix(normalized, "SIZE_") {
+		normalized = "SIZE_" + normalized
+	}
+	if s := ThumbnailSize(normalized); s.IsValid() {
+		return s
+	}
+	return ThumbnailSize(size)
+}
+
+func (s *ThumbnailSize) UnmarshalJSON(data []byte) error {
+	unquoted := strings.Trim(string(data), `"`)
+	*s = ParseThumbnailSize(unquoted)
+	return nil
+}
+
+func (s ThumbnailSize) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + string(s) + `"`), nil
+}
@@ -0,0 +1,32 @@
+package enums
+
+import "testing"
+
+func TestParseThumbnailSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ThumbnailSize
+		valid    bool
+	}{
+		{"SIZE_SMALL", ThumbnailSizeSmall, true},
+		{"SIZE_MEDIUM", ThumbnailSizeMedium, true},
+		{"SIZE_LARGE", ThumbnailSizeLarge, true},
+		{"large", ThumbnailSizeLarge, true},
+		{" Small ", ThumbnailSizeSmall, true},
+		{"SIZE_HUGE", ThumbnailSize("SIZE_HUGE"), false},
+		{"", ThumbnailSize(""), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := ParseThumbnailSize(tt.input)
+			if got != tt.expected || got.IsValid() != tt.valid {
+				t.Errorf("ParseThumbnailSize(%q) = %q (valid %v), want %q (valid %v)", tt.input, got, got.IsValid(), tt.expected, tt.valid)
+			}
+		})
+	}
+
+	if ThumbnailSizeDefault != ThumbnailSizeMedium {
+		t.Errorf("ThumbnailSizeDefault = %q", ThumbnailSizeDefault)
+	}
+}
@@ -0,0 +1,42 @@
+package enums
+
+import "strings"
+
+// UploadType is the upload_type of a create-file request. The server echoes
+// the type it chose in its response. Types this package does not know are
+// kept as they are.
+type UploadType string
+
+const (
+	UploadTypeURL       UploadType = "UPLOAD_TYPE_URL"
+	UploadTypeResumable UploadType = "UPLOAD_TYPE_RESUMABLE"
+	UploadTypeForm      UploadType = "UPLOAD_TYPE_FORM"
+)
+
+func (t UploadType) String() string {
+	return string(t)
+}
+
+// ParseUploadType accepts the server's names and, ignoring case, their short
+// forms such as "resumable". Unknown types are returned unchanged.
+func ParseUploadType(uploadType string) UploadType {
+	normalized := strings.ToUpper(strings.TrimSpace(uploadType))
+	if !strings.HasPrefix(normalized, "UPLOAD_TYPE_") {
+		normalized = "UPLOAD_TYPE_" + normalized
+	}
+	switch t := UploadType(normalized); t {
+	case UploadTypeURL, UploadTypeResumable, UploadTypeForm:
+		return t
+	}
+	return UploadType(uploadType)
+}
+
+func (t *UploadType) UnmarshalJSON(data []byte) error {
+	unquoted := strings.Trim(string(data), `"`)
+	*t = ParseUploadType(unquoted)
+	return nil
+}
+
+func (t UploadType) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + string(t) + `"`), nil
+}
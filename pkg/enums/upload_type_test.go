@@ -0,0 +1,67 @@
+package enums
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestUploadType_String(t *testing.T) {
+	tests := []struct {
+		uploadType UploadType
+		expected   string
+	}{
+		{UploadTypeURL, "UPLOAD_TYPE_URL"},
+		{UploadTypeResumable, "UPLOAD_TYPE_RESUMABLE"},
+		{UploadTypeForm, "UPLOAD_TYPE_FORM"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := tt.uploadType.String(); got != tt.expected {
+				t.Errorf("String() = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseUploadType(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected UploadType
+	}{
+		{"UPLOAD_TYPE_URL", UploadTypeURL},
+		{"UPLOAD_TYPE_RESUMABLE", UploadTypeResumable},
+		{"UPLOAD_TYPE_FORM", UploadTypeForm},
+		{"resumable", UploadTypeResumable},
+		{"Form", UploadTypeForm},
+		{"UPLOAD_TYPE_UNKNOWN", UploadType("UPLOAD_TYPE_UNKNOWN")},
+		{"", UploadType("")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := ParseUploadType(tt.input); got != tt.expected {
+				t.Errorf("ParseUploadType(%q) = %s, want %s", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUploadType_JSON(t *testing.T) {
+	var v struct {
+		UploadType UploadType `json:"upload_type"`
+	}
+	if err := json.Unmarshal([]byte(`{"upload_type":"UPLOAD_TYPE_RESUMABLE"}`), &v); err != nil {
+		t.Fatalf("Unmarshal error = %v", err)
+	}
+	if v.UploadType != UploadTypeResumable {
+		t.Errorf("UploadType = %s, want %s", v.UploadType, UploadTypeResumable)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal error = %v", err)
+	}
+	if string(data) != `{"upload_type":"UPLOAD_TYPE_RESUMABLE"}` {
+		t.Errorf("Marshal = %s", data)
+	}
+}
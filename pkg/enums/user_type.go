@@ -0,0 +1,62 @@
+package enums
+
+import (
+	"strconv"
+	"strings"
+)
+
+// UserType is the account tier, as the user_type number in the about
+// response. Numbers this package does not know are kept, so the raw value
+// stays available; IsKnown reports whether a value is one of the constants.
+type UserType int
+
+const (
+	UserTypeUnknown  UserType = 0
+	UserTypeFree     UserType = 1
+	UserTypePremium  UserType = 2
+	UserTypePlatinum UserType = 3
+)
+
+func (t UserType) String() string {
+	switch t {
+	case UserTypeUnknown:
+		return "unknown"
+	case UserTypeFree:
+		return "free"
+	case UserTypePremium:
+		return "premium"
+	case UserTypePlatinum:
+		return "platinum"
+	default:
+		return "unknown(" + strconv.Itoa(int(t)) + ")"
+	}
+}
+
+func (t UserType) IsKnown() bool {
+	return t >= UserTypeFree && t <= UserTypePlatinum
+}
+
+// IsPremium reports whether t is a paid tier.
+func (t UserType) IsPremium() bool {
+	return t == UserTypePremium || t == UserTypePlatinum
+}
+
+// ParseUserType accepts a user_type number and, ignoring case, the tier
+// names, including the "novip" used by the VIP endpoint. Unknown numbers
+// are kept; other unknown input gives UserTypeUnknown.
+func ParseUserType(userType string) UserType {
+	normalized := strings.ToLower(strings.TrimSpace(userType))
+	if n, err := strconv.Atoi(normalized); err == nil {
+		return UserType(n)
+	}
+	switch normalized {
+	case "free", "novip":
+		return UserTypeFree
+	case "premium", "vip":
+		return UserTypePremium
+	case "platinum":
+		return UserTypePlatinum
+	default:
+		return UserTypeUnknown
+	}
+}
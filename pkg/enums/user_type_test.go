@@ -0,0 +1,75 @@
+package enums
+
+import "testing"
+
+func TestUserType_String(t *testing.T) {
+	tests := []struct {
+		userType UserType
+		expected string
+	}{
+		{UserTypeUnknown, "unknown"},
+		{UserTypeFree, "free"},
+		{UserTypePremium, "premium"},
+		{UserTypePlatinum, "platinum"},
+		{UserType(7), "unknown(7)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := tt.userType.String(); got != tt.expected {
+				t.Errorf("String() = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseUserType(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected UserType
+	}{
+		{"1", UserTypeFree},
+		{"2", UserTypePremium},
+		{"3", UserTypePlatinum},
+		{"7", UserType(7)},
+		{"novip", UserTypeFree},
+		{"Free", UserTypeFree},
+		{"premium", UserTypePremium},
+		{"PLATINUM", UserTypePlatinum},
+		{"gold", UserTypeUnknown},
+		{"", UserTypeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := ParseUserType(tt.input); got != tt.expected {
+				t.Errorf("ParseUserType(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUserType_IsPremium(t *testing.T) {
+	tests := []struct {
+		userType UserType
+		premium  bool
+		known    bool
+	}{
+		{UserTypeUnknown, false, false},
+		{UserTypeFree, false, true},
+		{UserTypePremium, true, true},
+		{UserTypePlatinum, true, true},
+		{UserType(7), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.userType.String(), func(t *testing.T) {
+			if got := tt.userType.IsPremium(); got != tt.premium {
+				t.Errorf("IsPremium() = %v, want %v", got, tt.premium)
+			}
+			if got := tt.userType.IsKnown(); got != tt.known {
+				t.Errorf("IsKnown() = %v, want %v", got, tt.known)
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+// Package maintenance runs the housekeeping usually scheduled against an
+// account: retrying failed offline tasks, purging old completed ones,
+// emptying the trash when the drive fills up and alerting on quota.
+//
+// Every request goes through the client, one at a time, so the client's
+// retry policy and request limits apply and a run can share the client with
+// normal traffic.
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpak"
+)
+
+// purgeBatchSize bounds the tasks deleted by one request.
+const purgeBatchSize = 100
+
+// Client is the part of pikpak.PikPakAPI maintenance uses.
+type Client interface {
+	OfflineListPhases(ctx context.Context, size int, nextPageToken string, phases []enums.PhaseType) (map[string]interface{}, error)
+	OfflineTaskRetry(ctx context.Context, taskID string) error
+	DeleteTasks(ctx context.Context, taskIDs []string, deleteFiles bool) error
+	GetStorageInfo(ctx context.Context) (pikpak.StorageInfo, error)
+	EmptyTrash(ctx context.Context) error
+}
+
+// Policy selects what a run does. Zero values turn the steps off.
+type Policy struct {
+	// RetryFailed retries every failed offline task.
+	RetryFailed bool
+	// PurgeCompletedOlderThan deletes completed tasks, keeping their files,
+	// last updated longer ago than this.
+	PurgeCompletedOlderThan time.Duration
+	// EmptyTrashAbovePercent empties the trash when the drive is at least
+	// this full.
+	EmptyTrashAbovePercent int
+	// QuotaAlertPercent reports a quota alert when the drive is at least this
+	// full after the other steps.
+	QuotaAlertPercent int
+
+	// DryRun reports the actions without taking them.
+	DryRun bool
+	// OnAction, when set, is called with each action as it is taken; see
+	// LogActions.
+	OnAction func(Action)
+}
+
+type ActionKind string
+
+const (
+	ActionRetryTask  ActionKind = "retry_task"
+	ActionPurgeTasks ActionKind = "purge_tasks"
+	ActionEmptyTrash ActionKind = "empty_trash"
+	ActionQuotaAlert ActionKind = "quota_alert"
+)
+
+// Action is one thing a run did, or would do in a dry run.
+type Action struct {
+	Kind ActionKind
+	// TaskIDs are the tasks retried or purged.
+	TaskIDs []string
+	// Bytes is the size of the trash emptied.
+	Bytes uint64
+	// UsagePercent is the drive usage that triggered the action.
+	UsagePercent int
+	DryRun       bool
+	Err          error
+}
+
+func (a Action) String() string {
+	var s string
+	switch a.Kind {
+	case ActionRetryTask:
+		s = "retry task " + strings.Join(a.TaskIDs, ", ")
+	case ActionPurgeTasks:
+		s = fmt.Sprintf("purge %d completed tasks", len(a.TaskIDs))
+	case ActionEmptyTrash:
+		s = fmt.Sprintf("empty trash (%d bytes, drive %d%% full)", a.Bytes, a.UsagePercent)
+	case ActionQuotaAlert:
+		s = fmt.Sprintf("quota alert: drive %d%% full", a.UsagePercent)
+	default:
+		s = string(a.Kind)
+	}
+	if a.DryRun {
+		s += " (dry run)"
+	}
+	if a.Err != nil {
+		s += ": " + a.Err.Error()
+	}
+	return s
+}
+
+// LogActions returns an OnAction that prints each action to logger, or to
+// the standard logger when logger is nil.
+func LogActions(logger *log.Logger) func(Action) {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return func(a Action) {
+		logger.Printf("maintenance: %s", a)
+	}
+}
+
+// Report is the outcome of a run.
+type Report struct {
+	DryRun  bool
+	Actions []Action
+	// Retried and Purged are the tasks retried and purged without error,
+	// and TrashEmptied is set when the trash was emptied. In a dry run they
+	// describe what would have been done.
+	Retried      []string
+	Purged       []string
+	TrashEmptied bool
+	// Storage is the last storage reading and UsagePercent the share of the
+	// quota it uses, 0 for unlimited drives.
+	Storage      pikpak.StorageInfo
+	UsagePercent int
+	QuotaAlert   bool
+}
+
+var now = time.Now
+
+// Run applies policy to the account of client. It keeps going when a step
+// fails and returns the report together with a *pikpak.BatchError listing
+// the failed steps, each identified by its action kind or the listing that
+// failed.
+func Run(ctx context.Context, client Client, policy Policy) (*Report, error) {
+	r := &runner{client: client, policy: policy, report: &Report{DryRun: policy.DryRun}, errs: pikpak.NewBatchError(0)}
+
+	if policy.RetryFailed {
+		r.retryFailed(ctx)
+	}
+	if policy.PurgeCompletedOlderThan > 0 {
+		r.purgeCompleted(ctx)
+	}
+	if policy.EmptyTrashAbovePercent > 0 || policy.QuotaAlertPercent > 0 {
+		r.checkStorage(ctx)
+	}
+	return r.report, r.errs.Err()
+}
+
+// RunEvery runs policy immediately and then every interval until ctx is
+// done, passing each outcome to fn. It returns ctx.Err().
+func RunEvery(ctx context.Context, client Client, policy Policy, interval time.Duration, fn func(*Report, error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		report, err := Run(ctx, client, policy)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if fn != nil {
+			fn(report, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+type runner struct {
+	client Client
+	policy Policy
+	report *Report
+	errs   *pikpak.BatchError
+}
+
+// record counts a step of the run and keeps its error, if any.
+func (r *runner) record(id string, err error) {
+	r.errs.Total++
+	r.errs.Add(id, err)
+}
+
+func (r *runner) act(a Action) {
+	a.DryRun = r.policy.DryRun
+	r.report.Actions = append(r.report.Actions, a)
+	r.record(string(a.Kind), a.Err)
+	if r.policy.OnAction != nil {
+		r.policy.OnAction(a)
+	}
+}
+
+func (r *runner) retryFailed(ctx context.Context) {
+	tasks, err := r.listTasks(ctx, enums.PhaseTypeError)
+	r.record("list failed tasks", err)
+	if err != nil {
+		return
+	}
+	for _, task := range tasks {
+		id, _ := task["id"].(string)
+		if id == "" {
+			continue
+		}
+		var err error
+		if !r.policy.DryRun {
+			err = r.client.OfflineTaskRetry(ctx, id)
+		}
+		r.act(Action{Kind: ActionRetryTask, TaskIDs: []string{id}, Err: err})
+		if err == nil {
+			r.report.Retried = append(r.report.Retried, id)
+		}
+	}
+}
+
+func (r *runner) purgeCompleted(ctx context.Context) {
+	tasks, err := r.listTasks(ctx, enums.PhaseTypeComplete)
+	r.record("list completed tasks", err)
+	if err != nil {
+		return
+	}
+	cutoff := now().Add(-r.policy.PurgeCompletedOlderThan)
+	var ids []string
+	for _, task := range tasks {
+		id, _ := task["id"].(string)
+		if id != "" && taskTime(task).Before(cutoff) {
+			ids = append(ids, id)
+		}
+	}
+
+	for len(ids) > 0 {
+		batch := ids
+		if len(batch) > purgeBatchSize {
+			batch = batch[:purgeBatchSize]
+		}
+		ids = ids[len(batch):]
+
+		var err error
+		if !r.policy.DryRun {
+			err = r.client.DeleteTasks(ctx, batch, false)
+		}
+		r.act(Action{Kind: ActionPurgeTasks, TaskIDs: batch, Err: err})
+		if err == nil {
+			r.report.Purged = append(r.report.Purged, batch...)
+		}
+	}
+}
+
+func (r *runner) checkStorage(ctx context.Context) {
+	if !r.readStorage(ctx) {
+		return
+	}
+
+	if threshold := r.policy.EmptyTrashAbovePercent; threshold > 0 && r.report.UsagePercent >= threshold && r.report.Storage.TrashBytes > 0 {
+		var err error
+		if !r.policy.DryRun {
+			err = r.client.EmptyTrash(ctx)
+		}
+		r.act(Action{Kind: ActionEmptyTrash, Bytes: r.report.Storage.TrashBytes, UsagePercent: r.report.UsagePercent, Err: err})
+		if err == nil {
+			r.report.TrashEmptied = true
+			if !r.policy.DryRun && !r.readStorage(ctx) {
+				return
+			}
+		}
+	}
+
+	if threshold := r.policy.QuotaAlertPercent; threshold > 0 && r.report.UsagePercent >= threshold {
+		r.report.QuotaAlert = true
+		r.act(Action{Kind: ActionQuotaAlert, UsagePercent: r.report.UsagePercent})
+	}
+}
+
+func (r *runner) readStorage(ctx context.Context) bool {
+	storage, err := r.client.GetStorageInfo(ctx)
+	r.record("read storage", err)
+	if err != nil {
+		return false
+	}
+	r.report.Storage = storage
+	r.report.UsagePercent = 0
+	if !storage.IsUnlimited && storage.TotalBytes > 0 {
+		r.report.UsagePercent = int(storage.UsedBytes * 100 / storage.TotalBytes)
+	}
+	return true
+}
+
+func (r *runner) listTasks(ctx context.Context, phase enums.PhaseType) ([]map[string]interface{}, error) {
+	var tasks []map[string]interface{}
+	pageToken := ""
+	for {
+		result, err := r.client.OfflineListPhases(ctx, 0, pageToken, []enums.PhaseType{phase})
+		if err != nil {
+			return nil, err
+		}
+		list, _ := result["tasks"].([]interface{})
+		for _, t := range list {
+			if task, ok := t.(map[string]interface{}); ok {
+				tasks = append(tasks, task)
+			}
+		}
+		pageToken, _ = result["next_page_token"].(string)
+		if pageToken == "" {
+			return tasks, nil
+		}
+	}
+}
+
+// taskTime is when a task last changed, falling back to its creation. Tasks
+// without either never count as old.
+func taskTime(task map[string]interface{}) time.Time {
+	for _, key := range []string{"updated_time", "created_time"} {
+		if s, ok := task[key].(string); ok {
+			if t, err := time.Parse(time.RFC3339, s); err == nil {
+				return t
+			}
+		}
+	}
+	return now()
+}
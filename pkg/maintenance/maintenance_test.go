@@ -0,0 +1,199 @@
+package maintenance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
+	"github.com/zhz8888/pikpakapi-go/pkg/mocks"
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpak"
+	"go.uber.org/mock/gomock"
+)
+
+var testNow = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func init() {
+	now = func() time.Time { return testNow }
+}
+
+func daysAgo(days int) string {
+	return testNow.AddDate(0, 0, -days).Format(time.RFC3339)
+}
+
+// messyAccount is a mock account with two failed tasks, the second of which
+// cannot be retried, completed tasks of various ages over two pages, and a
+// 95% full drive with 20 GB in the trash. Tests expect the calls that change
+// the account themselves; emptyTrash implements EmptyTrash.
+type messyAccount struct {
+	*mocks.MockPikPakAPI
+	mu    sync.Mutex
+	trash uint64
+}
+
+const gb = 1 << 30
+
+func newMessyAccount(t *testing.T) *messyAccount {
+	a := &messyAccount{MockPikPakAPI: mocks.NewMockPikPakAPI(gomock.NewController(t)), trash: 20 * gb}
+	a.EXPECT().OfflineListPhases(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(ctx context.Context, size int, nextPageToken string, phases []enums.PhaseType) (map[string]interface{}, error) {
+			switch {
+			case phases[0] == enums.PhaseTypeError:
+				return map[string]interface{}{"tasks": []interface{}{
+					map[string]interface{}{"id": "e1", "phase": "PHASE_TYPE_ERROR"},
+					map[string]interface{}{"id": "e2", "phase": "PHASE_TYPE_ERROR"},
+				}}, nil
+			case nextPageToken == "":
+				return map[string]interface{}{
+					"tasks": []interface{}{
+						map[string]interface{}{"id": "c1", "updated_time": daysAgo(30), "created_time": daysAgo(31)},
+						map[string]interface{}{"id": "c2", "updated_time": daysAgo(1), "created_time": daysAgo(40)},
+					},
+					"next_page_token": "2",
+				}, nil
+			default:
+				return map[string]interface{}{"tasks": []interface{}{
+					map[string]interface{}{"id": "c3", "created_time": daysAgo(8)},
+					map[string]interface{}{"id": "c4"},
+				}}, nil
+			}
+		})
+	a.EXPECT().GetStorageInfo(gomock.Any()).AnyTimes().
+		DoAndReturn(func(ctx context.Context) (pikpak.StorageInfo, error) {
+			a.mu.Lock()
+			defer a.mu.Unlock()
+			return pikpak.StorageInfo{TotalBytes: 100 * gb, UsedBytes: 75*gb + a.trash, TrashBytes: a.trash}, nil
+		})
+	return a
+}
+
+func (a *messyAccount) emptyTrash(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.trash = 0
+	return nil
+}
+
+var fullPolicy = Policy{
+	RetryFailed:             true,
+	PurgeCompletedOlderThan: 7 * 24 * time.Hour,
+	EmptyTrashAbovePercent:  90,
+	QuotaAlertPercent:       75,
+}
+
+func TestRun(t *testing.T) {
+	account := newMessyAccount(t)
+	account.EXPECT().OfflineTaskRetry(gomock.Any(), "e1").Return(nil)
+	account.EXPECT().OfflineTaskRetry(gomock.Any(), "e2").Return(pikpak.ErrFileNotFound)
+	account.EXPECT().DeleteTasks(gomock.Any(), []string{"c1", "c3"}, false).Return(nil)
+	account.EXPECT().EmptyTrash(gomock.Any()).DoAndReturn(account.emptyTrash)
+	var seen []ActionKind
+	policy := fullPolicy
+	policy.OnAction = func(a Action) { seen = append(seen, a.Kind) }
+
+	report, err := Run(context.Background(), account, policy)
+	if !errors.Is(err, pikpak.ErrFileNotFound) {
+		t.Errorf("Expected the failed retry to be returned, got %v", err)
+	}
+	var batchErr *pikpak.BatchError
+	if !errors.As(err, &batchErr) || batchErr.Failed() != 1 || batchErr.Items[0].ID != string(ActionRetryTask) {
+		t.Errorf("Expected a BatchError with the failed retry, got %v", err)
+	}
+
+	if !reflect.DeepEqual(report.Retried, []string{"e1"}) {
+		t.Errorf("Expected e1 retried, got %v", report.Retried)
+	}
+
+	// c2 was updated recently and c4 has no time.
+	if !reflect.DeepEqual(report.Purged, []string{"c1", "c3"}) {
+		t.Errorf("Expected c1 and c3 purged, got %v", report.Purged)
+	}
+
+	if !report.TrashEmptied {
+		t.Error("Expected the trash to be emptied at 95%")
+	}
+	if report.UsagePercent != 75 || !report.QuotaAlert {
+		t.Errorf("Expected a quota alert at 75%% after emptying the trash, got %d%% %v", report.UsagePercent, report.QuotaAlert)
+	}
+
+	want := []ActionKind{ActionRetryTask, ActionRetryTask, ActionPurgeTasks, ActionEmptyTrash, ActionQuotaAlert}
+	if !reflect.DeepEqual(seen, want) {
+		t.Errorf("Expected actions %v, got %v", want, seen)
+	}
+	if len(report.Actions) != len(want) || report.Actions[3].UsagePercent != 95 || report.Actions[1].Err == nil {
+		t.Errorf("Unexpected report actions %+v", report.Actions)
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	// No calls that change the account are expected.
+	account := newMessyAccount(t)
+	var logs bytes.Buffer
+	policy := fullPolicy
+	policy.DryRun = true
+	policy.OnAction = LogActions(log.New(&logs, "", 0))
+
+	report, err := Run(context.Background(), account, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.DryRun || !reflect.DeepEqual(report.Retried, []string{"e1", "e2"}) || !report.TrashEmptied {
+		t.Errorf("Expected the report to describe the planned actions, got %+v", report)
+	}
+	// The trash was not really emptied.
+	if report.UsagePercent != 95 || !report.QuotaAlert {
+		t.Errorf("Expected the alert at 95%%, got %d%%", report.UsagePercent)
+	}
+
+	out := logs.String()
+	for _, line := range []string{
+		"maintenance: retry task e1 (dry run)",
+		"maintenance: purge 2 completed tasks (dry run)",
+		"maintenance: empty trash (21474836480 bytes, drive 95% full) (dry run)",
+		"maintenance: quota alert: drive 95% full (dry run)",
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("Expected log line %q in:\n%s", line, out)
+		}
+	}
+}
+
+func TestRunNothingToDo(t *testing.T) {
+	account := mocks.NewMockPikPakAPI(gomock.NewController(t))
+	account.EXPECT().GetStorageInfo(gomock.Any()).
+		Return(pikpak.StorageInfo{IsUnlimited: true, UsedBytes: 1 << 40, TrashBytes: 1 << 30}, nil)
+	report, err := Run(context.Background(), account, Policy{EmptyTrashAbovePercent: 1, QuotaAlertPercent: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Actions) != 0 || report.QuotaAlert {
+		t.Errorf("Expected no actions on an unlimited drive, got %+v", report.Actions)
+	}
+}
+
+func TestRunEvery(t *testing.T) {
+	account := newMessyAccount(t)
+	account.EXPECT().OfflineTaskRetry(gomock.Any(), gomock.Any()).Return(nil).Times(6)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runs := 0
+	err := RunEvery(ctx, account, Policy{RetryFailed: true}, time.Millisecond, func(report *Report, err error) {
+		runs++
+		if runs == 3 {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if runs != 3 {
+		t.Errorf("Expected three runs, got %d", runs)
+	}
+}
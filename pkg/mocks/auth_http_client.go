@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zhz8888/pikpakapi-go/internal/auth (interfaces: HTTPClient)
+//
+// Generated by this command:
+//
+//	mockgen -destination auth_http_client.go -package mocks -mock_names HTTPClient=MockAuthHTTPClient github.com/zhz8888/pikpakapi-go/internal/auth HTTPClient
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAuthHTTPClient is a mock of HTTPClient interface.
+type MockAuthHTTPClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthHTTPClientMockRecorder
+}
+
+// MockAuthHTTPClientMockRecorder is the mock recorder for MockAuthHTTPClient.
+type MockAuthHTTPClientMockRecorder struct {
+	mock *MockAuthHTTPClient
+}
+
+// NewMockAuthHTTPClient creates a new mock instance.
+func NewMockAuthHTTPClient(ctrl *gomock.Controller) *MockAuthHTTPClient {
+	mock := &MockAuthHTTPClient{ctrl: ctrl}
+	mock.recorder = &MockAuthHTTPClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthHTTPClient) EXPECT() *MockAuthHTTPClientMockRecorder {
+	return m.recorder
+}
+
+// PostForm mocks base method.
+func (m *MockAuthHTTPClient) PostForm(arg0 context.Context, arg1 string, arg2 map[string]string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostForm", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostForm indicates an expected call of PostForm.
+func (mr *MockAuthHTTPClientMockRecorder) PostForm(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostForm", reflect.TypeOf((*MockAuthHTTPClient)(nil).PostForm), arg0, arg1, arg2)
+}
+
+// PostJSON mocks base method.
+func (m *MockAuthHTTPClient) PostJSON(arg0 context.Context, arg1 string, arg2 any) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostJSON", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostJSON indicates an expected call of PostJSON.
+func (mr *MockAuthHTTPClientMockRecorder) PostJSON(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostJSON", reflect.TypeOf((*MockAuthHTTPClient)(nil).PostJSON), arg0, arg1, arg2)
+}
@@ -0,0 +1,85 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zhz8888/pikpakapi-go/internal/download (interfaces: HTTPClient)
+//
+// Generated by this command:
+//
+//	mockgen -destination download_http_client.go -package mocks -mock_names HTTPClient=MockDownloadHTTPClient github.com/zhz8888/pikpakapi-go/internal/download HTTPClient
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDownloadHTTPClient is a mock of HTTPClient interface.
+type MockDownloadHTTPClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockDownloadHTTPClientMockRecorder
+}
+
+// MockDownloadHTTPClientMockRecorder is the mock recorder for MockDownloadHTTPClient.
+type MockDownloadHTTPClientMockRecorder struct {
+	mock *MockDownloadHTTPClient
+}
+
+// NewMockDownloadHTTPClient creates a new mock instance.
+func NewMockDownloadHTTPClient(ctrl *gomock.Controller) *MockDownloadHTTPClient {
+	mock := &MockDownloadHTTPClient{ctrl: ctrl}
+	mock.recorder = &MockDownloadHTTPClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDownloadHTTPClient) EXPECT() *MockDownloadHTTPClientMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockDownloadHTTPClient) Delete(arg0 context.Context, arg1 string, arg2 map[string]string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockDownloadHTTPClientMockRecorder) Delete(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDownloadHTTPClient)(nil).Delete), arg0, arg1, arg2)
+}
+
+// GetJSON mocks base method.
+func (m *MockDownloadHTTPClient) GetJSON(arg0 context.Context, arg1 string, arg2 map[string]string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJSON", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJSON indicates an expected call of GetJSON.
+func (mr *MockDownloadHTTPClientMockRecorder) GetJSON(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJSON", reflect.TypeOf((*MockDownloadHTTPClient)(nil).GetJSON), arg0, arg1, arg2)
+}
+
+// PostJSON mocks base method.
+func (m *MockDownloadHTTPClient) PostJSON(arg0 context.Context, arg1 string, arg2 any) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostJSON", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostJSON indicates an expected call of PostJSON.
+func (mr *MockDownloadHTTPClientMockRecorder) PostJSON(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostJSON", reflect.TypeOf((*MockDownloadHTTPClient)(nil).PostJSON), arg0, arg1, arg2)
+}
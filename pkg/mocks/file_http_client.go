@@ -0,0 +1,85 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zhz8888/pikpakapi-go/internal/file (interfaces: HTTPClient)
+//
+// Generated by this command:
+//
+//	mockgen -destination file_http_client.go -package mocks -mock_names HTTPClient=MockFileHTTPClient github.com/zhz8888/pikpakapi-go/internal/file HTTPClient
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFileHTTPClient is a mock of HTTPClient interface.
+type MockFileHTTPClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockFileHTTPClientMockRecorder
+}
+
+// MockFileHTTPClientMockRecorder is the mock recorder for MockFileHTTPClient.
+type MockFileHTTPClientMockRecorder struct {
+	mock *MockFileHTTPClient
+}
+
+// NewMockFileHTTPClient creates a new mock instance.
+func NewMockFileHTTPClient(ctrl *gomock.Controller) *MockFileHTTPClient {
+	mock := &MockFileHTTPClient{ctrl: ctrl}
+	mock.recorder = &MockFileHTTPClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFileHTTPClient) EXPECT() *MockFileHTTPClientMockRecorder {
+	return m.recorder
+}
+
+// GetJSON mocks base method.
+func (m *MockFileHTTPClient) GetJSON(arg0 context.Context, arg1 string, arg2 map[string]string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJSON", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJSON indicates an expected call of GetJSON.
+func (mr *MockFileHTTPClientMockRecorder) GetJSON(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJSON", reflect.TypeOf((*MockFileHTTPClient)(nil).GetJSON), arg0, arg1, arg2)
+}
+
+// PatchJSON mocks base method.
+func (m *MockFileHTTPClient) PatchJSON(arg0 context.Context, arg1 string, arg2 any) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchJSON", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchJSON indicates an expected call of PatchJSON.
+func (mr *MockFileHTTPClientMockRecorder) PatchJSON(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchJSON", reflect.TypeOf((*MockFileHTTPClient)(nil).PatchJSON), arg0, arg1, arg2)
+}
+
+// PostJSON mocks base method.
+func (m *MockFileHTTPClient) PostJSON(arg0 context.Context, arg1 string, arg2 any) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostJSON", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostJSON indicates an expected call of PostJSON.
+func (mr *MockFileHTTPClientMockRecorder) PostJSON(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostJSON", reflect.TypeOf((*MockFileHTTPClient)(nil).PostJSON), arg0, arg1, arg2)
+}
@@ -0,0 +1,1047 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zhz8888/pikpakapi-go/internal/client (interfaces: PikPakAPI)
+//
+// Generated by this command:
+//
+//	mockgen -destination pikpak_api.go -package mocks github.com/zhz8888/pikpakapi-go/internal/client PikPakAPI
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+	time "time"
+
+	client "github.com/zhz8888/pikpakapi-go/internal/client"
+	enums "github.com/zhz8888/pikpakapi-go/pkg/enums"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPikPakAPI is a mock of PikPakAPI interface.
+type MockPikPakAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockPikPakAPIMockRecorder
+}
+
+// MockPikPakAPIMockRecorder is the mock recorder for MockPikPakAPI.
+type MockPikPakAPIMockRecorder struct {
+	mock *MockPikPakAPI
+}
+
+// NewMockPikPakAPI creates a new mock instance.
+func NewMockPikPakAPI(ctrl *gomock.Controller) *MockPikPakAPI {
+	mock := &MockPikPakAPI{ctrl: ctrl}
+	mock.recorder = &MockPikPakAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPikPakAPI) EXPECT() *MockPikPakAPIMockRecorder {
+	return m.recorder
+}
+
+// CancelShare mocks base method.
+func (m *MockPikPakAPI) CancelShare(arg0 context.Context, arg1 string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelShare", arg0, arg1)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelShare indicates an expected call of CancelShare.
+func (mr *MockPikPakAPIMockRecorder) CancelShare(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelShare", reflect.TypeOf((*MockPikPakAPI)(nil).CancelShare), arg0, arg1)
+}
+
+// CaptureScreenshot mocks base method.
+func (m *MockPikPakAPI) CaptureScreenshot(arg0 context.Context, arg1 string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureScreenshot", arg0, arg1)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureScreenshot indicates an expected call of CaptureScreenshot.
+func (mr *MockPikPakAPIMockRecorder) CaptureScreenshot(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureScreenshot", reflect.TypeOf((*MockPikPakAPI)(nil).CaptureScreenshot), arg0, arg1)
+}
+
+// Copy mocks base method.
+func (m *MockPikPakAPI) Copy(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Copy", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Copy indicates an expected call of Copy.
+func (mr *MockPikPakAPIMockRecorder) Copy(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Copy", reflect.TypeOf((*MockPikPakAPI)(nil).Copy), arg0, arg1, arg2)
+}
+
+// CreateFolder mocks base method.
+func (m *MockPikPakAPI) CreateFolder(arg0 context.Context, arg1, arg2 string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFolder", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFolder indicates an expected call of CreateFolder.
+func (mr *MockPikPakAPIMockRecorder) CreateFolder(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFolder", reflect.TypeOf((*MockPikPakAPI)(nil).CreateFolder), arg0, arg1, arg2)
+}
+
+// CreateShareLink mocks base method.
+func (m *MockPikPakAPI) CreateShareLink(arg0 context.Context, arg1 string, arg2 int, arg3 string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateShareLink", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateShareLink indicates an expected call of CreateShareLink.
+func (mr *MockPikPakAPIMockRecorder) CreateShareLink(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShareLink", reflect.TypeOf((*MockPikPakAPI)(nil).CreateShareLink), arg0, arg1, arg2, arg3)
+}
+
+// DecodeToken mocks base method.
+func (m *MockPikPakAPI) DecodeToken() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecodeToken")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DecodeToken indicates an expected call of DecodeToken.
+func (mr *MockPikPakAPIMockRecorder) DecodeToken() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecodeToken", reflect.TypeOf((*MockPikPakAPI)(nil).DecodeToken))
+}
+
+// DeleteForever mocks base method.
+func (m *MockPikPakAPI) DeleteForever(arg0 context.Context, arg1 []string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteForever", arg0, arg1)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteForever indicates an expected call of DeleteForever.
+func (mr *MockPikPakAPIMockRecorder) DeleteForever(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteForever", reflect.TypeOf((*MockPikPakAPI)(nil).DeleteForever), arg0, arg1)
+}
+
+// DeleteOfflineTasks mocks base method.
+func (m *MockPikPakAPI) DeleteOfflineTasks(arg0 context.Context, arg1 []string, arg2 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOfflineTasks", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOfflineTasks indicates an expected call of DeleteOfflineTasks.
+func (mr *MockPikPakAPIMockRecorder) DeleteOfflineTasks(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOfflineTasks", reflect.TypeOf((*MockPikPakAPI)(nil).DeleteOfflineTasks), arg0, arg1, arg2)
+}
+
+// DeleteTasks mocks base method.
+func (m *MockPikPakAPI) DeleteTasks(arg0 context.Context, arg1 []string, arg2 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTasks", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTasks indicates an expected call of DeleteTasks.
+func (mr *MockPikPakAPIMockRecorder) DeleteTasks(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTasks", reflect.TypeOf((*MockPikPakAPI)(nil).DeleteTasks), arg0, arg1, arg2)
+}
+
+// DeleteToTrash mocks base method.
+func (m *MockPikPakAPI) DeleteToTrash(arg0 context.Context, arg1 []string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteToTrash", arg0, arg1)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteToTrash indicates an expected call of DeleteToTrash.
+func (mr *MockPikPakAPIMockRecorder) DeleteToTrash(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteToTrash", reflect.TypeOf((*MockPikPakAPI)(nil).DeleteToTrash), arg0, arg1)
+}
+
+// DownloadToFile mocks base method.
+func (m *MockPikPakAPI) DownloadToFile(arg0 context.Context, arg1, arg2 string, arg3 ...client.LinkOption) error {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DownloadToFile", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DownloadToFile indicates an expected call of DownloadToFile.
+func (mr *MockPikPakAPIMockRecorder) DownloadToFile(arg0, arg1, arg2 any, arg3 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadToFile", reflect.TypeOf((*MockPikPakAPI)(nil).DownloadToFile), varargs...)
+}
+
+// EmptyTrash mocks base method.
+func (m *MockPikPakAPI) EmptyTrash(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EmptyTrash", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EmptyTrash indicates an expected call of EmptyTrash.
+func (mr *MockPikPakAPIMockRecorder) EmptyTrash(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EmptyTrash", reflect.TypeOf((*MockPikPakAPI)(nil).EmptyTrash), arg0)
+}
+
+// EncodeToken mocks base method.
+func (m *MockPikPakAPI) EncodeToken() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EncodeToken")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EncodeToken indicates an expected call of EncodeToken.
+func (mr *MockPikPakAPIMockRecorder) EncodeToken() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EncodeToken", reflect.TypeOf((*MockPikPakAPI)(nil).EncodeToken))
+}
+
+// Events mocks base method.
+func (m *MockPikPakAPI) Events(arg0 context.Context, arg1 int, arg2 string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Events", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Events indicates an expected call of Events.
+func (mr *MockPikPakAPIMockRecorder) Events(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Events", reflect.TypeOf((*MockPikPakAPI)(nil).Events), arg0, arg1, arg2)
+}
+
+// Favorite mocks base method.
+func (m *MockPikPakAPI) Favorite(arg0 context.Context, arg1, arg2 string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Favorite", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Favorite indicates an expected call of Favorite.
+func (mr *MockPikPakAPIMockRecorder) Favorite(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Favorite", reflect.TypeOf((*MockPikPakAPI)(nil).Favorite), arg0, arg1, arg2)
+}
+
+// FileBatchShare mocks base method.
+func (m *MockPikPakAPI) FileBatchShare(arg0 context.Context, arg1 []string, arg2 bool) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FileBatchShare", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FileBatchShare indicates an expected call of FileBatchShare.
+func (mr *MockPikPakAPIMockRecorder) FileBatchShare(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FileBatchShare", reflect.TypeOf((*MockPikPakAPI)(nil).FileBatchShare), arg0, arg1, arg2)
+}
+
+// FileBatchStar mocks base method.
+func (m *MockPikPakAPI) FileBatchStar(arg0 context.Context, arg1 []string, arg2 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FileBatchStar", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FileBatchStar indicates an expected call of FileBatchStar.
+func (mr *MockPikPakAPIMockRecorder) FileBatchStar(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FileBatchStar", reflect.TypeOf((*MockPikPakAPI)(nil).FileBatchStar), arg0, arg1, arg2)
+}
+
+// FileBatchUnstar mocks base method.
+func (m *MockPikPakAPI) FileBatchUnstar(arg0 context.Context, arg1 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FileBatchUnstar", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FileBatchUnstar indicates an expected call of FileBatchUnstar.
+func (mr *MockPikPakAPIMockRecorder) FileBatchUnstar(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FileBatchUnstar", reflect.TypeOf((*MockPikPakAPI)(nil).FileBatchUnstar), arg0, arg1)
+}
+
+// FileList mocks base method.
+func (m *MockPikPakAPI) FileList(arg0 context.Context, arg1 int, arg2, arg3, arg4 string, arg5 ...client.ListOption) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1, arg2, arg3, arg4}
+	for _, a := range arg5 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FileList", varargs...)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FileList indicates an expected call of FileList.
+func (mr *MockPikPakAPIMockRecorder) FileList(arg0, arg1, arg2, arg3, arg4 any, arg5 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1, arg2, arg3, arg4}, arg5...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FileList", reflect.TypeOf((*MockPikPakAPI)(nil).FileList), varargs...)
+}
+
+// FileRename mocks base method.
+func (m *MockPikPakAPI) FileRename(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FileRename", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FileRename indicates an expected call of FileRename.
+func (mr *MockPikPakAPIMockRecorder) FileRename(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FileRename", reflect.TypeOf((*MockPikPakAPI)(nil).FileRename), arg0, arg1, arg2)
+}
+
+// FileStarList mocks base method.
+func (m *MockPikPakAPI) FileStarList(arg0 context.Context, arg1 int, arg2 string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FileStarList", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FileStarList indicates an expected call of FileStarList.
+func (mr *MockPikPakAPIMockRecorder) FileStarList(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FileStarList", reflect.TypeOf((*MockPikPakAPI)(nil).FileStarList), arg0, arg1, arg2)
+}
+
+// GetAbout mocks base method.
+func (m *MockPikPakAPI) GetAbout(arg0 context.Context) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAbout", arg0)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAbout indicates an expected call of GetAbout.
+func (mr *MockPikPakAPIMockRecorder) GetAbout(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAbout", reflect.TypeOf((*MockPikPakAPI)(nil).GetAbout), arg0)
+}
+
+// GetFileByPath mocks base method.
+func (m *MockPikPakAPI) GetFileByPath(arg0 context.Context, arg1 string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileByPath", arg0, arg1)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileByPath indicates an expected call of GetFileByPath.
+func (mr *MockPikPakAPIMockRecorder) GetFileByPath(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileByPath", reflect.TypeOf((*MockPikPakAPI)(nil).GetFileByPath), arg0, arg1)
+}
+
+// GetFileDetails mocks base method.
+func (m *MockPikPakAPI) GetFileDetails(arg0 context.Context, arg1 string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileDetails", arg0, arg1)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileDetails indicates an expected call of GetFileDetails.
+func (mr *MockPikPakAPIMockRecorder) GetFileDetails(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileDetails", reflect.TypeOf((*MockPikPakAPI)(nil).GetFileDetails), arg0, arg1)
+}
+
+// GetFileLink mocks base method.
+func (m *MockPikPakAPI) GetFileLink(arg0 context.Context, arg1 string, arg2 ...client.LinkOption) (string, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetFileLink", varargs...)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileLink indicates an expected call of GetFileLink.
+func (mr *MockPikPakAPIMockRecorder) GetFileLink(arg0, arg1 any, arg2 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileLink", reflect.TypeOf((*MockPikPakAPI)(nil).GetFileLink), varargs...)
+}
+
+// GetMe mocks base method.
+func (m *MockPikPakAPI) GetMe(arg0 context.Context) (*client.UserProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMe", arg0)
+	ret0, _ := ret[0].(*client.UserProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMe indicates an expected call of GetMe.
+func (mr *MockPikPakAPIMockRecorder) GetMe(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMe", reflect.TypeOf((*MockPikPakAPI)(nil).GetMe), arg0)
+}
+
+// GetQuotaInfo mocks base method.
+func (m *MockPikPakAPI) GetQuotaInfo(arg0 context.Context) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuotaInfo", arg0)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQuotaInfo indicates an expected call of GetQuotaInfo.
+func (mr *MockPikPakAPIMockRecorder) GetQuotaInfo(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuotaInfo", reflect.TypeOf((*MockPikPakAPI)(nil).GetQuotaInfo), arg0)
+}
+
+// GetShareDownloadURL mocks base method.
+func (m *MockPikPakAPI) GetShareDownloadURL(arg0 context.Context, arg1, arg2 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShareDownloadURL", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShareDownloadURL indicates an expected call of GetShareDownloadURL.
+func (mr *MockPikPakAPIMockRecorder) GetShareDownloadURL(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShareDownloadURL", reflect.TypeOf((*MockPikPakAPI)(nil).GetShareDownloadURL), arg0, arg1, arg2)
+}
+
+// GetShareFileDownloadURL mocks base method.
+func (m *MockPikPakAPI) GetShareFileDownloadURL(arg0 context.Context, arg1, arg2 string, arg3 bool, arg4 ...client.LinkOption) (string, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1, arg2, arg3}
+	for _, a := range arg4 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetShareFileDownloadURL", varargs...)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShareFileDownloadURL indicates an expected call of GetShareFileDownloadURL.
+func (mr *MockPikPakAPIMockRecorder) GetShareFileDownloadURL(arg0, arg1, arg2, arg3 any, arg4 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1, arg2, arg3}, arg4...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShareFileDownloadURL", reflect.TypeOf((*MockPikPakAPI)(nil).GetShareFileDownloadURL), varargs...)
+}
+
+// GetShareFileInfo mocks base method.
+func (m *MockPikPakAPI) GetShareFileInfo(arg0 context.Context, arg1, arg2 string) (*client.ShareFileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShareFileInfo", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*client.ShareFileInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShareFileInfo indicates an expected call of GetShareFileInfo.
+func (mr *MockPikPakAPIMockRecorder) GetShareFileInfo(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShareFileInfo", reflect.TypeOf((*MockPikPakAPI)(nil).GetShareFileInfo), arg0, arg1, arg2)
+}
+
+// GetShareFileLink mocks base method.
+func (m *MockPikPakAPI) GetShareFileLink(arg0 context.Context, arg1, arg2, arg3 string, arg4 ...client.LinkOption) (string, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1, arg2, arg3}
+	for _, a := range arg4 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetShareFileLink", varargs...)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShareFileLink indicates an expected call of GetShareFileLink.
+func (mr *MockPikPakAPIMockRecorder) GetShareFileLink(arg0, arg1, arg2, arg3 any, arg4 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1, arg2, arg3}, arg4...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShareFileLink", reflect.TypeOf((*MockPikPakAPI)(nil).GetShareFileLink), varargs...)
+}
+
+// GetShareFiles mocks base method.
+func (m *MockPikPakAPI) GetShareFiles(arg0 context.Context, arg1, arg2 string) ([]*client.ShareFileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShareFiles", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*client.ShareFileInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShareFiles indicates an expected call of GetShareFiles.
+func (mr *MockPikPakAPIMockRecorder) GetShareFiles(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShareFiles", reflect.TypeOf((*MockPikPakAPI)(nil).GetShareFiles), arg0, arg1, arg2)
+}
+
+// GetShareInfo mocks base method.
+func (m *MockPikPakAPI) GetShareInfo(arg0 context.Context, arg1 string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShareInfo", arg0, arg1)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShareInfo indicates an expected call of GetShareInfo.
+func (mr *MockPikPakAPIMockRecorder) GetShareInfo(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShareInfo", reflect.TypeOf((*MockPikPakAPI)(nil).GetShareInfo), arg0, arg1)
+}
+
+// GetShareList mocks base method.
+func (m *MockPikPakAPI) GetShareList(arg0 context.Context, arg1 int, arg2 string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShareList", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShareList indicates an expected call of GetShareList.
+func (mr *MockPikPakAPIMockRecorder) GetShareList(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShareList", reflect.TypeOf((*MockPikPakAPI)(nil).GetShareList), arg0, arg1, arg2)
+}
+
+// GetSharePasscode mocks base method.
+func (m *MockPikPakAPI) GetSharePasscode(arg0 context.Context, arg1 string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSharePasscode", arg0, arg1)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSharePasscode indicates an expected call of GetSharePasscode.
+func (mr *MockPikPakAPIMockRecorder) GetSharePasscode(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharePasscode", reflect.TypeOf((*MockPikPakAPI)(nil).GetSharePasscode), arg0, arg1)
+}
+
+// GetStorageInfo mocks base method.
+func (m *MockPikPakAPI) GetStorageInfo(arg0 context.Context) (client.StorageInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStorageInfo", arg0)
+	ret0, _ := ret[0].(client.StorageInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStorageInfo indicates an expected call of GetStorageInfo.
+func (mr *MockPikPakAPIMockRecorder) GetStorageInfo(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStorageInfo", reflect.TypeOf((*MockPikPakAPI)(nil).GetStorageInfo), arg0)
+}
+
+// GetTaskStatus mocks base method.
+func (m *MockPikPakAPI) GetTaskStatus(arg0 context.Context, arg1, arg2 string) (enums.DownloadStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskStatus", arg0, arg1, arg2)
+	ret0, _ := ret[0].(enums.DownloadStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaskStatus indicates an expected call of GetTaskStatus.
+func (mr *MockPikPakAPIMockRecorder) GetTaskStatus(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskStatus", reflect.TypeOf((*MockPikPakAPI)(nil).GetTaskStatus), arg0, arg1, arg2)
+}
+
+// GetUploadURL mocks base method.
+func (m *MockPikPakAPI) GetUploadURL(arg0 context.Context, arg1 string, arg2 int64, arg3 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUploadURL", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUploadURL indicates an expected call of GetUploadURL.
+func (mr *MockPikPakAPIMockRecorder) GetUploadURL(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUploadURL", reflect.TypeOf((*MockPikPakAPI)(nil).GetUploadURL), arg0, arg1, arg2, arg3)
+}
+
+// GetUserInfo mocks base method.
+func (m *MockPikPakAPI) GetUserInfo() map[string]string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserInfo")
+	ret0, _ := ret[0].(map[string]string)
+	return ret0
+}
+
+// GetUserInfo indicates an expected call of GetUserInfo.
+func (mr *MockPikPakAPIMockRecorder) GetUserInfo() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserInfo", reflect.TypeOf((*MockPikPakAPI)(nil).GetUserInfo))
+}
+
+// InviteCancel mocks base method.
+func (m *MockPikPakAPI) InviteCancel(arg0 context.Context, arg1 string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InviteCancel", arg0, arg1)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InviteCancel indicates an expected call of InviteCancel.
+func (mr *MockPikPakAPIMockRecorder) InviteCancel(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InviteCancel", reflect.TypeOf((*MockPikPakAPI)(nil).InviteCancel), arg0, arg1)
+}
+
+// InviteList mocks base method.
+func (m *MockPikPakAPI) InviteList(arg0 context.Context, arg1 string, arg2 int, arg3 string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InviteList", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InviteList indicates an expected call of InviteList.
+func (mr *MockPikPakAPIMockRecorder) InviteList(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InviteList", reflect.TypeOf((*MockPikPakAPI)(nil).InviteList), arg0, arg1, arg2, arg3)
+}
+
+// InviteNewShare mocks base method.
+func (m *MockPikPakAPI) InviteNewShare(arg0 context.Context, arg1 string, arg2 []string, arg3 string, arg4 bool) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InviteNewShare", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InviteNewShare indicates an expected call of InviteNewShare.
+func (mr *MockPikPakAPIMockRecorder) InviteNewShare(arg0, arg1, arg2, arg3, arg4 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InviteNewShare", reflect.TypeOf((*MockPikPakAPI)(nil).InviteNewShare), arg0, arg1, arg2, arg3, arg4)
+}
+
+// Login mocks base method.
+func (m *MockPikPakAPI) Login(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockPikPakAPIMockRecorder) Login(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockPikPakAPI)(nil).Login), arg0)
+}
+
+// Move mocks base method.
+func (m *MockPikPakAPI) Move(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Move", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Move indicates an expected call of Move.
+func (mr *MockPikPakAPIMockRecorder) Move(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Move", reflect.TypeOf((*MockPikPakAPI)(nil).Move), arg0, arg1, arg2)
+}
+
+// OfflineDownload mocks base method.
+func (m *MockPikPakAPI) OfflineDownload(arg0 context.Context, arg1, arg2, arg3 string, arg4 ...client.DownloadOption) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1, arg2, arg3}
+	for _, a := range arg4 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "OfflineDownload", varargs...)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OfflineDownload indicates an expected call of OfflineDownload.
+func (mr *MockPikPakAPIMockRecorder) OfflineDownload(arg0, arg1, arg2, arg3 any, arg4 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1, arg2, arg3}, arg4...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OfflineDownload", reflect.TypeOf((*MockPikPakAPI)(nil).OfflineDownload), varargs...)
+}
+
+// OfflineDownloadBatch mocks base method.
+func (m *MockPikPakAPI) OfflineDownloadBatch(arg0 context.Context, arg1 []string, arg2 string, arg3 ...client.DownloadOption) ([]client.OfflineBatchResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "OfflineDownloadBatch", varargs...)
+	ret0, _ := ret[0].([]client.OfflineBatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OfflineDownloadBatch indicates an expected call of OfflineDownloadBatch.
+func (mr *MockPikPakAPIMockRecorder) OfflineDownloadBatch(arg0, arg1, arg2 any, arg3 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OfflineDownloadBatch", reflect.TypeOf((*MockPikPakAPI)(nil).OfflineDownloadBatch), varargs...)
+}
+
+// OfflineFileInfo mocks base method.
+func (m *MockPikPakAPI) OfflineFileInfo(arg0 context.Context, arg1 string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OfflineFileInfo", arg0, arg1)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OfflineFileInfo indicates an expected call of OfflineFileInfo.
+func (mr *MockPikPakAPIMockRecorder) OfflineFileInfo(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OfflineFileInfo", reflect.TypeOf((*MockPikPakAPI)(nil).OfflineFileInfo), arg0, arg1)
+}
+
+// OfflineList mocks base method.
+func (m *MockPikPakAPI) OfflineList(arg0 context.Context, arg1 int, arg2 string, arg3 []string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OfflineList", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OfflineList indicates an expected call of OfflineList.
+func (mr *MockPikPakAPIMockRecorder) OfflineList(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OfflineList", reflect.TypeOf((*MockPikPakAPI)(nil).OfflineList), arg0, arg1, arg2, arg3)
+}
+
+// OfflineListPhases mocks base method.
+func (m *MockPikPakAPI) OfflineListPhases(arg0 context.Context, arg1 int, arg2 string, arg3 []enums.PhaseType) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OfflineListPhases", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OfflineListPhases indicates an expected call of OfflineListPhases.
+func (mr *MockPikPakAPIMockRecorder) OfflineListPhases(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OfflineListPhases", reflect.TypeOf((*MockPikPakAPI)(nil).OfflineListPhases), arg0, arg1, arg2, arg3)
+}
+
+// OfflineTaskRetry mocks base method.
+func (m *MockPikPakAPI) OfflineTaskRetry(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OfflineTaskRetry", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// OfflineTaskRetry indicates an expected call of OfflineTaskRetry.
+func (mr *MockPikPakAPIMockRecorder) OfflineTaskRetry(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OfflineTaskRetry", reflect.TypeOf((*MockPikPakAPI)(nil).OfflineTaskRetry), arg0, arg1)
+}
+
+// OpenFile mocks base method.
+func (m *MockPikPakAPI) OpenFile(arg0 context.Context, arg1 string, arg2 int64, arg3 ...client.LinkOption) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "OpenFile", varargs...)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenFile indicates an expected call of OpenFile.
+func (mr *MockPikPakAPIMockRecorder) OpenFile(arg0, arg1, arg2 any, arg3 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenFile", reflect.TypeOf((*MockPikPakAPI)(nil).OpenFile), varargs...)
+}
+
+// Ping mocks base method.
+func (m *MockPikPakAPI) Ping(arg0 context.Context) (*client.PingResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(*client.PingResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockPikPakAPIMockRecorder) Ping(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockPikPakAPI)(nil).Ping), arg0)
+}
+
+// RefreshAccessToken mocks base method.
+func (m *MockPikPakAPI) RefreshAccessToken(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshAccessToken", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshAccessToken indicates an expected call of RefreshAccessToken.
+func (mr *MockPikPakAPIMockRecorder) RefreshAccessToken(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshAccessToken", reflect.TypeOf((*MockPikPakAPI)(nil).RefreshAccessToken), arg0)
+}
+
+// RemoteDownload mocks base method.
+func (m *MockPikPakAPI) RemoteDownload(arg0 context.Context, arg1 string, arg2 ...client.DownloadOption) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RemoteDownload", varargs...)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoteDownload indicates an expected call of RemoteDownload.
+func (mr *MockPikPakAPIMockRecorder) RemoteDownload(arg0, arg1 any, arg2 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoteDownload", reflect.TypeOf((*MockPikPakAPI)(nil).RemoteDownload), varargs...)
+}
+
+// Rename mocks base method.
+func (m *MockPikPakAPI) Rename(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rename", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Rename indicates an expected call of Rename.
+func (mr *MockPikPakAPIMockRecorder) Rename(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rename", reflect.TypeOf((*MockPikPakAPI)(nil).Rename), arg0, arg1, arg2)
+}
+
+// Restore mocks base method.
+func (m *MockPikPakAPI) Restore(arg0 context.Context, arg1, arg2 string, arg3 []string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockPikPakAPIMockRecorder) Restore(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockPikPakAPI)(nil).Restore), arg0, arg1, arg2, arg3)
+}
+
+// RestoreShareURL mocks base method.
+func (m *MockPikPakAPI) RestoreShareURL(arg0 context.Context, arg1, arg2 string, arg3 []string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreShareURL", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreShareURL indicates an expected call of RestoreShareURL.
+func (mr *MockPikPakAPIMockRecorder) RestoreShareURL(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreShareURL", reflect.TypeOf((*MockPikPakAPI)(nil).RestoreShareURL), arg0, arg1, arg2, arg3)
+}
+
+// SetSharePolicy mocks base method.
+func (m *MockPikPakAPI) SetSharePolicy(arg0 context.Context, arg1, arg2 string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSharePolicy", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetSharePolicy indicates an expected call of SetSharePolicy.
+func (mr *MockPikPakAPIMockRecorder) SetSharePolicy(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSharePolicy", reflect.TypeOf((*MockPikPakAPI)(nil).SetSharePolicy), arg0, arg1, arg2)
+}
+
+// Share mocks base method.
+func (m *MockPikPakAPI) Share(arg0 context.Context, arg1 string, arg2, arg3 int, arg4 string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Share", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Share indicates an expected call of Share.
+func (mr *MockPikPakAPIMockRecorder) Share(arg0, arg1, arg2, arg3, arg4 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Share", reflect.TypeOf((*MockPikPakAPI)(nil).Share), arg0, arg1, arg2, arg3, arg4)
+}
+
+// Untrash mocks base method.
+func (m *MockPikPakAPI) Untrash(arg0 context.Context, arg1 []string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Untrash", arg0, arg1)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Untrash indicates an expected call of Untrash.
+func (mr *MockPikPakAPIMockRecorder) Untrash(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Untrash", reflect.TypeOf((*MockPikPakAPI)(nil).Untrash), arg0, arg1)
+}
+
+// Upload mocks base method.
+func (m *MockPikPakAPI) Upload(arg0 context.Context, arg1, arg2 string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upload", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upload indicates an expected call of Upload.
+func (mr *MockPikPakAPIMockRecorder) Upload(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upload", reflect.TypeOf((*MockPikPakAPI)(nil).Upload), arg0, arg1, arg2)
+}
+
+// UploadFile mocks base method.
+func (m *MockPikPakAPI) UploadFile(arg0 context.Context, arg1, arg2 string, arg3 int) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadFile", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadFile indicates an expected call of UploadFile.
+func (mr *MockPikPakAPIMockRecorder) UploadFile(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadFile", reflect.TypeOf((*MockPikPakAPI)(nil).UploadFile), arg0, arg1, arg2, arg3)
+}
+
+// UploadReader mocks base method.
+func (m *MockPikPakAPI) UploadReader(arg0 context.Context, arg1 io.Reader, arg2 string, arg3 int64, arg4 string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadReader", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadReader indicates an expected call of UploadReader.
+func (mr *MockPikPakAPIMockRecorder) UploadReader(arg0, arg1, arg2, arg3, arg4 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadReader", reflect.TypeOf((*MockPikPakAPI)(nil).UploadReader), arg0, arg1, arg2, arg3, arg4)
+}
+
+// WaitForTask mocks base method.
+func (m *MockPikPakAPI) WaitForTask(arg0 context.Context, arg1 string, arg2 time.Duration) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForTask", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WaitForTask indicates an expected call of WaitForTask.
+func (mr *MockPikPakAPIMockRecorder) WaitForTask(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForTask", reflect.TypeOf((*MockPikPakAPI)(nil).WaitForTask), arg0, arg1, arg2)
+}
+
+// WatchTasks mocks base method.
+func (m *MockPikPakAPI) WatchTasks(arg0 context.Context, arg1 []string, arg2 time.Duration, arg3 func([]map[string]any)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchTasks", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WatchTasks indicates an expected call of WatchTasks.
+func (mr *MockPikPakAPIMockRecorder) WatchTasks(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchTasks", reflect.TypeOf((*MockPikPakAPI)(nil).WatchTasks), arg0, arg1, arg2, arg3)
+}
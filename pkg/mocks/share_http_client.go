@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zhz8888/pikpakapi-go/internal/share (interfaces: HTTPClient)
+//
+// Generated by this command:
+//
+//	mockgen -destination share_http_client.go -package mocks -mock_names HTTPClient=MockShareHTTPClient github.com/zhz8888/pikpakapi-go/internal/share HTTPClient
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockShareHTTPClient is a mock of HTTPClient interface.
+type MockShareHTTPClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockShareHTTPClientMockRecorder
+}
+
+// MockShareHTTPClientMockRecorder is the mock recorder for MockShareHTTPClient.
+type MockShareHTTPClientMockRecorder struct {
+	mock *MockShareHTTPClient
+}
+
+// NewMockShareHTTPClient creates a new mock instance.
+func NewMockShareHTTPClient(ctrl *gomock.Controller) *MockShareHTTPClient {
+	mock := &MockShareHTTPClient{ctrl: ctrl}
+	mock.recorder = &MockShareHTTPClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockShareHTTPClient) EXPECT() *MockShareHTTPClientMockRecorder {
+	return m.recorder
+}
+
+// GetJSON mocks base method.
+func (m *MockShareHTTPClient) GetJSON(arg0 context.Context, arg1 string, arg2 map[string]string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJSON", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJSON indicates an expected call of GetJSON.
+func (mr *MockShareHTTPClientMockRecorder) GetJSON(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJSON", reflect.TypeOf((*MockShareHTTPClient)(nil).GetJSON), arg0, arg1, arg2)
+}
+
+// PostJSON mocks base method.
+func (m *MockShareHTTPClient) PostJSON(arg0 context.Context, arg1 string, arg2 any) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostJSON", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostJSON indicates an expected call of PostJSON.
+func (mr *MockShareHTTPClientMockRecorder) PostJSON(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostJSON", reflect.TypeOf((*MockShareHTTPClient)(nil).PostJSON), arg0, arg1, arg2)
+}
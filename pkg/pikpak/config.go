@@ -0,0 +1,78 @@
+package pikpak
+
+import (
+	"github.com/zhz8888/pikpakapi-go/internal/config"
+	"github.com/zhz8888/pikpakapi-go/internal/token"
+)
+
+type (
+	Config          = config.Config
+	ConfigBuilder   = config.ConfigBuilder
+	ValidationError = config.ValidationError
+	ImportError     = config.ImportError
+
+	TokenStore        = token.TokenStore
+	FileTokenStore    = token.FileTokenStore
+	KeyringTokenStore = token.KeyringTokenStore
+	Keyring           = token.Keyring
+)
+
+const (
+	ConfigEnvVar   = config.ConfigEnvVar
+	DefaultProfile = config.DefaultProfile
+
+	EnvUsername     = config.EnvUsername
+	EnvPassword     = config.EnvPassword
+	EnvAccessToken  = config.EnvAccessToken
+	EnvRefreshToken = config.EnvRefreshToken
+	EnvEncodedToken = config.EnvEncodedToken
+	EnvDeviceID     = config.EnvDeviceID
+	EnvProxy        = config.EnvProxy
+	EnvBaseURL      = config.EnvBaseURL
+
+	KeyringService = token.KeyringService
+)
+
+// Config loading and saving.
+var (
+	LoadConfig         = config.LoadConfig
+	LoadConfigFrom     = config.LoadConfigFrom
+	LoadProfile        = config.LoadProfile
+	LoadEncrypted      = config.LoadEncrypted
+	FromEnv            = config.FromEnv
+	ImportPython       = config.ImportPython
+	SaveConfig         = config.SaveConfig
+	SaveConfigAtomic   = config.SaveConfigAtomic
+	SaveDefault        = config.SaveDefault
+	SaveEncrypted      = config.SaveEncrypted
+	SaveProfile        = config.SaveProfile
+	ListProfiles       = config.ListProfiles
+	DefaultProfileName = config.DefaultProfileName
+	DefaultPath        = config.DefaultPath
+	ValidateConfig     = config.ValidateConfig
+	WatchConfig        = config.Watch
+	NewConfigBuilder   = config.NewConfigBuilder
+)
+
+// Config errors for use with errors.Is.
+var (
+	ErrEmptyUsername      = config.ErrEmptyUsername
+	ErrEmptyPassword      = config.ErrEmptyPassword
+	ErrInvalidEmail       = config.ErrInvalidEmail
+	ErrInvalidPhone       = config.ErrInvalidPhone
+	ErrInvalidUsername    = config.ErrInvalidUsername
+	ErrPassphraseRequired = config.ErrPassphraseRequired
+	ErrWrongPassphrase    = config.ErrWrongPassphrase
+	ErrCorruptConfig      = config.ErrCorruptConfig
+	ErrProfileNotFound    = config.ErrProfileNotFound
+)
+
+// Token stores, passed to WithTokenStore.
+var (
+	NewFileTokenStore    = token.NewFileTokenStore
+	NewKeyringTokenStore = token.NewKeyringTokenStore
+	SystemKeyring        = token.SystemKeyring
+
+	ErrTokenNotFound      = token.ErrTokenNotFound
+	ErrKeyringUnavailable = token.ErrKeyringUnavailable
+)
@@ -0,0 +1,156 @@
+package pikpak
+
+import "github.com/zhz8888/pikpakapi-go/internal/exception"
+
+// Error types returned by the client. Use errors.As to inspect them.
+type (
+	ErrorCode            = exception.ErrorCode
+	PikpakException      = exception.PikpakException
+	HTTPError            = exception.HTTPError
+	BatchError           = exception.BatchError
+	ItemError            = exception.ItemError
+	CaptchaRequiredError = exception.CaptchaRequiredError
+	PremiumRequiredError = exception.PremiumRequiredError
+)
+
+const (
+	ErrCodeSuccess                  = exception.ErrCodeSuccess
+	ErrCodeInvalidUsernamePassword  = exception.ErrCodeInvalidUsernamePassword
+	ErrCodeInvalidEncodedToken      = exception.ErrCodeInvalidEncodedToken
+	ErrCodeCaptchaTokenFailed       = exception.ErrCodeCaptchaTokenFailed
+	ErrCodeUsernamePasswordRequired = exception.ErrCodeUsernamePasswordRequired
+	ErrCodeMaxRetriesReached        = exception.ErrCodeMaxRetriesReached
+	ErrCodeUnknownError             = exception.ErrCodeUnknownError
+	ErrCodeEmptyJSONData            = exception.ErrCodeEmptyJSONData
+	ErrCodeInvalidFileID            = exception.ErrCodeInvalidFileID
+	ErrCodeInvalidFileName          = exception.ErrCodeInvalidFileName
+	ErrCodeEmptyFileIDs             = exception.ErrCodeEmptyFileIDs
+	ErrCodeInvalidURL               = exception.ErrCodeInvalidURL
+	ErrCodeInvalidAccessToken       = exception.ErrCodeInvalidAccessToken
+	ErrCodeInvalidCredentials       = exception.ErrCodeInvalidCredentials
+	ErrCodeInvalidShareURL          = exception.ErrCodeInvalidShareURL
+	ErrCodeSharePasswordWrong       = exception.ErrCodeSharePasswordWrong
+	ErrCodeNetworkError             = exception.ErrCodeNetworkError
+	ErrCodeServerError              = exception.ErrCodeServerError
+	ErrCodeTimeout                  = exception.ErrCodeTimeout
+	ErrCodeUnauthorized             = exception.ErrCodeUnauthorized
+	ErrCodeForbidden                = exception.ErrCodeForbidden
+	ErrCodeNotFound                 = exception.ErrCodeNotFound
+	ErrCodeConflict                 = exception.ErrCodeConflict
+	ErrCodeInternalServerError      = exception.ErrCodeInternalServerError
+	ErrCodeServiceUnavailable       = exception.ErrCodeServiceUnavailable
+	ErrCodeInvalidParameter         = exception.ErrCodeInvalidParameter
+	ErrCodeInvalidMediaFormat       = exception.ErrCodeInvalidMediaFormat
+	ErrCodeMarshalFailed            = exception.ErrCodeMarshalFailed
+	ErrCodeCreateRequestFailed      = exception.ErrCodeCreateRequestFailed
+	ErrCodeReadResponseFailed       = exception.ErrCodeReadResponseFailed
+	ErrCodeUnmarshalFailed          = exception.ErrCodeUnmarshalFailed
+	ErrCodeMaxRetriesExceeded       = exception.ErrCodeMaxRetriesExceeded
+	ErrCodeOpenFileFailed           = exception.ErrCodeOpenFileFailed
+	ErrCodeGetFileInfoFailed        = exception.ErrCodeGetFileInfoFailed
+	ErrCodeReadFileFailed           = exception.ErrCodeReadFileFailed
+	ErrCodeCreateFormFileFailed     = exception.ErrCodeCreateFormFileFailed
+	ErrCodeWriteFileContentFailed   = exception.ErrCodeWriteFileContentFailed
+	ErrCodeReadChunkFailed          = exception.ErrCodeReadChunkFailed
+	ErrCodeDownloadFailed           = exception.ErrCodeDownloadFailed
+	ErrCodeCreateDirectoryFailed    = exception.ErrCodeCreateDirectoryFailed
+	ErrCodeCreateFileFailed         = exception.ErrCodeCreateFileFailed
+	ErrCodeWriteFileFailed          = exception.ErrCodeWriteFileFailed
+	ErrCodeResponseTooLarge         = exception.ErrCodeResponseTooLarge
+	ErrCodeTooManyRequests          = exception.ErrCodeTooManyRequests
+	ErrCodeFileNotFound             = exception.ErrCodeFileNotFound
+	ErrCodeQuotaExceeded            = exception.ErrCodeQuotaExceeded
+	ErrCodeTaskDailyLimitExceeded   = exception.ErrCodeTaskDailyLimitExceeded
+	ErrCodeCaptchaRequired          = exception.ErrCodeCaptchaRequired
+	ErrCodeShareExpired             = exception.ErrCodeShareExpired
+	ErrCodePremiumRequired          = exception.ErrCodePremiumRequired
+	ErrCodeMissingRefreshToken      = exception.ErrCodeMissingRefreshToken
+	ErrCodeVerificationCodeInvalid  = exception.ErrCodeVerificationCodeInvalid
+	ErrCodeVerificationCodeExpired  = exception.ErrCodeVerificationCodeExpired
+	ErrCodeInviteCodeInvalid        = exception.ErrCodeInviteCodeInvalid
+	ErrCodeInviteCodeUsed           = exception.ErrCodeInviteCodeUsed
+)
+
+// ErrCodeInvalidPassCode is the former name of ErrCodeSharePasswordWrong.
+//
+// Deprecated: use ErrCodeSharePasswordWrong.
+const ErrCodeInvalidPassCode = exception.ErrCodeInvalidPassCode
+
+// Sentinel errors for use with errors.Is.
+var (
+	ErrInvalidUsernamePassword  = exception.ErrInvalidUsernamePassword
+	ErrInvalidEncodedToken      = exception.ErrInvalidEncodedToken
+	ErrCaptchaTokenFailed       = exception.ErrCaptchaTokenFailed
+	ErrUsernamePasswordRequired = exception.ErrUsernamePasswordRequired
+	ErrMaxRetriesReached        = exception.ErrMaxRetriesReached
+	ErrUnknownError             = exception.ErrUnknownError
+	ErrEmptyJSONData            = exception.ErrEmptyJSONData
+	ErrInvalidFileID            = exception.ErrInvalidFileID
+	ErrInvalidFileName          = exception.ErrInvalidFileName
+	ErrEmptyFileIDs             = exception.ErrEmptyFileIDs
+	ErrInvalidURL               = exception.ErrInvalidURL
+	ErrInvalidParameter         = exception.ErrInvalidParameter
+	ErrInvalidAccessToken       = exception.ErrInvalidAccessToken
+	ErrInvalidCredentials       = exception.ErrInvalidCredentials
+	ErrInvalidShareURL          = exception.ErrInvalidShareURL
+	ErrSharePasswordWrong       = exception.ErrSharePasswordWrong
+	ErrShareExpired             = exception.ErrShareExpired
+	ErrPremiumRequired          = exception.ErrPremiumRequired
+	ErrMissingRefreshToken      = exception.ErrMissingRefreshToken
+	ErrVerificationCodeInvalid  = exception.ErrVerificationCodeInvalid
+	ErrVerificationCodeExpired  = exception.ErrVerificationCodeExpired
+	ErrInviteCodeInvalid        = exception.ErrInviteCodeInvalid
+	ErrInviteCodeUsed           = exception.ErrInviteCodeUsed
+	ErrNetworkError             = exception.ErrNetworkError
+	ErrServerError              = exception.ErrServerError
+	ErrTimeout                  = exception.ErrTimeout
+	ErrUnauthorized             = exception.ErrUnauthorized
+	ErrForbidden                = exception.ErrForbidden
+	ErrNotFound                 = exception.ErrNotFound
+	ErrConflict                 = exception.ErrConflict
+	ErrInternalServerError      = exception.ErrInternalServerError
+	ErrServiceUnavailable       = exception.ErrServiceUnavailable
+	ErrResponseTooLarge         = exception.ErrResponseTooLarge
+	ErrTooManyRequests          = exception.ErrTooManyRequests
+	ErrFileNotFound             = exception.ErrFileNotFound
+	ErrQuotaExceeded            = exception.ErrQuotaExceeded
+	ErrTaskDailyLimitExceeded   = exception.ErrTaskDailyLimitExceeded
+	ErrCaptchaRequired          = exception.ErrCaptchaRequired
+)
+
+// ErrInvalidPassCode is the former name of ErrSharePasswordWrong.
+//
+// Deprecated: use ErrSharePasswordWrong.
+var ErrInvalidPassCode = exception.ErrInvalidPassCode
+
+// Is reports whether any PikpakException in err's chain carries code.
+func Is(err error, code ErrorCode) bool {
+	return exception.Is(err, code)
+}
+
+// GetErrorCode returns the code of the PikpakException in err's chain.
+func GetErrorCode(err error) ErrorCode {
+	return exception.GetErrorCode(err)
+}
+
+// IsPikpakException reports whether err's chain holds a PikpakException.
+func IsPikpakException(err error) bool {
+	return exception.IsPikpakException(err)
+}
+
+// IsRetryable reports whether err wraps a retryable PikpakException.
+func IsRetryable(err error) bool {
+	return exception.IsRetryable(err)
+}
+
+// HTTPStatus returns the HTTP status of the first PikpakException or
+// HTTPError in err's chain that has one, or zero for failures that never got
+// a response.
+func HTTPStatus(err error) int {
+	return exception.HTTPStatus(err)
+}
+
+// NewBatchError starts an empty BatchError for total items.
+func NewBatchError(total int) *BatchError {
+	return exception.NewBatchError(total)
+}
@@ -0,0 +1,173 @@
+// Package pikpak is the public entry point of the PikPak Drive client. It
+// re-exports the client, its options, typed results and errors from the
+// module's internal packages; enum types live in pkg/enums.
+package pikpak
+
+import (
+	"log"
+
+	"github.com/zhz8888/pikpakapi-go/internal/client"
+	"github.com/zhz8888/pikpakapi-go/internal/event"
+	"github.com/zhz8888/pikpakapi-go/internal/utils"
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
+)
+
+type (
+	Client          = client.Client
+	ClientInterface = client.ClientInterface
+	Option          = client.Option
+	LinkOption      = client.LinkOption
+	DownloadOption  = client.DownloadOption
+	ListOption      = client.ListOption
+
+	PikPakAPI     = client.PikPakAPI
+	Authenticator = client.Authenticator
+	FileService   = client.FileService
+	TaskService   = client.TaskService
+	ShareService  = client.ShareService
+
+	LinkPreference     = client.LinkPreference
+	RetryPolicy        = client.RetryPolicy
+	DefaultRetryPolicy = client.DefaultRetryPolicy
+	MetricsCollector   = client.MetricsCollector
+	DialContextFunc    = client.DialContextFunc
+	Progress           = client.Progress
+	ProgressFunc       = client.ProgressFunc
+	SigningConfig      = client.SigningConfig
+	Logger             = client.Logger
+
+	EventBus   = event.EventBus
+	Event      = event.Event
+	EventType  = event.EventType
+	LoginEvent = event.LoginEvent
+	BusOption  = event.BusOption
+
+	AboutResponse = client.AboutResponse
+	StorageInfo   = client.StorageInfo
+	PingResult    = client.PingResult
+	ShareFileInfo = client.ShareFileInfo
+	ShareOption   = client.ShareOption
+	UserProfile   = client.UserProfile
+	UserProvider  = client.UserProvider
+	VipInfo       = client.VipInfo
+	InviteInfo    = client.InviteInfo
+	InviteReward  = client.InviteReward
+
+	OfflineBatchResult = client.OfflineBatchResult
+	Magnet             = utils.Magnet
+)
+
+const (
+	LinkAuto       = client.LinkAuto
+	LinkOriginal   = client.LinkOriginal
+	LinkTranscoded = client.LinkTranscoded
+
+	HTTPTimeout               = client.HTTPTimeout
+	DefaultMaxResponseBytes   = client.DefaultMaxResponseBytes
+	DefaultTaskPollInterval   = client.DefaultTaskPollInterval
+	DefaultTokenRefreshMargin = client.DefaultTokenRefreshMargin
+
+	ProviderGoogle = client.ProviderGoogle
+	ProviderApple  = client.ProviderApple
+
+	EventLogout = event.EventLogout
+
+	VipTypeNone     = client.VipTypeNone
+	VipTypePlatinum = client.VipTypePlatinum
+)
+
+// NewClient creates a client configured by opts. Invalid option values are
+// logged and replaced by their defaults.
+func NewClient(opts ...Option) *Client {
+	return client.NewClient(opts...)
+}
+
+// NewClientE creates a client configured by opts, or returns every invalid
+// option value as one joined error.
+func NewClientE(opts ...Option) (*Client, error) {
+	return client.NewClientE(opts...)
+}
+
+// NewClientFromConfig creates a client from the credentials, tokens, device
+// id and behavior settings in cfg. opts are applied after the config.
+func NewClientFromConfig(cfg *Config, opts ...Option) (*Client, error) {
+	return client.NewClientFromConfig(cfg, opts...)
+}
+
+// DefaultSigningConfig returns the signing config of the app release the
+// client was written against, for use with WithSigningConfig.
+func DefaultSigningConfig() SigningConfig {
+	return client.DefaultSigningConfig()
+}
+
+// NewStdLogger returns a Logger that prints to logger, or to the standard
+// logger when logger is nil; see WithLogger.
+func NewStdLogger(logger *log.Logger, debug bool) Logger {
+	return client.NewStdLogger(logger, debug)
+}
+
+// NewEventBus returns an event bus for WithEventBus.
+func NewEventBus(opts ...BusOption) *EventBus {
+	return event.NewEventBus(opts...)
+}
+
+// ParseMagnet parses a magnet link with a urn:btih exact topic. The info
+// hash is returned as lower case hex.
+func ParseMagnet(s string) (*Magnet, error) {
+	return utils.ParseMagnet(s)
+}
+
+// UploadTypeOf returns the upload_type the server echoed in a create-file
+// response, or "" if the response has none.
+func UploadTypeOf(result map[string]interface{}) enums.UploadType {
+	return client.UploadTypeOf(result)
+}
+
+// Client options, passed to NewClient.
+var (
+	WithAccessToken             = client.WithAccessToken
+	WithBackoffJitter           = client.WithBackoffJitter
+	WithBandwidthLimit          = client.WithBandwidthLimit
+	WithBaseURL                 = client.WithBaseURL
+	WithConfigAutoSave          = client.WithConfigAutoSave
+	WithDeviceID                = client.WithDeviceID
+	WithDialContext             = client.WithDialContext
+	WithDownloadHost            = client.WithDownloadHost
+	WithDriveBaseURL            = client.WithDriveBaseURL
+	WithDriveHosts              = client.WithDriveHosts
+	WithEncryptedConfigAutoSave = client.WithEncryptedConfigAutoSave
+	WithEventBus                = client.WithEventBus
+	WithHostIPOverride          = client.WithHostIPOverride
+	WithInitialBackoff          = client.WithInitialBackoff
+	WithLogger                  = client.WithLogger
+	WithMaxConcurrentRequests   = client.WithMaxConcurrentRequests
+	WithMaxResponseBytes        = client.WithMaxResponseBytes
+	WithMaxRetries              = client.WithMaxRetries
+	WithMetadataCache           = client.WithMetadataCache
+	WithMetricsCollector        = client.WithMetricsCollector
+	WithPassword                = client.WithPassword
+	WithPreferredLink           = client.WithPreferredLink
+	WithProgress                = client.WithProgress
+	WithProxy                   = client.WithProxy
+	WithRateLimit               = client.WithRateLimit
+	WithRefreshToken            = client.WithRefreshToken
+	WithRetryNonIdempotent      = client.WithRetryNonIdempotent
+	WithRetryPolicy             = client.WithRetryPolicy
+	WithSigningConfig           = client.WithSigningConfig
+	WithThumbnailSize           = client.WithThumbnailSize
+	WithTimeout                 = client.WithTimeout
+	WithTokenRefreshCallback    = client.WithTokenRefreshCallback
+	WithTokenRefreshMargin      = client.WithTokenRefreshMargin
+	WithTokenStore              = client.WithTokenStore
+	WithUserBaseURL             = client.WithUserBaseURL
+	WithUsername                = client.WithUsername
+)
+
+// Per-call options for GetFileLink, DownloadToFile, OfflineDownload,
+// RemoteDownload and FileList.
+var (
+	WithFolderType     = client.WithFolderType
+	WithLinkHost       = client.WithLinkHost
+	WithLinkPreference = client.WithLinkPreference
+	WithSort           = client.WithSort
+)
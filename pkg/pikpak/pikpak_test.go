@@ -0,0 +1,82 @@
+package pikpak_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpak"
+)
+
+// This file imports only the public packages, as an external module would.
+
+var _ pikpak.PikPakAPI = (*pikpak.Client)(nil)
+
+func TestPublicClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/drive/v1/about":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"quota":     map[string]interface{}{"limit": "100", "usage": "40"},
+				"user_type": 1,
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error": "file_not_found"})
+		}
+	}))
+	defer server.Close()
+
+	cli := pikpak.NewClient(
+		pikpak.WithBaseURL(server.URL),
+		pikpak.WithAccessToken("token"),
+		pikpak.WithMaxRetries(0),
+	)
+	ctx := context.Background()
+
+	storage, err := cli.GetStorageInfo(ctx)
+	if err != nil {
+		t.Fatalf("GetStorageInfo: %v", err)
+	}
+	if storage.TotalBytes != 100 || storage.UserType != enums.UserTypeFree {
+		t.Errorf("Unexpected storage info %+v", storage)
+	}
+
+	_, err = cli.GetFileDetails(ctx, "missing")
+	var pe *pikpak.PikpakException
+	if !errors.As(err, &pe) || !errors.Is(err, pikpak.ErrFileNotFound) {
+		t.Errorf("Expected a PikpakException matching ErrFileNotFound, got %v", err)
+	}
+	if pikpak.HTTPStatus(err) != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", pikpak.HTTPStatus(err))
+	}
+}
+
+func Example() {
+	ctx := context.Background()
+
+	cli := pikpak.NewClient(
+		pikpak.WithUsername("your_email@example.com"),
+		pikpak.WithPassword("your_password"),
+		pikpak.WithMaxRetries(3),
+		pikpak.WithTokenRefreshCallback(func(c *pikpak.Client) {
+			log.Println("Token refreshed successfully!")
+		}),
+	)
+
+	if err := cli.Login(ctx); err != nil {
+		log.Fatalf("Login failed: %v", err)
+	}
+
+	files, err := cli.FileList(ctx, 20, "", "", "",
+		pikpak.WithSort(enums.SortFieldModifiedTime, enums.SortOrderDesc))
+	if err != nil {
+		log.Fatalf("List files failed: %v", err)
+	}
+	log.Printf("Files: %+v", files)
+}
@@ -0,0 +1,341 @@
+// Package pikpakfs exposes a PikPak drive as a read-only io/fs file system,
+// so that fs.WalkDir, http.FileServer and other fs.FS consumers can work on
+// cloud files directly.
+package pikpakfs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpak"
+)
+
+// DefaultCacheTTL is how long a directory listing is reused.
+const DefaultCacheTTL = 5 * time.Second
+
+const listPageSize = 100
+
+// Client is the part of pikpak.PikPakAPI the file system uses.
+type Client interface {
+	FileList(ctx context.Context, size int, parentID string, nextPageToken string, query string, opts ...pikpak.ListOption) (map[string]interface{}, error)
+	OpenFile(ctx context.Context, fileID string, offset int64, opts ...pikpak.LinkOption) (io.ReadCloser, error)
+}
+
+// FS is a read-only view of the drive. Paths are relative to the drive root
+// and follow fs.ValidPath. It is safe for concurrent use.
+type FS struct {
+	client Client
+	ctx    context.Context
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu   sync.Mutex
+	dirs map[string]cachedDir
+}
+
+type cachedDir struct {
+	entries []fs.DirEntry
+	expires time.Time
+}
+
+var (
+	_ fs.FS        = (*FS)(nil)
+	_ fs.ReadDirFS = (*FS)(nil)
+	_ fs.StatFS    = (*FS)(nil)
+)
+
+type Option func(*FS)
+
+// WithContext sets the context of the requests made by the file system,
+// since fs.FS methods take none. It defaults to context.Background().
+func WithContext(ctx context.Context) Option {
+	return func(f *FS) {
+		f.ctx = ctx
+	}
+}
+
+// WithCacheTTL sets how long directory listings are reused. Zero or
+// negative disables caching.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(f *FS) {
+		f.ttl = ttl
+	}
+}
+
+func New(client Client, opts ...Option) *FS {
+	f := &FS{
+		client: client,
+		ctx:    context.Background(),
+		ttl:    DefaultCacheTTL,
+		now:    time.Now,
+		dirs:   make(map[string]cachedDir),
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// Open opens the named file or directory. Files are only fetched on the
+// first Read, from the offset of the last Seek.
+func (f *FS) Open(name string) (fs.File, error) {
+	info, err := f.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &dir{fsys: f, info: info, name: name}, nil
+	}
+	return &file{fsys: f, info: info}, nil
+}
+
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	info, err := f.stat("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	info, err := f.stat("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	entries, err := f.list(info.id)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	return append([]fs.DirEntry(nil), entries...), nil
+}
+
+// InvalidateCache drops every cached directory listing.
+func (f *FS) InvalidateCache() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dirs = make(map[string]cachedDir)
+}
+
+// stat looks name up in the cached listing of its parent, which is looked up
+// the same way, so that walking a tree lists each folder once.
+func (f *FS) stat(op, name string) (*fileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return &fileInfo{name: ".", dir: true}, nil
+	}
+	parent, err := f.stat(op, path.Dir(name))
+	if err != nil {
+		return nil, err
+	}
+	if !parent.IsDir() {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	entries, err := f.list(parent.id)
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	base := path.Base(name)
+	i := sort.Search(len(entries), func(i int) bool { return entries[i].Name() >= base })
+	if i == len(entries) || entries[i].Name() != base {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	info, err := entries[i].Info()
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	return info.(*fileInfo), nil
+}
+
+// list returns the entries of the folder id, sorted by name.
+func (f *FS) list(id string) ([]fs.DirEntry, error) {
+	f.mu.Lock()
+	cached, ok := f.dirs[id]
+	f.mu.Unlock()
+	if ok && f.now().Before(cached.expires) {
+		return cached.entries, nil
+	}
+
+	var entries []fs.DirEntry
+	pageToken := ""
+	for {
+		result, err := f.client.FileList(f.ctx, listPageSize, id, pageToken, "")
+		if err != nil {
+			return nil, err
+		}
+		files, _ := result["files"].([]interface{})
+		for _, item := range files {
+			if m, ok := item.(map[string]interface{}); ok {
+				entries = append(entries, fs.FileInfoToDirEntry(newFileInfo(m)))
+			}
+		}
+		pageToken, _ = result["next_page_token"].(string)
+		if pageToken == "" {
+			break
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	if f.ttl > 0 {
+		f.mu.Lock()
+		f.dirs[id] = cachedDir{entries: entries, expires: f.now().Add(f.ttl)}
+		f.mu.Unlock()
+	}
+	return entries, nil
+}
+
+// fileInfo describes a drive file from its metadata. Sys returns the raw
+// metadata map.
+type fileInfo struct {
+	id      string
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+	raw     map[string]interface{}
+}
+
+func newFileInfo(m map[string]interface{}) *fileInfo {
+	info := &fileInfo{raw: m}
+	info.id, _ = m["id"].(string)
+	info.name, _ = m["name"].(string)
+	kind, _ := m["kind"].(string)
+	info.dir = enums.ParseFileKind(kind).IsFolder()
+	if size, ok := m["size"].(string); ok {
+		info.size, _ = strconv.ParseInt(size, 10, 64)
+	}
+	if modified, ok := m["modified_time"].(string); ok {
+		info.modTime, _ = time.Parse(time.RFC3339, modified)
+	}
+	return info
+}
+
+func (i *fileInfo) Name() string       { return i.name }
+func (i *fileInfo) Size() int64        { return i.size }
+func (i *fileInfo) ModTime() time.Time { return i.modTime }
+func (i *fileInfo) IsDir() bool        { return i.dir }
+func (i *fileInfo) Sys() interface{}   { return i.raw }
+
+func (i *fileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+// file streams a drive file. The body is opened lazily and reopened at the
+// new offset after a Seek.
+type file struct {
+	fsys   *FS
+	info   *fileInfo
+	body   io.ReadCloser
+	offset int64
+	closed bool
+}
+
+func (f *file) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+func (f *file) Read(p []byte) (int, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "read", Path: f.info.name, Err: fs.ErrClosed}
+	}
+	if f.offset >= f.info.size {
+		return 0, io.EOF
+	}
+	if f.body == nil {
+		// The original, since a rendition would not match the size.
+		body, err := f.fsys.client.OpenFile(f.fsys.ctx, f.info.id, f.offset, pikpak.WithLinkPreference(pikpak.LinkOriginal))
+		if err != nil {
+			return 0, &fs.PathError{Op: "read", Path: f.info.name, Err: err}
+		}
+		f.body = body
+	}
+	n, err := f.body.Read(p)
+	f.offset += int64(n)
+	return n, err
+}
+
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "seek", Path: f.info.name, Err: fs.ErrClosed}
+	}
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.info.size
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.info.name, Err: fs.ErrInvalid}
+	}
+	if offset != f.offset && f.body != nil {
+		f.body.Close()
+		f.body = nil
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *file) Close() error {
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.info.name, Err: fs.ErrClosed}
+	}
+	f.closed = true
+	if f.body != nil {
+		return f.body.Close()
+	}
+	return nil
+}
+
+// dir is an open directory. Its entries are read on the first ReadDir.
+type dir struct {
+	fsys    *FS
+	info    *fileInfo
+	name    string
+	entries []fs.DirEntry
+	read    bool
+}
+
+func (d *dir) Stat() (fs.FileInfo, error) { return d.info, nil }
+
+func (d *dir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+func (d *dir) Close() error { return nil }
+
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.read {
+		entries, err := d.fsys.list(d.info.id)
+		if err != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: d.name, Err: err}
+		}
+		d.entries, d.read = entries, true
+	}
+	if n <= 0 {
+		entries := append([]fs.DirEntry(nil), d.entries...)
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
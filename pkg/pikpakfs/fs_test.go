@@ -0,0 +1,245 @@
+package pikpakfs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpak"
+)
+
+type stubFile struct {
+	id, parent, name string
+	folder           bool
+	content          string
+}
+
+var stubTree = []stubFile{
+	{id: "d1", name: "docs", folder: true},
+	{id: "f1", name: "hello.txt", content: "hello, world\n"},
+	{id: "f3", name: "empty.txt"},
+	{id: "f2", parent: "d1", name: "a.txt", content: "alpha"},
+	{id: "d2", parent: "d1", name: "nested", folder: true},
+	{id: "f4", parent: "d2", name: "b.bin", content: strings.Repeat("0123456789", 1000)},
+}
+
+var stubModified = time.Date(2024, 4, 25, 16, 40, 0, 0, time.UTC)
+
+// stubDrive serves stubTree, one entry per listing page, and the file
+// contents with range support. listings counts the folder listings.
+type stubDrive struct {
+	*httptest.Server
+	listings int32
+}
+
+func newStubDrive(t *testing.T) *stubDrive {
+	d := &stubDrive{}
+	d.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/drive/v1/files":
+			atomic.AddInt32(&d.listings, 1)
+			parent := r.URL.Query().Get("parent_id")
+			var children []stubFile
+			for _, f := range stubTree {
+				if f.parent == parent {
+					children = append(children, f)
+				}
+			}
+			page, _ := strconv.Atoi(r.URL.Query().Get("page_token"))
+			result := map[string]interface{}{"files": []interface{}{}}
+			if page < len(children) {
+				result["files"] = []interface{}{stubMetadata(children[page])}
+			}
+			if page+1 < len(children) {
+				result["next_page_token"] = strconv.Itoa(page + 1)
+			}
+			json.NewEncoder(w).Encode(result)
+		case strings.HasPrefix(r.URL.Path, "/drive/v1/files/"):
+			id := strings.TrimPrefix(r.URL.Path, "/drive/v1/files/")
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": id, "web_content_link": d.URL + "/content/" + id})
+		case strings.HasPrefix(r.URL.Path, "/content/"):
+			id := strings.TrimPrefix(r.URL.Path, "/content/")
+			for _, f := range stubTree {
+				if f.id == id {
+					http.ServeContent(w, r, f.name, stubModified, strings.NewReader(f.content))
+					return
+				}
+			}
+			http.NotFound(w, r)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error": "file_not_found"})
+		}
+	}))
+	t.Cleanup(d.Close)
+	return d
+}
+
+func stubMetadata(f stubFile) map[string]interface{} {
+	kind := enums.FileKindFile
+	if f.folder {
+		kind = enums.FileKindFolder
+	}
+	return map[string]interface{}{
+		"id":            f.id,
+		"name":          f.name,
+		"kind":          kind.String(),
+		"size":          strconv.Itoa(len(f.content)),
+		"modified_time": stubModified.Format(time.RFC3339),
+	}
+}
+
+func newTestFS(t *testing.T, opts ...Option) (*FS, *stubDrive) {
+	drive := newStubDrive(t)
+	cli := pikpak.NewClient(
+		pikpak.WithBaseURL(drive.URL),
+		pikpak.WithAccessToken("token"),
+		pikpak.WithMaxRetries(0),
+	)
+	return New(cli, opts...), drive
+}
+
+func TestFS(t *testing.T) {
+	fsys, _ := newTestFS(t)
+	if err := fstest.TestFS(fsys, "hello.txt", "empty.txt", "docs/a.txt", "docs/nested/b.bin"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFSNotExist(t *testing.T) {
+	fsys, _ := newTestFS(t)
+
+	if _, err := fsys.Open("docs/missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist, got %v", err)
+	}
+	if _, err := fsys.Stat("hello.txt/inner"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist below a file, got %v", err)
+	}
+	if _, err := fsys.Open("/hello.txt"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("Expected fs.ErrInvalid for a rooted path, got %v", err)
+	}
+}
+
+func TestFSSeek(t *testing.T) {
+	fsys, _ := newTestFS(t)
+
+	f, err := fsys.Open("docs/nested/b.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	seeker := f.(io.ReadSeeker)
+	if _, err := seeker.Seek(-5, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	tail, err := io.ReadAll(seeker)
+	if err != nil || string(tail) != "56789" {
+		t.Errorf("Expected the last 5 bytes, got %q, %v", tail, err)
+	}
+}
+
+func TestFSReadDirCache(t *testing.T) {
+	fsys, drive := newTestFS(t)
+	now := time.Now()
+	fsys.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		entries, err := fsys.ReadDir(".")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 3 || entries[0].Name() != "docs" || !entries[0].IsDir() {
+			t.Fatalf("Expected the sorted root entries, got %v", entries)
+		}
+	}
+	// The root has three entries, one per page.
+	if n := atomic.LoadInt32(&drive.listings); n != 3 {
+		t.Errorf("Expected the second listing from the cache, got %d requests", n)
+	}
+
+	now = now.Add(DefaultCacheTTL)
+	if _, err := fsys.ReadDir("."); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&drive.listings); n != 6 {
+		t.Errorf("Expected the listing to expire, got %d requests", n)
+	}
+}
+
+func TestFSStatUsesListings(t *testing.T) {
+	fsys, drive := newTestFS(t)
+
+	for i := 0; i < 2; i++ {
+		info, err := fsys.Stat("docs/nested/b.bin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() != 10000 || info.IsDir() {
+			t.Errorf("Unexpected info %v %d", info.Name(), info.Size())
+		}
+	}
+	// The root, docs and nested listings take three, two and one pages.
+	if n := atomic.LoadInt32(&drive.listings); n != 6 {
+		t.Errorf("Expected each folder to be listed once, got %d requests", n)
+	}
+
+	if _, err := fsys.Stat("docs/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fsys.Stat("hello.txt/a.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected a path below a file not to exist, got %v", err)
+	}
+	if n := atomic.LoadInt32(&drive.listings); n != 6 {
+		t.Errorf("Expected the cached listings to be used, got %d requests", n)
+	}
+}
+
+func TestFileServer(t *testing.T) {
+	fsys, _ := newTestFS(t)
+	server := httptest.NewServer(http.FileServer(http.FS(fsys)))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/hello.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "hello, world\n" {
+		t.Errorf("Expected the file, got %d %q", resp.StatusCode, body)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/docs/nested/b.bin", nil)
+	req.Header.Set("Range", "bytes=10-19")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent || !bytes.Equal(body, []byte("0123456789")) {
+		t.Errorf("Expected a partial response, got %d %q", resp.StatusCode, body)
+	}
+
+	resp, err = http.Get(server.URL + "/docs/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "a.txt") || !strings.Contains(string(body), "nested/") {
+		t.Errorf("Expected a directory listing, got %q", body)
+	}
+}
@@ -0,0 +1,161 @@
+//go:build fuse
+
+package pikpakfuse
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"syscall"
+
+	"bazil.org/fuse"
+	fusefs "bazil.org/fuse/fs"
+)
+
+// Mount mounts the drive read-only at mountpoint and serves it until ctx is
+// done, then unmounts it.
+func Mount(ctx context.Context, mountpoint string, client Client, opts ...Option) error {
+	tree := NewTree(client, append([]Option{WithContext(ctx)}, opts...)...)
+
+	conn, err := fuse.Mount(mountpoint,
+		fuse.FSName("pikpak"),
+		fuse.Subtype("pikpak"),
+		fuse.ReadOnly(),
+	)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	errc := make(chan error, 1)
+	go func() { errc <- fusefs.Serve(conn, root{tree}) }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	if err := fuse.Unmount(mountpoint); err != nil {
+		return err
+	}
+	return <-errc
+}
+
+type root struct {
+	tree *Tree
+}
+
+func (r root) Root() (fusefs.Node, error) {
+	return &dirNode{tree: r.tree, node: r.tree.Root()}, nil
+}
+
+type dirNode struct {
+	tree *Tree
+	node Node
+}
+
+var (
+	_ fusefs.NodeRequestLookuper = (*dirNode)(nil)
+	_ fusefs.HandleReadDirAller  = (*dirNode)(nil)
+)
+
+func (d *dirNode) Attr(ctx context.Context, a *fuse.Attr) error {
+	fillAttr(a, d.tree.Attr(d.node))
+	return nil
+}
+
+func (d *dirNode) Lookup(ctx context.Context, req *fuse.LookupRequest, resp *fuse.LookupResponse) (fusefs.Node, error) {
+	n, err := d.tree.Lookup(ctx, d.node, req.Name)
+	if err != nil {
+		return nil, errno(err)
+	}
+	resp.EntryValid = d.tree.TTL()
+	if n.Dir {
+		return &dirNode{tree: d.tree, node: n}, nil
+	}
+	return &fileNode{tree: d.tree, node: n}, nil
+}
+
+func (d *dirNode) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
+	nodes, err := d.tree.ReadDir(ctx, d.node)
+	if err != nil {
+		return nil, errno(err)
+	}
+	dirents := make([]fuse.Dirent, len(nodes))
+	for i, n := range nodes {
+		dirents[i] = fuse.Dirent{Inode: d.tree.Attr(n).Inode, Name: n.Name, Type: fuse.DT_File}
+		if n.Dir {
+			dirents[i].Type = fuse.DT_Dir
+		}
+	}
+	return dirents, nil
+}
+
+type fileNode struct {
+	tree *Tree
+	node Node
+}
+
+var _ fusefs.NodeOpener = (*fileNode)(nil)
+
+func (f *fileNode) Attr(ctx context.Context, a *fuse.Attr) error {
+	fillAttr(a, f.tree.Attr(f.node))
+	return nil
+}
+
+func (f *fileNode) Open(ctx context.Context, req *fuse.OpenRequest, resp *fuse.OpenResponse) (fusefs.Handle, error) {
+	if !req.Flags.IsReadOnly() {
+		return nil, fuse.Errno(syscall.EROFS)
+	}
+	h, err := f.tree.Open(f.node)
+	if err != nil {
+		return nil, errno(err)
+	}
+	return &fileHandle{h: h}, nil
+}
+
+type fileHandle struct {
+	h *Handle
+}
+
+var (
+	_ fusefs.HandleReader   = (*fileHandle)(nil)
+	_ fusefs.HandleReleaser = (*fileHandle)(nil)
+)
+
+func (h *fileHandle) Read(ctx context.Context, req *fuse.ReadRequest, resp *fuse.ReadResponse) error {
+	buf := make([]byte, req.Size)
+	n, err := h.h.ReadAt(buf, req.Offset)
+	if err != nil {
+		return errno(err)
+	}
+	resp.Data = buf[:n]
+	return nil
+}
+
+func (h *fileHandle) Release(ctx context.Context, req *fuse.ReleaseRequest) error {
+	return h.h.Close()
+}
+
+func fillAttr(dst *fuse.Attr, a Attr) {
+	dst.Inode = a.Inode
+	dst.Size = a.Size
+	dst.Blocks = a.Blocks
+	dst.Mode = a.Mode
+	dst.Nlink = a.Nlink
+	dst.Mtime = a.Mtime
+	dst.Ctime = a.Mtime
+	dst.Valid = a.Valid
+}
+
+// errno maps the errors of Tree to the codes the kernel expects.
+func errno(err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return fuse.ENOENT
+	case errors.Is(err, ErrNotDir):
+		return fuse.Errno(syscall.ENOTDIR)
+	default:
+		return fuse.Errno(syscall.EIO)
+	}
+}
@@ -0,0 +1,298 @@
+// Package pikpakfuse mounts a PikPak drive read-only with FUSE.
+//
+// The mapping from the drive to file system nodes lives in Tree and builds
+// everywhere. Mount, which talks to the kernel through bazil.org/fuse, is
+// only compiled with the fuse build tag:
+//
+//	go build -tags fuse ./cmd/pikpak
+package pikpakfuse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpak"
+)
+
+// DefaultCacheTTL is how long directory listings are reused and how long the
+// kernel may cache attributes and entries.
+const DefaultCacheTTL = 10 * time.Second
+
+const listPageSize = 100
+
+// RootInode is the inode number of the drive root.
+const RootInode = 1
+
+var ErrNotDir = errors.New("not a directory")
+
+// Client is the part of pikpak.PikPakAPI the mount uses.
+type Client interface {
+	FileList(ctx context.Context, size int, parentID string, nextPageToken string, query string, opts ...pikpak.ListOption) (map[string]interface{}, error)
+	OpenFile(ctx context.Context, fileID string, offset int64, opts ...pikpak.LinkOption) (io.ReadCloser, error)
+}
+
+// Node is a file or folder of the drive. The root has an empty ID.
+type Node struct {
+	ID       string
+	Name     string
+	Dir      bool
+	Size     int64
+	Modified time.Time
+}
+
+// Attr is the file system view of a node.
+type Attr struct {
+	Inode  uint64
+	Size   uint64
+	Blocks uint64
+	Mode   os.FileMode
+	Nlink  uint32
+	Mtime  time.Time
+	// Valid is how long the kernel may cache the attributes.
+	Valid time.Duration
+}
+
+// Tree maps drive folders to directories. Listings are cached for the cache
+// TTL and inode numbers stay stable for the life of the Tree. It is safe for
+// concurrent use.
+type Tree struct {
+	client Client
+	ctx    context.Context
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	dirs      map[string]cachedDir
+	inodes    map[string]uint64
+	nextInode uint64
+}
+
+type cachedDir struct {
+	nodes   []Node
+	expires time.Time
+}
+
+type Option func(*Tree)
+
+// WithCacheTTL sets how long listings and attributes are cached. Zero
+// disables caching.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(t *Tree) {
+		t.ttl = ttl
+	}
+}
+
+// WithContext sets the context file contents are streamed with, since an
+// open file outlives the request that opened it. It defaults to
+// context.Background().
+func WithContext(ctx context.Context) Option {
+	return func(t *Tree) {
+		t.ctx = ctx
+	}
+}
+
+func NewTree(client Client, opts ...Option) *Tree {
+	t := &Tree{
+		client:    client,
+		ctx:       context.Background(),
+		ttl:       DefaultCacheTTL,
+		now:       time.Now,
+		dirs:      make(map[string]cachedDir),
+		inodes:    map[string]uint64{"": RootInode},
+		nextInode: RootInode + 1,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+func (t *Tree) Root() Node {
+	return Node{Dir: true}
+}
+
+// TTL is how long the kernel may cache attributes and entries.
+func (t *Tree) TTL() time.Duration {
+	return t.ttl
+}
+
+// ReadDir returns the children of dir sorted as the drive lists them.
+func (t *Tree) ReadDir(ctx context.Context, dir Node) ([]Node, error) {
+	if !dir.Dir {
+		return nil, ErrNotDir
+	}
+
+	t.mu.Lock()
+	cached, ok := t.dirs[dir.ID]
+	t.mu.Unlock()
+	if ok && t.now().Before(cached.expires) {
+		return cached.nodes, nil
+	}
+
+	var nodes []Node
+	pageToken := ""
+	for {
+		result, err := t.client.FileList(ctx, listPageSize, dir.ID, pageToken, "")
+		if err != nil {
+			return nil, err
+		}
+		files, _ := result["files"].([]interface{})
+		for _, item := range files {
+			if m, ok := item.(map[string]interface{}); ok {
+				nodes = append(nodes, newNode(m))
+			}
+		}
+		pageToken, _ = result["next_page_token"].(string)
+		if pageToken == "" {
+			break
+		}
+	}
+
+	if t.ttl > 0 {
+		t.mu.Lock()
+		t.dirs[dir.ID] = cachedDir{nodes: nodes, expires: t.now().Add(t.ttl)}
+		t.mu.Unlock()
+	}
+	return nodes, nil
+}
+
+// Lookup returns the child of dir called name, or fs.ErrNotExist.
+func (t *Tree) Lookup(ctx context.Context, dir Node, name string) (Node, error) {
+	nodes, err := t.ReadDir(ctx, dir)
+	if err != nil {
+		return Node{}, err
+	}
+	for _, n := range nodes {
+		if n.Name == name {
+			return n, nil
+		}
+	}
+	return Node{}, fs.ErrNotExist
+}
+
+// Invalidate drops every cached listing.
+func (t *Tree) Invalidate() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.dirs = make(map[string]cachedDir)
+}
+
+// Attr returns the attributes of n. Everything is read-only.
+func (t *Tree) Attr(n Node) Attr {
+	a := Attr{
+		Inode: t.inode(n.ID),
+		Mtime: n.Modified,
+		Valid: t.ttl,
+	}
+	if n.Dir {
+		a.Mode = os.ModeDir | 0o555
+		a.Nlink = 2
+		return a
+	}
+	a.Mode = 0o444
+	a.Nlink = 1
+	if n.Size > 0 {
+		a.Size = uint64(n.Size)
+		a.Blocks = (a.Size + 511) / 512
+	}
+	return a
+}
+
+// inode returns the inode number of the file id, assigning the next free one
+// on first sight.
+func (t *Tree) inode(id string) uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ino, ok := t.inodes[id]; ok {
+		return ino
+	}
+	ino := t.nextInode
+	t.nextInode++
+	t.inodes[id] = ino
+	return ino
+}
+
+func newNode(m map[string]interface{}) Node {
+	var n Node
+	n.ID, _ = m["id"].(string)
+	n.Name, _ = m["name"].(string)
+	kind, _ := m["kind"].(string)
+	n.Dir = enums.ParseFileKind(kind).IsFolder()
+	if size, ok := m["size"].(string); ok {
+		n.Size, _ = strconv.ParseInt(size, 10, 64)
+	}
+	if modified, ok := m["modified_time"].(string); ok {
+		n.Modified, _ = time.Parse(time.RFC3339, modified)
+	}
+	return n
+}
+
+// Open returns a handle reading the content of the file n.
+func (t *Tree) Open(n Node) (*Handle, error) {
+	if n.Dir {
+		return nil, errors.New("is a directory")
+	}
+	return &Handle{tree: t, node: n}, nil
+}
+
+// Handle reads a file with ranged downloads. Sequential reads share one
+// download; a read at another offset starts a new one there.
+type Handle struct {
+	tree *Tree
+	node Node
+
+	mu     sync.Mutex
+	body   io.ReadCloser
+	offset int64
+}
+
+// ReadAt reads up to len(p) bytes at off. It returns fewer bytes, and a nil
+// error, only at the end of the file, as FUSE expects.
+func (h *Handle) ReadAt(p []byte, off int64) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if off >= h.node.Size {
+		return 0, nil
+	}
+	if remaining := h.node.Size - off; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	if h.body != nil && off != h.offset {
+		h.body.Close()
+		h.body = nil
+	}
+	if h.body == nil {
+		// The original, since a rendition would not match the size.
+		body, err := h.tree.client.OpenFile(h.tree.ctx, h.node.ID, off, pikpak.WithLinkPreference(pikpak.LinkOriginal))
+		if err != nil {
+			return 0, err
+		}
+		h.body, h.offset = body, off
+	}
+
+	n, err := io.ReadFull(h.body, p)
+	h.offset += int64(n)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return n, err
+}
+
+func (h *Handle) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.body == nil {
+		return nil
+	}
+	err := h.body.Close()
+	h.body = nil
+	return err
+}
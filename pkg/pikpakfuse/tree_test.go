@@ -0,0 +1,195 @@
+package pikpakfuse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/mocks"
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpak"
+	"github.com/zhz8888/pikpakapi-go/pkg/testsupport"
+	"go.uber.org/mock/gomock"
+)
+
+var modified = time.Date(2024, 4, 25, 16, 40, 0, 0, time.UTC)
+
+func listing(files ...map[string]interface{}) map[string]interface{} {
+	items := make([]interface{}, len(files))
+	for i, f := range files {
+		items[i] = f
+	}
+	return map[string]interface{}{"files": items}
+}
+
+func folder(id, name string) map[string]interface{} {
+	return map[string]interface{}{"id": id, "name": name, "kind": "drive#folder"}
+}
+
+func file(id, name, size string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":            id,
+		"name":          name,
+		"kind":          "drive#file",
+		"size":          size,
+		"modified_time": modified.Format(time.RFC3339),
+	}
+}
+
+func TestTreeLookupAndAttr(t *testing.T) {
+	fake := testsupport.NewFakeClient().
+		On("FileList", listing(folder("d1", "docs"), file("f1", "hello.txt", "1000")), nil)
+	tree := NewTree(fake)
+
+	root := tree.Root()
+	if a := tree.Attr(root); a.Inode != RootInode || a.Mode != os.ModeDir|0o555 || a.Nlink != 2 {
+		t.Errorf("Expected a read-only root directory, got %+v", a)
+	}
+
+	n, err := tree.Lookup(context.Background(), root, "hello.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := tree.Attr(n)
+	if a.Mode != 0o444 || a.Size != 1000 || a.Blocks != 2 || !a.Mtime.Equal(modified) || a.Valid != DefaultCacheTTL {
+		t.Errorf("Unexpected file attributes %+v", a)
+	}
+	if again := tree.Attr(n); again.Inode != a.Inode || a.Inode == RootInode {
+		t.Errorf("Expected a stable inode, got %d then %d", a.Inode, again.Inode)
+	}
+
+	d, err := tree.Lookup(context.Background(), root, "docs")
+	if err != nil || !d.Dir {
+		t.Fatalf("Expected the docs folder, got %+v, %v", d, err)
+	}
+	if tree.Attr(d).Inode == a.Inode {
+		t.Error("Expected distinct inodes for distinct files")
+	}
+
+	if _, err := tree.Lookup(context.Background(), root, "missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist, got %v", err)
+	}
+	if _, err := tree.ReadDir(context.Background(), n); !errors.Is(err, ErrNotDir) {
+		t.Errorf("Expected ErrNotDir for a file, got %v", err)
+	}
+
+	// Every lookup above was served by the first listing.
+	if calls := fake.CallsTo("FileList"); len(calls) != 1 {
+		t.Errorf("Expected one listing, got %d", len(calls))
+	}
+}
+
+func TestTreeReadDirPagesAndExpires(t *testing.T) {
+	var parents []string
+	mock := mocks.NewMockPikPakAPI(gomock.NewController(t))
+	mock.EXPECT().FileList(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(ctx context.Context, size int, parentID string, nextPageToken string, query string, opts ...pikpak.ListOption) (map[string]interface{}, error) {
+			parents = append(parents, parentID)
+			if nextPageToken == "" {
+				result := listing(file("f1", "a.txt", "1"))
+				result["next_page_token"] = "2"
+				return result, nil
+			}
+			return listing(file("f2", "b.txt", "2")), nil
+		})
+	tree := NewTree(mock)
+	now := time.Now()
+	tree.now = func() time.Time { return now }
+
+	dir := Node{ID: "d1", Dir: true}
+	nodes, err := tree.ReadDir(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 2 || nodes[0].Name != "a.txt" || nodes[1].Name != "b.txt" {
+		t.Fatalf("Expected both pages, got %+v", nodes)
+	}
+	if len(parents) != 2 || parents[0] != "d1" {
+		t.Errorf("Expected two pages of d1, got %v", parents)
+	}
+
+	tree.ReadDir(context.Background(), dir)
+	if len(parents) != 2 {
+		t.Errorf("Expected the cached listing, got %d requests", len(parents))
+	}
+
+	now = now.Add(DefaultCacheTTL)
+	tree.ReadDir(context.Background(), dir)
+	if len(parents) != 4 {
+		t.Errorf("Expected the listing to expire, got %d requests", len(parents))
+	}
+
+	tree.Invalidate()
+	tree.ReadDir(context.Background(), dir)
+	if len(parents) != 6 {
+		t.Errorf("Expected Invalidate to drop the listing, got %d requests", len(parents))
+	}
+}
+
+func TestHandleReadAt(t *testing.T) {
+	const content = "0123456789abcdef"
+	var offsets []int64
+	mock := mocks.NewMockPikPakAPI(gomock.NewController(t))
+	mock.EXPECT().OpenFile(gomock.Any(), "f1", gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(ctx context.Context, fileID string, offset int64, opts ...pikpak.LinkOption) (io.ReadCloser, error) {
+			offsets = append(offsets, offset)
+			return io.NopCloser(strings.NewReader(content[offset:])), nil
+		})
+	tree := NewTree(mock)
+	h, err := tree.Open(Node{ID: "f1", Name: "f.bin", Size: int64(len(content))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	read := func(off int64, size int) string {
+		t.Helper()
+		buf := make([]byte, size)
+		n, err := h.ReadAt(buf, off)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(buf[:n])
+	}
+
+	if got := read(0, 4); got != "0123" {
+		t.Errorf("Expected 0123, got %q", got)
+	}
+	if got := read(4, 4); got != "4567" {
+		t.Errorf("Expected 4567, got %q", got)
+	}
+	if len(offsets) != 1 {
+		t.Errorf("Expected sequential reads to share a download, got offsets %v", offsets)
+	}
+
+	if got := read(12, 8); got != "cdef" {
+		t.Errorf("Expected a short read at the end, got %q", got)
+	}
+	if got := read(16, 8); got != "" {
+		t.Errorf("Expected nothing past the end, got %q", got)
+	}
+	if len(offsets) != 2 || offsets[1] != 12 {
+		t.Errorf("Expected a new download at 12, got offsets %v", offsets)
+	}
+
+	if _, err := tree.Open(Node{Dir: true}); err == nil {
+		t.Error("Expected opening a directory to fail")
+	}
+}
+
+func TestHandleReadAtError(t *testing.T) {
+	fake := testsupport.NewFakeClient().On("OpenFile", nil, pikpak.ErrFileNotFound)
+	h, _ := NewTree(fake).Open(Node{ID: "f1", Size: 10})
+
+	if _, err := h.ReadAt(make([]byte, 4), 0); !errors.Is(err, pikpak.ErrFileNotFound) {
+		t.Errorf("Expected the download error, got %v", err)
+	}
+	calls := fake.CallsTo("OpenFile")
+	if len(calls) != 1 || calls[0].Args[0] != "f1" {
+		t.Errorf("Expected one download of f1, got %+v", calls)
+	}
+}
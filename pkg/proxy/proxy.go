@@ -0,0 +1,297 @@
+// Package proxy streams drive and share files over plain HTTP, so that media
+// players which cannot send the PikPak headers or follow expiring download
+// links can play them.
+//
+//	GET /file/{fileID}
+//	GET /share/{shareID}/{fileID}[?password=PASSCODE]
+//
+// Download links are resolved once and reused until they expire. Range and
+// conditional headers are forwarded, so players can seek. When the upstream
+// server rejects a cached link, it is resolved again and the request retried
+// once.
+package proxy
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpak"
+)
+
+// DefaultLinkTTL is how long a resolved download link is reused. Links
+// usually stay valid for longer; a link that expires earlier is resolved
+// again on the next rejected request.
+const DefaultLinkTTL = 10 * time.Minute
+
+// Client is the part of pikpak.PikPakAPI the proxy uses.
+type Client interface {
+	GetFileLink(ctx context.Context, fileID string, opts ...pikpak.LinkOption) (string, error)
+	GetShareFileLink(ctx context.Context, shareID string, fileID string, sharePassword string, opts ...pikpak.LinkOption) (string, error)
+}
+
+// forwardedRequestHeaders are copied to the upstream request.
+var forwardedRequestHeaders = []string{"Range", "If-Range", "If-Modified-Since", "If-None-Match"}
+
+// forwardedResponseHeaders are copied back to the player.
+var forwardedResponseHeaders = []string{
+	"Accept-Ranges", "Content-Disposition", "Content-Length", "Content-Range",
+	"Content-Type", "ETag", "Last-Modified",
+}
+
+type handler struct {
+	client     Client
+	httpClient *http.Client
+	token      string
+	user       string
+	pass       string
+	linkTTL    time.Duration
+	logger     pikpak.Logger
+	now        func() time.Time
+
+	mu    sync.Mutex
+	links map[string]cachedLink
+	// nextSweep is when link next drops the expired entries of links.
+	nextSweep time.Time
+}
+
+type cachedLink struct {
+	url     string
+	expires time.Time
+}
+
+type Option func(*handler)
+
+// WithToken accepts requests carrying token in the token query parameter.
+func WithToken(token string) Option {
+	return func(h *handler) {
+		h.token = token
+	}
+}
+
+// WithBasicAuth accepts requests authenticated with user and pass.
+func WithBasicAuth(user, pass string) Option {
+	return func(h *handler) {
+		h.user, h.pass = user, pass
+	}
+}
+
+// WithLinkTTL sets how long resolved links are reused, DefaultLinkTTL by
+// default. Zero resolves the link on every request.
+func WithLinkTTL(ttl time.Duration) Option {
+	return func(h *handler) {
+		h.linkTTL = ttl
+	}
+}
+
+// WithHTTPClient sets the client that fetches the files from the download
+// servers. It defaults to http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(h *handler) {
+		h.httpClient = httpClient
+	}
+}
+
+// WithLogger reports the errors behind failed requests to logger: missing
+// files at Debug level, everything else at Error level. Players only get the
+// status text, since the errors can carry API details.
+func WithLogger(logger pikpak.Logger) Option {
+	return func(h *handler) {
+		h.logger = logger
+	}
+}
+
+// Handler returns the proxy for the drive of client. When WithToken or
+// WithBasicAuth is given, a request must satisfy one of them.
+func Handler(client Client, opts ...Option) http.Handler {
+	h := &handler{
+		client:     client,
+		httpClient: http.DefaultClient,
+		linkTTL:    DefaultLinkTTL,
+		now:        time.Now,
+		links:      make(map[string]cachedLink),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		if h.user != "" || h.pass != "" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="PikPak"`)
+		}
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// The original, since a rendition would not honour the ranges of the
+	// original size.
+	linkOpt := pikpak.WithLinkPreference(pikpak.LinkOriginal)
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 2 && parts[0] == "file" && parts[1] != "":
+		fileID := parts[1]
+		h.stream(w, r, "file/"+fileID, func(ctx context.Context) (string, error) {
+			return h.client.GetFileLink(ctx, fileID, linkOpt)
+		})
+	case len(parts) == 3 && parts[0] == "share" && parts[1] != "" && parts[2] != "":
+		shareID, fileID, password := parts[1], parts[2], r.URL.Query().Get("password")
+		// The link depends on the password, which is hashed so that the
+		// cache does not hold it.
+		sum := sha256.Sum256([]byte(password))
+		h.stream(w, r, "share/"+shareID+"/"+fileID+"?"+hex.EncodeToString(sum[:]), func(ctx context.Context) (string, error) {
+			return h.client.GetShareFileLink(ctx, shareID, fileID, password, linkOpt)
+		})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *handler) authorized(r *http.Request) bool {
+	if h.token == "" && h.user == "" && h.pass == "" {
+		return true
+	}
+	if h.token != "" && subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(h.token)) == 1 {
+		return true
+	}
+	if h.user != "" || h.pass != "" {
+		user, pass, ok := r.BasicAuth()
+		return ok && subtle.ConstantTimeCompare([]byte(user), []byte(h.user)) == 1 && subtle.ConstantTimeCompare([]byte(pass), []byte(h.pass)) == 1
+	}
+	return false
+}
+
+// stream fetches the file at the link of key and copies the response. A
+// cached link the upstream server rejects is resolved again once.
+func (h *handler) stream(w http.ResponseWriter, r *http.Request, key string, resolve func(context.Context) (string, error)) {
+	for attempt := 0; ; attempt++ {
+		link, cached, err := h.link(r.Context(), key, resolve)
+		if err != nil {
+			h.fail(w, r, errorStatus(err), err)
+			return
+		}
+
+		req, err := http.NewRequestWithContext(r.Context(), r.Method, link, nil)
+		if err != nil {
+			h.fail(w, r, http.StatusBadGateway, err)
+			return
+		}
+		for _, name := range forwardedRequestHeaders {
+			if v := r.Header.Get(name); v != "" {
+				req.Header.Set(name, v)
+			}
+		}
+		resp, err := h.httpClient.Do(req)
+		if err != nil {
+			h.fail(w, r, http.StatusBadGateway, err)
+			return
+		}
+
+		if linkExpired(resp.StatusCode) {
+			resp.Body.Close()
+			h.forget(key)
+			if cached && attempt == 0 {
+				continue
+			}
+			h.fail(w, r, http.StatusBadGateway, fmt.Errorf("download link rejected: %s", resp.Status))
+			return
+		}
+
+		defer resp.Body.Close()
+		for _, name := range forwardedResponseHeaders {
+			if v := resp.Header.Get(name); v != "" {
+				w.Header().Set(name, v)
+			}
+		}
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+		return
+	}
+}
+
+// fail answers with the text of status and logs err.
+func (h *handler) fail(w http.ResponseWriter, r *http.Request, status int, err error) {
+	if h.logger != nil {
+		if status == http.StatusNotFound {
+			h.logger.Debugf("proxy: %s %s: %v", r.Method, r.URL.Path, err)
+		} else {
+			h.logger.Errorf("proxy: %s %s: %v", r.Method, r.URL.Path, err)
+		}
+	}
+	http.Error(w, http.StatusText(status), status)
+}
+
+// link returns the download link of key, reporting whether it came from the
+// cache. Expired links are dropped at most once per link TTL, when a new one
+// is cached, so the cache only holds the links of the last two TTLs.
+func (h *handler) link(ctx context.Context, key string, resolve func(context.Context) (string, error)) (string, bool, error) {
+	h.mu.Lock()
+	entry, ok := h.links[key]
+	h.mu.Unlock()
+	if ok && h.now().Before(entry.expires) {
+		return entry.url, true, nil
+	}
+
+	link, err := resolve(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	if h.linkTTL > 0 {
+		h.mu.Lock()
+		now := h.now()
+		if !now.Before(h.nextSweep) {
+			for k, entry := range h.links {
+				if !now.Before(entry.expires) {
+					delete(h.links, k)
+				}
+			}
+			h.nextSweep = now.Add(h.linkTTL)
+		}
+		h.links[key] = cachedLink{url: link, expires: now.Add(h.linkTTL)}
+		h.mu.Unlock()
+	}
+	return link, false, nil
+}
+
+func (h *handler) forget(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.links, key)
+}
+
+// linkExpired reports whether the download server rejected the link itself,
+// as it does once the signature has expired.
+func linkExpired(status int) bool {
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusGone:
+		return true
+	}
+	return false
+}
+
+// errorStatus maps an error resolving a link to a status.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, pikpak.ErrFileNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, pikpak.ErrSharePasswordWrong):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadGateway
+	}
+}
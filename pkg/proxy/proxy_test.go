@@ -0,0 +1,285 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpak"
+)
+
+const content = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// upstream serves content at /dl/{id}?sig=N and rejects every signature but
+// the latest with 403, like an expired download link.
+type upstream struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	sig     int
+	resolve map[string]int
+}
+
+func newUpstream(t *testing.T) *upstream {
+	u := &upstream{resolve: make(map[string]int)}
+	u.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u.mu.Lock()
+		valid := r.URL.Query().Get("sig") == fmt.Sprint(u.sig)
+		u.mu.Unlock()
+		if !valid || !strings.HasPrefix(r.URL.Path, "/dl/") {
+			http.Error(w, "expired", http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "video/mp4")
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(content))
+	}))
+	t.Cleanup(u.Close)
+	return u
+}
+
+// expire invalidates every link handed out so far.
+func (u *upstream) expire() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.sig++
+}
+
+func (u *upstream) link(key string) string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.resolve[key]++
+	return fmt.Sprintf("%s/dl/%s?sig=%d", u.URL, key, u.sig)
+}
+
+func (u *upstream) resolves(key string) int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.resolve[key]
+}
+
+func (u *upstream) GetFileLink(ctx context.Context, fileID string, opts ...pikpak.LinkOption) (string, error) {
+	if fileID == "missing" {
+		return "", pikpak.ErrFileNotFound
+	}
+	return u.link(fileID), nil
+}
+
+func (u *upstream) GetShareFileLink(ctx context.Context, shareID string, fileID string, sharePassword string, opts ...pikpak.LinkOption) (string, error) {
+	if sharePassword != "secret" {
+		return "", pikpak.ErrSharePasswordWrong
+	}
+	return u.link(shareID + "-" + fileID), nil
+}
+
+func get(t *testing.T, url string, header http.Header) (*http.Response, string) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp, string(body)
+}
+
+func TestRangePassthrough(t *testing.T) {
+	up := newUpstream(t)
+	server := httptest.NewServer(Handler(up))
+	defer server.Close()
+
+	resp, body := get(t, server.URL+"/file/f1", http.Header{"Range": {"bytes=10-15"}})
+	if resp.StatusCode != http.StatusPartialContent || body != "abcdef" {
+		t.Fatalf("Expected a partial response, got %d %q", resp.StatusCode, body)
+	}
+	if got := resp.Header.Get("Content-Range"); got != "bytes 10-15/36" {
+		t.Errorf("Expected the upstream Content-Range, got %q", got)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "video/mp4" {
+		t.Errorf("Expected the upstream Content-Type, got %q", got)
+	}
+
+	resp, body = get(t, server.URL+"/file/f1", nil)
+	if resp.StatusCode != http.StatusOK || body != content {
+		t.Errorf("Expected the whole file, got %d %q", resp.StatusCode, body)
+	}
+	if n := up.resolves("f1"); n != 1 {
+		t.Errorf("Expected the link to be cached, resolved %d times", n)
+	}
+
+	resp, body = get(t, server.URL+"/share/s1/f2?password=secret", http.Header{"Range": {"bytes=-3"}})
+	if resp.StatusCode != http.StatusPartialContent || body != "xyz" {
+		t.Errorf("Expected the share file tail, got %d %q", resp.StatusCode, body)
+	}
+}
+
+func TestExpiredLinkResolvedAgain(t *testing.T) {
+	up := newUpstream(t)
+	server := httptest.NewServer(Handler(up))
+	defer server.Close()
+
+	if resp, _ := get(t, server.URL+"/file/f1", http.Header{"Range": {"bytes=0-3"}}); resp.StatusCode != http.StatusPartialContent {
+		t.Fatalf("Expected the first range, got %d", resp.StatusCode)
+	}
+
+	// The cached link expires while the player is still seeking.
+	up.expire()
+	resp, body := get(t, server.URL+"/file/f1", http.Header{"Range": {"bytes=4-7"}})
+	if resp.StatusCode != http.StatusPartialContent || body != "4567" {
+		t.Fatalf("Expected the range through a fresh link, got %d %q", resp.StatusCode, body)
+	}
+	if n := up.resolves("f1"); n != 2 {
+		t.Errorf("Expected one re-resolve, resolved %d times", n)
+	}
+}
+
+func TestLinkCacheSweep(t *testing.T) {
+	up := newUpstream(t)
+	h := Handler(up).(*handler)
+	now := time.Now()
+	h.now = func() time.Time { return now }
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	get(t, server.URL+"/file/f1", nil)
+	get(t, server.URL+"/share/s1/f2?password=secret", nil)
+	now = now.Add(DefaultLinkTTL)
+	get(t, server.URL+"/file/f3", nil)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.links) != 1 {
+		t.Errorf("Expected the expired links to be dropped, got %v", h.links)
+	}
+	for key := range h.links {
+		if key != "file/f3" {
+			t.Errorf("Unexpected cached link %q", key)
+		}
+	}
+}
+
+func TestLinkCacheKeepsNoPassword(t *testing.T) {
+	up := newUpstream(t)
+	h := Handler(up).(*handler)
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	get(t, server.URL+"/share/s1/f2?password=secret", nil)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for key := range h.links {
+		if strings.Contains(key, "secret") {
+			t.Errorf("Expected the share password not to be cached, got %q", key)
+		}
+	}
+	if len(h.links) != 1 {
+		t.Errorf("Expected the share link to be cached, got %v", h.links)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	up := newUpstream(t)
+	server := httptest.NewServer(Handler(up))
+	defer server.Close()
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/file/missing", http.StatusNotFound},
+		{"/share/s1/f2?password=wrong", http.StatusForbidden},
+		{"/file/", http.StatusNotFound},
+		{"/other/f1", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if resp, _ := get(t, server.URL+tt.path, nil); resp.StatusCode != tt.status {
+			t.Errorf("%s: expected %d, got %d", tt.path, tt.status, resp.StatusCode)
+		}
+	}
+
+	resp, err := http.Post(server.URL+"/file/f1", "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected POST to be rejected, got %d", resp.StatusCode)
+	}
+}
+
+// recordingLogger keeps the Error and Debug messages it is given.
+type recordingLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) record(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+func (l *recordingLogger) Debugf(format string, args ...interface{}) { l.record(format, args...) }
+func (l *recordingLogger) Infof(format string, args ...interface{})  {}
+func (l *recordingLogger) Warnf(format string, args ...interface{})  {}
+func (l *recordingLogger) Errorf(format string, args ...interface{}) { l.record(format, args...) }
+
+func TestErrorsStayServerSide(t *testing.T) {
+	up := newUpstream(t)
+	logger := &recordingLogger{}
+	server := httptest.NewServer(Handler(up, WithLogger(logger)))
+	defer server.Close()
+
+	resp, body := get(t, server.URL+"/share/s1/f2?password=wrong", nil)
+	if resp.StatusCode != http.StatusForbidden || strings.TrimSpace(body) != http.StatusText(http.StatusForbidden) {
+		t.Errorf("Expected only the status text, got %d %q", resp.StatusCode, body)
+	}
+	up.Close()
+	resp, body = get(t, server.URL+"/file/f1", nil)
+	if resp.StatusCode != http.StatusBadGateway || strings.Contains(body, up.URL) {
+		t.Errorf("Expected the upstream error to stay server side, got %d %q", resp.StatusCode, body)
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.messages) != 2 || !strings.Contains(logger.messages[0], "GET /share/s1/f2") || !strings.Contains(logger.messages[1], up.URL) {
+		t.Errorf("Expected both errors to be logged, got %q", logger.messages)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	up := newUpstream(t)
+	server := httptest.NewServer(Handler(up, WithToken("tok"), WithBasicAuth("me", "pw")))
+	defer server.Close()
+
+	if resp, _ := get(t, server.URL+"/file/f1", nil); resp.StatusCode != http.StatusUnauthorized || resp.Header.Get("WWW-Authenticate") == "" {
+		t.Errorf("Expected an authentication challenge, got %d", resp.StatusCode)
+	}
+	if resp, _ := get(t, server.URL+"/file/f1?token=bad", nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a wrong token to be rejected, got %d", resp.StatusCode)
+	}
+	if resp, body := get(t, server.URL+"/file/f1?token=tok", nil); resp.StatusCode != http.StatusOK || body != content {
+		t.Errorf("Expected the token to be accepted, got %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/file/f1", nil)
+	req.SetBasicAuth("me", "pw")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected basic auth to be accepted, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,371 @@
+// Package testsupport provides a fake PikPak client for tests that should not
+// reach the network.
+package testsupport
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/zhz8888/pikpakapi-go/internal/client"
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
+)
+
+// Call is one recorded call to a FakeClient. Args holds the arguments after
+// the context, in order.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+type response struct {
+	result interface{}
+	err    error
+}
+
+// FakeClient implements client.PikPakAPI in memory. Every call is recorded
+// and answered with the response programmed through On, or with zero values
+// and a nil error.
+type FakeClient struct {
+	mu        sync.Mutex
+	calls     []Call
+	responses map[string]response
+}
+
+var _ client.PikPakAPI = (*FakeClient)(nil)
+
+func NewFakeClient() *FakeClient {
+	return &FakeClient{responses: make(map[string]response)}
+}
+
+// On makes method return result and err from now on. result must have the
+// method's result type, e.g. map[string]interface{} for FileList or
+// client.StorageInfo for GetStorageInfo; nil means the zero value.
+func (f *FakeClient) On(method string, result interface{}, err error) *FakeClient {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[method] = response{result: result, err: err}
+	return f
+}
+
+// Calls returns the calls made so far, oldest first.
+func (f *FakeClient) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// CallsTo returns the calls made so far to method.
+func (f *FakeClient) CallsTo(method string) []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var calls []Call
+	for _, c := range f.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Reset forgets the recorded calls and programmed responses.
+func (f *FakeClient) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = nil
+	f.responses = make(map[string]response)
+}
+
+func (f *FakeClient) call(method string, args ...interface{}) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, Call{Method: method, Args: args})
+	r := f.responses[method]
+	return r.result, r.err
+}
+
+func result[T any](v interface{}, err error) (T, error) {
+	t, _ := v.(T)
+	return t, err
+}
+
+func (f *FakeClient) Login(ctx context.Context) error {
+	_, err := f.call("Login")
+	return err
+}
+
+func (f *FakeClient) RefreshAccessToken(ctx context.Context) error {
+	_, err := f.call("RefreshAccessToken")
+	return err
+}
+
+func (f *FakeClient) DecodeToken() error {
+	_, err := f.call("DecodeToken")
+	return err
+}
+
+func (f *FakeClient) EncodeToken() error {
+	_, err := f.call("EncodeToken")
+	return err
+}
+
+func (f *FakeClient) GetUserInfo() map[string]string {
+	v, _ := result[map[string]string](f.call("GetUserInfo"))
+	return v
+}
+
+func (f *FakeClient) GetMe(ctx context.Context) (*client.UserProfile, error) {
+	return result[*client.UserProfile](f.call("GetMe"))
+}
+
+func (f *FakeClient) Ping(ctx context.Context) (*client.PingResult, error) {
+	return result[*client.PingResult](f.call("Ping"))
+}
+
+func (f *FakeClient) FileList(ctx context.Context, size int, parentID string, nextPageToken string, query string, opts ...client.ListOption) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("FileList", size, parentID, nextPageToken, query, opts))
+}
+
+func (f *FakeClient) CreateFolder(ctx context.Context, name string, parentID string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("CreateFolder", name, parentID))
+}
+
+func (f *FakeClient) GetFileLink(ctx context.Context, fileID string, opts ...client.LinkOption) (string, error) {
+	return result[string](f.call("GetFileLink", fileID, opts))
+}
+
+func (f *FakeClient) GetFileDetails(ctx context.Context, fileID string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("GetFileDetails", fileID))
+}
+
+func (f *FakeClient) GetFileByPath(ctx context.Context, p string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("GetFileByPath", p))
+}
+
+func (f *FakeClient) OpenFile(ctx context.Context, fileID string, offset int64, opts ...client.LinkOption) (io.ReadCloser, error) {
+	return result[io.ReadCloser](f.call("OpenFile", fileID, offset, opts))
+}
+
+func (f *FakeClient) Move(ctx context.Context, fileID string, parentID string) error {
+	_, err := f.call("Move", fileID, parentID)
+	return err
+}
+
+func (f *FakeClient) Copy(ctx context.Context, fileID string, parentID string) error {
+	_, err := f.call("Copy", fileID, parentID)
+	return err
+}
+
+func (f *FakeClient) Rename(ctx context.Context, fileID string, newName string) error {
+	_, err := f.call("Rename", fileID, newName)
+	return err
+}
+
+func (f *FakeClient) FileRename(ctx context.Context, fileID string, newName string) error {
+	_, err := f.call("FileRename", fileID, newName)
+	return err
+}
+
+func (f *FakeClient) DeleteToTrash(ctx context.Context, ids []string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("DeleteToTrash", ids))
+}
+
+func (f *FakeClient) Untrash(ctx context.Context, ids []string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("Untrash", ids))
+}
+
+func (f *FakeClient) DeleteForever(ctx context.Context, ids []string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("DeleteForever", ids))
+}
+
+func (f *FakeClient) EmptyTrash(ctx context.Context) error {
+	_, err := f.call("EmptyTrash")
+	return err
+}
+
+func (f *FakeClient) FileBatchStar(ctx context.Context, ids []string, star bool) error {
+	_, err := f.call("FileBatchStar", ids, star)
+	return err
+}
+
+func (f *FakeClient) FileBatchUnstar(ctx context.Context, ids []string) error {
+	_, err := f.call("FileBatchUnstar", ids)
+	return err
+}
+
+func (f *FakeClient) FileStarList(ctx context.Context, size int, nextPageToken string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("FileStarList", size, nextPageToken))
+}
+
+func (f *FakeClient) Favorite(ctx context.Context, fileID string, category string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("Favorite", fileID, category))
+}
+
+func (f *FakeClient) Events(ctx context.Context, size int, nextPageToken string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("Events", size, nextPageToken))
+}
+
+func (f *FakeClient) GetAbout(ctx context.Context) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("GetAbout"))
+}
+
+func (f *FakeClient) GetQuotaInfo(ctx context.Context) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("GetQuotaInfo"))
+}
+
+func (f *FakeClient) GetStorageInfo(ctx context.Context) (client.StorageInfo, error) {
+	return result[client.StorageInfo](f.call("GetStorageInfo"))
+}
+
+func (f *FakeClient) Upload(ctx context.Context, filePath string, parentID string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("Upload", filePath, parentID))
+}
+
+func (f *FakeClient) UploadReader(ctx context.Context, reader io.Reader, fileName string, fileSize int64, parentID string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("UploadReader", reader, fileName, fileSize, parentID))
+}
+
+func (f *FakeClient) UploadFile(ctx context.Context, filePath string, parentID string, chunkSize int) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("UploadFile", filePath, parentID, chunkSize))
+}
+
+func (f *FakeClient) GetUploadURL(ctx context.Context, fileName string, fileSize int64, parentID string) (string, error) {
+	return result[string](f.call("GetUploadURL", fileName, fileSize, parentID))
+}
+
+func (f *FakeClient) DownloadToFile(ctx context.Context, fileID string, filePath string, opts ...client.LinkOption) error {
+	_, err := f.call("DownloadToFile", fileID, filePath, opts)
+	return err
+}
+
+func (f *FakeClient) OfflineDownload(ctx context.Context, fileURL string, parentID string, name string, opts ...client.DownloadOption) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("OfflineDownload", fileURL, parentID, name, opts))
+}
+
+func (f *FakeClient) OfflineDownloadBatch(ctx context.Context, urls []string, parentID string, opts ...client.DownloadOption) ([]client.OfflineBatchResult, error) {
+	return result[[]client.OfflineBatchResult](f.call("OfflineDownloadBatch", urls, parentID, opts))
+}
+
+func (f *FakeClient) RemoteDownload(ctx context.Context, fileURL string, opts ...client.DownloadOption) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("RemoteDownload", fileURL, opts))
+}
+
+func (f *FakeClient) OfflineList(ctx context.Context, size int, nextPageToken string, phases []string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("OfflineList", size, nextPageToken, phases))
+}
+
+func (f *FakeClient) OfflineListPhases(ctx context.Context, size int, nextPageToken string, phases []enums.PhaseType) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("OfflineListPhases", size, nextPageToken, phases))
+}
+
+func (f *FakeClient) OfflineFileInfo(ctx context.Context, fileID string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("OfflineFileInfo", fileID))
+}
+
+func (f *FakeClient) OfflineTaskRetry(ctx context.Context, taskID string) error {
+	_, err := f.call("OfflineTaskRetry", taskID)
+	return err
+}
+
+func (f *FakeClient) DeleteOfflineTasks(ctx context.Context, taskIDs []string, deleteFiles bool) error {
+	_, err := f.call("DeleteOfflineTasks", taskIDs, deleteFiles)
+	return err
+}
+
+func (f *FakeClient) DeleteTasks(ctx context.Context, taskIDs []string, deleteFiles bool) error {
+	_, err := f.call("DeleteTasks", taskIDs, deleteFiles)
+	return err
+}
+
+func (f *FakeClient) GetTaskStatus(ctx context.Context, taskID string, fileID string) (enums.DownloadStatus, error) {
+	return result[enums.DownloadStatus](f.call("GetTaskStatus", taskID, fileID))
+}
+
+// WatchTasks calls fn once with the configured tasks and returns the
+// configured error.
+func (f *FakeClient) WatchTasks(ctx context.Context, taskIDs []string, interval time.Duration, fn func(tasks []map[string]interface{})) error {
+	tasks, err := result[[]map[string]interface{}](f.call("WatchTasks", taskIDs, interval))
+	fn(tasks)
+	return err
+}
+
+func (f *FakeClient) WaitForTask(ctx context.Context, taskID string, interval time.Duration) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("WaitForTask", taskID, interval))
+}
+
+func (f *FakeClient) CaptureScreenshot(ctx context.Context, fileID string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("CaptureScreenshot", fileID))
+}
+
+func (f *FakeClient) FileBatchShare(ctx context.Context, ids []string, needPassword bool) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("FileBatchShare", ids, needPassword))
+}
+
+func (f *FakeClient) CreateShareLink(ctx context.Context, fileID string, expireSec int, passCode string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("CreateShareLink", fileID, expireSec, passCode))
+}
+
+func (f *FakeClient) Share(ctx context.Context, fileID string, shareType int, expireSec int, passCode string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("Share", fileID, shareType, expireSec, passCode))
+}
+
+func (f *FakeClient) SetSharePolicy(ctx context.Context, shareID string, policy string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("SetSharePolicy", shareID, policy))
+}
+
+func (f *FakeClient) GetShareList(ctx context.Context, size int, nextPageToken string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("GetShareList", size, nextPageToken))
+}
+
+func (f *FakeClient) GetSharePasscode(ctx context.Context, shareID string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("GetSharePasscode", shareID))
+}
+
+func (f *FakeClient) CancelShare(ctx context.Context, shareID string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("CancelShare", shareID))
+}
+
+func (f *FakeClient) InviteNewShare(ctx context.Context, shareID string, fileIDs []string, inviteMsg string, isNewInvite bool) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("InviteNewShare", shareID, fileIDs, inviteMsg, isNewInvite))
+}
+
+func (f *FakeClient) InviteList(ctx context.Context, shareID string, size int, nextPageToken string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("InviteList", shareID, size, nextPageToken))
+}
+
+func (f *FakeClient) InviteCancel(ctx context.Context, inviteID string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("InviteCancel", inviteID))
+}
+
+func (f *FakeClient) GetShareInfo(ctx context.Context, shareURL string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("GetShareInfo", shareURL))
+}
+
+func (f *FakeClient) GetShareFileInfo(ctx context.Context, shareURL string, sharePassword string) (*client.ShareFileInfo, error) {
+	return result[*client.ShareFileInfo](f.call("GetShareFileInfo", shareURL, sharePassword))
+}
+
+func (f *FakeClient) GetShareFiles(ctx context.Context, shareURL string, sharePassword string) ([]*client.ShareFileInfo, error) {
+	return result[[]*client.ShareFileInfo](f.call("GetShareFiles", shareURL, sharePassword))
+}
+
+func (f *FakeClient) GetShareDownloadURL(ctx context.Context, shareURL string, sharePassword string) (string, error) {
+	return result[string](f.call("GetShareDownloadURL", shareURL, sharePassword))
+}
+
+func (f *FakeClient) GetShareFileDownloadURL(ctx context.Context, shareURL string, sharePassword string, useTranscoding bool, opts ...client.LinkOption) (string, error) {
+	return result[string](f.call("GetShareFileDownloadURL", shareURL, sharePassword, useTranscoding, opts))
+}
+
+func (f *FakeClient) GetShareFileLink(ctx context.Context, shareID string, fileID string, sharePassword string, opts ...client.LinkOption) (string, error) {
+	return result[string](f.call("GetShareFileLink", shareID, fileID, sharePassword, opts))
+}
+
+func (f *FakeClient) Restore(ctx context.Context, shareID string, passCodeToken string, fileIDs []string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("Restore", shareID, passCodeToken, fileIDs))
+}
+
+func (f *FakeClient) RestoreShareURL(ctx context.Context, shareURL string, sharePassword string, fileIDs []string) (map[string]interface{}, error) {
+	return result[map[string]interface{}](f.call("RestoreShareURL", shareURL, sharePassword, fileIDs))
+}
@@ -0,0 +1,92 @@
+package testsupport_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpak"
+	"github.com/zhz8888/pikpakapi-go/pkg/testsupport"
+)
+
+// retryFailed is the kind of consumer code the fake is for: it only needs a
+// TaskService.
+func retryFailed(ctx context.Context, tasks pikpak.TaskService) (int, error) {
+	result, err := tasks.OfflineList(ctx, 100, "", []string{"PHASE_TYPE_ERROR"})
+	if err != nil {
+		return 0, err
+	}
+	list, _ := result["tasks"].([]interface{})
+	for _, item := range list {
+		task, _ := item.(map[string]interface{})
+		id, _ := task["id"].(string)
+		if err := tasks.OfflineTaskRetry(ctx, id); err != nil {
+			return 0, err
+		}
+	}
+	return len(list), nil
+}
+
+func TestFakeClient(t *testing.T) {
+	fake := testsupport.NewFakeClient().
+		On("OfflineList", map[string]interface{}{
+			"tasks": []interface{}{
+				map[string]interface{}{"id": "t1"},
+				map[string]interface{}{"id": "t2"},
+			},
+		}, nil)
+
+	n, err := retryFailed(context.Background(), fake)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Expected 2 retries, got %d", n)
+	}
+
+	list := fake.CallsTo("OfflineList")
+	if len(list) != 1 || !reflect.DeepEqual(list[0].Args[2], []string{"PHASE_TYPE_ERROR"}) {
+		t.Errorf("Unexpected OfflineList calls %v", list)
+	}
+	var retried []interface{}
+	for _, c := range fake.CallsTo("OfflineTaskRetry") {
+		retried = append(retried, c.Args[0])
+	}
+	if !reflect.DeepEqual(retried, []interface{}{"t1", "t2"}) {
+		t.Errorf("Expected retries of t1 and t2, got %v", retried)
+	}
+	if got := len(fake.Calls()); got != 3 {
+		t.Errorf("Expected 3 calls, got %d", got)
+	}
+}
+
+func TestFakeClient_Errors(t *testing.T) {
+	errBoom := errors.New("boom")
+	fake := testsupport.NewFakeClient().On("OfflineTaskRetry", nil, errBoom)
+	fake.On("OfflineList", map[string]interface{}{"tasks": []interface{}{map[string]interface{}{"id": "t1"}}}, nil)
+
+	if _, err := retryFailed(context.Background(), fake); !errors.Is(err, errBoom) {
+		t.Errorf("Expected the programmed error, got %v", err)
+	}
+}
+
+func TestFakeClient_Defaults(t *testing.T) {
+	fake := testsupport.NewFakeClient()
+	ctx := context.Background()
+
+	info, err := fake.GetStorageInfo(ctx)
+	if err != nil || info != (pikpak.StorageInfo{}) {
+		t.Errorf("Expected a zero StorageInfo, got %+v, %v", info, err)
+	}
+
+	fake.On("GetStorageInfo", pikpak.StorageInfo{TotalBytes: 10}, nil)
+	if info, _ := fake.GetStorageInfo(ctx); info.TotalBytes != 10 {
+		t.Errorf("Expected the programmed StorageInfo, got %+v", info)
+	}
+
+	fake.Reset()
+	if info, _ := fake.GetStorageInfo(ctx); info.TotalBytes != 0 || len(fake.Calls()) != 1 {
+		t.Errorf("Expected Reset to clear responses and calls, got %+v, %v", info, fake.Calls())
+	}
+}
@@ -0,0 +1,313 @@
+package webdav
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpakfs"
+	xwebdav "golang.org/x/net/webdav"
+)
+
+// fileSystem is the drive as an x/net/webdav file system. Reads go through a
+// pikpakfs view per call, so that listings are shared by the lookups of one
+// call but changes show up in the next; writes call the client.
+type fileSystem struct {
+	client Client
+}
+
+var _ xwebdav.FileSystem = (*fileSystem)(nil)
+
+// drive returns a new pikpakfs view of the drive bound to ctx.
+func (s *fileSystem) drive(ctx context.Context) *pikpakfs.FS {
+	return pikpakfs.New(s.client, pikpakfs.WithContext(ctx))
+}
+
+// fsPath turns a WebDAV name, such as "/docs/a.txt", into a pikpakfs path.
+func fsPath(name string) string {
+	p := strings.Trim(path.Clean("/"+name), "/")
+	if p == "" {
+		return "."
+	}
+	return p
+}
+
+// parent returns the ID of the folder that would contain p. A missing
+// parent is reported as fs.ErrNotExist, which the handler answers with 409.
+func (s *fileSystem) parent(fsys *pikpakfs.FS, p string) (string, error) {
+	dir := path.Dir(p)
+	info, err := fsys.Stat(dir)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", &fs.PathError{Op: "stat", Path: dir, Err: fs.ErrNotExist}
+	}
+	return fileID(info), nil
+}
+
+func (s *fileSystem) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	info, err := s.drive(ctx).Stat(fsPath(name))
+	if err != nil {
+		return nil, err
+	}
+	return fileInfo{info}, nil
+}
+
+func (s *fileSystem) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	p := fsPath(name)
+	fsys := s.drive(ctx)
+	if _, err := fsys.Stat(p); err == nil {
+		return &fs.PathError{Op: "mkdir", Path: p, Err: fs.ErrExist}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	parentID, err := s.parent(fsys, p)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.CreateFolder(ctx, path.Base(p), parentID)
+	return err
+}
+
+// OpenFile opens name for reading, or for replacing its content when flag
+// asks for writing. Written content is uploaded on Close.
+func (s *fileSystem) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (xwebdav.File, error) {
+	p := fsPath(name)
+	fsys := s.drive(ctx)
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) == 0 {
+		f, err := fsys.Open(p)
+		if err != nil {
+			return nil, err
+		}
+		return &readFile{File: f, name: p}, nil
+	}
+
+	if p == "." {
+		return nil, &fs.PathError{Op: "open", Path: p, Err: fs.ErrInvalid}
+	}
+	existing, err := fsys.Stat(p)
+	switch {
+	case err == nil:
+		if flag&os.O_EXCL != 0 {
+			return nil, &fs.PathError{Op: "open", Path: p, Err: fs.ErrExist}
+		}
+		if existing.IsDir() {
+			return nil, &fs.PathError{Op: "open", Path: p, Err: errors.New("is a directory")}
+		}
+		if flag&os.O_TRUNC == 0 {
+			return nil, &fs.PathError{Op: "open", Path: p, Err: errors.New("drive files can only be replaced")}
+		}
+	case !errors.Is(err, fs.ErrNotExist) || flag&os.O_CREATE == 0:
+		return nil, err
+	}
+	parentID, err := s.parent(fsys, p)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp("", "pikpak-webdav-*")
+	if err != nil {
+		return nil, err
+	}
+	f := &writeFile{ctx: ctx, client: s.client, name: p, parentID: parentID, tmp: tmp}
+	if existing != nil {
+		f.replaces = fileID(existing)
+	}
+	return f, nil
+}
+
+// RemoveAll moves name to the trash.
+func (s *fileSystem) RemoveAll(ctx context.Context, name string) error {
+	p := fsPath(name)
+	if p == "." {
+		return &fs.PathError{Op: "remove", Path: p, Err: fs.ErrPermission}
+	}
+	info, err := s.drive(ctx).Stat(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = s.client.DeleteToTrash(ctx, []string{fileID(info)})
+	return err
+}
+
+// Rename moves oldName to another folder and renames it as needed. The
+// handler has already trashed an existing newName when Overwrite allows it.
+func (s *fileSystem) Rename(ctx context.Context, oldName, newName string) error {
+	from, to := fsPath(oldName), fsPath(newName)
+	if from == "." || to == "." || strings.HasPrefix(to, from+"/") {
+		return &fs.PathError{Op: "rename", Path: from, Err: fs.ErrPermission}
+	}
+	fsys := s.drive(ctx)
+	info, err := fsys.Stat(from)
+	if err != nil {
+		return err
+	}
+	parentID, err := s.parent(fsys, to)
+	if err != nil {
+		return err
+	}
+
+	id := fileID(info)
+	if path.Dir(to) != path.Dir(from) {
+		if err := s.client.Move(ctx, id, parentID); err != nil {
+			return err
+		}
+	}
+	if path.Base(to) != path.Base(from) {
+		if err := s.client.Rename(ctx, id, path.Base(to)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileInfo adds the content type and ETag to a pikpakfs file info, so that
+// PROPFIND need not open files to find them.
+type fileInfo struct {
+	fs.FileInfo
+}
+
+func (i fileInfo) ContentType(ctx context.Context) (string, error) {
+	if ctype := mime.TypeByExtension(path.Ext(i.Name())); ctype != "" {
+		return ctype, nil
+	}
+	return "application/octet-stream", nil
+}
+
+// ETag identifies a version of a file by its id, size and modification time.
+func (i fileInfo) ETag(ctx context.Context) (string, error) {
+	return fmt.Sprintf(`"%s-%x-%x"`, fileID(i.FileInfo), i.Size(), i.ModTime().UnixNano()), nil
+}
+
+func fileID(info fs.FileInfo) string {
+	if fi, ok := info.(fileInfo); ok {
+		info = fi.FileInfo
+	}
+	m, _ := info.Sys().(map[string]interface{})
+	id, _ := m["id"].(string)
+	return id
+}
+
+// readFile is a pikpakfs file or directory opened for reading.
+type readFile struct {
+	fs.File
+	name string
+}
+
+func (f *readFile) Stat() (os.FileInfo, error) {
+	info, err := f.File.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return fileInfo{info}, nil
+}
+
+func (f *readFile) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := f.File.(io.Seeker)
+	if !ok {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: errors.New("is a directory")}
+	}
+	return seeker.Seek(offset, whence)
+}
+
+func (f *readFile) Readdir(count int) ([]os.FileInfo, error) {
+	dir, ok := f.File.(fs.ReadDirFile)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: f.name, Err: errors.New("not a directory")}
+	}
+	entries, err := dir.ReadDir(count)
+	infos := make([]os.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return infos, err
+		}
+		infos = append(infos, fileInfo{info})
+	}
+	return infos, err
+}
+
+func (f *readFile) Write([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "write", Path: f.name, Err: fs.ErrPermission}
+}
+
+// writeFile collects the new content of a file in a temporary file, since
+// an upload needs the size up front, and uploads it on Close. The file it
+// replaces is moved to the trash once the upload has succeeded.
+type writeFile struct {
+	ctx      context.Context
+	client   Client
+	name     string
+	parentID string
+	replaces string
+	tmp      *os.File
+	closed   bool
+}
+
+func (f *writeFile) Write(p []byte) (int, error) {
+	return f.tmp.Write(p)
+}
+
+func (f *writeFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrPermission}
+}
+
+func (f *writeFile) Seek(offset int64, whence int) (int64, error) {
+	return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrPermission}
+}
+
+func (f *writeFile) Readdir(int) ([]os.FileInfo, error) {
+	return nil, &fs.PathError{Op: "readdir", Path: f.name, Err: errors.New("not a directory")}
+}
+
+func (f *writeFile) Stat() (os.FileInfo, error) {
+	info, err := f.tmp.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return pendingInfo{FileInfo: info, name: path.Base(f.name)}, nil
+}
+
+func (f *writeFile) Close() error {
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.name, Err: fs.ErrClosed}
+	}
+	f.closed = true
+	defer os.Remove(f.tmp.Name())
+	defer f.tmp.Close()
+
+	size, err := f.tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := f.tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := f.client.UploadReader(f.ctx, f.tmp, path.Base(f.name), size, f.parentID); err != nil {
+		return err
+	}
+	if f.replaces != "" {
+		if _, err := f.client.DeleteToTrash(f.ctx, []string{f.replaces}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pendingInfo describes a file being written under its drive name.
+type pendingInfo struct {
+	os.FileInfo
+	name string
+}
+
+func (i pendingInfo) Name() string { return i.name }
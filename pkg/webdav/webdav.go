@@ -0,0 +1,141 @@
+// Package webdav serves a PikPak drive over WebDAV, so that file managers,
+// media players and rclone can mount it.
+//
+// The protocol is handled by golang.org/x/net/webdav with an in-memory lock
+// system, so clients that lock before writing, such as the macOS Finder and
+// Windows Explorer, can mount the drive read-write. The file system reads
+// through pikpakfs: listings come from FileList and files are streamed with
+// Range support. Unless the handler is read-only, MKCOL creates folders,
+// DELETE moves to the trash, MOVE renames or moves and PUT uploads.
+package webdav
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpak"
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpakfs"
+	xwebdav "golang.org/x/net/webdav"
+)
+
+// Client is the part of pikpak.PikPakAPI the handler uses.
+type Client interface {
+	pikpakfs.Client
+	CreateFolder(ctx context.Context, name string, parentID string) (map[string]interface{}, error)
+	DeleteToTrash(ctx context.Context, ids []string) (map[string]interface{}, error)
+	Rename(ctx context.Context, fileID string, newName string) error
+	Move(ctx context.Context, fileID string, parentID string) error
+	UploadReader(ctx context.Context, reader io.Reader, fileName string, fileSize int64, parentID string) (map[string]interface{}, error)
+}
+
+type handler struct {
+	dav      *xwebdav.Handler
+	prefix   string
+	readOnly bool
+	user     string
+	pass     string
+	logger   pikpak.Logger
+}
+
+type Option func(*handler)
+
+// WithPrefix serves the drive below prefix, such as "/dav", instead of at
+// the root of the URL space.
+func WithPrefix(prefix string) Option {
+	return func(h *handler) {
+		h.prefix = strings.TrimSuffix(prefix, "/")
+	}
+}
+
+// WithReadOnly rejects every method that would change the drive or take a
+// lock with 403.
+func WithReadOnly(readOnly bool) Option {
+	return func(h *handler) {
+		h.readOnly = readOnly
+	}
+}
+
+// WithBasicAuth requires HTTP basic authentication with user and pass.
+func WithBasicAuth(user, pass string) Option {
+	return func(h *handler) {
+		h.user, h.pass = user, pass
+	}
+}
+
+// WithLogger reports the errors behind failed requests to logger: missing
+// and existing files at Debug level, everything else at Error level. Clients
+// only get the status text, since the errors can carry file names and API
+// details.
+func WithLogger(logger pikpak.Logger) Option {
+	return func(h *handler) {
+		h.logger = logger
+	}
+}
+
+// Handler returns a WebDAV handler for the drive of client.
+func Handler(client Client, opts ...Option) http.Handler {
+	h := &handler{}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.dav = &xwebdav.Handler{
+		Prefix:     h.prefix,
+		FileSystem: &fileSystem{client: client},
+		LockSystem: xwebdav.NewMemLS(),
+		Logger:     h.logError,
+	}
+	return h
+}
+
+// writeMethods change the drive or its locks.
+var writeMethods = map[string]bool{
+	"PUT":       true,
+	"DELETE":    true,
+	"MKCOL":     true,
+	"COPY":      true,
+	"MOVE":      true,
+	"PROPPATCH": true,
+	"LOCK":      true,
+	"UNLOCK":    true,
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.user != "" || h.pass != "" {
+		user, pass, ok := r.BasicAuth()
+		if !ok || subtle.ConstantTimeCompare([]byte(user), []byte(h.user)) != 1 || subtle.ConstantTimeCompare([]byte(pass), []byte(h.pass)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="PikPak"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	if h.readOnly && writeMethods[r.Method] {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	// A listing of the whole drive would take a FileList call per folder,
+	// so Depth infinity is refused, as RFC 4918 allows.
+	if r.Method == "PROPFIND" {
+		if depth := r.Header.Get("Depth"); depth == "" || strings.EqualFold(depth, "infinity") {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+	}
+	h.dav.ServeHTTP(w, r)
+}
+
+func (h *handler) logError(r *http.Request, err error) {
+	if err == nil || h.logger == nil {
+		return
+	}
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrExist) {
+		h.logger.Debugf("webdav: %s %s: %v", r.Method, r.URL.Path, err)
+	} else {
+		h.logger.Errorf("webdav: %s %s: %v", r.Method, r.URL.Path, err)
+	}
+}
@@ -0,0 +1,388 @@
+package webdav
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/studio-b12/gowebdav"
+	"github.com/zhz8888/pikpakapi-go/pkg/enums"
+	"github.com/zhz8888/pikpakapi-go/pkg/pikpak"
+)
+
+// memDrive is an in-memory drive implementing Client.
+type memDrive struct {
+	mu        sync.Mutex
+	nextID    int
+	files     map[string]*memFile
+	uploadErr error
+}
+
+type memFile struct {
+	id, parent, name string
+	folder           bool
+	content          string
+	trashed          bool
+}
+
+var memModified = time.Date(2024, 4, 25, 16, 40, 0, 0, time.UTC)
+
+func newMemDrive() *memDrive {
+	d := &memDrive{files: map[string]*memFile{}}
+	docs := d.add("", "docs", true, "")
+	d.add("", "hello.txt", false, "hello, world\n")
+	d.add(docs, "a.txt", false, "alpha")
+	return d
+}
+
+func (d *memDrive) add(parent, name string, folder bool, content string) string {
+	d.nextID++
+	id := "id" + strconv.Itoa(d.nextID)
+	d.files[id] = &memFile{id: id, parent: parent, name: name, folder: folder, content: content}
+	return id
+}
+
+func (d *memDrive) metadata(f *memFile) map[string]interface{} {
+	kind := enums.FileKindFile
+	if f.folder {
+		kind = enums.FileKindFolder
+	}
+	return map[string]interface{}{
+		"id":            f.id,
+		"name":          f.name,
+		"kind":          kind.String(),
+		"size":          strconv.Itoa(len(f.content)),
+		"modified_time": memModified.Format(time.RFC3339),
+	}
+}
+
+func (d *memDrive) children(parent string) []*memFile {
+	var result []*memFile
+	for _, f := range d.files {
+		if f.parent == parent && !f.trashed {
+			result = append(result, f)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].id < result[j].id })
+	return result
+}
+
+func (d *memDrive) FileList(ctx context.Context, size int, parentID string, nextPageToken string, query string, opts ...pikpak.ListOption) (map[string]interface{}, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	files := []interface{}{}
+	for _, f := range d.children(parentID) {
+		files = append(files, d.metadata(f))
+	}
+	return map[string]interface{}{"files": files}, nil
+}
+
+func (d *memDrive) OpenFile(ctx context.Context, fileID string, offset int64, opts ...pikpak.LinkOption) (io.ReadCloser, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return io.NopCloser(strings.NewReader(d.files[fileID].content[offset:])), nil
+}
+
+func (d *memDrive) CreateFolder(ctx context.Context, name string, parentID string) (map[string]interface{}, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	id := d.add(parentID, name, true, "")
+	return map[string]interface{}{"file": d.metadata(d.files[id])}, nil
+}
+
+func (d *memDrive) DeleteToTrash(ctx context.Context, ids []string) (map[string]interface{}, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, id := range ids {
+		d.files[id].trashed = true
+	}
+	return map[string]interface{}{}, nil
+}
+
+func (d *memDrive) Rename(ctx context.Context, fileID string, newName string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.files[fileID].name = newName
+	return nil
+}
+
+func (d *memDrive) Move(ctx context.Context, fileID string, parentID string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.files[fileID].parent = parentID
+	return nil
+}
+
+func (d *memDrive) UploadReader(ctx context.Context, reader io.Reader, fileName string, fileSize int64, parentID string) (map[string]interface{}, error) {
+	if d.uploadErr != nil {
+		return nil, d.uploadErr
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) != fileSize {
+		return nil, fmt.Errorf("expected %d bytes, got %d", fileSize, len(content))
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	id := d.add(parentID, fileName, false, string(content))
+	return map[string]interface{}{"file": d.metadata(d.files[id])}, nil
+}
+
+func newTestServer(t *testing.T, opts ...Option) (*httptest.Server, *memDrive) {
+	drive := newMemDrive()
+	server := httptest.NewServer(Handler(drive, opts...))
+	t.Cleanup(server.Close)
+	return server, drive
+}
+
+func do(t *testing.T, method, url string, body io.Reader, header ...string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp, string(data)
+}
+
+func names(infos []os.FileInfo) string {
+	var out []string
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() {
+			name += "/"
+		}
+		out = append(out, name)
+	}
+	return strings.Join(out, " ")
+}
+
+func TestReadDirAndStat(t *testing.T) {
+	server, _ := newTestServer(t)
+	c := gowebdav.NewClient(server.URL, "", "")
+
+	infos, err := c.ReadDir("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(infos); got != "docs/ hello.txt" {
+		t.Errorf("Unexpected listing %q", got)
+	}
+
+	info, err := c.Stat("/hello.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != 13 || info.IsDir() || !info.ModTime().Equal(memModified) {
+		t.Errorf("Unexpected file properties %d %v %v", info.Size(), info.IsDir(), info.ModTime())
+	}
+	if file, ok := info.(*gowebdav.File); !ok || file.ContentType() != "text/plain; charset=utf-8" || file.ETag() == "" {
+		t.Errorf("Expected the content type and ETag without reading the file, got %+v", info)
+	}
+
+	if _, err := c.Stat("/missing"); !gowebdav.IsErrNotFound(err) {
+		t.Errorf("Expected not found, got %v", err)
+	}
+	if status, _ := do(t, "PROPFIND", server.URL+"/", nil, "Depth", "infinity"); status.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected Depth infinity to be refused, got %d", status.StatusCode)
+	}
+}
+
+func TestReadRange(t *testing.T) {
+	server, _ := newTestServer(t)
+	c := gowebdav.NewClient(server.URL, "", "")
+
+	if data, err := c.Read("/hello.txt"); err != nil || string(data) != "hello, world\n" {
+		t.Errorf("Expected the file, got %q %v", data, err)
+	}
+	stream, err := c.ReadStreamRange("/hello.txt", 7, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(stream)
+	stream.Close()
+	if string(data) != "world" {
+		t.Errorf("Expected a partial read, got %q", data)
+	}
+	if _, err := c.Read("/docs"); err == nil {
+		t.Error("Expected reading a folder to fail")
+	}
+}
+
+func TestWriteMethods(t *testing.T) {
+	server, drive := newTestServer(t)
+	c := gowebdav.NewClient(server.URL, "", "")
+
+	if err := c.Mkdir("/new", 0o755); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+	// The client takes 405 for success, so this is checked on the wire.
+	if resp, _ := do(t, "MKCOL", server.URL+"/new", nil); resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("MKCOL on an existing folder: expected 405, got %d", resp.StatusCode)
+	}
+	if err := c.Mkdir("/missing/new", 0o755); !gowebdav.IsErrCode(err, http.StatusConflict) {
+		t.Errorf("Mkdir without a parent: expected 409, got %v", err)
+	}
+
+	if err := c.Write("/new/b.txt", []byte("bravo"), 0o644); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := c.Write("/new/b.txt", []byte("bravo 2"), 0o644); err != nil {
+		t.Fatalf("Write over a file: %v", err)
+	}
+	if data, _ := c.Read("/new/b.txt"); string(data) != "bravo 2" {
+		t.Errorf("Expected the replaced content, got %q", data)
+	}
+
+	if err := c.Rename("/new/b.txt", "/docs/c.txt", false); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+	if data, _ := c.Read("/docs/c.txt"); string(data) != "bravo 2" {
+		t.Errorf("Expected the moved file, got %q", data)
+	}
+	if err := c.Rename("/docs/c.txt", "/docs/a.txt", false); !gowebdav.IsErrCode(err, http.StatusPreconditionFailed) {
+		t.Errorf("Rename without overwrite: expected 412, got %v", err)
+	}
+
+	if err := c.Remove("/docs/a.txt"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	trashed := 0
+	for _, f := range drive.files {
+		if f.trashed {
+			trashed++
+		}
+	}
+	if trashed != 2 {
+		t.Errorf("Expected the replaced and the deleted file in the trash, got %d", trashed)
+	}
+	if err := c.Remove("/"); err == nil {
+		t.Error("Expected removing the root to fail")
+	}
+}
+
+func TestLock(t *testing.T) {
+	server, _ := newTestServer(t)
+	lockBody := `<?xml version="1.0" encoding="utf-8"?><D:lockinfo xmlns:D="DAV:"><D:lockscope><D:exclusive/></D:lockscope><D:locktype><D:write/></D:locktype></D:lockinfo>`
+
+	resp, _ := do(t, "LOCK", server.URL+"/docs/a.txt", strings.NewReader(lockBody), "Timeout", "Second-60")
+	token := resp.Header.Get("Lock-Token")
+	if resp.StatusCode != http.StatusOK || token == "" {
+		t.Fatalf("Expected a lock, got %d %q", resp.StatusCode, token)
+	}
+	if resp, _ := do(t, http.MethodPut, server.URL+"/docs/a.txt", strings.NewReader("x")); resp.StatusCode != http.StatusLocked {
+		t.Errorf("Expected a write without the token to be refused, got %d", resp.StatusCode)
+	}
+	if resp, _ := do(t, http.MethodPut, server.URL+"/docs/a.txt", strings.NewReader("x"), "If", "("+token+")"); resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected a write with the token to succeed, got %d", resp.StatusCode)
+	}
+	if resp, _ := do(t, "UNLOCK", server.URL+"/docs/a.txt", nil, "Lock-Token", token); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected the lock to be released, got %d", resp.StatusCode)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	server, _ := newTestServer(t, WithReadOnly(true))
+	c := gowebdav.NewClient(server.URL, "", "")
+
+	if err := c.Mkdir("/new", 0o755); !gowebdav.IsErrCode(err, http.StatusForbidden) {
+		t.Errorf("Mkdir: expected 403, got %v", err)
+	}
+	if err := c.Write("/docs/a.txt", []byte("x"), 0o644); !gowebdav.IsErrCode(err, http.StatusForbidden) {
+		t.Errorf("Write: expected 403, got %v", err)
+	}
+	if err := c.Remove("/docs/a.txt"); !gowebdav.IsErrCode(err, http.StatusForbidden) {
+		t.Errorf("Remove: expected 403, got %v", err)
+	}
+	if err := c.Rename("/docs/a.txt", "/b.txt", true); !gowebdav.IsErrCode(err, http.StatusForbidden) {
+		t.Errorf("Rename: expected 403, got %v", err)
+	}
+	if resp, _ := do(t, "LOCK", server.URL+"/docs/a.txt", nil); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("LOCK: expected 403, got %d", resp.StatusCode)
+	}
+	if data, err := c.Read("/docs/a.txt"); err != nil || string(data) != "alpha" {
+		t.Errorf("Expected reads to work, got %q %v", data, err)
+	}
+}
+
+// recordingLogger keeps the Error and Debug messages it is given.
+type recordingLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) record(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+func (l *recordingLogger) Debugf(format string, args ...interface{}) { l.record(format, args...) }
+func (l *recordingLogger) Infof(format string, args ...interface{})  {}
+func (l *recordingLogger) Warnf(format string, args ...interface{})  {}
+func (l *recordingLogger) Errorf(format string, args ...interface{}) { l.record(format, args...) }
+
+func TestErrorsStayServerSide(t *testing.T) {
+	logger := &recordingLogger{}
+	server, drive := newTestServer(t, WithLogger(logger))
+	drive.uploadErr = errors.New("upload rejected for token secret-123")
+
+	resp, body := do(t, http.MethodPut, server.URL+"/docs/b.txt", strings.NewReader("bravo"))
+	if resp.StatusCode < 400 || strings.Contains(body, "secret-123") {
+		t.Fatalf("Expected a failure without the error text, got %d %q", resp.StatusCode, body)
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.messages) != 1 || !strings.Contains(logger.messages[0], "PUT /docs/b.txt") || !strings.Contains(logger.messages[0], "secret-123") {
+		t.Errorf("Expected the error to be logged, got %q", logger.messages)
+	}
+}
+
+func TestBasicAuthAndPrefix(t *testing.T) {
+	server, _ := newTestServer(t, WithBasicAuth("me", "secret"), WithPrefix("/dav/"))
+
+	if _, err := gowebdav.NewClient(server.URL+"/dav", "me", "wrong").ReadDir("/"); err == nil {
+		t.Error("Expected a wrong password to be refused")
+	}
+
+	c := gowebdav.NewClient(server.URL+"/dav", "me", "secret")
+	infos, err := c.ReadDir("/docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(infos); got != "a.txt" {
+		t.Errorf("Unexpected listing %q", got)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/hello.txt", nil)
+	req.SetBasicAuth("me", "secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 outside the prefix, got %d", resp.StatusCode)
+	}
+}